@@ -11,6 +11,7 @@ skip_regexes=()
 skip_regexes+=("^LICENSE$")
 skip_regexes+=("^NOTICE$")
 skip_regexes+=("^README.md$")
+skip_regexes+=("^COMPATIBILITY.md$")
 skip_regexes+=("^Third_Party_Code/.*$")
 skip_regexes+=("^\.gitignore$")
 skip_regexes+=("^\.notices.tpl$")
@@ -0,0 +1,156 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	apiUrlAaaApiTokens       = apiUrlAaaPrefix + "api-tokens"
+	apiUrlAaaApiTokensPrefix = apiUrlAaaApiTokens + apiUrlPathDelim
+	apiUrlAaaApiTokenById    = apiUrlAaaApiTokensPrefix + "%s"
+)
+
+// ApiTokenRequest creates an API token belonging to a user. A nil ExpiresAt
+// requests a token which doesn't expire, subject to server policy.
+type ApiTokenRequest struct {
+	Label     string
+	UserId    ObjectId
+	ExpiresAt *time.Time
+}
+
+func (o *ApiTokenRequest) raw() *rawApiTokenRequest {
+	return &rawApiTokenRequest{
+		Label:     o.Label,
+		UserId:    o.UserId,
+		ExpiresAt: o.ExpiresAt,
+	}
+}
+
+type rawApiTokenRequest struct {
+	Label     string     `json:"label"`
+	UserId    ObjectId   `json:"user_id"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type ApiTokenData struct {
+	Label     string
+	UserId    ObjectId
+	ExpiresAt *time.Time
+}
+
+// ApiToken describes an API token. The token value itself is returned only by
+// CreateApiToken.
+type ApiToken struct {
+	Id         ObjectId
+	CreatedAt  *time.Time
+	LastUsedAt *time.Time
+	Data       *ApiTokenData
+}
+
+type rawApiToken struct {
+	Id         ObjectId   `json:"id"`
+	Label      string     `json:"label"`
+	UserId     ObjectId   `json:"user_id"`
+	CreatedAt  *time.Time `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func (o *rawApiToken) polish() *ApiToken {
+	return &ApiToken{
+		Id:         o.Id,
+		CreatedAt:  o.CreatedAt,
+		LastUsedAt: o.LastUsedAt,
+		Data: &ApiTokenData{
+			Label:     o.Label,
+			UserId:    o.UserId,
+			ExpiresAt: o.ExpiresAt,
+		},
+	}
+}
+
+// GetAllApiTokens returns every API token visible to the client's user
+func (o *Client) GetAllApiTokens(ctx context.Context) ([]ApiToken, error) {
+	if err := o.checkSupports("Client.GetAllApiTokens"); err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Items []rawApiToken `json:"items"`
+	}
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      apiUrlAaaApiTokens,
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+
+	result := make([]ApiToken, len(response.Items))
+	for i, raw := range response.Items {
+		result[i] = *raw.polish()
+	}
+	return result, nil
+}
+
+// GetApiToken returns the API token with the given ID
+func (o *Client) GetApiToken(ctx context.Context, id ObjectId) (*ApiToken, error) {
+	if err := o.checkSupports("Client.GetApiToken"); err != nil {
+		return nil, err
+	}
+
+	var response rawApiToken
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      fmt.Sprintf(apiUrlAaaApiTokenById, id),
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+	return response.polish(), nil
+}
+
+// CreateApiToken creates an API token, returning its ID and value. The value
+// can't be retrieved later.
+func (o *Client) CreateApiToken(ctx context.Context, in *ApiTokenRequest) (ObjectId, string, error) {
+	if err := o.checkSupports("Client.CreateApiToken"); err != nil {
+		return "", "", err
+	}
+
+	var response struct {
+		Id    ObjectId `json:"id"`
+		Token string   `json:"token"`
+	}
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodPost,
+		urlStr:      apiUrlAaaApiTokens,
+		apiInput:    in.raw(),
+		apiResponse: &response,
+	})
+	if err != nil {
+		return "", "", convertTtaeToAceWherePossible(err)
+	}
+	return response.Id, response.Token, nil
+}
+
+// DeleteApiToken revokes the API token with the given ID
+func (o *Client) DeleteApiToken(ctx context.Context, id ObjectId) error {
+	if err := o.checkSupports("Client.DeleteApiToken"); err != nil {
+		return err
+	}
+
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method: http.MethodDelete,
+		urlStr: fmt.Sprintf(apiUrlAaaApiTokenById, id),
+	})
+	return convertTtaeToAceWherePossible(err)
+}
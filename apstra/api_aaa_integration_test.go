@@ -0,0 +1,157 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrudUsersAndRoles(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			myPermissions, err := client.client.GetCurrentUserPermissions(ctx)
+			require.NoError(t, err)
+			require.NotEmpty(t, myPermissions)
+
+			roleData := RoleData{
+				Label:       "role-" + randString(6, "hex"),
+				Description: "test role",
+				Permissions: myPermissions[:1],
+			}
+
+			log.Printf("testing CreateRole() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			roleId, err := client.client.CreateRole(ctx, &roleData)
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, client.client.DeleteRole(ctx, roleId)) })
+
+			role, err := client.client.GetRoleByLabel(ctx, roleData.Label)
+			require.NoError(t, err)
+			require.Equal(t, roleId, role.Id)
+			require.Equal(t, roleData.Permissions, role.Data.Permissions)
+
+			password, err := randomPassword(rotatedPasswordLength)
+			require.NoError(t, err)
+
+			userRequest := UserRequest{
+				Username:  "user-" + randString(6, "hex"),
+				Password:  password,
+				FirstName: "Test",
+				LastName:  "User",
+				Roles:     []ObjectId{roleId},
+			}
+
+			log.Printf("testing CreateUser() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			userId, err := client.client.CreateUser(ctx, &userRequest)
+			require.NoError(t, err)
+
+			user, err := client.client.GetUserByName(ctx, userRequest.Username)
+			require.NoError(t, err)
+			require.Equal(t, userId, user.Id)
+			require.Equal(t, userRequest.Roles, user.Data.Roles)
+
+			permissions, err := client.client.GetUserPermissions(ctx, userId)
+			require.NoError(t, err)
+			require.Equal(t, roleData.Permissions, permissions)
+
+			log.Printf("testing UpdateUser() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			userRequest.Email = "test@example.com"
+			require.NoError(t, client.client.UpdateUser(ctx, userId, &userRequest))
+			user, err = client.client.GetUser(ctx, userId)
+			require.NoError(t, err)
+			require.Equal(t, userRequest.Email, user.Data.Email)
+
+			log.Printf("testing RotateUserPassword() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			newPassword, err := client.client.RotateUserPassword(ctx, userId, password)
+			require.NoError(t, err)
+			require.NotEqual(t, password, newPassword)
+
+			log.Printf("testing DeleteUser() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, client.client.DeleteUser(ctx, userId))
+			_, err = client.client.GetUser(ctx, userId)
+			var ace ClientErr
+			require.ErrorAs(t, err, &ace)
+			require.Equal(t, ErrNotfound, ace.Type())
+		})
+	}
+}
+
+// TestCrudApiTokens exercises the API token endpoint, which appeared in
+// Apstra 5.0.0. Against earlier versions it checks that the SDK refuses the
+// call rather than hitting a nonexistent endpoint.
+func TestCrudApiTokens(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			if !client.client.Supports("Client.CreateApiToken") {
+				_, err := client.client.GetAllApiTokens(ctx)
+				var ace ClientErr
+				require.ErrorAs(t, err, &ace)
+				require.Equal(t, ErrCompatibility, ace.Type())
+				t.Skipf("skipping Apstra %s: API tokens not supported", client.client.apiVersion)
+			}
+
+			expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+			request := ApiTokenRequest{
+				Label:     "token-" + randString(6, "hex"),
+				UserId:    client.client.ID(),
+				ExpiresAt: &expires,
+			}
+
+			log.Printf("testing CreateApiToken() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			id, value, err := client.client.CreateApiToken(ctx, &request)
+			require.NoError(t, err)
+			require.NotEmpty(t, id)
+			require.NotEmpty(t, value)
+
+			log.Printf("testing GetApiToken() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			token, err := client.client.GetApiToken(ctx, id)
+			require.NoError(t, err)
+			require.Equal(t, id, token.Id)
+			require.Equal(t, request.Label, token.Data.Label)
+			require.Equal(t, request.UserId, token.Data.UserId)
+			require.NotNil(t, token.Data.ExpiresAt)
+			require.True(t, expires.Equal(*token.Data.ExpiresAt))
+
+			log.Printf("testing GetAllApiTokens() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			tokens, err := client.client.GetAllApiTokens(ctx)
+			require.NoError(t, err)
+			var found bool
+			for _, token := range tokens {
+				found = found || token.Id == id
+			}
+			require.True(t, found, "token %q not found in GetAllApiTokens() result", id)
+
+			log.Printf("testing DeleteApiToken() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, client.client.DeleteApiToken(ctx, id))
+			_, err = client.client.GetApiToken(ctx, id)
+			var ace ClientErr
+			require.ErrorAs(t, err, &ace)
+			require.Equal(t, ErrNotfound, ace.Type())
+		})
+	}
+}
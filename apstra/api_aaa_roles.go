@@ -0,0 +1,198 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+const (
+	apiUrlAaaRoles       = apiUrlAaaPrefix + "roles"
+	apiUrlAaaRolesPrefix = apiUrlAaaRoles + apiUrlPathDelim
+	apiUrlAaaRoleById    = apiUrlAaaRolesPrefix + "%s"
+)
+
+// RoleData describes a role: a named set of permissions which may be granted
+// to users.
+type RoleData struct {
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type Role struct {
+	Id       ObjectId
+	Data     *RoleData
+	ReadOnly bool // built-in roles can't be modified
+}
+
+type rawRole struct {
+	Id          ObjectId `json:"id"`
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	CanEdit     *bool    `json:"can_edit,omitempty"`
+}
+
+func (o *rawRole) polish() *Role {
+	return &Role{
+		Id:       o.Id,
+		ReadOnly: o.CanEdit != nil && !*o.CanEdit,
+		Data: &RoleData{
+			Label:       o.Label,
+			Description: o.Description,
+			Permissions: o.Permissions,
+		},
+	}
+}
+
+func (o *Client) getAllRoles(ctx context.Context) ([]rawRole, error) {
+	var response struct {
+		Items []rawRole `json:"items"`
+	}
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      apiUrlAaaRoles,
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+	return response.Items, nil
+}
+
+// GetAllRoles returns every role known to Apstra, including built-in roles
+func (o *Client) GetAllRoles(ctx context.Context) ([]Role, error) {
+	rawRoles, err := o.getAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Role, len(rawRoles))
+	for i, raw := range rawRoles {
+		result[i] = *raw.polish()
+	}
+	return result, nil
+}
+
+// GetRole returns the role with the given ID
+func (o *Client) GetRole(ctx context.Context, id ObjectId) (*Role, error) {
+	var response rawRole
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      fmt.Sprintf(apiUrlAaaRoleById, id),
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+	return response.polish(), nil
+}
+
+// GetRoleByLabel returns the role with the given label
+func (o *Client) GetRoleByLabel(ctx context.Context, label string) (*Role, error) {
+	rawRoles, err := o.getAllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range rawRoles {
+		if raw.Label == label {
+			return raw.polish(), nil
+		}
+	}
+
+	return nil, ClientErr{
+		errType: ErrNotfound,
+		err:     fmt.Errorf("role with label %q not found", label),
+	}
+}
+
+// CreateRole creates a role and returns its ID
+func (o *Client) CreateRole(ctx context.Context, in *RoleData) (ObjectId, error) {
+	var response objectIdResponse
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodPost,
+		urlStr:      apiUrlAaaRoles,
+		apiInput:    in,
+		apiResponse: &response,
+	})
+	if err != nil {
+		return "", convertTtaeToAceWherePossible(err)
+	}
+	return response.Id, nil
+}
+
+// UpdateRole replaces the role's label, description and permissions
+func (o *Client) UpdateRole(ctx context.Context, id ObjectId, in *RoleData) error {
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:   http.MethodPut,
+		urlStr:   fmt.Sprintf(apiUrlAaaRoleById, id),
+		apiInput: in,
+	})
+	return convertTtaeToAceWherePossible(err)
+}
+
+// DeleteRole deletes the role with the given ID
+func (o *Client) DeleteRole(ctx context.Context, id ObjectId) error {
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method: http.MethodDelete,
+		urlStr: fmt.Sprintf(apiUrlAaaRoleById, id),
+	})
+	return convertTtaeToAceWherePossible(err)
+}
+
+// GetUserPermissions returns the union of the permissions granted to the user
+// by each of its roles, sorted.
+func (o *Client) GetUserPermissions(ctx context.Context, userId ObjectId) ([]string, error) {
+	user, err := o.GetUser(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching user %q - %w", userId, err)
+	}
+
+	rawRoles, err := o.getAllRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching roles - %w", err)
+	}
+
+	rolesById := make(map[ObjectId]rawRole, len(rawRoles))
+	for _, raw := range rawRoles {
+		rolesById[raw.Id] = raw
+	}
+
+	return effectivePermissions(user.Data.Roles, rolesById)
+}
+
+// GetCurrentUserPermissions returns the permissions of the user the client
+// logged in as. See GetUserPermissions.
+func (o *Client) GetCurrentUserPermissions(ctx context.Context) ([]string, error) {
+	return o.GetUserPermissions(ctx, o.id)
+}
+
+func effectivePermissions(roleIds []ObjectId, rolesById map[ObjectId]rawRole) ([]string, error) {
+	permissions := make(map[string]struct{})
+	for _, roleId := range roleIds {
+		role, ok := rolesById[roleId]
+		if !ok {
+			return nil, ClientErr{
+				errType: ErrNotfound,
+				err:     fmt.Errorf("role %q not found", roleId),
+			}
+		}
+		for _, permission := range role.Permissions {
+			permissions[permission] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(permissions))
+	for permission := range permissions {
+		result = append(result, permission)
+	}
+	sort.Strings(result)
+	return result, nil
+}
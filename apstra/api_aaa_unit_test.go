@@ -0,0 +1,80 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomPassword(t *testing.T) {
+	_, err := randomPassword(3)
+	require.Error(t, err)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		password, err := randomPassword(rotatedPasswordLength)
+		require.NoError(t, err)
+		require.Len(t, password, rotatedPasswordLength)
+		require.False(t, seen[password])
+		seen[password] = true
+
+		for _, class := range []string{rotatedPasswordLower, rotatedPasswordUpper, rotatedPasswordDigits, rotatedPasswordSymbol} {
+			require.True(t, strings.ContainsAny(password, class), "password %q lacks any of %q", password, class)
+		}
+	}
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	rolesById := map[ObjectId]rawRole{
+		"viewer":   {Id: "viewer", Permissions: []string{"blueprint.read", "design.read"}},
+		"operator": {Id: "operator", Permissions: []string{"blueprint.read", "blueprint.write"}},
+	}
+
+	result, err := effectivePermissions([]ObjectId{"viewer", "operator"}, rolesById)
+	require.NoError(t, err)
+	require.Equal(t, []string{"blueprint.read", "blueprint.write", "design.read"}, result)
+
+	result, err = effectivePermissions(nil, rolesById)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	_, err = effectivePermissions([]ObjectId{"bogus"}, rolesById)
+	var ace ClientErr
+	require.ErrorAs(t, err, &ace)
+	require.Equal(t, ErrNotfound, ace.Type())
+}
+
+func TestApiTokenRawPolish(t *testing.T) {
+	var raw rawApiToken
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "token1",
+		"label": "ci",
+		"user_id": "user1",
+		"created_at": "2024-01-02T03:04:05Z",
+		"expires_at": null,
+		"last_used_at": "2024-02-03T04:05:06Z"
+	}`), &raw))
+
+	token := raw.polish()
+	require.Equal(t, ObjectId("token1"), token.Id)
+	require.Equal(t, "ci", token.Data.Label)
+	require.Equal(t, ObjectId("user1"), token.Data.UserId)
+	require.Nil(t, token.Data.ExpiresAt)
+	require.NotNil(t, token.LastUsedAt)
+
+	request, err := json.Marshal((&ApiTokenRequest{Label: "ci", UserId: "user1"}).raw())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"label": "ci", "user_id": "user1"}`, string(request))
+
+	expires := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	request, err = json.Marshal((&ApiTokenRequest{Label: "ci", UserId: "user1", ExpiresAt: &expires}).raw())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"label": "ci", "user_id": "user1", "expires_at": "2025-01-01T00:00:00Z"}`, string(request))
+}
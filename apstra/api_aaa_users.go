@@ -0,0 +1,316 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	apiUrlAaa                   = "/api/aaa"
+	apiUrlAaaPrefix             = apiUrlAaa + apiUrlPathDelim
+	apiUrlAaaUsers              = apiUrlAaaPrefix + "users"
+	apiUrlAaaUsersPrefix        = apiUrlAaaUsers + apiUrlPathDelim
+	apiUrlAaaUserById           = apiUrlAaaUsersPrefix + "%s"
+	apiUrlAaaUserChangePassword = apiUrlAaaUserById + apiUrlPathDelim + "change-password"
+
+	rotatedPasswordLength = 24
+	rotatedPasswordLower  = "abcdefghijklmnopqrstuvwxyz"
+	rotatedPasswordUpper  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	rotatedPasswordDigits = "0123456789"
+	rotatedPasswordSymbol = "!#%*+-=?@^_"
+)
+
+// UserRequest creates or updates a local user. Password is required by
+// CreateUser, and ignored by UpdateUser (use ChangeUserPassword).
+type UserRequest struct {
+	Username  string
+	Password  string
+	FirstName string
+	LastName  string
+	Email     string
+	Roles     []ObjectId
+}
+
+func (o *UserRequest) raw() *rawUserRequest {
+	roles := o.Roles
+	if roles == nil {
+		roles = []ObjectId{}
+	}
+
+	return &rawUserRequest{
+		Username:  o.Username,
+		Password:  o.Password,
+		FirstName: o.FirstName,
+		LastName:  o.LastName,
+		Email:     o.Email,
+		Roles:     roles,
+	}
+}
+
+type rawUserRequest struct {
+	Username  string     `json:"username"`
+	Password  string     `json:"password,omitempty"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	Email     string     `json:"email"`
+	Roles     []ObjectId `json:"roles"`
+}
+
+type UserData struct {
+	Username  string
+	FirstName string
+	LastName  string
+	Email     string
+	Roles     []ObjectId
+	UserType  string // "local", or the name of the remote authentication provider
+}
+
+type User struct {
+	Id             ObjectId
+	CreatedAt      *time.Time
+	LastModifiedAt *time.Time
+	Data           *UserData
+}
+
+type rawUser struct {
+	Id             ObjectId   `json:"id"`
+	Username       string     `json:"username"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	Email          string     `json:"email"`
+	Roles          []ObjectId `json:"roles"`
+	UserType       string     `json:"user_type"`
+	CreatedAt      *time.Time `json:"created_at"`
+	LastModifiedAt *time.Time `json:"last_modified_at"`
+}
+
+func (o *rawUser) polish() *User {
+	return &User{
+		Id:             o.Id,
+		CreatedAt:      o.CreatedAt,
+		LastModifiedAt: o.LastModifiedAt,
+		Data: &UserData{
+			Username:  o.Username,
+			FirstName: o.FirstName,
+			LastName:  o.LastName,
+			Email:     o.Email,
+			Roles:     o.Roles,
+			UserType:  o.UserType,
+		},
+	}
+}
+
+func (o *Client) getAllUsers(ctx context.Context) ([]rawUser, error) {
+	var response struct {
+		Items []rawUser `json:"items"`
+	}
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      apiUrlAaaUsers,
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+	return response.Items, nil
+}
+
+// GetAllUsers returns every user known to Apstra
+func (o *Client) GetAllUsers(ctx context.Context) ([]User, error) {
+	rawUsers, err := o.getAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]User, len(rawUsers))
+	for i, raw := range rawUsers {
+		result[i] = *raw.polish()
+	}
+	return result, nil
+}
+
+// GetUser returns the user with the given ID
+func (o *Client) GetUser(ctx context.Context, id ObjectId) (*User, error) {
+	var response rawUser
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      fmt.Sprintf(apiUrlAaaUserById, id),
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+	return response.polish(), nil
+}
+
+// GetUserByName returns the user with the given username
+func (o *Client) GetUserByName(ctx context.Context, username string) (*User, error) {
+	rawUsers, err := o.getAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range rawUsers {
+		if raw.Username == username {
+			return raw.polish(), nil
+		}
+	}
+
+	return nil, ClientErr{
+		errType: ErrNotfound,
+		err:     fmt.Errorf("user with username %q not found", username),
+	}
+}
+
+// GetCurrentUser returns the user the client logged in as
+func (o *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	if o.id == "" {
+		return nil, errors.New("client has not logged in")
+	}
+	return o.GetUser(ctx, o.id)
+}
+
+// CreateUser creates a local user and returns its ID
+func (o *Client) CreateUser(ctx context.Context, in *UserRequest) (ObjectId, error) {
+	if in.Password == "" {
+		return "", errors.New("password is required when creating a user")
+	}
+
+	var response objectIdResponse
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodPost,
+		urlStr:      apiUrlAaaUsers,
+		apiInput:    in.raw(),
+		apiResponse: &response,
+	})
+	if err != nil {
+		return "", convertTtaeToAceWherePossible(err)
+	}
+	return response.Id, nil
+}
+
+// UpdateUser updates a local user. The password is not changed.
+func (o *Client) UpdateUser(ctx context.Context, id ObjectId, in *UserRequest) error {
+	raw := in.raw()
+	raw.Password = ""
+
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:   http.MethodPut,
+		urlStr:   fmt.Sprintf(apiUrlAaaUserById, id),
+		apiInput: raw,
+	})
+	return convertTtaeToAceWherePossible(err)
+}
+
+// DeleteUser deletes a local user
+func (o *Client) DeleteUser(ctx context.Context, id ObjectId) error {
+	if id == o.id {
+		return errors.New("refusing to delete the user the client is logged in as")
+	}
+
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method: http.MethodDelete,
+		urlStr: fmt.Sprintf(apiUrlAaaUserById, id),
+	})
+	return convertTtaeToAceWherePossible(err)
+}
+
+// ChangeUserPassword changes the password of a local user. When the user is
+// the one the client logged in as, the client's configuration is updated so
+// that future logins use the new password. Clients configured with a
+// CredentialProvider rely on the provider to return the new password.
+func (o *Client) ChangeUserPassword(ctx context.Context, id ObjectId, currentPassword, newPassword string) error {
+	apiInput := struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}{
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	}
+
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:   http.MethodPut,
+		urlStr:   fmt.Sprintf(apiUrlAaaUserChangePassword, id),
+		apiInput: &apiInput,
+	})
+	if err != nil {
+		return convertTtaeToAceWherePossible(err)
+	}
+
+	if id == o.id && o.cfg.CredentialProvider == nil {
+		o.lock(mutexKeyCredentials)
+		o.cfg.Pass = newPassword
+		o.unlock(mutexKeyCredentials)
+	}
+
+	return nil
+}
+
+// RotateUserPassword replaces the password of a local user with a randomly
+// generated one, which it returns. See ChangeUserPassword.
+func (o *Client) RotateUserPassword(ctx context.Context, id ObjectId, currentPassword string) (string, error) {
+	newPassword, err := randomPassword(rotatedPasswordLength)
+	if err != nil {
+		return "", err
+	}
+
+	err = o.ChangeUserPassword(ctx, id, currentPassword, newPassword)
+	if err != nil {
+		return "", err
+	}
+
+	return newPassword, nil
+}
+
+// randomPassword returns a password of the given length (at least 4) which
+// includes lower case, upper case, digit and symbol characters.
+func randomPassword(length int) (string, error) {
+	classes := []string{rotatedPasswordLower, rotatedPasswordUpper, rotatedPasswordDigits, rotatedPasswordSymbol}
+	if length < len(classes) {
+		return "", fmt.Errorf("password length must be at least %d", len(classes))
+	}
+
+	randomIndex := func(n int) (int, error) {
+		i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+		if err != nil {
+			return 0, fmt.Errorf("failed generating random password - %w", err)
+		}
+		return int(i.Int64()), nil
+	}
+
+	// one character from each class, the rest from any class
+	all := strings.Join(classes, "")
+	result := make([]byte, length)
+	for i := range result {
+		charset := all
+		if i < len(classes) {
+			charset = classes[i]
+		}
+		j, err := randomIndex(len(charset))
+		if err != nil {
+			return "", err
+		}
+		result[i] = charset[j]
+	}
+
+	// shuffle so the guaranteed characters aren't always first
+	for i := len(result) - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return string(result), nil
+}
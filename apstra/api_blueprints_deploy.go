@@ -45,6 +45,9 @@ func (o DeployStatus) String() string {
 	case DeployStatusFailure:
 		return string(deployStatusFailure)
 	default:
+		if s, ok := lenientEnumString[deployStatus](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(deployStatusUnknown, o)
 	}
 }
@@ -60,6 +63,9 @@ func (o deployStatus) parse() (int, error) {
 	case deployStatusFailure:
 		return int(DeployStatusFailure), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(DeployStatusUnknown, o)
 	}
 }
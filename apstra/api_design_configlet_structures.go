@@ -55,12 +55,15 @@ func (o PlatformOS) String() string {
 	case PlatformOSSonic:
 		return string(platformOSSonic)
 	default:
+		if s, ok := lenientEnumString[platformOS](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(platformOSUnknown, o)
 	}
 }
 
 func (o *PlatformOS) FromString(s string) error {
-	i, err := platformOS(s).parse()
+	i, err := parseKnown(platformOS(s))
 	if err != nil {
 		return err
 	}
@@ -132,6 +135,9 @@ func (o platformOS) parse() (int, error) {
 	case platformOSSonic:
 		return int(PlatformOSSonic), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(PlatformOSUnknown, o)
 	}
 }
@@ -206,6 +212,9 @@ func (o ConfigletSection) String() string {
 	case ConfigletSectionDeleteBasedInterface:
 		return string(configletSectionDeleteBasedInterface)
 	default:
+		if s, ok := lenientEnumString[configletSection](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(configletSectionUnknown, o)
 	}
 }
@@ -239,12 +248,15 @@ func (o configletSection) parse() (int, error) {
 	case configletSectionDeleteBasedInterface:
 		return int(ConfigletSectionDeleteBasedInterface), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(ConfigletSectionUnknown, o)
 	}
 }
 
 func (o *ConfigletSection) FromString(s string) error {
-	i, err := configletSection(s).parse()
+	i, err := parseKnown(configletSection(s))
 	if err != nil {
 		return err
 	}
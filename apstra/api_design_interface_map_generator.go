@@ -0,0 +1,294 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// logicalDevicePort is a single port of a logical device, identified by its
+// (1-indexed) panel and port number, as used in InterfaceMapMapping.
+type logicalDevicePort struct {
+	panel int
+	port  int
+	speed LogicalDevicePortSpeed
+	roles LogicalDevicePortRoleFlags
+}
+
+// ports returns the panel's ports in index order. Port groups occupy
+// consecutive port indexes, so the layout and indexing order only matter when
+// relating a port index to a physical location.
+func (o *LogicalDevicePanel) ports(panel int) []logicalDevicePort {
+	start := o.PortIndexing.StartIndex
+	if start < 1 {
+		start = 1
+	}
+
+	var result []logicalDevicePort
+	for _, pg := range o.PortGroups {
+		for i := 0; i < pg.Count; i++ {
+			result = append(result, logicalDevicePort{
+				panel: panel,
+				port:  start + len(result),
+				speed: pg.Speed,
+				roles: pg.Roles,
+			})
+		}
+	}
+	return result
+}
+
+// sortPortsByIndexing orders device profile ports by panel, then by row and
+// column according to the logical device panel's port indexing order.
+func sortPortsByIndexing(ports []PortInfo, indexing LogicalDevicePortIndexing) {
+	sort.SliceStable(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+		if a.SlotId != b.SlotId {
+			return a.SlotId < b.SlotId
+		}
+		if a.PanelId != b.PanelId {
+			return a.PanelId < b.PanelId
+		}
+		switch indexing.Order {
+		case PortIndexingHorizontalFirst: // top-to-bottom, then left-to-right
+			if a.ColumnId != b.ColumnId {
+				return a.ColumnId < b.ColumnId
+			}
+			if a.RowId != b.RowId {
+				return a.RowId < b.RowId
+			}
+		default: // left-to-right, then top-to-bottom
+			if a.RowId != b.RowId {
+				return a.RowId < b.RowId
+			}
+			if a.ColumnId != b.ColumnId {
+				return a.ColumnId < b.ColumnId
+			}
+		}
+		return a.PortId < b.PortId
+	})
+}
+
+// activeInterfaces returns the transformation's active interfaces, or nil if
+// any active interface runs at a speed other than speed.
+func (o *Transformation) activeInterfaces(speed LogicalDevicePortSpeed) []TransformInterface {
+	var result []TransformInterface
+	for _, intf := range o.Interfaces {
+		if rawInterfaceState(intf.State) != rawInterfaceStateTrue {
+			continue
+		}
+		if !intf.Speed.IsEqual(speed) {
+			return nil
+		}
+		result = append(result, intf)
+	}
+	return result
+}
+
+// bestTransformation selects the transformation which delivers the most
+// interfaces at speed without exceeding limit. If every candidate exceeds
+// limit, the one with the fewest interfaces is selected. The default
+// transformation wins ties, followed by the lowest transformation ID. Returns
+// nil if no transformation delivers interfaces at speed.
+func (o *PortInfo) bestTransformation(speed LogicalDevicePortSpeed, limit int) *Transformation {
+	var result *Transformation
+	var resultCount int
+	for i, t := range o.Transformations {
+		count := len(t.activeInterfaces(speed))
+		if count == 0 {
+			continue
+		}
+		switch {
+		case result == nil,
+			count <= limit && (resultCount > limit || count > resultCount),
+			count > limit && resultCount > limit && count < resultCount,
+			count == resultCount && t.IsDefault && !result.IsDefault,
+			count == resultCount && t.IsDefault == result.IsDefault && t.TransformationId < result.TransformationId:
+			result = &o.Transformations[i]
+			resultCount = count
+		}
+	}
+	return result
+}
+
+// GenerateInterfaceMapData builds an interface map which maps every port of
+// the logical device onto an interface of the device profile.
+//
+// Logical device ports are assigned panel by panel in port index order. For
+// each panel, device profile ports are consumed in the panel's indexing order
+// (PortIndexingVerticalFirst or PortIndexingHorizontalFirst). For each device
+// profile port, the transformation yielding the most interfaces of the
+// required speed (preferably without exceeding the run of consecutive
+// same-speed ports) is chosen, so breakout transformations are used where
+// needed. Breakout interfaces beyond the end of a run, and device profile
+// ports left over (using their default transformation) are included, marked
+// inactive.
+//
+// If no mapping exists, the returned error explains which logical device port
+// could not be mapped and what the device profile offers at that speed.
+func GenerateInterfaceMapData(ldId ObjectId, ld *LogicalDeviceData, dpId ObjectId, dp *DeviceProfileData) (*InterfaceMapData, error) {
+	result := InterfaceMapData{
+		LogicalDeviceId: ldId,
+		DeviceProfileId: dpId,
+		Label:           ld.DisplayName + "__" + dp.Label,
+	}
+
+	used := make(map[int]bool) // keyed by device profile port ID
+
+	for panelIdx, panel := range ld.Panels {
+		ldPorts := panel.ports(panelIdx + 1)
+
+		dpPorts := append([]PortInfo{}, dp.Ports...)
+		sortPortsByIndexing(dpPorts, panel.PortIndexing)
+
+		for i := 0; i < len(ldPorts); {
+			// count the run of consecutive same-speed ports starting here
+			run := 1
+			for i+run < len(ldPorts) && ldPorts[i+run].speed.IsEqual(ldPorts[i].speed) {
+				run++
+			}
+
+			var dpPort *PortInfo
+			var transformation *Transformation
+			for j := range dpPorts {
+				if used[dpPorts[j].PortId] {
+					continue
+				}
+				if t := dpPorts[j].bestTransformation(ldPorts[i].speed, run); t != nil {
+					dpPort, transformation = &dpPorts[j], t
+					break
+				}
+			}
+			if dpPort == nil {
+				return nil, interfaceMapGenerationError(ld, dp, used, ldPorts[i])
+			}
+			used[dpPort.PortId] = true
+
+			for n, intf := range transformation.activeInterfaces(ldPorts[i].speed) {
+				if n >= run {
+					// breakout delivered more interfaces than this run of ports needs
+					result.Interfaces = append(result.Interfaces, inactiveInterfaceMapInterface(dpPort, transformation, intf))
+					continue
+				}
+				ldPort := ldPorts[i]
+				result.Interfaces = append(result.Interfaces, InterfaceMapInterface{
+					Name:  intf.Name,
+					Roles: ldPort.roles,
+					Mapping: InterfaceMapMapping{
+						DPPortId:      dpPort.PortId,
+						DPTransformId: transformation.TransformationId,
+						DPInterfaceId: intf.InterfaceId,
+						LDPanel:       ldPort.panel,
+						LDPort:        ldPort.port,
+					},
+					ActiveState: true,
+					Speed:       ldPort.speed,
+					Setting:     InterfaceMapInterfaceSetting{Param: intf.Setting},
+				})
+				i++
+			}
+		}
+	}
+
+	// include unused device profile ports as inactive
+	unused := append([]PortInfo{}, dp.Ports...)
+	sortPortsByIndexing(unused, LogicalDevicePortIndexing{})
+	for _, port := range unused {
+		if used[port.PortId] {
+			continue
+		}
+		transformation := port.DefaultTransform()
+		if transformation == nil {
+			continue
+		}
+		for _, intf := range transformation.Interfaces {
+			if rawInterfaceState(intf.State) != rawInterfaceStateTrue {
+				continue
+			}
+			result.Interfaces = append(result.Interfaces, inactiveInterfaceMapInterface(&port, transformation, intf))
+		}
+	}
+
+	for i := range result.Interfaces {
+		result.Interfaces[i].Position = i + 1
+	}
+
+	return &result, nil
+}
+
+// inactiveInterfaceMapInterface returns an interface map entry for a device
+// profile interface which isn't mapped to any logical device port.
+func inactiveInterfaceMapInterface(port *PortInfo, transformation *Transformation, intf TransformInterface) InterfaceMapInterface {
+	return InterfaceMapInterface{
+		Name:  intf.Name,
+		Roles: LogicalDevicePortRoleUnused,
+		Mapping: InterfaceMapMapping{
+			DPPortId:      port.PortId,
+			DPTransformId: transformation.TransformationId,
+			DPInterfaceId: intf.InterfaceId,
+			LDPanel:       -1,
+			LDPort:        -1,
+		},
+		ActiveState: false,
+		Speed:       intf.Speed,
+		Setting:     InterfaceMapInterfaceSetting{Param: intf.Setting},
+	}
+}
+
+// interfaceMapGenerationError explains why ldPort couldn't be mapped onto any
+// of the device profile's remaining ports.
+func interfaceMapGenerationError(ld *LogicalDeviceData, dp *DeviceProfileData, used map[int]bool, ldPort logicalDevicePort) error {
+	var required int
+	for _, panel := range ld.Panels {
+		for _, pg := range panel.PortGroups {
+			if pg.Speed.IsEqual(ldPort.speed) {
+				required += pg.Count
+			}
+		}
+	}
+
+	var capable, remaining int
+	speeds := make(map[int64]LogicalDevicePortSpeed)
+	for _, port := range dp.Ports {
+		var best int
+		for _, t := range port.Transformations {
+			if n := len(t.activeInterfaces(ldPort.speed)); n > best {
+				best = n
+			}
+			for _, intf := range t.Interfaces {
+				if rawInterfaceState(intf.State) == rawInterfaceStateTrue {
+					speeds[intf.Speed.BitsPerSecond()] = intf.Speed
+				}
+			}
+		}
+		capable += best
+		if !used[port.PortId] {
+			remaining += best
+		}
+	}
+
+	var speedStrings []string
+	for _, bps := range sortedSpeeds(speeds) {
+		speedStrings = append(speedStrings, string(speeds[bps]))
+	}
+
+	return fmt.Errorf("cannot map logical device %q panel %d port %d (%s) onto device profile %q: "+
+		"logical device requires %d %s ports, device profile offers at most %d %s interfaces (%d on remaining ports); "+
+		"device profile interface speeds: %s",
+		ld.DisplayName, ldPort.panel, ldPort.port, ldPort.speed, dp.Label,
+		required, ldPort.speed, capable, ldPort.speed, remaining,
+		strings.Join(speedStrings, ", "))
+}
+
+func sortedSpeeds(m map[int64]LogicalDevicePortSpeed) []int64 {
+	result := make([]int64, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateInterfaceMapData(t *testing.T) {
+	single := func(id int, isDefault bool, speed LogicalDevicePortSpeed, name string) Transformation {
+		return Transformation{
+			TransformationId: id,
+			IsDefault:        isDefault,
+			Interfaces: []TransformInterface{
+				{InterfaceId: 1, Name: name, Speed: speed, State: "active"},
+			},
+		}
+	}
+	breakout := func(id int, name string) Transformation {
+		result := Transformation{TransformationId: id}
+		for i := 1; i <= 4; i++ {
+			result.Interfaces = append(result.Interfaces, TransformInterface{
+				InterfaceId: i,
+				Name:        fmt.Sprintf("%s:%d", name, i-1),
+				Speed:       "10G",
+				State:       "active",
+			})
+		}
+		return result
+	}
+
+	// 2x10G ports on row 1, 2x40G (4x10G breakout capable) ports on row 2
+	dp := DeviceProfileData{
+		Label: "dp",
+		Ports: []PortInfo{
+			{PortId: 4, PanelId: 1, RowId: 2, ColumnId: 2, Transformations: []Transformation{single(1, true, "40G", "et-0/0/3"), breakout(2, "xe-0/0/3")}},
+			{PortId: 3, PanelId: 1, RowId: 2, ColumnId: 1, Transformations: []Transformation{single(1, true, "40G", "et-0/0/2"), breakout(2, "xe-0/0/2")}},
+			{PortId: 1, PanelId: 1, RowId: 1, ColumnId: 1, Transformations: []Transformation{single(1, true, "10G", "xe-0/0/0")}},
+			{PortId: 2, PanelId: 1, RowId: 1, ColumnId: 2, Transformations: []Transformation{single(1, true, "10G", "xe-0/0/1")}},
+		},
+	}
+
+	ld := func(groups ...LogicalDevicePortGroup) *LogicalDeviceData {
+		return &LogicalDeviceData{
+			DisplayName: "ld",
+			Panels: []LogicalDevicePanel{{
+				PortIndexing: LogicalDevicePortIndexing{Order: PortIndexingVerticalFirst, StartIndex: 1},
+				PortGroups:   groups,
+			}},
+		}
+	}
+
+	t.Run("breakout", func(t *testing.T) {
+		// 4x10G uses ports 1, 2 and two breakout interfaces of port 3; 1x40G uses port 4
+		result, err := GenerateInterfaceMapData("ld_id", ld(
+			LogicalDevicePortGroup{Count: 4, Speed: "10G", Roles: LogicalDevicePortRoleGeneric},
+			LogicalDevicePortGroup{Count: 1, Speed: "40G", Roles: LogicalDevicePortRoleSpine},
+		), "dp_id", &dp)
+		require.NoError(t, err)
+		require.Equal(t, "ld__dp", result.Label)
+
+		type summary struct {
+			name   string
+			active InterfaceStateActive
+			ldPort int
+			dpPort int
+		}
+		var summaries []summary
+		for i, intf := range result.Interfaces {
+			require.Equal(t, i+1, intf.Position)
+			summaries = append(summaries, summary{intf.Name, intf.ActiveState, intf.Mapping.LDPort, intf.Mapping.DPPortId})
+		}
+		require.Equal(t, []summary{
+			{"xe-0/0/0", true, 1, 1},
+			{"xe-0/0/1", true, 2, 2},
+			{"xe-0/0/2:0", true, 3, 3},
+			{"xe-0/0/2:1", true, 4, 3},
+			{"xe-0/0/2:2", false, -1, 3},
+			{"xe-0/0/2:3", false, -1, 3},
+			{"et-0/0/3", true, 5, 4},
+		}, summaries)
+		require.Equal(t, LogicalDevicePortRoleSpine, result.Interfaces[6].Roles)
+		require.Equal(t, LogicalDevicePortRoleUnused, result.Interfaces[4].Roles)
+	})
+
+	t.Run("unused", func(t *testing.T) {
+		result, err := GenerateInterfaceMapData("ld_id", ld(
+			LogicalDevicePortGroup{Count: 1, Speed: "40G", Roles: LogicalDevicePortRoleSpine},
+		), "dp_id", &dp)
+		require.NoError(t, err)
+		require.Len(t, result.Interfaces, 4)
+		require.Equal(t, "et-0/0/2", result.Interfaces[0].Name)
+		require.True(t, bool(result.Interfaces[0].ActiveState))
+		for _, intf := range result.Interfaces[1:] {
+			require.False(t, bool(intf.ActiveState))
+			require.Equal(t, -1, intf.Mapping.LDPort)
+		}
+	})
+
+	t.Run("impossible", func(t *testing.T) {
+		_, err := GenerateInterfaceMapData("ld_id", ld(
+			LogicalDevicePortGroup{Count: 3, Speed: "40G", Roles: LogicalDevicePortRoleSpine},
+		), "dp_id", &dp)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "panel 1 port 3 (40G)")
+		require.Contains(t, err.Error(), "requires 3 40G ports, device profile offers at most 2 40G interfaces")
+	})
+}
@@ -233,6 +233,8 @@ type InterfaceMapData struct {
 	DeviceProfileId ObjectId
 	Label           string
 	Interfaces      []InterfaceMapInterface
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 type InterfaceMap struct {
@@ -264,6 +266,23 @@ type rawInterfaceMap struct {
 	Id              ObjectId                   `json:"id,omitempty"`
 	Label           string                     `json:"label"`
 	Interfaces      []rawInterfaceMapInterface `json:"interfaces"`
+	unknownFields   unknownJsonFields
+}
+
+func (o *rawInterfaceMap) UnmarshalJSON(b []byte) error {
+	type alias rawInterfaceMap
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{})
+	return err
+}
+
+func (o rawInterfaceMap) MarshalJSON() ([]byte, error) {
+	type alias rawInterfaceMap
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 func (o *rawInterfaceMap) polish() (*InterfaceMap, error) {
@@ -284,6 +303,7 @@ func (o *rawInterfaceMap) polish() (*InterfaceMap, error) {
 			DeviceProfileId: o.DeviceProfileId,
 			Label:           o.Label,
 			Interfaces:      interfaces,
+			unknownFields:   o.unknownFields,
 		},
 	}, nil
 }
@@ -377,10 +397,12 @@ func (o *Client) createInterfaceMap(ctx context.Context, in *InterfaceMapData) (
 }
 
 func (o *Client) updateInterfaceMap(ctx context.Context, id ObjectId, in *InterfaceMapData) error {
+	apiInput := in.raw()
+	apiInput.unknownFields = o.unknownJsonFieldsForUpdate(in.unknownFields)
 	return o.talkToApstra(ctx, &talkToApstraIn{
 		method:   http.MethodPut,
 		urlStr:   fmt.Sprintf(apiUrlDesignInterfaceMapById, id),
-		apiInput: in.raw(),
+		apiInput: apiInput,
 	})
 }
 
@@ -6,6 +6,7 @@ package apstra
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -160,6 +161,9 @@ func (o AccessRedundancyProtocol) String() string {
 	case AccessRedundancyProtocolEsi:
 		return string(accessRedundancyProtocolEsi)
 	default:
+		if s, ok := lenientEnumString[accessRedundancyProtocol](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(accessRedundancyProtocolUnknown, o)
 	}
 }
@@ -179,12 +183,15 @@ func (o accessRedundancyProtocol) parse() (int, error) {
 	case accessRedundancyProtocolEsi:
 		return int(AccessRedundancyProtocolEsi), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(AccessRedundancyProtocolUnknown, o)
 	}
 }
 
 func (o *AccessRedundancyProtocol) FromString(in string) error {
-	i, err := accessRedundancyProtocol(in).parse()
+	i, err := parseKnown(accessRedundancyProtocol(in))
 	if err != nil {
 		return err
 	}
@@ -205,6 +212,9 @@ func (o LeafRedundancyProtocol) String() string {
 	case LeafRedundancyProtocolMlag:
 		return string(leafRedundancyProtocolMlag)
 	default:
+		if s, ok := lenientEnumString[leafRedundancyProtocol](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(leafRedundancyProtocolUnknown, o)
 	}
 }
@@ -226,12 +236,15 @@ func (o leafRedundancyProtocol) parse() (int, error) {
 	case leafRedundancyProtocolMlag:
 		return int(LeafRedundancyProtocolMlag), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(LeafRedundancyProtocolUnknown, o)
 	}
 }
 
 func (o *LeafRedundancyProtocol) FromString(in string) error {
-	i, err := leafRedundancyProtocol(in).parse()
+	i, err := parseKnown(leafRedundancyProtocol(in))
 	if err != nil {
 		return err
 	}
@@ -250,6 +263,9 @@ func (o FabricConnectivityDesign) String() string {
 	case FabricConnectivityDesignL3Collapsed:
 		return string(fabricConnectivityDesignL3Collapsed)
 	default:
+		if s, ok := lenientEnumString[fabricConnectivityDesign](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(fabricConnectivityDesignUnknown, o)
 	}
 }
@@ -269,12 +285,15 @@ func (o fabricConnectivityDesign) parse() (int, error) {
 	case fabricConnectivityDesignL3Collapsed:
 		return int(FabricConnectivityDesignL3Collapsed), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(FabricConnectivityDesignUnknown, o)
 	}
 }
 
 func (o *FabricConnectivityDesign) FromString(in string) error {
-	i, err := fabricConnectivityDesign(in).parse()
+	i, err := parseKnown(fabricConnectivityDesign(in))
 	if err != nil {
 		return err
 	}
@@ -293,6 +312,9 @@ func (o FeatureSwitch) String() string {
 	case FeatureSwitchEnabled:
 		return string(featureSwitchEnabled)
 	default:
+		if s, ok := lenientEnumString[featureSwitch](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(featureSwitchUnknown, o)
 	}
 }
@@ -308,6 +330,9 @@ func (o featureSwitch) parse() (int, error) {
 	case featureSwitchEnabled:
 		return int(FeatureSwitchEnabled), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(FeatureSwitchUnknown, o)
 	}
 }
@@ -327,6 +352,9 @@ func (o SystemManagementLevel) String() string {
 	case SystemManagementLevelNone:
 		return string(systemManagementLevelNone)
 	default:
+		if s, ok := lenientEnumString[systemManagementLevel](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(systemManagementLevelUnknown, o)
 	}
 }
@@ -346,6 +374,9 @@ func (o systemManagementLevel) parse() (int, error) {
 	case systemManagementLevelNone:
 		return int(SystemManagementLevelNone), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(SystemManagementLevelUnknown, o)
 	}
 }
@@ -361,6 +392,9 @@ func (o RackLinkAttachmentType) String() string {
 	case RackLinkAttachmentTypeDual:
 		return string(rackLinkAttachmentTypeDual)
 	default:
+		if s, ok := lenientEnumString[rackLinkAttachmentType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(rackLinkAttachmentTypeUnknown, o)
 	}
 }
@@ -376,6 +410,9 @@ func (o rackLinkAttachmentType) parse() (int, error) {
 	case rackLinkAttachmentTypeDual:
 		return int(RackLinkAttachmentTypeDual), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(RackLinkAttachmentTypeUnknown, o)
 	}
 }
@@ -395,12 +432,15 @@ func (o RackLinkLagMode) String() string {
 	case RackLinkLagModeStatic:
 		return string(rackLinkLagModeStatic)
 	default:
+		if s, ok := lenientEnumString[rackLinkLagMode](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(rackLinkLagModeUnknown, o)
 	}
 }
 
 func (o *RackLinkLagMode) FromString(in string) error {
-	i, err := rackLinkLagMode(in).parse()
+	i, err := parseKnown(rackLinkLagMode(in))
 	if err != nil {
 		return err
 	}
@@ -423,6 +463,9 @@ func (o rackLinkLagMode) parse() (int, error) {
 	case rackLinkLagModeStatic:
 		return int(RackLinkLagModeStatic), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(RackLinkLagModeUnknown, o)
 	}
 }
@@ -440,12 +483,15 @@ func (o RackLinkSwitchPeer) String() string {
 	case RackLinkSwitchPeerSecond:
 		return string(rackLinkSwitchPeerSecond)
 	default:
+		if s, ok := lenientEnumString[rackLinkSwitchPeer](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(rackLinkSwitchPeerUnknown, o)
 	}
 }
 
 func (o *RackLinkSwitchPeer) FromString(in string) error {
-	i, err := rackLinkSwitchPeer(in).parse()
+	i, err := parseKnown(rackLinkSwitchPeer(in))
 	if err != nil {
 		return err
 	}
@@ -466,6 +512,9 @@ func (o rackLinkSwitchPeer) parse() (int, error) {
 	case rackLinkSwitchPeerSecond:
 		return int(RackLinkSwitchPeerSecond), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(RackLinkSwitchPeerUnknown, o)
 	}
 }
@@ -999,6 +1048,15 @@ type RackTypeRequest struct {
 	LeafSwitches             []RackElementLeafSwitchRequest
 	AccessSwitches           []RackElementAccessSwitchRequest
 	GenericSystems           []RackElementGenericSystemRequest
+
+	unknownFields unknownJsonFields // see CopyUnknownFields
+}
+
+// CopyUnknownFields copies the fields of src which aren't modelled by the SDK
+// into o, so that UpdateRackType sends them back to the API, unless the Client
+// is configured with ClientCfg.DropUnknownJsonFields.
+func (o *RackTypeRequest) CopyUnknownFields(src *RackTypeData) {
+	o.unknownFields = src.unknownFields
 }
 
 func (o *RackTypeRequest) raw(ctx context.Context, client *Client) (*rawRackTypeRequest, error) {
@@ -1160,6 +1218,12 @@ type rawRackTypeRequest struct {
 	GenericSystems           []rawRackElementGenericSystem `json:"generic_systems,omitempty"`
 	LeafSwitches             []rawRackElementLeafSwitch    `json:"leafs,omitempty"`
 	AccessSwitches           []rawRackElementAccessSwitch  `json:"access_switches,omitempty"`
+	unknownFields            unknownJsonFields
+}
+
+func (o rawRackTypeRequest) MarshalJSON() ([]byte, error) {
+	type alias rawRackTypeRequest
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 type RackType struct {
@@ -1176,6 +1240,8 @@ type RackTypeData struct {
 	LeafSwitches             []RackElementLeafSwitch
 	GenericSystems           []RackElementGenericSystem
 	AccessSwitches           []RackElementAccessSwitch
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 type rawRackType struct {
@@ -1190,6 +1256,18 @@ type rawRackType struct {
 	GenericSystems           []rawRackElementGenericSystem `json:"generic_systems,omitempty"`
 	LeafSwitches             []rawRackElementLeafSwitch    `json:"leafs,omitempty"`
 	AccessSwitches           []rawRackElementAccessSwitch  `json:"access_switches,omitempty"`
+	unknownFields            unknownJsonFields
+}
+
+func (o *rawRackType) UnmarshalJSON(b []byte) error {
+	type alias rawRackType
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{})
+	return err
 }
 
 func (o *rawRackType) polish() (*RackType, error) {
@@ -1209,6 +1287,7 @@ func (o *rawRackType) polish() (*RackType, error) {
 			LeafSwitches:             make([]RackElementLeafSwitch, len(o.LeafSwitches)),
 			AccessSwitches:           make([]RackElementAccessSwitch, len(o.AccessSwitches)),
 			GenericSystems:           make([]RackElementGenericSystem, len(o.GenericSystems)),
+			unknownFields:            o.unknownFields,
 		},
 	}
 
@@ -1344,6 +1423,8 @@ func (o *Client) updateRackType(ctx context.Context, id ObjectId, request *RackT
 		return err
 	}
 
+	rawRequest.unknownFields = o.unknownJsonFieldsForUpdate(request.unknownFields)
+
 	err = o.talkToApstra(ctx, &talkToApstraIn{
 		method:   http.MethodPut,
 		urlStr:   fmt.Sprintf(apiUrlDesignRackTypeById, id),
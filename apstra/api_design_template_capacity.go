@@ -0,0 +1,432 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FabricCapacityTier identifies the layer of the fabric occupied by a
+// FabricCapacityElement.
+type FabricCapacityTier string
+
+const (
+	FabricCapacityTierSuperspine = FabricCapacityTier("superspine")
+	FabricCapacityTierSpine      = FabricCapacityTier("spine")
+	FabricCapacityTierLeaf       = FabricCapacityTier("leaf")
+	FabricCapacityTierAccess     = FabricCapacityTier("access")
+	FabricCapacityTierGeneric    = FabricCapacityTier("generic")
+)
+
+// FabricCapacityElement describes the port consumption of one element of a
+// template (the spine layer, a leaf switch in a rack type, etc...). Count is
+// the number of devices represented by the element across the whole fabric.
+// Port and bandwidth figures are per-device. When the members of a redundant
+// pair consume ports differently, figures describe the busiest member.
+type FabricCapacityElement struct {
+	Path          string
+	Label         string
+	Tier          FabricCapacityTier
+	Count         int
+	LogicalDevice string
+	TotalPorts    int
+	UsedPorts     int
+	FreePorts     int
+	UplinkBps     int64
+	DownlinkBps   int64
+}
+
+// Oversubscription returns the ratio of downlink to uplink bandwidth, or 0
+// when the element has no uplinks.
+func (o FabricCapacityElement) Oversubscription() float64 {
+	if o.UplinkBps == 0 {
+		return 0
+	}
+	return float64(o.DownlinkBps) / float64(o.UplinkBps)
+}
+
+// FabricCapacityTierSummary totals the port figures of all devices in a tier.
+type FabricCapacityTierSummary struct {
+	Tier       FabricCapacityTier
+	Devices    int
+	TotalPorts int
+	UsedPorts  int
+	FreePorts  int
+}
+
+// FabricCapacityReport describes the port consumption of a template.
+//   - MaxRackCount is keyed by rack info path (e.g. "RackInfo[rack_a]"). Each
+//     value is the number of racks of that type the spine logical device could
+//     serve if its pod contained nothing else.
+//   - MaxPodCount is keyed by pod info path (e.g. "PodInfo[pod_a]") and is
+//     only populated for pod-based templates. Each value is the number of
+//     pods of that type the superspine logical device could serve.
+//   - Problems lists port shortfalls and other design errors found while
+//     calculating capacity.
+type FabricCapacityReport struct {
+	Elements     []FabricCapacityElement
+	Tiers        []FabricCapacityTierSummary
+	MaxRackCount map[string]int
+	MaxPodCount  map[string]int
+	Problems     DesignValidationErrors
+}
+
+// Capacity calculates per-tier port consumption, free ports, oversubscription
+// and the maximum rack count supported by the spine logical device. The rack
+// types and logical devices embedded in the template are used, so no API
+// calls are required.
+func (o *TemplateRackBasedData) Capacity() *FabricCapacityReport {
+	result := FabricCapacityReport{
+		MaxRackCount: make(map[string]int),
+	}
+	result.addRackBased("", o, 1, 0)
+	result.summarize()
+	return &result
+}
+
+// Capacity calculates per-tier port consumption, free ports, oversubscription
+// and the maximum rack and pod counts supported by the spine and superspine
+// logical devices. The rack types and logical devices embedded in the
+// template are used, so no API calls are required.
+func (o *TemplatePodBasedData) Capacity() *FabricCapacityReport {
+	result := FabricCapacityReport{
+		MaxRackCount: make(map[string]int),
+		MaxPodCount:  make(map[string]int),
+	}
+
+	superspine := FabricCapacityElement{
+		Path:          "Superspine",
+		Tier:          FabricCapacityTierSuperspine,
+		Count:         o.Superspine.PlaneCount * o.Superspine.SuperspinePerPlane,
+		LogicalDevice: o.Superspine.LogicalDevice.DisplayName,
+		TotalPorts:    o.Superspine.LogicalDevice.portCount(),
+	}
+
+	// spine-facing ports required on each superspine, keyed by speed
+	demands := newFabricCapacityDemands("Superspine.LogicalDevice", LogicalDevicePortRoleSpine)
+
+	for _, podId := range sortedKeys(o.PodInfo) {
+		podInfo := o.PodInfo[podId]
+		p := fmt.Sprintf("PodInfo[%s]", podId)
+		if podInfo.TemplateRackBasedData == nil {
+			result.Problems.add(p+".TemplateRackBasedData", "must not be nil")
+			continue
+		}
+
+		pod := podInfo.TemplateRackBasedData
+		result.addRackBased(p+".", pod, podInfo.Count, o.Superspine.SuperspinePerPlane)
+
+		// spines are distributed among the planes, and connect to each
+		// superspine in their plane
+		spine := pod.Spine
+		if o.Superspine.PlaneCount < 1 || spine.Count%o.Superspine.PlaneCount != 0 {
+			result.Problems.add(p+".TemplateRackBasedData.Spine.Count", "spine count %d must be a multiple of the superspine plane count %d",
+				spine.Count, o.Superspine.PlaneCount)
+			continue
+		}
+		perPod := fabricCapacityDemand{
+			speed: spine.LinkPerSuperspineSpeed,
+			count: spine.Count / o.Superspine.PlaneCount * spine.LinkPerSuperspineCount,
+		}
+		demands.add(perPod, podInfo.Count)
+		superspine.DownlinkBps += int64(podInfo.Count*perPod.count) * perPod.speed.BitsPerSecond()
+
+		if perPod.count > 0 {
+			result.MaxPodCount[p] = o.Superspine.LogicalDevice.maxInstances(
+				newFabricCapacityDemands("", LogicalDevicePortRoleSpine).add(perPod, 1).list(), nil)
+		}
+	}
+
+	superspine.UsedPorts = demands.total()
+	superspine.FreePorts = superspine.TotalPorts - superspine.UsedPorts
+	o.Superspine.LogicalDevice.checkPorts("Superspine.LogicalDevice", demands.list(), &result.Problems)
+
+	result.Elements = append([]FabricCapacityElement{superspine}, result.Elements...)
+	result.summarize()
+	return &result
+}
+
+// addRackBased adds the spine and rack elements of a rack-based template (or
+// pod) to the report. podCount is the number of copies of the rack-based
+// template in the fabric, superspinesPerSpine is the number of superspines
+// each spine connects to (0 if none).
+func (o *FabricCapacityReport) addRackBased(path string, template *TemplateRackBasedData, podCount, superspinesPerSpine int) {
+	spineLd := template.Spine.LogicalDevice
+	spine := FabricCapacityElement{
+		Path:          path + "Spine",
+		Tier:          FabricCapacityTierSpine,
+		Count:         template.Spine.Count * podCount,
+		LogicalDevice: spineLd.DisplayName,
+		TotalPorts:    spineLd.portCount(),
+	}
+
+	// leaf-facing ports required on each spine, keyed by speed
+	spineDemands := newFabricCapacityDemands(path+"Spine.LogicalDevice", LogicalDevicePortRoleLeaf)
+
+	var superspineDemands []logicalDevicePortDemand
+	if superspinesPerSpine > 0 && template.Spine.LinkPerSuperspineCount > 0 {
+		superspineDemands = append(superspineDemands, logicalDevicePortDemand{
+			Path:  path + "Spine.LinkPerSuperspineCount",
+			Role:  LogicalDevicePortRoleSuperspine,
+			Speed: template.Spine.LinkPerSuperspineSpeed,
+			Count: template.Spine.LinkPerSuperspineCount * superspinesPerSpine,
+		})
+		spine.UplinkBps = int64(superspineDemands[0].Count) * superspineDemands[0].Speed.BitsPerSecond()
+	}
+
+	for _, rackId := range sortedKeys(template.RackInfo) {
+		rackInfo := template.RackInfo[rackId]
+		p := fmt.Sprintf("%sRackInfo[%s]", path, rackId)
+		if rackInfo.RackTypeData == nil {
+			o.Problems.add(p+".RackTypeData", "must not be nil")
+			continue
+		}
+
+		rack := rackInfo.RackTypeData
+		demands := validateRackTypeData(p+".", rack, template.Spine.Count, &o.Problems)
+		racks := rackInfo.Count * podCount
+
+		perRack := newFabricCapacityDemands("", LogicalDevicePortRoleLeaf)
+		for i, leaf := range rack.LeafSwitches {
+			element := newFabricCapacityElement(demands.leafSwitches[i], leaf.LogicalDevice, racks, FabricCapacityTierLeaf, leaf.Label)
+			o.Elements = append(o.Elements, element)
+
+			if leaf.LinkPerSpineCount > 0 && rack.FabricConnectivityDesign != FabricConnectivityDesignL3Collapsed {
+				leafsPerRack := 1
+				if demands.leafSwitches[i].redundant {
+					leafsPerRack = 2
+				}
+				d := fabricCapacityDemand{speed: leaf.LinkPerSpineSpeed, count: leaf.LinkPerSpineCount * leafsPerRack}
+				perRack.add(d, 1)
+				spineDemands.add(d, rackInfo.Count) // per spine, so pod count doesn't matter
+				spine.DownlinkBps += int64(rackInfo.Count*d.count) * d.speed.BitsPerSecond()
+			}
+		}
+
+		for i, access := range rack.AccessSwitches {
+			o.Elements = append(o.Elements, newFabricCapacityElement(demands.accessSwitches[i], access.LogicalDevice, racks*access.InstanceCount, FabricCapacityTierAccess, access.Label))
+		}
+
+		for i, generic := range rack.GenericSystems {
+			element := FabricCapacityElement{
+				Path:  fmt.Sprintf("%s.GenericSystems[%d]", p, i),
+				Label: generic.Label,
+				Tier:  FabricCapacityTierGeneric,
+				Count: racks * generic.Count,
+			}
+			element.addDemands(demands.genericSystems[i], generic.LogicalDevice)
+			o.Elements = append(o.Elements, element)
+		}
+
+		if perRack.total() > 0 {
+			o.MaxRackCount[p] = spineLd.maxInstances(perRack.list(), superspineDemands)
+		}
+	}
+
+	spine.UsedPorts = spineDemands.total()
+	for _, d := range superspineDemands {
+		spine.UsedPorts += d.Count
+	}
+	spine.FreePorts = spine.TotalPorts - spine.UsedPorts
+	spineLd.checkPorts(path+"Spine.LogicalDevice", append(spineDemands.list(), superspineDemands...), &o.Problems)
+
+	o.Elements = append([]FabricCapacityElement{spine}, o.Elements...)
+}
+
+// newFabricCapacityElement describes a leaf or access switch element of which
+// there are count devices (or pairs, when the switch is redundant) in the
+// fabric.
+func newFabricCapacityElement(sw *rackSwitch, ld *LogicalDeviceData, count int, tier FabricCapacityTier, label string) FabricCapacityElement {
+	result := FabricCapacityElement{
+		Path:  sw.path,
+		Label: label,
+		Tier:  tier,
+		Count: count,
+	}
+
+	members := 1
+	if sw.redundant {
+		members = 2
+		result.Count *= 2
+	}
+
+	// report on the busiest member
+	var busiest FabricCapacityElement
+	for member := 0; member < members; member++ {
+		e := result
+		e.addDemands(sw.demands[member], ld)
+		if member == 0 || e.UsedPorts > busiest.UsedPorts {
+			busiest = e
+		}
+	}
+
+	return busiest
+}
+
+// addDemands tallies the per-device port consumption and bandwidth of demands,
+// which are uplinks, downlinks or peer links according to the element's tier.
+func (o *FabricCapacityElement) addDemands(demands []logicalDevicePortDemand, ld *LogicalDeviceData) {
+	var uplinkRoles, downlinkRoles LogicalDevicePortRoleFlags
+	switch o.Tier {
+	case FabricCapacityTierLeaf:
+		uplinkRoles = LogicalDevicePortRoleSpine
+		downlinkRoles = LogicalDevicePortRoleAccess | LogicalDevicePortRoleGeneric
+	case FabricCapacityTierAccess:
+		uplinkRoles = LogicalDevicePortRoleLeaf
+		downlinkRoles = LogicalDevicePortRoleGeneric
+	case FabricCapacityTierGeneric:
+		uplinkRoles = LogicalDevicePortRoleLeaf | LogicalDevicePortRoleAccess
+	}
+
+	for _, d := range demands {
+		o.UsedPorts += d.Count
+		bps := int64(d.Count) * d.Speed.BitsPerSecond()
+		switch {
+		case d.Role&uplinkRoles != 0:
+			o.UplinkBps += bps
+		case d.Role&downlinkRoles != 0:
+			o.DownlinkBps += bps
+		}
+	}
+
+	if ld != nil {
+		o.LogicalDevice = ld.DisplayName
+		o.TotalPorts = ld.portCount()
+	}
+	o.FreePorts = o.TotalPorts - o.UsedPorts
+}
+
+func (o *FabricCapacityReport) summarize() {
+	summaries := make(map[FabricCapacityTier]*FabricCapacityTierSummary)
+	for _, e := range o.Elements {
+		s, ok := summaries[e.Tier]
+		if !ok {
+			s = &FabricCapacityTierSummary{Tier: e.Tier}
+			summaries[e.Tier] = s
+		}
+		s.Devices += e.Count
+		s.TotalPorts += e.Count * e.TotalPorts
+		s.UsedPorts += e.Count * e.UsedPorts
+		s.FreePorts += e.Count * e.FreePorts
+	}
+
+	o.Tiers = nil
+	for _, tier := range []FabricCapacityTier{
+		FabricCapacityTierSuperspine,
+		FabricCapacityTierSpine,
+		FabricCapacityTierLeaf,
+		FabricCapacityTierAccess,
+		FabricCapacityTierGeneric,
+	} {
+		if s, ok := summaries[tier]; ok {
+			o.Tiers = append(o.Tiers, *s)
+		}
+	}
+}
+
+// portCount returns the number of ports on the logical device.
+func (o *LogicalDeviceData) portCount() int {
+	var result int
+	for _, panel := range o.Panels {
+		for _, pg := range panel.PortGroups {
+			result += pg.Count
+		}
+	}
+	return result
+}
+
+// maxInstances returns the number of copies of perInstance demands which the
+// logical device can satisfy alongside the fixed demands.
+func (o *LogicalDeviceData) maxInstances(perInstance, fixed []logicalDevicePortDemand) int {
+	fits := func(n int) bool {
+		demands := append([]logicalDevicePortDemand{}, fixed...)
+		for _, d := range perInstance {
+			d.Count *= n
+			demands = append(demands, d)
+		}
+		for _, unmet := range o.allocatePorts(demands) {
+			if unmet != 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	// binary search between 0 (always fits) and the port count (never fits,
+	// since each instance needs at least one port)
+	lo, hi := 0, o.portCount()+1
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	if !fits(lo) {
+		return 0 // the fixed demands alone don't fit
+	}
+
+	return lo
+}
+
+// fabricCapacityDemand is a number of links of a given speed.
+type fabricCapacityDemand struct {
+	speed LogicalDevicePortSpeed
+	count int
+}
+
+// fabricCapacityDemands accumulates links by speed to produce
+// logicalDevicePortDemands for a single role.
+type fabricCapacityDemands struct {
+	path   string
+	role   LogicalDevicePortRoleFlags
+	counts map[int64]*logicalDevicePortDemand
+}
+
+func newFabricCapacityDemands(path string, role LogicalDevicePortRoleFlags) *fabricCapacityDemands {
+	return &fabricCapacityDemands{
+		path:   path,
+		role:   role,
+		counts: make(map[int64]*logicalDevicePortDemand),
+	}
+}
+
+func (o *fabricCapacityDemands) add(d fabricCapacityDemand, multiplier int) *fabricCapacityDemands {
+	if d.count == 0 {
+		return o
+	}
+	bps := d.speed.BitsPerSecond()
+	if _, ok := o.counts[bps]; !ok {
+		o.counts[bps] = &logicalDevicePortDemand{Path: o.path, Role: o.role, Speed: d.speed}
+	}
+	o.counts[bps].Count += d.count * multiplier
+	return o
+}
+
+func (o *fabricCapacityDemands) total() int {
+	var result int
+	for _, d := range o.counts {
+		result += d.Count
+	}
+	return result
+}
+
+// list returns the accumulated demands ordered by speed.
+func (o *fabricCapacityDemands) list() []logicalDevicePortDemand {
+	keys := make([]int64, 0, len(o.counts))
+	for k := range o.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	result := make([]logicalDevicePortDemand, len(keys))
+	for i, k := range keys {
+		result[i] = *o.counts[k]
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateCapacity(t *testing.T) {
+	leafLd := LogicalDeviceData{
+		DisplayName: "48x10+6x40",
+		Panels: []LogicalDevicePanel{{
+			PortGroups: []LogicalDevicePortGroup{
+				{Count: 48, Speed: "10G", Roles: LogicalDevicePortRoleAccess | LogicalDevicePortRoleGeneric},
+				{Count: 6, Speed: "40G", Roles: LogicalDevicePortRoleSpine | LogicalDevicePortRolePeer},
+			},
+		}},
+	}
+	spineLd := LogicalDeviceData{
+		DisplayName: "32x40",
+		Panels: []LogicalDevicePanel{{
+			PortGroups: []LogicalDevicePortGroup{
+				{Count: 32, Speed: "40G", Roles: LogicalDevicePortRoleLeaf | LogicalDevicePortRoleSuperspine},
+			},
+		}},
+	}
+	serverLd := LogicalDeviceData{
+		DisplayName: "2x10",
+		Panels: []LogicalDevicePanel{{
+			PortGroups: []LogicalDevicePortGroup{
+				{Count: 2, Speed: "10G", Roles: LogicalDevicePortRoleLeaf},
+			},
+		}},
+	}
+
+	// single leaf with 2x40G to each spine, 40 single-homed servers
+	rack := RackTypeData{
+		DisplayName:              "rack",
+		FabricConnectivityDesign: FabricConnectivityDesignL3Clos,
+		LeafSwitches: []RackElementLeafSwitch{{
+			Label:              "leaf",
+			LinkPerSpineCount:  2,
+			LinkPerSpineSpeed:  "40G",
+			RedundancyProtocol: LeafRedundancyProtocolNone,
+			LogicalDevice:      &leafLd,
+		}},
+		GenericSystems: []RackElementGenericSystem{{
+			Count:            40,
+			Label:            "server",
+			PortChannelIdMax: 1,
+			LogicalDevice:    &serverLd,
+			Links: []RackLink{{
+				LinkPerSwitchCount: 1,
+				LinkSpeed:          "10G",
+				TargetSwitchLabel:  "leaf",
+				AttachmentType:     RackLinkAttachmentTypeSingle,
+			}},
+		}},
+	}
+
+	template := TemplateRackBasedData{
+		DisplayName: "template",
+		Spine: Spine{
+			Count:                  2,
+			LinkPerSuperspineCount: 1,
+			LinkPerSuperspineSpeed: "40G",
+			LogicalDevice:          spineLd,
+		},
+		RackInfo: map[ObjectId]TemplateRackBasedRackInfo{"rack": {Count: 10, RackTypeData: &rack}},
+	}
+
+	t.Run("rack_based", func(t *testing.T) {
+		report := template.Capacity()
+		require.Empty(t, report.Problems)
+		require.Len(t, report.Elements, 3)
+
+		spine := report.Elements[0]
+		require.Equal(t, FabricCapacityTierSpine, spine.Tier)
+		require.Equal(t, 2, spine.Count)
+		require.Equal(t, 32, spine.TotalPorts)
+		require.Equal(t, 20, spine.UsedPorts) // 10 racks * 2 links
+		require.Equal(t, 12, spine.FreePorts)
+
+		leaf := report.Elements[1]
+		require.Equal(t, "RackInfo[rack].LeafSwitches[0]", leaf.Path)
+		require.Equal(t, 10, leaf.Count)
+		require.Equal(t, 44, leaf.UsedPorts) // 4 uplinks + 40 servers
+		require.Equal(t, int64(160_000_000_000), leaf.UplinkBps)
+		require.Equal(t, int64(400_000_000_000), leaf.DownlinkBps)
+		require.Equal(t, 2.5, leaf.Oversubscription())
+
+		server := report.Elements[2]
+		require.Equal(t, 400, server.Count)
+		require.Equal(t, 1, server.UsedPorts)
+		require.Equal(t, 1, server.FreePorts)
+
+		require.Equal(t, map[string]int{"RackInfo[rack]": 16}, report.MaxRackCount)
+
+		require.Equal(t, []FabricCapacityTierSummary{
+			{Tier: FabricCapacityTierSpine, Devices: 2, TotalPorts: 64, UsedPorts: 40, FreePorts: 24},
+			{Tier: FabricCapacityTierLeaf, Devices: 10, TotalPorts: 540, UsedPorts: 440, FreePorts: 100},
+			{Tier: FabricCapacityTierGeneric, Devices: 400, TotalPorts: 800, UsedPorts: 400, FreePorts: 400},
+		}, report.Tiers)
+	})
+
+	t.Run("rack_based_too_many_racks", func(t *testing.T) {
+		tooMany := template
+		tooMany.RackInfo = map[ObjectId]TemplateRackBasedRackInfo{"rack": {Count: 17, RackTypeData: &rack}}
+		report := tooMany.Capacity()
+		require.Len(t, report.Problems, 1)
+		require.Equal(t, "Spine.LogicalDevice", report.Problems[0].Path)
+		require.Equal(t, -2, report.Elements[0].FreePorts)
+	})
+
+	t.Run("pod_based", func(t *testing.T) {
+		pods := TemplatePodBasedData{
+			DisplayName: "pods",
+			Superspine: Superspine{
+				PlaneCount:         2,
+				SuperspinePerPlane: 2,
+				LogicalDevice: LogicalDeviceData{
+					DisplayName: "32x40",
+					Panels: []LogicalDevicePanel{{
+						PortGroups: []LogicalDevicePortGroup{
+							{Count: 32, Speed: "40G", Roles: LogicalDevicePortRoleSpine},
+						},
+					}},
+				},
+			},
+			PodInfo: map[ObjectId]TemplatePodBasedInfo{"pod": {Count: 3, TemplateRackBasedData: &template}},
+		}
+
+		report := pods.Capacity()
+		require.Empty(t, report.Problems)
+
+		superspine := report.Elements[0]
+		require.Equal(t, FabricCapacityTierSuperspine, superspine.Tier)
+		require.Equal(t, 4, superspine.Count)
+		require.Equal(t, 3, superspine.UsedPorts) // 3 pods * 1 spine per plane * 1 link
+
+		spine := report.Elements[1]
+		require.Equal(t, "PodInfo[pod].Spine", spine.Path)
+		require.Equal(t, 6, spine.Count)
+		require.Equal(t, 22, spine.UsedPorts) // 20 leaf links + 2 superspine links
+		require.Equal(t, 10.0, spine.Oversubscription())
+
+		require.Equal(t, map[string]int{"PodInfo[pod].RackInfo[rack]": 15}, report.MaxRackCount)
+		require.Equal(t, map[string]int{"PodInfo[pod]": 32}, report.MaxPodCount)
+	})
+}
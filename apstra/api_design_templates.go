@@ -12,8 +12,6 @@ import (
 	"net/http"
 	"sort"
 	"time"
-
-	"github.com/Juniper/apstra-go-sdk/apstra/compatibility"
 )
 
 const (
@@ -141,12 +139,15 @@ func (o AntiAffinityMode) String() string {
 	case AntiAffinityModeStrict:
 		return string(antiAffinityModeStrict)
 	default:
+		if s, ok := lenientEnumString[antiAffinityMode](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(antiAffinityModeUnknown, o)
 	}
 }
 
 func (o *AntiAffinityMode) FromString(s string) error {
-	i, err := antiAffinityMode(s).parse()
+	i, err := parseKnown(antiAffinityMode(s))
 	if err != nil {
 		return err
 	}
@@ -175,6 +176,9 @@ func (o antiAffinityMode) parse() (int, error) {
 	case antiAffinityModeStrict:
 		return int(AntiAffinityModeStrict), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(AntiAffinityModeUnknown, o)
 	}
 }
@@ -188,6 +192,9 @@ func (o AntiAffninityAlgorithm) String() string {
 	case AlgorithmHeuristic:
 		return string(algorithmHeuristic)
 	default:
+		if s, ok := lenientEnumString[antiAffinityAlgorithm](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(algorithmUnknown, o)
 	}
 }
@@ -205,6 +212,9 @@ func (o antiAffinityAlgorithm) parse() (int, error) {
 	case algorithmHeuristic:
 		return int(AlgorithmHeuristic), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(AlgorithmUnknown, o)
 	}
 }
@@ -224,12 +234,15 @@ func (o TemplateType) String() string {
 	case TemplateTypeNone:
 		return string(templateTypeNone)
 	default:
+		if s, ok := lenientEnumString[templateType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(templateTypeUnknown, o)
 	}
 }
 
 func (o *TemplateType) FromString(s string) error {
-	i, err := templateType(s).parse()
+	i, err := parseKnown(templateType(s))
 	if err != nil {
 		return err
 	}
@@ -252,6 +265,9 @@ func (o templateType) parse() (int, error) {
 	case templateTypeNone:
 		return int(TemplateTypeNone), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(TemplateTypeUnknown, o)
 	}
 }
@@ -267,6 +283,9 @@ func (o AsnAllocationScheme) String() string {
 	case AsnAllocationSchemeSingle:
 		return string(asnAllocationSchemeSingle)
 	default:
+		if s, ok := lenientEnumString[asnAllocationScheme](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(asnAllocationUnknown, o)
 	}
 }
@@ -276,7 +295,7 @@ func (o AsnAllocationScheme) raw() asnAllocationScheme {
 }
 
 func (o *AsnAllocationScheme) FromString(in string) error {
-	i, err := asnAllocationScheme(in).parse()
+	i, err := parseKnown(asnAllocationScheme(in))
 	if err != nil {
 		return err
 	}
@@ -295,6 +314,9 @@ func (o asnAllocationScheme) parse() (int, error) {
 	case asnAllocationSchemeSingle:
 		return int(AsnAllocationSchemeSingle), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(AsnAllocationSchemeUnknown, o)
 	}
 }
@@ -312,6 +334,9 @@ func (o AddressingScheme) String() string {
 	case AddressingSchemeIp46:
 		return string(addressingSchemeIp46)
 	default:
+		if s, ok := lenientEnumString[addressingScheme](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(addressingSchemeUnknown, o)
 	}
 }
@@ -321,7 +346,7 @@ func (o AddressingScheme) raw() addressingScheme {
 }
 
 func (o *AddressingScheme) FromString(in string) error {
-	i, err := addressingScheme(in).parse()
+	i, err := parseKnown(addressingScheme(in))
 	if err != nil {
 		return err
 	}
@@ -342,6 +367,9 @@ func (o addressingScheme) parse() (int, error) {
 	case addressingSchemeIp46:
 		return int(AddressingSchemeIp46), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(AddressingSchemeUnknown, o)
 	}
 }
@@ -357,6 +385,9 @@ func (o OverlayControlProtocol) String() string {
 	case OverlayControlProtocolEvpn:
 		return string(overlayControlProtocolEvpn)
 	default:
+		if s, ok := lenientEnumString[overlayControlProtocol](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(overlayControlProtocolUnknown, o)
 	}
 }
@@ -366,7 +397,7 @@ func (o OverlayControlProtocol) raw() overlayControlProtocol {
 }
 
 func (o *OverlayControlProtocol) FromString(in string) error {
-	i, err := overlayControlProtocol(in).parse()
+	i, err := parseKnown(overlayControlProtocol(in))
 	if err != nil {
 		return err
 	}
@@ -385,6 +416,9 @@ func (o overlayControlProtocol) parse() (int, error) {
 	case overlayControlProtocolEvpn:
 		return int(OverlayControlProtocolEvpn), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(OverlayControlProtocolUnknown, o)
 	}
 }
@@ -402,6 +436,9 @@ func (o TemplateCapability) String() string {
 	case TemplateCapabilityNone:
 		return string(templateCapabilityNone)
 	default:
+		if s, ok := lenientEnumString[templateCapability](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(templateCapabilityUnknown, o)
 	}
 }
@@ -419,6 +456,9 @@ func (o templateCapability) parse() (int, error) {
 	case templateCapabilityNone:
 		return int(TemplateCapabilityNone), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(TemplateCapabilityUnknown, o)
 	}
 }
@@ -732,6 +772,8 @@ type TemplateRackBasedData struct {
 	Spine                Spine
 	RackInfo             map[ObjectId]TemplateRackBasedRackInfo
 	DhcpServiceIntent    DhcpServiceIntent
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 type TemplateRackBasedRackInfo struct {
@@ -757,6 +799,18 @@ type rawTemplateRackBased struct {
 	RackTypes            []rawRackType           `json:"rack_types"`
 	RackTypeCounts       []RackTypeCount         `json:"rack_type_counts"`
 	DhcpServiceIntent    DhcpServiceIntent       `json:"dhcp_service_intent"`
+	unknownFields        unknownJsonFields
+}
+
+func (o *rawTemplateRackBased) UnmarshalJSON(b []byte) error {
+	type alias rawTemplateRackBased
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{})
+	return err
 }
 
 func (o rawTemplateRackBased) polish() (*TemplateRackBased, error) {
@@ -826,6 +880,7 @@ OUTER:
 			Spine:                *s,
 			RackInfo:             rackTypeInfos,
 			DhcpServiceIntent:    o.DhcpServiceIntent,
+			unknownFields:        o.unknownFields,
 		},
 	}, nil
 }
@@ -869,6 +924,8 @@ type TemplatePodBasedData struct {
 	Superspine         Superspine
 	Capability         TemplateCapability
 	PodInfo            map[ObjectId]TemplatePodBasedInfo
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 type TemplatePodBasedInfo struct {
@@ -887,6 +944,18 @@ type rawTemplatePodBased struct {
 	Capability              templateCapability       `json:"capability,omitempty"`
 	RackBasedTemplates      []rawTemplateRackBased   `json:"rack_based_templates"`
 	RackBasedTemplateCounts []RackBasedTemplateCount `json:"rack_based_template_counts"`
+	unknownFields           unknownJsonFields
+}
+
+func (o *rawTemplatePodBased) UnmarshalJSON(b []byte) error {
+	type alias rawTemplatePodBased
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{})
+	return err
 }
 
 func (o rawTemplatePodBased) polish() (*TemplatePodBased, error) {
@@ -957,6 +1026,7 @@ OUTER:
 			Superspine:         *superspine,
 			Capability:         TemplateCapability(capability),
 			PodInfo:            podTypeInfos,
+			unknownFields:      o.unknownFields,
 		},
 	}, nil
 }
@@ -996,6 +1066,8 @@ type TemplateL3CollapsedData struct {
 	MeshLinkCount        int
 	RackTypeCounts       []RackTypeCount
 	DhcpServiceIntent    DhcpServiceIntent
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 type rawTemplateL3Collapsed struct {
@@ -1012,6 +1084,18 @@ type rawTemplateL3Collapsed struct {
 	MeshLinkCount        int                        `json:"mesh_link_count"`
 	RackTypeCounts       []RackTypeCount            `json:"rack_type_counts"`
 	DhcpServiceIntent    DhcpServiceIntent          `json:"dhcp_service_intent"`
+	unknownFields        unknownJsonFields
+}
+
+func (o *rawTemplateL3Collapsed) UnmarshalJSON(b []byte) error {
+	type alias rawTemplateL3Collapsed
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{})
+	return err
 }
 
 func (o rawTemplateL3Collapsed) polish() (*TemplateL3Collapsed, error) {
@@ -1057,6 +1141,7 @@ func (o rawTemplateL3Collapsed) polish() (*TemplateL3Collapsed, error) {
 			MeshLinkCount:        o.MeshLinkCount,
 			RackTypeCounts:       o.RackTypeCounts,
 			DhcpServiceIntent:    o.DhcpServiceIntent,
+			unknownFields:        o.unknownFields,
 		},
 	}, nil
 }
@@ -1327,6 +1412,15 @@ type CreateRackBasedTemplateRequest struct {
 	AntiAffinityPolicy   *AntiAffinityPolicy
 	AsnAllocationPolicy  *AsnAllocationPolicy
 	VirtualNetworkPolicy *VirtualNetworkPolicy
+
+	unknownFields unknownJsonFields // see CopyUnknownFields
+}
+
+// CopyUnknownFields copies the fields of src which aren't modelled by the SDK
+// into o, so that UpdateRackBasedTemplate sends them back to the API,
+// unless the Client is configured with ClientCfg.DropUnknownJsonFields.
+func (o *CreateRackBasedTemplateRequest) CopyUnknownFields(src *TemplateRackBasedData) {
+	o.unknownFields = src.unknownFields
 }
 
 func (o *CreateRackBasedTemplateRequest) raw(ctx context.Context, client *Client) (*rawCreateRackBasedTemplateRequest, error) {
@@ -1355,8 +1449,8 @@ func (o *CreateRackBasedTemplateRequest) raw(ctx context.Context, client *Client
 	switch {
 	case o.Spine == nil:
 		return nil, errors.New("spine cannot be <nil> when creating a rack-based template")
-	case o.AntiAffinityPolicy == nil && compatibility.TemplateRequestRequiresAntiAffinityPolicy.Check(client.apiVersion):
-		return nil, fmt.Errorf("anti-affinity policy cannot be <nil> when creating a rack-based template with Apstra %s", compatibility.TemplateRequestRequiresAntiAffinityPolicy)
+	case o.AntiAffinityPolicy == nil && client.Supports("CreateRackBasedTemplateRequest.AntiAffinityPolicy.Required"):
+		return nil, fmt.Errorf("anti-affinity policy cannot be <nil> when creating a rack-based template with Apstra %s", client.apiVersion)
 	case o.AsnAllocationPolicy == nil:
 		return nil, errors.New("asn allocation policy cannot be <nil> when creating a rack-based template")
 	case o.VirtualNetworkPolicy == nil:
@@ -1406,6 +1500,12 @@ type rawCreateRackBasedTemplateRequest struct {
 	AntiAffinityPolicy   *rawAntiAffinityPolicy  `json:"anti_affinity_policy,omitempty"`
 	AsnAllocationPolicy  rawAsnAllocationPolicy  `json:"asn_allocation_policy"`
 	VirtualNetworkPolicy rawVirtualNetworkPolicy `json:"virtual_network_policy"`
+	unknownFields        unknownJsonFields
+}
+
+func (o rawCreateRackBasedTemplateRequest) MarshalJSON() ([]byte, error) {
+	type alias rawCreateRackBasedTemplateRequest
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 func (o *Client) createRackBasedTemplate(ctx context.Context, in *rawCreateRackBasedTemplateRequest) (ObjectId, error) {
@@ -1427,6 +1527,9 @@ func (o *Client) updateRackBasedTemplate(ctx context.Context, id ObjectId, in *C
 	if err != nil {
 		return err
 	}
+
+	raw.unknownFields = o.unknownJsonFieldsForUpdate(in.unknownFields)
+
 	err = o.talkToApstra(ctx, &talkToApstraIn{
 		method:   http.MethodPut,
 		urlStr:   fmt.Sprintf(apiUrlDesignTemplateById, id),
@@ -1443,6 +1546,15 @@ type CreatePodBasedTemplateRequest struct {
 	Superspine         *TemplateElementSuperspineRequest
 	PodInfos           map[ObjectId]TemplatePodBasedInfo
 	AntiAffinityPolicy *AntiAffinityPolicy
+
+	unknownFields unknownJsonFields // see CopyUnknownFields
+}
+
+// CopyUnknownFields copies the fields of src which aren't modelled by the SDK
+// into o, so that UpdatePodBasedTemplate sends them back to the API,
+// unless the Client is configured with ClientCfg.DropUnknownJsonFields.
+func (o *CreatePodBasedTemplateRequest) CopyUnknownFields(src *TemplatePodBasedData) {
+	o.unknownFields = src.unknownFields
 }
 
 func (o *CreatePodBasedTemplateRequest) raw(ctx context.Context, client *Client) (*rawCreatePodBasedTemplateRequest, error) {
@@ -1475,8 +1587,8 @@ func (o *CreatePodBasedTemplateRequest) raw(ctx context.Context, client *Client)
 	switch {
 	case o.Superspine == nil:
 		return nil, errors.New("super spine cannot be <nil> when creating a pod-based template")
-	case o.AntiAffinityPolicy == nil && compatibility.TemplateRequestRequiresAntiAffinityPolicy.Check(client.apiVersion):
-		return nil, fmt.Errorf("anti-affinity policy cannot be <nil> when creating a pod-based template with Apstra %s", compatibility.TemplateRequestRequiresAntiAffinityPolicy)
+	case o.AntiAffinityPolicy == nil && client.Supports("CreatePodBasedTemplateRequest.AntiAffinityPolicy.Required"):
+		return nil, fmt.Errorf("anti-affinity policy cannot be <nil> when creating a pod-based template with Apstra %s", client.apiVersion)
 	}
 
 	var err error
@@ -1510,6 +1622,12 @@ type rawCreatePodBasedTemplateRequest struct {
 	RackBasedTemplates      []rawTemplateRackBased   `json:"rack_based_templates"`
 	RackBasedTemplateCounts []RackBasedTemplateCount `json:"rack_based_template_counts"`
 	AntiAffinityPolicy      *rawAntiAffinityPolicy   `json:"anti_affinity_policy,omitempty"`
+	unknownFields           unknownJsonFields
+}
+
+func (o rawCreatePodBasedTemplateRequest) MarshalJSON() ([]byte, error) {
+	type alias rawCreatePodBasedTemplateRequest
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 func (o *Client) createPodBasedTemplate(ctx context.Context, in *rawCreatePodBasedTemplateRequest) (ObjectId, error) {
@@ -1532,6 +1650,9 @@ func (o *Client) updatePodBasedTemplate(ctx context.Context, id ObjectId, in *Cr
 	if err != nil {
 		return err
 	}
+
+	apiInput.unknownFields = o.unknownJsonFieldsForUpdate(in.unknownFields)
+
 	err = o.talkToApstra(ctx, &talkToApstraIn{
 		method:   http.MethodPut,
 		urlStr:   fmt.Sprintf(apiUrlDesignTemplateById, id),
@@ -1553,6 +1674,15 @@ type CreateL3CollapsedTemplateRequest struct {
 	DhcpServiceIntent    DhcpServiceIntent      `json:"dhcp_service_intent"`
 	AntiAffinityPolicy   *AntiAffinityPolicy    `json:"anti_affinity_policy,omitempty"`
 	VirtualNetworkPolicy VirtualNetworkPolicy   `json:"virtual_network_policy"`
+
+	unknownFields unknownJsonFields // see CopyUnknownFields
+}
+
+// CopyUnknownFields copies the fields of src which aren't modelled by the SDK
+// into o, so that UpdateL3CollapsedTemplate sends them back to the API,
+// unless the Client is configured with ClientCfg.DropUnknownJsonFields.
+func (o *CreateL3CollapsedTemplateRequest) CopyUnknownFields(src *TemplateL3CollapsedData) {
+	o.unknownFields = src.unknownFields
 }
 
 func (o *CreateL3CollapsedTemplateRequest) raw(ctx context.Context, client *Client) (*rawCreateL3CollapsedTemplateRequest, error) {
@@ -1593,6 +1723,12 @@ type rawCreateL3CollapsedTemplateRequest struct {
 	DhcpServiceIntent    DhcpServiceIntent         `json:"dhcp_service_intent"`
 	AntiAffinityPolicy   *rawAntiAffinityPolicy    `json:"anti_affinity_policy,omitempty"`
 	VirtualNetworkPolicy rawVirtualNetworkPolicy   `json:"virtual_network_policy"`
+	unknownFields        unknownJsonFields
+}
+
+func (o rawCreateL3CollapsedTemplateRequest) MarshalJSON() ([]byte, error) {
+	type alias rawCreateL3CollapsedTemplateRequest
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 func (o *Client) createL3CollapsedTemplate(ctx context.Context, in *rawCreateL3CollapsedTemplateRequest) (ObjectId, error) {
@@ -1615,6 +1751,9 @@ func (o *Client) updateL3CollapsedTemplate(ctx context.Context, id ObjectId, in
 	if err != nil {
 		return err
 	}
+
+	apiInput.unknownFields = o.unknownJsonFieldsForUpdate(in.unknownFields)
+
 	err = o.talkToApstra(ctx, &talkToApstraIn{
 		method:   http.MethodPut,
 		urlStr:   fmt.Sprintf(apiUrlDesignTemplateById, id),
@@ -0,0 +1,799 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DesignValidationError describes a single problem found by offline
+// validation of a design (rack type, template) request. Path identifies the
+// offending field using Go field names, e.g. "LeafSwitches[0].LinkPerSpineCount".
+type DesignValidationError struct {
+	Path    string
+	Message string
+}
+
+func (o DesignValidationError) Error() string {
+	return o.Path + ": " + o.Message
+}
+
+// DesignValidationErrors is the collection of problems found by offline
+// validation of a design request.
+type DesignValidationErrors []DesignValidationError
+
+func (o DesignValidationErrors) Error() string {
+	s := make([]string, len(o))
+	for i, e := range o {
+		s[i] = e.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// err returns nil when o is empty, so that callers don't wind up with a
+// non-nil error interface holding an empty slice.
+func (o DesignValidationErrors) err() error {
+	if len(o) == 0 {
+		return nil
+	}
+	return o
+}
+
+// add records a problem. Duplicates (e.g. the same shortfall found on both
+// members of a redundant pair) are recorded only once.
+func (o *DesignValidationErrors) add(path, format string, a ...any) {
+	e := DesignValidationError{Path: path, Message: fmt.Sprintf(format, a...)}
+	for _, existing := range *o {
+		if existing == e {
+			return
+		}
+	}
+	*o = append(*o, e)
+}
+
+var portSpeedRegex = regexp.MustCompile(`^\d+[mgt]?(bps|b/s)?$`)
+
+// validPortSpeed returns true when s can be parsed as a LogicalDevicePortSpeed.
+// LogicalDevicePortSpeed.raw() quietly substitutes 1G for unparseable values.
+func validPortSpeed(s LogicalDevicePortSpeed) bool {
+	return portSpeedRegex.MatchString(strings.ToLower(strings.TrimSpace(string(s))))
+}
+
+// logicalDevicePortDemand is a requirement for Count ports with the given
+// Speed, taken from port groups which support Role. Path is used when
+// reporting a shortfall.
+type logicalDevicePortDemand struct {
+	Path  string
+	Role  LogicalDevicePortRoleFlags
+	Speed LogicalDevicePortSpeed
+	Count int
+}
+
+// allocatePorts determines whether the logical device's port groups can
+// satisfy all demands at once. Port groups frequently support more than one
+// role, so this is solved as a max-flow problem (demands -> port groups)
+// rather than by checking each demand in isolation. Demands are satisfied in
+// order, so any shortfall is attributed to the later demands. The returned
+// slice indicates the unmet port count of each demand.
+func (o *LogicalDeviceData) allocatePorts(demands []logicalDevicePortDemand) []int {
+	var groups []LogicalDevicePortGroup
+	for _, panel := range o.Panels {
+		groups = append(groups, panel.PortGroups...)
+	}
+
+	// node 0 is the source, followed by demands, then port groups, then the sink
+	d, g := len(demands), len(groups)
+	source, sink := 0, d+g+1
+	capacity := make([][]int, d+g+2)
+	for i := range capacity {
+		capacity[i] = make([]int, d+g+2)
+	}
+
+	for i, demand := range demands {
+		for j, group := range groups {
+			if group.Roles&demand.Role != 0 && group.Speed.IsEqual(demand.Speed) {
+				capacity[1+i][1+d+j] = demand.Count
+			}
+		}
+	}
+	for j, group := range groups {
+		capacity[1+d+j][sink] = group.Count
+	}
+
+	result := make([]int, d)
+	for i, demand := range demands {
+		// open the source->demand edge for this demand only, then Edmonds-Karp.
+		// Earlier allocations may be rerouted, but never reduced.
+		capacity[source][1+i] = demand.Count
+		for {
+			parent := make([]int, len(capacity))
+			for j := range parent {
+				parent[j] = -1
+			}
+			parent[source] = source
+			queue := []int{source}
+			for len(queue) > 0 && parent[sink] == -1 {
+				u := queue[0]
+				queue = queue[1:]
+				for v := range capacity[u] {
+					if parent[v] == -1 && capacity[u][v] > 0 {
+						parent[v] = u
+						queue = append(queue, v)
+					}
+				}
+			}
+			if parent[sink] == -1 {
+				break
+			}
+
+			flow := -1
+			for v := sink; v != source; v = parent[v] {
+				if c := capacity[parent[v]][v]; flow == -1 || c < flow {
+					flow = c
+				}
+			}
+			for v := sink; v != source; v = parent[v] {
+				capacity[parent[v]][v] -= flow
+				capacity[v][parent[v]] += flow
+			}
+		}
+
+		// whatever remains on the source->demand edge went unallocated
+		result[i] = capacity[source][1+i]
+		capacity[source][1+i] = 0
+	}
+
+	return result
+}
+
+// checkPorts adds an error for each demand the logical device cannot satisfy.
+func (o *LogicalDeviceData) checkPorts(path string, demands []logicalDevicePortDemand, errs *DesignValidationErrors) {
+	for i, unmet := range o.allocatePorts(demands) {
+		if unmet == 0 {
+			continue
+		}
+		errs.add(demands[i].Path, "logical device %s (%q) cannot supply %d %s port(s) with role %q: %d port(s) short",
+			path, o.DisplayName, demands[i].Count, demands[i].Speed, strings.Join(demands[i].Role.Strings(), ","), unmet)
+	}
+}
+
+// rackSwitch describes one leaf or access switch element while validating a
+// rack. demands are indexed by pair member (0 = "first", 1 = "second").
+type rackSwitch struct {
+	path      string
+	redundant bool
+	isLeaf    bool
+	demands   [2][]logicalDevicePortDemand
+}
+
+// rackDemands is the port consumption of each element of a rack. Leaf and
+// access switch demands are per-device, generic system demands are
+// per-instance. Slices are indexed like the corresponding RackTypeData slices.
+type rackDemands struct {
+	leafSwitches   []*rackSwitch
+	accessSwitches []*rackSwitch
+	genericSystems [][]logicalDevicePortDemand
+}
+
+// validateRackTypeData checks the internal consistency and port feasibility
+// of a rack. spineCount is the number of spines each leaf connects to. It is
+// only known in the context of a template, so it may be 0 when validating a
+// standalone rack type, in which case a single spine is assumed. The port
+// consumption of each rack element is returned.
+func validateRackTypeData(path string, rack *RackTypeData, spineCount int, errs *DesignValidationErrors) *rackDemands {
+	demands := newRackDemands(path, rack, spineCount, errs)
+
+	for i, generic := range rack.GenericSystems {
+		if generic.LogicalDevice != nil {
+			generic.LogicalDevice.checkPorts(fmt.Sprintf("%sGenericSystems[%d].LogicalDevice", path, i), demands.genericSystems[i], errs)
+		}
+	}
+
+	for i, access := range rack.AccessSwitches {
+		if access.LogicalDevice != nil {
+			demands.accessSwitches[i].checkPorts(access.LogicalDevice, errs)
+		}
+	}
+
+	for i, leaf := range rack.LeafSwitches {
+		if leaf.LogicalDevice != nil {
+			demands.leafSwitches[i].checkPorts(leaf.LogicalDevice, errs)
+		}
+	}
+
+	return demands
+}
+
+// checkPorts checks the demands of each member of the switch (pair) against
+// the logical device.
+func (o *rackSwitch) checkPorts(ld *LogicalDeviceData, errs *DesignValidationErrors) {
+	for member := 0; member < 2; member++ {
+		if member == 1 && !o.redundant {
+			break
+		}
+		ld.checkPorts(o.path+".LogicalDevice", o.demands[member], errs)
+	}
+}
+
+// newRackDemands checks the internal consistency of a rack and calculates the
+// port consumption of each of its elements. See validateRackTypeData for the
+// meaning of spineCount.
+func newRackDemands(path string, rack *RackTypeData, spineCount int, errs *DesignValidationErrors) *rackDemands {
+	if spineCount < 1 {
+		spineCount = 1
+	}
+
+	if rack.DisplayName == "" {
+		errs.add(path+"DisplayName", "must not be empty")
+	}
+
+	if len(rack.LeafSwitches) == 0 {
+		errs.add(path+"LeafSwitches", "at least one leaf switch is required")
+	}
+
+	// switches by label; labels must be unique among all rack elements
+	switches := make(map[string]*rackSwitch)
+	labels := make(map[string]string) // label -> path of first use
+	checkLabel := func(p, label string) {
+		if label == "" {
+			errs.add(p+".Label", "must not be empty")
+			return
+		}
+		if prior, ok := labels[label]; ok {
+			errs.add(p+".Label", "label %q already used by %s", label, prior)
+			return
+		}
+		labels[label] = p
+	}
+
+	l3Collapsed := rack.FabricConnectivityDesign == FabricConnectivityDesignL3Collapsed
+
+	result := rackDemands{
+		leafSwitches:   make([]*rackSwitch, len(rack.LeafSwitches)),
+		accessSwitches: make([]*rackSwitch, len(rack.AccessSwitches)),
+		genericSystems: make([][]logicalDevicePortDemand, len(rack.GenericSystems)),
+	}
+
+	for i, leaf := range rack.LeafSwitches {
+		p := fmt.Sprintf("%sLeafSwitches[%d]", path, i)
+		checkLabel(p, leaf.Label)
+
+		sw := &rackSwitch{
+			path:      p,
+			redundant: leaf.RedundancyProtocol != LeafRedundancyProtocolNone,
+			isLeaf:    true,
+		}
+		result.leafSwitches[i] = sw
+		if leaf.Label != "" {
+			switches[leaf.Label] = sw
+		}
+
+		var demands []logicalDevicePortDemand
+
+		switch {
+		case leaf.RedundancyProtocol == LeafRedundancyProtocolMlag && leaf.MlagInfo == nil:
+			errs.add(p+".MlagInfo", "required with redundancy protocol %q", leaf.RedundancyProtocol)
+		case leaf.RedundancyProtocol != LeafRedundancyProtocolMlag && leaf.MlagInfo != nil:
+			errs.add(p+".MlagInfo", "must be nil with redundancy protocol %q", leaf.RedundancyProtocol)
+		case leaf.MlagInfo != nil:
+			mi := leaf.MlagInfo
+			if mi.LeafLeafLinkCount < 1 {
+				errs.add(p+".MlagInfo.LeafLeafLinkCount", "must be at least 1")
+			} else if !validPortSpeed(mi.LeafLeafLinkSpeed) {
+				errs.add(p+".MlagInfo.LeafLeafLinkSpeed", "invalid port speed %q", mi.LeafLeafLinkSpeed)
+			} else {
+				demands = append(demands, logicalDevicePortDemand{
+					Path:  p + ".MlagInfo.LeafLeafLinkCount",
+					Role:  LogicalDevicePortRolePeer,
+					Speed: mi.LeafLeafLinkSpeed,
+					Count: mi.LeafLeafLinkCount,
+				})
+			}
+			if mi.LeafLeafL3LinkCount > 0 {
+				if !validPortSpeed(mi.LeafLeafL3LinkSpeed) {
+					errs.add(p+".MlagInfo.LeafLeafL3LinkSpeed", "invalid port speed %q", mi.LeafLeafL3LinkSpeed)
+				} else {
+					demands = append(demands, logicalDevicePortDemand{
+						Path:  p + ".MlagInfo.LeafLeafL3LinkCount",
+						Role:  LogicalDevicePortRolePeer,
+						Speed: mi.LeafLeafL3LinkSpeed,
+						Count: mi.LeafLeafL3LinkCount,
+					})
+				}
+			}
+			if mi.MlagVlanId < 1 || mi.MlagVlanId > 4094 {
+				errs.add(p+".MlagInfo.MlagVlanId", "must be between 1 and 4094, got %d", mi.MlagVlanId)
+			}
+		}
+
+		// spine uplinks come after peer links so that a port shortfall is blamed
+		// on the uplinks, which scale with the number of spines
+		switch {
+		case l3Collapsed && leaf.LinkPerSpineCount != 0:
+			errs.add(p+".LinkPerSpineCount", "must be 0 with fabric connectivity design %q", rack.FabricConnectivityDesign)
+		case !l3Collapsed && leaf.LinkPerSpineCount < 1:
+			errs.add(p+".LinkPerSpineCount", "must be at least 1 with fabric connectivity design %q", rack.FabricConnectivityDesign)
+		case !l3Collapsed && !validPortSpeed(leaf.LinkPerSpineSpeed):
+			errs.add(p+".LinkPerSpineSpeed", "invalid port speed %q", leaf.LinkPerSpineSpeed)
+		case !l3Collapsed:
+			demands = append(demands, logicalDevicePortDemand{
+				Path:  p + ".LinkPerSpineCount",
+				Role:  LogicalDevicePortRoleSpine,
+				Speed: leaf.LinkPerSpineSpeed,
+				Count: leaf.LinkPerSpineCount * spineCount,
+			})
+		}
+
+		sw.demands[0] = demands
+		sw.demands[1] = append([]logicalDevicePortDemand{}, demands...)
+	}
+
+	for i, access := range rack.AccessSwitches {
+		p := fmt.Sprintf("%sAccessSwitches[%d]", path, i)
+		checkLabel(p, access.Label)
+
+		if access.InstanceCount < 1 {
+			errs.add(p+".InstanceCount", "must be at least 1")
+		}
+
+		sw := &rackSwitch{
+			path:      p,
+			redundant: access.RedundancyProtocol == AccessRedundancyProtocolEsi,
+		}
+		result.accessSwitches[i] = sw
+		if access.Label != "" {
+			switches[access.Label] = sw
+		}
+
+		var demands []logicalDevicePortDemand
+		switch {
+		case sw.redundant && access.EsiLagInfo == nil:
+			errs.add(p+".EsiLagInfo", "required with redundancy protocol %q", access.RedundancyProtocol)
+		case !sw.redundant && access.EsiLagInfo != nil:
+			errs.add(p+".EsiLagInfo", "must be nil with redundancy protocol %q", access.RedundancyProtocol)
+		case access.EsiLagInfo != nil && access.EsiLagInfo.AccessAccessLinkCount > 0:
+			if !validPortSpeed(access.EsiLagInfo.AccessAccessLinkSpeed) {
+				errs.add(p+".EsiLagInfo.AccessAccessLinkSpeed", "invalid port speed %q", access.EsiLagInfo.AccessAccessLinkSpeed)
+			} else {
+				demands = append(demands, logicalDevicePortDemand{
+					Path:  p + ".EsiLagInfo.AccessAccessLinkCount",
+					Role:  LogicalDevicePortRolePeer,
+					Speed: access.EsiLagInfo.AccessAccessLinkSpeed,
+					Count: access.EsiLagInfo.AccessAccessLinkCount,
+				})
+			}
+		}
+		sw.demands[0] = demands
+		sw.demands[1] = append([]logicalDevicePortDemand{}, demands...)
+	}
+
+	// addLinkDemands records the port consumption of a link on both ends. The
+	// link's source (an access switch or generic system) consumes localRole
+	// ports on each of its instances. The target switch consumes remoteRole
+	// ports for each of those instances.
+	addLinkDemands := func(p string, link RackLink, instances int, localRole, remoteRole LogicalDevicePortRoleFlags, local *[]logicalDevicePortDemand, allowedTarget func(*rackSwitch) bool) {
+		if link.LinkPerSwitchCount < 1 {
+			errs.add(p+".LinkPerSwitchCount", "must be at least 1")
+			return
+		}
+		if !validPortSpeed(link.LinkSpeed) {
+			errs.add(p+".LinkSpeed", "invalid port speed %q", link.LinkSpeed)
+			return
+		}
+
+		target, ok := switches[link.TargetSwitchLabel]
+		if !ok {
+			errs.add(p+".TargetSwitchLabel", "no switch with label %q in rack", link.TargetSwitchLabel)
+			return
+		}
+		if !allowedTarget(target) {
+			errs.add(p+".TargetSwitchLabel", "switch %q (%s) cannot be the target of this link", link.TargetSwitchLabel, target.path)
+			return
+		}
+
+		dual := link.AttachmentType == RackLinkAttachmentTypeDual
+		switch {
+		case dual && !target.redundant:
+			errs.add(p+".AttachmentType", "dual-attached links require a redundant target switch, %q is not redundant", link.TargetSwitchLabel)
+			return
+		case dual && link.LagMode == RackLinkLagModeNone:
+			errs.add(p+".LagMode", "dual-attached links require a LAG mode")
+			return
+		case dual && link.SwitchPeer != RackLinkSwitchPeerNone:
+			errs.add(p+".SwitchPeer", "must not be set for dual-attached links")
+			return
+		case !target.redundant && link.SwitchPeer != RackLinkSwitchPeerNone:
+			errs.add(p+".SwitchPeer", "must not be set when target switch %q is not redundant", link.TargetSwitchLabel)
+			return
+		}
+
+		// ports consumed at each instance of the link's source
+		localCount := link.LinkPerSwitchCount
+		if dual {
+			localCount *= 2
+		}
+		*local = append(*local, logicalDevicePortDemand{
+			Path:  p + ".LinkPerSwitchCount",
+			Role:  localRole,
+			Speed: link.LinkSpeed,
+			Count: localCount,
+		})
+
+		// ports consumed at the target switch (or each member of the target pair)
+		for member := 0; member < 2; member++ {
+			switch {
+			case dual:
+			case member == 0 && link.SwitchPeer != RackLinkSwitchPeerSecond:
+			case member == 1 && link.SwitchPeer == RackLinkSwitchPeerSecond:
+			default:
+				continue
+			}
+			target.demands[member] = append(target.demands[member], logicalDevicePortDemand{
+				Path:  p + ".LinkPerSwitchCount",
+				Role:  remoteRole,
+				Speed: link.LinkSpeed,
+				Count: link.LinkPerSwitchCount * instances,
+			})
+		}
+	}
+
+	// access switch uplinks
+	accessDemands := make([][]logicalDevicePortDemand, len(rack.AccessSwitches))
+	for i, access := range rack.AccessSwitches {
+		p := fmt.Sprintf("%sAccessSwitches[%d]", path, i)
+		if len(access.Links) == 0 {
+			errs.add(p+".Links", "access switches require at least one link to a leaf switch")
+		}
+		for j, link := range access.Links {
+			addLinkDemands(fmt.Sprintf("%s.Links[%d]", p, j), link, access.InstanceCount,
+				LogicalDevicePortRoleLeaf, LogicalDevicePortRoleAccess, &accessDemands[i],
+				func(target *rackSwitch) bool { return target.isLeaf })
+		}
+	}
+
+	// generic systems
+	for i, generic := range rack.GenericSystems {
+		p := fmt.Sprintf("%sGenericSystems[%d]", path, i)
+		checkLabel(p, generic.Label)
+
+		if generic.Count < 1 {
+			errs.add(p+".Count", "must be at least 1")
+		}
+		if generic.PortChannelIdMin > generic.PortChannelIdMax {
+			errs.add(p+".PortChannelIdMin", "must not exceed PortChannelIdMax (%d)", generic.PortChannelIdMax)
+		}
+
+		var demands []logicalDevicePortDemand
+		for j, link := range generic.Links {
+			localRole := LogicalDevicePortRoleLeaf
+			if target, ok := switches[link.TargetSwitchLabel]; ok && !target.isLeaf {
+				localRole = LogicalDevicePortRoleAccess
+			}
+			addLinkDemands(fmt.Sprintf("%s.Links[%d]", p, j), link, generic.Count,
+				localRole, LogicalDevicePortRoleGeneric, &demands,
+				func(*rackSwitch) bool { return true })
+		}
+
+		result.genericSystems[i] = demands
+	}
+
+	// access switch uplinks are consumed on each member of the access pair
+	for i, sw := range result.accessSwitches {
+		for member := range sw.demands {
+			sw.demands[member] = append(append([]logicalDevicePortDemand{}, accessDemands[i]...), sw.demands[member]...)
+		}
+	}
+
+	return &result
+}
+
+// rackTypeData converts the request into RackTypeData using the supplied
+// logical devices (keyed by ID) so that it can be validated offline.
+// Unresolvable logical devices are reported in errs.
+func (o *RackTypeRequest) rackTypeData(logicalDevices map[ObjectId]LogicalDeviceData, errs *DesignValidationErrors) *RackTypeData {
+	resolve := func(path string, id ObjectId) *LogicalDeviceData {
+		if id == "" {
+			errs.add(path, "must not be empty")
+			return nil
+		}
+		ld, ok := logicalDevices[id]
+		if !ok {
+			errs.add(path, "logical device %q not found", id)
+			return nil
+		}
+		return &ld
+	}
+
+	links := func(in []RackLinkRequest) []RackLink {
+		result := make([]RackLink, len(in))
+		for i, l := range in {
+			result[i] = RackLink{
+				Label:              l.Label,
+				LinkPerSwitchCount: l.LinkPerSwitchCount,
+				LinkSpeed:          l.LinkSpeed,
+				TargetSwitchLabel:  l.TargetSwitchLabel,
+				AttachmentType:     l.AttachmentType,
+				LagMode:            l.LagMode,
+				SwitchPeer:         l.SwitchPeer,
+			}
+		}
+		return result
+	}
+
+	result := RackTypeData{
+		DisplayName:              o.DisplayName,
+		Description:              o.Description,
+		FabricConnectivityDesign: o.FabricConnectivityDesign,
+		LeafSwitches:             make([]RackElementLeafSwitch, len(o.LeafSwitches)),
+		AccessSwitches:           make([]RackElementAccessSwitch, len(o.AccessSwitches)),
+		GenericSystems:           make([]RackElementGenericSystem, len(o.GenericSystems)),
+	}
+
+	for i, leaf := range o.LeafSwitches {
+		result.LeafSwitches[i] = RackElementLeafSwitch{
+			Label:              leaf.Label,
+			LinkPerSpineCount:  leaf.LinkPerSpineCount,
+			LinkPerSpineSpeed:  leaf.LinkPerSpineSpeed,
+			MlagInfo:           leaf.MlagInfo,
+			RedundancyProtocol: leaf.RedundancyProtocol,
+			LogicalDevice:      resolve(fmt.Sprintf("LeafSwitches[%d].LogicalDeviceId", i), leaf.LogicalDeviceId),
+		}
+	}
+
+	for i, access := range o.AccessSwitches {
+		result.AccessSwitches[i] = RackElementAccessSwitch{
+			InstanceCount:      access.InstanceCount,
+			RedundancyProtocol: access.RedundancyProtocol,
+			Links:              links(access.Links),
+			Label:              access.Label,
+			LogicalDevice:      resolve(fmt.Sprintf("AccessSwitches[%d].LogicalDeviceId", i), access.LogicalDeviceId),
+			EsiLagInfo:         access.EsiLagInfo,
+		}
+	}
+
+	for i, generic := range o.GenericSystems {
+		result.GenericSystems[i] = RackElementGenericSystem{
+			Count:            generic.Count,
+			AsnDomain:        generic.AsnDomain,
+			ManagementLevel:  generic.ManagementLevel,
+			PortChannelIdMin: generic.PortChannelIdMin,
+			PortChannelIdMax: generic.PortChannelIdMax,
+			Loopback:         generic.Loopback,
+			Label:            generic.Label,
+			Links:            links(generic.Links),
+			LogicalDevice:    resolve(fmt.Sprintf("GenericSystems[%d].LogicalDeviceId", i), generic.LogicalDeviceId),
+		}
+	}
+
+	return &result
+}
+
+// logicalDeviceIds returns the IDs of the logical devices referenced by the
+// request.
+func (o *RackTypeRequest) logicalDeviceIds() []ObjectId {
+	m := make(map[ObjectId]struct{})
+	for _, leaf := range o.LeafSwitches {
+		m[leaf.LogicalDeviceId] = struct{}{}
+	}
+	for _, access := range o.AccessSwitches {
+		m[access.LogicalDeviceId] = struct{}{}
+	}
+	for _, generic := range o.GenericSystems {
+		m[generic.LogicalDeviceId] = struct{}{}
+	}
+	delete(m, "")
+
+	result := make([]ObjectId, 0, len(m))
+	for id := range m {
+		result = append(result, id)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
+
+// Validate checks the rack type request without contacting the Apstra API.
+// logicalDevices must contain every logical device referenced by the request,
+// keyed by ID. The returned error, if any, is DesignValidationErrors.
+func (o *RackTypeRequest) Validate(logicalDevices map[ObjectId]LogicalDeviceData) error {
+	var errs DesignValidationErrors
+	validateRackTypeData("", o.rackTypeData(logicalDevices, &errs), 0, &errs)
+	return errs.err()
+}
+
+// Validate checks the template request without contacting the Apstra API.
+// spineLogicalDevice is the logical device referenced by the Spine element,
+// rackTypes must contain every rack type referenced by RackInfos, keyed by ID.
+// Problems with the template's fields, with the referenced rack types, and
+// with the port capacity of leaf and spine logical devices are reported. The
+// returned error, if any, is DesignValidationErrors.
+func (o *CreateRackBasedTemplateRequest) Validate(spineLogicalDevice *LogicalDeviceData, rackTypes map[ObjectId]RackTypeData) error {
+	var errs DesignValidationErrors
+
+	if o.DisplayName == "" {
+		errs.add("DisplayName", "must not be empty")
+	}
+	if o.AsnAllocationPolicy == nil {
+		errs.add("AsnAllocationPolicy", "must not be nil")
+	}
+	if o.VirtualNetworkPolicy == nil {
+		errs.add("VirtualNetworkPolicy", "must not be nil")
+	}
+
+	spineCount := 0
+	switch {
+	case o.Spine == nil:
+		errs.add("Spine", "must not be nil")
+	case o.Spine.Count < 1:
+		errs.add("Spine.Count", "must be at least 1")
+	default:
+		spineCount = o.Spine.Count
+	}
+	if o.Spine != nil && spineLogicalDevice == nil {
+		errs.add("Spine.LogicalDevice", "logical device %q not found", o.Spine.LogicalDevice)
+	}
+
+	if len(o.RackInfos) == 0 {
+		errs.add("RackInfos", "at least one rack type is required")
+	}
+
+	// leaf-facing ports required on each spine, keyed by speed (bps)
+	spineDemands := make(map[int64]*logicalDevicePortDemand)
+	var spineDemandKeys []int64
+
+	rackTypeIds := make([]ObjectId, 0, len(o.RackInfos))
+	for id := range o.RackInfos {
+		rackTypeIds = append(rackTypeIds, id)
+	}
+	sort.Slice(rackTypeIds, func(i, j int) bool { return rackTypeIds[i] < rackTypeIds[j] })
+
+	for _, id := range rackTypeIds {
+		rackInfo := o.RackInfos[id]
+		p := fmt.Sprintf("RackInfos[%s]", id)
+		if rackInfo.RackTypeData != nil {
+			errs.add(p+".RackTypeData", "must be nil when creating a rack-based template")
+		}
+		if rackInfo.Count < 1 {
+			errs.add(p+".Count", "must be at least 1")
+		}
+
+		rack, ok := rackTypes[id]
+		if !ok {
+			errs.add(p, "rack type %q not found", id)
+			continue
+		}
+
+		if rack.FabricConnectivityDesign != FabricConnectivityDesignL3Clos {
+			errs.add(p, "rack type %q has fabric connectivity design %q, rack-based templates require %q",
+				id, rack.FabricConnectivityDesign, FabricConnectivityDesignL3Clos)
+			continue
+		}
+
+		validateRackTypeData(p+".", &rack, spineCount, &errs)
+
+		for _, leaf := range rack.LeafSwitches {
+			if leaf.LinkPerSpineCount < 1 || !validPortSpeed(leaf.LinkPerSpineSpeed) {
+				continue // already reported
+			}
+			leafCount := 1
+			if leaf.RedundancyProtocol != LeafRedundancyProtocolNone {
+				leafCount = 2
+			}
+			bps := leaf.LinkPerSpineSpeed.BitsPerSecond()
+			if _, ok := spineDemands[bps]; !ok {
+				spineDemands[bps] = &logicalDevicePortDemand{
+					Path:  "Spine.LogicalDevice",
+					Role:  LogicalDevicePortRoleLeaf,
+					Speed: leaf.LinkPerSpineSpeed,
+				}
+				spineDemandKeys = append(spineDemandKeys, bps)
+			}
+			spineDemands[bps].Count += rackInfo.Count * leafCount * leaf.LinkPerSpineCount
+		}
+	}
+
+	if o.Spine != nil && spineLogicalDevice != nil {
+		var demands []logicalDevicePortDemand
+		sort.Slice(spineDemandKeys, func(i, j int) bool { return spineDemandKeys[i] < spineDemandKeys[j] })
+		for _, k := range spineDemandKeys {
+			demands = append(demands, *spineDemands[k])
+		}
+		if o.Spine.LinkPerSuperspineCount > 0 {
+			if !validPortSpeed(o.Spine.LinkPerSuperspineSpeed) {
+				errs.add("Spine.LinkPerSuperspineSpeed", "invalid port speed %q", o.Spine.LinkPerSuperspineSpeed)
+			} else {
+				demands = append(demands, logicalDevicePortDemand{
+					Path:  "Spine.LinkPerSuperspineCount",
+					Role:  LogicalDevicePortRoleSuperspine,
+					Speed: o.Spine.LinkPerSuperspineSpeed,
+					Count: o.Spine.LinkPerSuperspineCount,
+				})
+			}
+		}
+		spineLogicalDevice.checkPorts("Spine.LogicalDevice", demands, &errs)
+	}
+
+	return errs.err()
+}
+
+// ValidateRackTypeRequest fetches the logical devices referenced by the rack
+// type request and validates it with RackTypeRequest.Validate. Nothing is
+// created. Validation problems are returned as DesignValidationErrors.
+func (o *Client) ValidateRackTypeRequest(ctx context.Context, in *RackTypeRequest) error {
+	logicalDevices := make(map[ObjectId]LogicalDeviceData)
+	for _, id := range in.logicalDeviceIds() {
+		ld, err := o.getLogicalDevice(ctx, id)
+		if err != nil {
+			var ace ClientErr
+			if errors.As(err, &ace) && ace.Type() == ErrNotfound {
+				continue // Validate will report it
+			}
+			return err
+		}
+		polished, err := ld.polish()
+		if err != nil {
+			return err
+		}
+		logicalDevices[id] = *polished.Data
+	}
+
+	return in.Validate(logicalDevices)
+}
+
+// ValidateRackBasedTemplateRequest fetches the spine logical device and rack
+// types referenced by the template request and validates it with
+// CreateRackBasedTemplateRequest.Validate. Nothing is created. Validation
+// problems are returned as DesignValidationErrors.
+func (o *Client) ValidateRackBasedTemplateRequest(ctx context.Context, in *CreateRackBasedTemplateRequest) error {
+	var errs DesignValidationErrors
+
+	if in.AntiAffinityPolicy == nil && o.Supports("CreateRackBasedTemplateRequest.AntiAffinityPolicy.Required") {
+		errs.add("AntiAffinityPolicy", "must not be nil with Apstra %s", o.apiVersion)
+	}
+
+	var spineLogicalDevice *LogicalDeviceData
+	if in.Spine != nil {
+		ld, err := o.getLogicalDevice(ctx, in.Spine.LogicalDevice)
+		var ace ClientErr
+		if err != nil && !(errors.As(err, &ace) && ace.Type() == ErrNotfound) {
+			return err
+		}
+		if err == nil {
+			polished, err := ld.polish()
+			if err != nil {
+				return err
+			}
+			spineLogicalDevice = polished.Data
+		}
+	}
+
+	rackTypes := make(map[ObjectId]RackTypeData, len(in.RackInfos))
+	for id := range in.RackInfos {
+		rt, err := o.GetRackType(ctx, id)
+		if err != nil {
+			var ace ClientErr
+			if errors.As(err, &ace) && ace.Type() == ErrNotfound {
+				continue // Validate will report it
+			}
+			return err
+		}
+		rackTypes[id] = *rt.Data
+	}
+
+	err := in.Validate(spineLogicalDevice, rackTypes)
+	var validationErrs DesignValidationErrors
+	if errors.As(err, &validationErrs) {
+		errs = append(errs, validationErrs...)
+	} else if err != nil {
+		return err
+	}
+
+	return errs.err()
+}
@@ -0,0 +1,198 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesignValidation(t *testing.T) {
+	// 48x10G leaf/access/generic ports, 6x40G spine/peer ports
+	leafLd := LogicalDeviceData{
+		DisplayName: "48x10+6x40",
+		Panels: []LogicalDevicePanel{{
+			PortGroups: []LogicalDevicePortGroup{
+				{Count: 48, Speed: "10G", Roles: LogicalDevicePortRoleLeaf | LogicalDevicePortRoleAccess | LogicalDevicePortRoleGeneric},
+				{Count: 6, Speed: "40G", Roles: LogicalDevicePortRoleSpine | LogicalDevicePortRolePeer},
+			},
+		}},
+	}
+	// 32x40G leaf-facing ports
+	spineLd := LogicalDeviceData{
+		DisplayName: "32x40",
+		Panels: []LogicalDevicePanel{{
+			PortGroups: []LogicalDevicePortGroup{
+				{Count: 32, Speed: "40G", Roles: LogicalDevicePortRoleLeaf | LogicalDevicePortRoleSuperspine},
+			},
+		}},
+	}
+	serverLd := LogicalDeviceData{
+		DisplayName: "2x10",
+		Panels: []LogicalDevicePanel{{
+			PortGroups: []LogicalDevicePortGroup{
+				{Count: 2, Speed: "10G", Roles: LogicalDevicePortRoleLeaf | LogicalDevicePortRoleAccess},
+			},
+		}},
+	}
+	logicalDevices := map[ObjectId]LogicalDeviceData{"leaf": leafLd, "server": serverLd}
+
+	validRack := func() RackTypeRequest {
+		return RackTypeRequest{
+			DisplayName:              "rack",
+			FabricConnectivityDesign: FabricConnectivityDesignL3Clos,
+			LeafSwitches: []RackElementLeafSwitchRequest{{
+				Label:              "leaf",
+				LinkPerSpineCount:  2,
+				LinkPerSpineSpeed:  "40G",
+				RedundancyProtocol: LeafRedundancyProtocolMlag,
+				MlagInfo: &LeafMlagInfo{
+					LeafLeafLinkCount: 2,
+					LeafLeafLinkSpeed: "40G",
+					MlagVlanId:        2999,
+				},
+				LogicalDeviceId: "leaf",
+			}},
+			GenericSystems: []RackElementGenericSystemRequest{{
+				Count:            20,
+				Label:            "server",
+				LogicalDeviceId:  "server",
+				PortChannelIdMin: 1,
+				PortChannelIdMax: 100,
+				Links: []RackLinkRequest{{
+					Label:              "link",
+					LinkPerSwitchCount: 1,
+					LinkSpeed:          "10G",
+					TargetSwitchLabel:  "leaf",
+					AttachmentType:     RackLinkAttachmentTypeDual,
+					LagMode:            RackLinkLagModeActive,
+				}},
+			}},
+		}
+	}
+
+	paths := func(t *testing.T, err error) []string {
+		t.Helper()
+		var errs DesignValidationErrors
+		require.True(t, errors.As(err, &errs), "expected DesignValidationErrors, got %v", err)
+		result := make([]string, len(errs))
+		for i, e := range errs {
+			result[i] = e.Path
+		}
+		return result
+	}
+
+	t.Run("rack_ok", func(t *testing.T) {
+		rack := validRack()
+		require.NoError(t, rack.Validate(logicalDevices))
+	})
+
+	t.Run("rack_missing_logical_device", func(t *testing.T) {
+		rack := validRack()
+		rack.LeafSwitches[0].LogicalDeviceId = "bogus"
+		require.Equal(t, []string{"LeafSwitches[0].LogicalDeviceId"}, paths(t, rack.Validate(logicalDevices)))
+	})
+
+	t.Run("rack_too_many_servers", func(t *testing.T) {
+		rack := validRack()
+		rack.GenericSystems[0].Count = 49 // each leaf has only 48 10G ports
+		require.Equal(t, []string{"GenericSystems[0].Links[0].LinkPerSwitchCount"}, paths(t, rack.Validate(logicalDevices)))
+	})
+
+	t.Run("rack_too_few_server_ports", func(t *testing.T) {
+		rack := validRack()
+		rack.GenericSystems[0].Links[0].LinkPerSwitchCount = 2 // dual-attached: 4 ports per server, only 2 exist
+		require.Contains(t, paths(t, rack.Validate(logicalDevices)), "GenericSystems[0].Links[0].LinkPerSwitchCount")
+	})
+
+	t.Run("rack_shared_port_groups", func(t *testing.T) {
+		// 4 spine uplinks + 2 peer links fit in 6 ports only if allocated together correctly
+		rack := validRack()
+		rack.LeafSwitches[0].LinkPerSpineCount = 4
+		require.NoError(t, rack.Validate(logicalDevices))
+		rack.LeafSwitches[0].LinkPerSpineCount = 5
+		require.Equal(t, []string{"LeafSwitches[0].LinkPerSpineCount"}, paths(t, rack.Validate(logicalDevices)))
+	})
+
+	t.Run("rack_redundancy_constraints", func(t *testing.T) {
+		rack := validRack()
+		rack.LeafSwitches[0].RedundancyProtocol = LeafRedundancyProtocolNone
+		require.Equal(t, []string{
+			"LeafSwitches[0].MlagInfo",
+			"GenericSystems[0].Links[0].AttachmentType",
+		}, paths(t, rack.Validate(logicalDevices)))
+
+		rack = validRack()
+		rack.GenericSystems[0].Links[0].LagMode = RackLinkLagModeNone
+		require.Equal(t, []string{"GenericSystems[0].Links[0].LagMode"}, paths(t, rack.Validate(logicalDevices)))
+	})
+
+	t.Run("rack_labels_and_speeds", func(t *testing.T) {
+		rack := validRack()
+		rack.GenericSystems[0].Label = "leaf"
+		rack.GenericSystems[0].Links[0].TargetSwitchLabel = "nope"
+		rack.LeafSwitches[0].LinkPerSpineSpeed = "fast"
+		require.Equal(t, []string{
+			"LeafSwitches[0].LinkPerSpineSpeed",
+			"GenericSystems[0].Label",
+			"GenericSystems[0].Links[0].TargetSwitchLabel",
+		}, paths(t, rack.Validate(logicalDevices)))
+	})
+
+	rackTypeData := func(t *testing.T, rack RackTypeRequest) RackTypeData {
+		t.Helper()
+		var errs DesignValidationErrors
+		result := rack.rackTypeData(logicalDevices, &errs)
+		require.Empty(t, errs)
+		return *result
+	}
+
+	validTemplate := func() CreateRackBasedTemplateRequest {
+		return CreateRackBasedTemplateRequest{
+			DisplayName: "template",
+			Spine: &TemplateElementSpineRequest{
+				Count:         2,
+				LogicalDevice: "spine",
+			},
+			RackInfos:            map[ObjectId]TemplateRackBasedRackInfo{"rack": {Count: 4}},
+			AsnAllocationPolicy:  &AsnAllocationPolicy{},
+			VirtualNetworkPolicy: &VirtualNetworkPolicy{},
+		}
+	}
+
+	t.Run("template_ok", func(t *testing.T) {
+		template := validTemplate()
+		// 4 racks * 2 leafs * 2 links = 16 ports per spine
+		require.NoError(t, template.Validate(&spineLd, map[ObjectId]RackTypeData{"rack": rackTypeData(t, validRack())}))
+	})
+
+	t.Run("template_spine_capacity", func(t *testing.T) {
+		template := validTemplate()
+		template.RackInfos["rack"] = TemplateRackBasedRackInfo{Count: 9} // 36 ports per spine
+		require.Equal(t, []string{"Spine.LogicalDevice"},
+			paths(t, template.Validate(&spineLd, map[ObjectId]RackTypeData{"rack": rackTypeData(t, validRack())})))
+	})
+
+	t.Run("template_leaf_uplinks_scale_with_spines", func(t *testing.T) {
+		template := validTemplate()
+		template.Spine.Count = 4 // 2 links to each of 4 spines + 2 peer links exceeds 6 ports
+		require.Equal(t, []string{"RackInfos[rack].LeafSwitches[0].LinkPerSpineCount"},
+			paths(t, template.Validate(&spineLd, map[ObjectId]RackTypeData{"rack": rackTypeData(t, validRack())})))
+	})
+
+	t.Run("template_missing_references", func(t *testing.T) {
+		template := validTemplate()
+		template.Spine.Count = 0
+		template.VirtualNetworkPolicy = nil
+		require.Equal(t, []string{
+			"VirtualNetworkPolicy",
+			"Spine.Count",
+			"Spine.LogicalDevice",
+			"RackInfos[rack]",
+		}, paths(t, template.Validate(nil, nil)))
+	})
+}
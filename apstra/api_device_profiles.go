@@ -6,6 +6,7 @@ package apstra
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -261,6 +262,8 @@ type DeviceProfileData struct {
 	ChassisInfo          DeviceProfileChassisInfo
 	LinecardsInfo        []DeviceProfileLinecardInfo
 	SlotConfiguration    []DeviceProfileSlotConfiguration
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 func (o *DeviceProfileData) raw() *rawDeviceProfileData {
@@ -348,6 +351,12 @@ type rawDeviceProfileData struct {
 	ChassisInfo          DeviceProfileChassisInfo
 	LinecardsInfo        []DeviceProfileLinecardInfo
 	SlotConfiguration    []DeviceProfileSlotConfiguration
+	unknownFields        unknownJsonFields
+}
+
+func (o rawDeviceProfileData) MarshalJSON() ([]byte, error) {
+	type alias rawDeviceProfileData
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 type DeviceProfileChassisInfo struct {
@@ -384,6 +393,18 @@ type rawDeviceProfile struct {
 	ChassisInfo          DeviceProfileChassisInfo         `json:"chassis_info"`
 	LinecardsInfo        []DeviceProfileLinecardInfo      `json:"linecards_info"`
 	SlotConfiguration    []DeviceProfileSlotConfiguration `json:"slot_configuration"`
+	unknownFields        unknownJsonFields
+}
+
+func (o *rawDeviceProfile) UnmarshalJSON(b []byte) error {
+	type alias rawDeviceProfile
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{})
+	return err
 }
 
 func (o *rawDeviceProfile) polish() *DeviceProfile {
@@ -409,6 +430,7 @@ func (o *rawDeviceProfile) polish() *DeviceProfile {
 			ChassisInfo:          o.ChassisInfo,
 			LinecardsInfo:        o.LinecardsInfo,
 			SlotConfiguration:    o.SlotConfiguration,
+			unknownFields:        o.unknownFields,
 		},
 	}
 }
@@ -0,0 +1,949 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/enum"
+)
+
+// IbaProbeProcessorProperties are the data structures which make the various
+// IBA probe processors (extensible data collector, range check, accumulate,
+// etc...) different from each other. Each supported processor type has an
+// implementation of this interface. Processors of types not known to the SDK
+// are represented by IbaProbeProcessorPropertiesOther.
+type IbaProbeProcessorProperties interface {
+	ProcessorType() string
+	fromRawJson(json.RawMessage) error
+	raw() (json.RawMessage, error)
+}
+
+// IbaProbeProcessorInput identifies the stage (and optionally the column
+// within that stage) consumed by a processor.
+type IbaProbeProcessorInput struct {
+	Stage  string `json:"stage"`
+	Column string `json:"column,omitempty"`
+}
+
+// IbaProbeProcessor is a single processor within an IBA probe. Inputs are keyed
+// by the processor's input name (usually "in"), Outputs map the processor's
+// output name (usually "out") to the name of the stage it produces.
+type IbaProbeProcessor struct {
+	Name       string
+	Inputs     map[string]IbaProbeProcessorInput
+	Outputs    map[string]string
+	Properties IbaProbeProcessorProperties
+}
+
+type rawIbaProbeProcessor struct {
+	Name       string                            `json:"name"`
+	Type       string                            `json:"type"`
+	Inputs     map[string]IbaProbeProcessorInput `json:"inputs"`
+	Outputs    map[string]string                 `json:"outputs"`
+	Properties json.RawMessage                   `json:"properties"`
+}
+
+func (o *rawIbaProbeProcessor) polish() (*IbaProbeProcessor, error) {
+	var properties IbaProbeProcessorProperties
+	switch o.Type {
+	case enum.IbaProbeProcessorTypeAccumulate.Value:
+		properties = new(IbaProbeProcessorPropertiesAccumulate)
+	case enum.IbaProbeProcessorTypeExtensibleDataCollector.Value:
+		properties = new(IbaProbeProcessorPropertiesExtensibleDataCollector)
+	case enum.IbaProbeProcessorTypeMatchString.Value:
+		properties = new(IbaProbeProcessorPropertiesMatchString)
+	case enum.IbaProbeProcessorTypePeriodicAverage.Value:
+		properties = new(IbaProbeProcessorPropertiesPeriodicAverage)
+	case enum.IbaProbeProcessorTypeRangeCheck.Value:
+		properties = new(IbaProbeProcessorPropertiesRangeCheck)
+	default:
+		properties = &IbaProbeProcessorPropertiesOther{Type: o.Type}
+	}
+
+	if len(o.Properties) > 0 {
+		err := properties.fromRawJson(o.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing properties of %q processor %q - %w", o.Type, o.Name, err)
+		}
+	}
+
+	return &IbaProbeProcessor{
+		Name:       o.Name,
+		Inputs:     o.Inputs,
+		Outputs:    o.Outputs,
+		Properties: properties,
+	}, nil
+}
+
+func (o *IbaProbeProcessor) raw() (*rawIbaProbeProcessor, error) {
+	if o.Properties == nil {
+		return nil, fmt.Errorf("processor %q has no properties", o.Name)
+	}
+
+	properties, err := o.Properties.raw()
+	if err != nil {
+		return nil, fmt.Errorf("failed rendering properties of processor %q - %w", o.Name, err)
+	}
+
+	result := rawIbaProbeProcessor{
+		Name:       o.Name,
+		Type:       o.Properties.ProcessorType(),
+		Inputs:     o.Inputs,
+		Outputs:    o.Outputs,
+		Properties: properties,
+	}
+
+	// don't send `null` to the API. Send empty objects instead.
+	if result.Inputs == nil {
+		result.Inputs = make(map[string]IbaProbeProcessorInput)
+	}
+	if result.Outputs == nil {
+		result.Outputs = make(map[string]string)
+	}
+
+	return &result, nil
+}
+
+// IbaProbeQueryTagFilter restricts the graph query of a collector processor
+// to nodes carrying (or not carrying) particular tags.
+type IbaProbeQueryTagFilter struct {
+	Filter    map[string][]string `json:"filter"`
+	Operation string              `json:"operation"`
+}
+
+// IbaProbeProcessorPropertiesExtensibleDataCollector collects data from an
+// extensible telemetry service (a service registered with the telemetry
+// service registry) for each result of the graph query.
+var _ IbaProbeProcessorProperties = &IbaProbeProcessorPropertiesExtensibleDataCollector{}
+
+type IbaProbeProcessorPropertiesExtensibleDataCollector struct {
+	ServiceName               string
+	ServiceInterval           *time.Duration
+	ServiceIntervalExpression string // used instead of ServiceInterval, e.g. "{{ interval }}"
+	DataType                  string
+	GraphQuery                []string
+	QueryGroupBy              []string
+	QueryTagFilter            *IbaProbeQueryTagFilter
+	SystemId                  string
+	Keys                      []string
+	IngestionFilter           map[string]interface{}
+	EnableStreaming           bool
+	Extra                     map[string]interface{} // expression properties (e.g. "interface": "interface.if_name")
+}
+
+type rawIbaProbeProcessorPropertiesExtensibleDataCollector struct {
+	ServiceName     string                  `json:"service_name"`
+	ServiceInterval json.RawMessage         `json:"service_interval,omitempty"`
+	DataType        string                  `json:"data_type"`
+	GraphQuery      []string                `json:"graph_query"`
+	QueryGroupBy    []string                `json:"query_group_by"`
+	QueryTagFilter  *IbaProbeQueryTagFilter `json:"query_tag_filter,omitempty"`
+	SystemId        string                  `json:"system_id"`
+	Keys            []string                `json:"keys"`
+	IngestionFilter map[string]interface{}  `json:"ingestion_filter,omitempty"`
+	EnableStreaming bool                    `json:"enable_streaming"`
+}
+
+func (o *IbaProbeProcessorPropertiesExtensibleDataCollector) ProcessorType() string {
+	return enum.IbaProbeProcessorTypeExtensibleDataCollector.Value
+}
+
+func (o *IbaProbeProcessorPropertiesExtensibleDataCollector) fromRawJson(in json.RawMessage) error {
+	var raw rawIbaProbeProcessorPropertiesExtensibleDataCollector
+	err := json.Unmarshal(in, &raw)
+	if err != nil {
+		return err
+	}
+
+	extra, err := ibaProbePropertiesExtra(in, raw)
+	if err != nil {
+		return err
+	}
+
+	serviceInterval, serviceIntervalExpression, err := ibaProbeDurationOrExpression(raw.ServiceInterval)
+	if err != nil {
+		return fmt.Errorf("failed parsing service_interval %s - %w", string(raw.ServiceInterval), err)
+	}
+
+	o.ServiceName = raw.ServiceName
+	o.ServiceInterval = serviceInterval
+	o.ServiceIntervalExpression = serviceIntervalExpression
+	o.DataType = raw.DataType
+	o.GraphQuery = raw.GraphQuery
+	o.QueryGroupBy = raw.QueryGroupBy
+	o.QueryTagFilter = raw.QueryTagFilter
+	o.SystemId = raw.SystemId
+	o.Keys = raw.Keys
+	o.IngestionFilter = raw.IngestionFilter
+	o.EnableStreaming = raw.EnableStreaming
+	o.Extra = extra
+
+	return nil
+}
+
+func (o *IbaProbeProcessorPropertiesExtensibleDataCollector) raw() (json.RawMessage, error) {
+	raw := rawIbaProbeProcessorPropertiesExtensibleDataCollector{
+		ServiceName:     o.ServiceName,
+		DataType:        o.DataType,
+		GraphQuery:      o.GraphQuery,
+		QueryGroupBy:    o.QueryGroupBy,
+		QueryTagFilter:  o.QueryTagFilter,
+		SystemId:        o.SystemId,
+		Keys:            o.Keys,
+		IngestionFilter: o.IngestionFilter,
+		EnableStreaming: o.EnableStreaming,
+	}
+
+	switch {
+	case o.ServiceInterval != nil && o.ServiceIntervalExpression != "":
+		return nil, errors.New("service interval and service interval expression are mutually exclusive")
+	case o.ServiceInterval != nil:
+		seconds, err := ibaProbeDurationSeconds("service interval", *o.ServiceInterval)
+		if err != nil {
+			return nil, err
+		}
+		raw.ServiceInterval = json.RawMessage(strconv.Quote(strconv.Itoa(seconds)))
+	case o.ServiceIntervalExpression != "":
+		raw.ServiceInterval = json.RawMessage(strconv.Quote(o.ServiceIntervalExpression))
+	}
+
+	// don't send `null` to the API. Send empty arrays instead.
+	if raw.GraphQuery == nil {
+		raw.GraphQuery = []string{}
+	}
+	if raw.QueryGroupBy == nil {
+		raw.QueryGroupBy = []string{}
+	}
+	if raw.Keys == nil {
+		raw.Keys = []string{}
+	}
+
+	return ibaProbePropertiesWithExtra(raw, o.Extra)
+}
+
+// IbaProbeRange is the (inclusive) range used by check processors. Either end
+// may be omitted.
+type IbaProbeRange struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// IbaProbeProcessorPropertiesRangeCheck checks whether the input values fall
+// within (or outside of, depending on the server's interpretation of Range) a
+// range, optionally raising an anomaly for each anomalous item.
+var _ IbaProbeProcessorProperties = &IbaProbeProcessorPropertiesRangeCheck{}
+
+type IbaProbeProcessorPropertiesRangeCheck struct {
+	Property        string
+	Range           IbaProbeRange
+	RaiseAnomaly    bool
+	EnableStreaming bool
+	Extra           map[string]interface{}
+}
+
+type rawIbaProbeProcessorPropertiesRangeCheck struct {
+	Property        string        `json:"property"`
+	Range           IbaProbeRange `json:"range"`
+	RaiseAnomaly    bool          `json:"raise_anomaly"`
+	EnableStreaming bool          `json:"enable_streaming"`
+}
+
+func (o *IbaProbeProcessorPropertiesRangeCheck) ProcessorType() string {
+	return enum.IbaProbeProcessorTypeRangeCheck.Value
+}
+
+func (o *IbaProbeProcessorPropertiesRangeCheck) fromRawJson(in json.RawMessage) error {
+	var raw rawIbaProbeProcessorPropertiesRangeCheck
+	err := json.Unmarshal(in, &raw)
+	if err != nil {
+		return err
+	}
+
+	extra, err := ibaProbePropertiesExtra(in, raw)
+	if err != nil {
+		return err
+	}
+
+	o.Property = raw.Property
+	o.Range = raw.Range
+	o.RaiseAnomaly = raw.RaiseAnomaly
+	o.EnableStreaming = raw.EnableStreaming
+	o.Extra = extra
+
+	return nil
+}
+
+func (o *IbaProbeProcessorPropertiesRangeCheck) raw() (json.RawMessage, error) {
+	raw := rawIbaProbeProcessorPropertiesRangeCheck{
+		Property:        o.Property,
+		Range:           o.Range,
+		RaiseAnomaly:    o.RaiseAnomaly,
+		EnableStreaming: o.EnableStreaming,
+	}
+
+	if raw.Property == "" {
+		raw.Property = "value"
+	}
+
+	return ibaProbePropertiesWithExtra(raw, o.Extra)
+}
+
+// IbaProbeProcessorPropertiesMatchString checks input string values against
+// a list of regular expressions, optionally raising an anomaly for each
+// anomalous item.
+var _ IbaProbeProcessorProperties = &IbaProbeProcessorPropertiesMatchString{}
+
+type IbaProbeProcessorPropertiesMatchString struct {
+	Property        string
+	RegexValues     []string
+	RaiseAnomaly    bool
+	EnableStreaming bool
+	Extra           map[string]interface{}
+}
+
+type rawIbaProbeProcessorPropertiesMatchString struct {
+	Property        string   `json:"property"`
+	RegexValues     []string `json:"regex_values"`
+	RaiseAnomaly    bool     `json:"raise_anomaly"`
+	EnableStreaming bool     `json:"enable_streaming"`
+}
+
+func (o *IbaProbeProcessorPropertiesMatchString) ProcessorType() string {
+	return enum.IbaProbeProcessorTypeMatchString.Value
+}
+
+func (o *IbaProbeProcessorPropertiesMatchString) fromRawJson(in json.RawMessage) error {
+	var raw rawIbaProbeProcessorPropertiesMatchString
+	err := json.Unmarshal(in, &raw)
+	if err != nil {
+		return err
+	}
+
+	extra, err := ibaProbePropertiesExtra(in, raw)
+	if err != nil {
+		return err
+	}
+
+	o.Property = raw.Property
+	o.RegexValues = raw.RegexValues
+	o.RaiseAnomaly = raw.RaiseAnomaly
+	o.EnableStreaming = raw.EnableStreaming
+	o.Extra = extra
+
+	return nil
+}
+
+func (o *IbaProbeProcessorPropertiesMatchString) raw() (json.RawMessage, error) {
+	raw := rawIbaProbeProcessorPropertiesMatchString{
+		Property:        o.Property,
+		RegexValues:     o.RegexValues,
+		RaiseAnomaly:    o.RaiseAnomaly,
+		EnableStreaming: o.EnableStreaming,
+	}
+
+	if raw.Property == "" {
+		raw.Property = "value"
+	}
+
+	// don't send `null` to the API. Send an empty array instead.
+	if raw.RegexValues == nil {
+		raw.RegexValues = []string{}
+	}
+
+	return ibaProbePropertiesWithExtra(raw, o.Extra)
+}
+
+// IbaProbeProcessorPropertiesAccumulate accumulates input samples into a
+// time series. Samples are retained until either MaxSamples or TotalDuration
+// is exceeded.
+var _ IbaProbeProcessorProperties = &IbaProbeProcessorPropertiesAccumulate{}
+
+type IbaProbeProcessorPropertiesAccumulate struct {
+	MaxSamples              *int
+	TotalDuration           *time.Duration
+	TotalDurationExpression string // used instead of TotalDuration, e.g. "{{ duration }}"
+	EnableStreaming         bool
+	Extra                   map[string]interface{}
+}
+
+type rawIbaProbeProcessorPropertiesAccumulate struct {
+	MaxSamples      *int            `json:"max_samples,omitempty"`
+	TotalDuration   json.RawMessage `json:"total_duration,omitempty"`
+	EnableStreaming bool            `json:"enable_streaming"`
+}
+
+func (o *IbaProbeProcessorPropertiesAccumulate) ProcessorType() string {
+	return enum.IbaProbeProcessorTypeAccumulate.Value
+}
+
+func (o *IbaProbeProcessorPropertiesAccumulate) fromRawJson(in json.RawMessage) error {
+	var raw rawIbaProbeProcessorPropertiesAccumulate
+	err := json.Unmarshal(in, &raw)
+	if err != nil {
+		return err
+	}
+
+	extra, err := ibaProbePropertiesExtra(in, raw)
+	if err != nil {
+		return err
+	}
+
+	totalDuration, totalDurationExpression, err := ibaProbeDurationOrExpression(raw.TotalDuration)
+	if err != nil {
+		return fmt.Errorf("failed parsing total_duration %s - %w", string(raw.TotalDuration), err)
+	}
+
+	o.MaxSamples = raw.MaxSamples
+	o.TotalDuration = totalDuration
+	o.TotalDurationExpression = totalDurationExpression
+	o.EnableStreaming = raw.EnableStreaming
+	o.Extra = extra
+
+	return nil
+}
+
+func (o *IbaProbeProcessorPropertiesAccumulate) raw() (json.RawMessage, error) {
+	raw := rawIbaProbeProcessorPropertiesAccumulate{
+		MaxSamples:      o.MaxSamples,
+		EnableStreaming: o.EnableStreaming,
+	}
+
+	switch {
+	case o.TotalDuration != nil && o.TotalDurationExpression != "":
+		return nil, errors.New("total duration and total duration expression are mutually exclusive")
+	case o.TotalDuration != nil:
+		seconds, err := ibaProbeDurationSeconds("total duration", *o.TotalDuration)
+		if err != nil {
+			return nil, err
+		}
+		raw.TotalDuration = json.RawMessage(strconv.Itoa(seconds))
+	case o.TotalDurationExpression != "":
+		raw.TotalDuration = json.RawMessage(strconv.Quote(o.TotalDurationExpression))
+	}
+
+	return ibaProbePropertiesWithExtra(raw, o.Extra)
+}
+
+// IbaProbeProcessorPropertiesPeriodicAverage averages input values over Period.
+var _ IbaProbeProcessorProperties = &IbaProbeProcessorPropertiesPeriodicAverage{}
+
+type IbaProbeProcessorPropertiesPeriodicAverage struct {
+	Period          time.Duration
+	GraphQuery      []string
+	EnableStreaming bool
+	Extra           map[string]interface{}
+}
+
+type rawIbaProbeProcessorPropertiesPeriodicAverage struct {
+	Period          int      `json:"period"`
+	GraphQuery      []string `json:"graph_query"`
+	EnableStreaming bool     `json:"enable_streaming"`
+}
+
+func (o *IbaProbeProcessorPropertiesPeriodicAverage) ProcessorType() string {
+	return enum.IbaProbeProcessorTypePeriodicAverage.Value
+}
+
+func (o *IbaProbeProcessorPropertiesPeriodicAverage) fromRawJson(in json.RawMessage) error {
+	var raw rawIbaProbeProcessorPropertiesPeriodicAverage
+	err := json.Unmarshal(in, &raw)
+	if err != nil {
+		return err
+	}
+
+	extra, err := ibaProbePropertiesExtra(in, raw)
+	if err != nil {
+		return err
+	}
+
+	o.Period = time.Duration(raw.Period) * time.Second
+	o.GraphQuery = raw.GraphQuery
+	o.EnableStreaming = raw.EnableStreaming
+	o.Extra = extra
+
+	return nil
+}
+
+func (o *IbaProbeProcessorPropertiesPeriodicAverage) raw() (json.RawMessage, error) {
+	if o.Period < time.Second {
+		return nil, fmt.Errorf("periodic average period must be at least 1s, got %s", o.Period)
+	}
+
+	period, err := ibaProbeDurationSeconds("periodic average period", o.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := rawIbaProbeProcessorPropertiesPeriodicAverage{
+		Period:          period,
+		GraphQuery:      o.GraphQuery,
+		EnableStreaming: o.EnableStreaming,
+	}
+
+	// don't send `null` to the API. Send an empty array instead.
+	if raw.GraphQuery == nil {
+		raw.GraphQuery = []string{}
+	}
+
+	return ibaProbePropertiesWithExtra(raw, o.Extra)
+}
+
+// IbaProbeProcessorPropertiesOther carries the properties of processor types
+// which don't have a dedicated implementation in this SDK. Type must be the
+// processor type name used by the Apstra API.
+var _ IbaProbeProcessorProperties = &IbaProbeProcessorPropertiesOther{}
+
+type IbaProbeProcessorPropertiesOther struct {
+	Type       string
+	Properties map[string]interface{}
+}
+
+func (o *IbaProbeProcessorPropertiesOther) ProcessorType() string {
+	return o.Type
+}
+
+func (o *IbaProbeProcessorPropertiesOther) fromRawJson(in json.RawMessage) error {
+	return json.Unmarshal(in, &o.Properties)
+}
+
+func (o *IbaProbeProcessorPropertiesOther) raw() (json.RawMessage, error) {
+	if o.Type == "" {
+		return nil, errors.New("processor type must be specified")
+	}
+
+	if o.Properties == nil {
+		return json.RawMessage("{}"), nil
+	}
+
+	return json.Marshal(o.Properties)
+}
+
+// ibaProbeDurationSeconds converts d to the whole seconds used by the API,
+// rather than silently truncating durations like 1500ms.
+func ibaProbeDurationSeconds(name string, d time.Duration) (int, error) {
+	if d%time.Second != 0 {
+		return 0, fmt.Errorf("%s must be a whole number of seconds, got %s", name, d)
+	}
+
+	return int(d / time.Second), nil
+}
+
+// ibaProbeDurationOrExpression parses a duration property, which the API
+// represents as a number of seconds (possibly within a string), or as an
+// expression string like "{{ interval }}". Expressions are returned as-is.
+func ibaProbeDurationOrExpression(in json.RawMessage) (*time.Duration, string, error) {
+	if len(in) == 0 || string(in) == "null" {
+		return nil, "", nil
+	}
+
+	var s string
+	if json.Unmarshal(in, &s) != nil {
+		s = string(in) // not a string, so it had better be a number
+	}
+
+	seconds, err := strconv.Atoi(strings.TrimSpace(s))
+	switch {
+	case err == nil:
+		d := time.Duration(seconds) * time.Second
+		return &d, "", nil
+	case s != string(in):
+		return nil, s, nil // an expression string
+	default:
+		return nil, "", err
+	}
+}
+
+// ibaProbePropertiesExtra returns the elements of the JSON object in which
+// are not represented by the fields of known.
+func ibaProbePropertiesExtra(in json.RawMessage, known interface{}) (map[string]interface{}, error) {
+	var all map[string]interface{}
+	err := json.Unmarshal(in, &all)
+	if err != nil {
+		return nil, err
+	}
+
+	knownJson, err := json.Marshal(known)
+	if err != nil {
+		return nil, err
+	}
+
+	var knownMap map[string]interface{}
+	err = json.Unmarshal(knownJson, &knownMap)
+	if err != nil {
+		return nil, err
+	}
+
+	for k := range knownMap {
+		delete(all, k)
+	}
+
+	return all, nil
+}
+
+// ibaProbePropertiesWithExtra renders known as a JSON object, with the
+// elements of extra added. Elements of known take precedence.
+func ibaProbePropertiesWithExtra(known interface{}, extra map[string]interface{}) (json.RawMessage, error) {
+	if len(extra) == 0 {
+		return json.Marshal(known)
+	}
+
+	knownJson, err := json.Marshal(known)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	err = json.Unmarshal(knownJson, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range extra {
+		if _, ok := result[k]; !ok {
+			result[k] = v
+		}
+	}
+
+	return json.Marshal(result)
+}
+
+// IbaProbeStage describes a stage produced by one of the probe's processors.
+// Name must match one of the processor output stage names.
+type IbaProbeStage struct {
+	Name                string                 `json:"name"`
+	Description         string                 `json:"description,omitempty"`
+	Units               map[string]string      `json:"units,omitempty"`
+	EnableMetricLogging bool                   `json:"enable_metric_logging,omitempty"`
+	RetentionDuration   *int                   `json:"retention_duration,omitempty"` // seconds
+	Extra               map[string]interface{} `json:"-"`                            // e.g. "retention_size", "graph_annotation_properties"
+}
+
+func (o *IbaProbeStage) UnmarshalJSON(b []byte) error {
+	type alias IbaProbeStage
+	var raw alias
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+
+	raw.Extra, err = ibaProbePropertiesExtra(b, raw)
+	if err != nil {
+		return err
+	}
+
+	*o = IbaProbeStage(raw)
+	return nil
+}
+
+func (o IbaProbeStage) MarshalJSON() ([]byte, error) {
+	type alias IbaProbeStage
+	return ibaProbePropertiesWithExtra(alias(o), o.Extra)
+}
+
+// IbaProbeRequest is used to create or update an IBA probe with typed
+// processors and stages. Use IbaProbeBuilder or call Validate before sending.
+type IbaProbeRequest struct {
+	Label           string
+	Description     string
+	Tags            []string
+	Disabled        bool
+	PredefinedProbe string
+	Processors      []IbaProbeProcessor
+	Stages          []IbaProbeStage
+}
+
+type rawIbaProbeRequest struct {
+	Label           string                 `json:"label"`
+	Description     string                 `json:"description"`
+	Tags            []string               `json:"tags"`
+	Disabled        bool                   `json:"disabled"`
+	PredefinedProbe string                 `json:"predefined_probe,omitempty"`
+	Processors      []rawIbaProbeProcessor `json:"processors"`
+	Stages          []IbaProbeStage        `json:"stages"`
+}
+
+func (o *IbaProbeRequest) raw() (*rawIbaProbeRequest, error) {
+	processors := make([]rawIbaProbeProcessor, len(o.Processors))
+	for i, processor := range o.Processors {
+		p, err := processor.raw()
+		if err != nil {
+			return nil, err
+		}
+		processors[i] = *p
+	}
+
+	result := rawIbaProbeRequest{
+		Label:           o.Label,
+		Description:     o.Description,
+		Tags:            o.Tags,
+		Disabled:        o.Disabled,
+		PredefinedProbe: o.PredefinedProbe,
+		Processors:      processors,
+		Stages:          o.Stages,
+	}
+
+	// don't send `null` to the API. Send empty arrays instead.
+	if result.Tags == nil {
+		result.Tags = []string{}
+	}
+	if result.Stages == nil {
+		result.Stages = []IbaProbeStage{}
+	}
+
+	return &result, nil
+}
+
+// Validate checks the probe's stage graph: processor names and output stage
+// names must be unique, every processor input must reference a stage produced
+// by some other processor, processors must not form a cycle, and every entry
+// in Stages must name a stage produced by a processor.
+func (o *IbaProbeRequest) Validate() error {
+	if o.Label == "" {
+		return errors.New("probe label must be specified")
+	}
+
+	if len(o.Processors) == 0 {
+		return fmt.Errorf("probe %q has no processors", o.Label)
+	}
+
+	var errs []error
+	processorNames := make(map[string]struct{}, len(o.Processors))
+	producers := make(map[string]string) // stage name -> processor name
+	for _, processor := range o.Processors {
+		if processor.Name == "" {
+			errs = append(errs, errors.New("processor name must be specified"))
+			continue
+		}
+		if _, ok := processorNames[processor.Name]; ok {
+			errs = append(errs, fmt.Errorf("duplicate processor name %q", processor.Name))
+		}
+		processorNames[processor.Name] = struct{}{}
+
+		if processor.Properties == nil {
+			errs = append(errs, fmt.Errorf("processor %q has no properties", processor.Name))
+		}
+
+		if len(processor.Outputs) == 0 {
+			errs = append(errs, fmt.Errorf("processor %q has no outputs", processor.Name))
+		}
+		for _, stage := range processor.Outputs {
+			if p, ok := producers[stage]; ok {
+				errs = append(errs, fmt.Errorf("stage %q is output by both processor %q and processor %q", stage, p, processor.Name))
+				continue
+			}
+			producers[stage] = processor.Name
+		}
+	}
+
+	// dependencies maps each processor name to the names of the processors producing its inputs
+	dependencies := make(map[string][]string, len(o.Processors))
+	for _, processor := range o.Processors {
+		for inputName, input := range processor.Inputs {
+			producer, ok := producers[input.Stage]
+			if !ok {
+				errs = append(errs, fmt.Errorf("processor %q input %q references stage %q which is not output by any processor", processor.Name, inputName, input.Stage))
+				continue
+			}
+			if producer == processor.Name {
+				errs = append(errs, fmt.Errorf("processor %q input %q consumes its own output stage %q", processor.Name, inputName, input.Stage))
+				continue
+			}
+			dependencies[processor.Name] = append(dependencies[processor.Name], producer)
+		}
+	}
+
+	if cycle := ibaProbeProcessorCycle(dependencies); cycle != nil {
+		errs = append(errs, fmt.Errorf("processors form a cycle: %v", cycle))
+	}
+
+	stageNames := make(map[string]struct{}, len(o.Stages))
+	for _, stage := range o.Stages {
+		if _, ok := stageNames[stage.Name]; ok {
+			errs = append(errs, fmt.Errorf("duplicate stage %q", stage.Name))
+		}
+		stageNames[stage.Name] = struct{}{}
+
+		if _, ok := producers[stage.Name]; !ok {
+			errs = append(errs, fmt.Errorf("stage %q is not output by any processor", stage.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ibaProbeProcessorCycle returns the processor names forming a dependency
+// cycle, or nil if the dependency graph is acyclic.
+func ibaProbeProcessorCycle(dependencies map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(dependencies))
+	var path []string
+
+	var visit func(string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range dependencies[name] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+
+		return nil
+	}
+
+	// iterate in a predictable order so that errors are stable
+	names := make([]string, 0, len(dependencies))
+	for name := range dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// IbaProbeBuilder assembles an IbaProbeRequest one processor at a time.
+type IbaProbeBuilder struct {
+	request IbaProbeRequest
+}
+
+// NewIbaProbeBuilder returns an IbaProbeBuilder for a probe with the given label.
+func NewIbaProbeBuilder(label string) *IbaProbeBuilder {
+	return &IbaProbeBuilder{request: IbaProbeRequest{Label: label}}
+}
+
+// Description sets the probe description.
+func (o *IbaProbeBuilder) Description(description string) *IbaProbeBuilder {
+	o.request.Description = description
+	return o
+}
+
+// Tags sets the probe tags.
+func (o *IbaProbeBuilder) Tags(tags ...string) *IbaProbeBuilder {
+	o.request.Tags = tags
+	return o
+}
+
+// Processor adds a processor which reads the named input stages (by input
+// name "in" when a single stage is given, "in0", "in1", ... otherwise) and
+// emits a single output stage named outStage.
+func (o *IbaProbeBuilder) Processor(name string, properties IbaProbeProcessorProperties, outStage string, inStages ...string) *IbaProbeBuilder {
+	inputs := make(map[string]IbaProbeProcessorInput, len(inStages))
+	switch len(inStages) {
+	case 0:
+	case 1:
+		inputs["in"] = IbaProbeProcessorInput{Stage: inStages[0], Column: "value"}
+	default:
+		for i, stage := range inStages {
+			inputs[fmt.Sprintf("in%d", i)] = IbaProbeProcessorInput{Stage: stage, Column: "value"}
+		}
+	}
+
+	return o.AddProcessor(IbaProbeProcessor{
+		Name:       name,
+		Inputs:     inputs,
+		Outputs:    map[string]string{"out": outStage},
+		Properties: properties,
+	})
+}
+
+// AddProcessor adds a fully-specified processor.
+func (o *IbaProbeBuilder) AddProcessor(processor IbaProbeProcessor) *IbaProbeBuilder {
+	o.request.Processors = append(o.request.Processors, processor)
+	return o
+}
+
+// Stage adds stage metadata (description, units, etc...) for a stage output
+// by one of the processors.
+func (o *IbaProbeBuilder) Stage(stage IbaProbeStage) *IbaProbeBuilder {
+	o.request.Stages = append(o.request.Stages, stage)
+	return o
+}
+
+// Build validates the stage graph and returns the resulting IbaProbeRequest.
+func (o *IbaProbeBuilder) Build() (*IbaProbeRequest, error) {
+	err := o.request.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	result := o.request
+	return &result, nil
+}
+
+// Request returns an IbaProbeRequest with typed processors and stages parsed
+// from the opaque maps in o. It may be modified and passed to UpdateIbaProbe.
+func (o *IbaProbe) Request() (*IbaProbeRequest, error) {
+	processorsJson, err := json.Marshal(o.Processors)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshaling probe %q processors - %w", o.Id, err)
+	}
+
+	var rawProcessors []rawIbaProbeProcessor
+	err = json.Unmarshal(processorsJson, &rawProcessors)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling probe %q processors - %w", o.Id, err)
+	}
+
+	processors := make([]IbaProbeProcessor, len(rawProcessors))
+	for i, rawProcessor := range rawProcessors {
+		p, err := rawProcessor.polish()
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing probe %q processor - %w", o.Id, err)
+		}
+		processors[i] = *p
+	}
+
+	stagesJson, err := json.Marshal(o.Stages)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshaling probe %q stages - %w", o.Id, err)
+	}
+
+	var stages []IbaProbeStage
+	err = json.Unmarshal(stagesJson, &stages)
+	if err != nil {
+		return nil, fmt.Errorf("failed unmarshaling probe %q stages - %w", o.Id, err)
+	}
+
+	return &IbaProbeRequest{
+		Label:           o.Label,
+		Description:     o.Description,
+		Tags:            o.Tags,
+		Disabled:        o.Disabled,
+		PredefinedProbe: o.PredefinedProbe,
+		Processors:      processors,
+		Stages:          stages,
+	}, nil
+}
@@ -0,0 +1,275 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIbaProbeBuilderValidation(t *testing.T) {
+	collector := func() IbaProbeProcessorProperties {
+		return &IbaProbeProcessorPropertiesExtensibleDataCollector{
+			ServiceName: "lldp",
+			DataType:    "dynamic",
+			GraphQuery:  []string{"node('system', name='system', role='leaf')"},
+			SystemId:    "system.system_id",
+		}
+	}
+	average := func() IbaProbeProcessorProperties {
+		return &IbaProbeProcessorPropertiesPeriodicAverage{Period: time.Minute}
+	}
+	rangeCheck := func() IbaProbeProcessorProperties {
+		max := 10.0
+		return &IbaProbeProcessorPropertiesRangeCheck{Range: IbaProbeRange{Max: &max}, RaiseAnomaly: true}
+	}
+
+	type testCase struct {
+		builder *IbaProbeBuilder
+		expErr  bool
+	}
+
+	testCases := map[string]testCase{
+		"ok": {
+			builder: NewIbaProbeBuilder("ok").
+				Processor("collect", collector(), "raw").
+				Processor("average", average(), "avg", "raw").
+				Processor("check", rangeCheck(), "anomalous", "avg").
+				Stage(IbaProbeStage{Name: "avg", Units: map[string]string{"value": "count"}}),
+		},
+		"no_label": {
+			builder: NewIbaProbeBuilder("").
+				Processor("collect", collector(), "raw"),
+			expErr: true,
+		},
+		"no_processors": {
+			builder: NewIbaProbeBuilder("empty"),
+			expErr:  true,
+		},
+		"missing_input_stage": {
+			builder: NewIbaProbeBuilder("missing").
+				Processor("collect", collector(), "raw").
+				Processor("average", average(), "avg", "bogus"),
+			expErr: true,
+		},
+		"duplicate_processor": {
+			builder: NewIbaProbeBuilder("dup").
+				Processor("collect", collector(), "raw").
+				Processor("collect", collector(), "raw2"),
+			expErr: true,
+		},
+		"duplicate_output_stage": {
+			builder: NewIbaProbeBuilder("dup").
+				Processor("collect1", collector(), "raw").
+				Processor("collect2", collector(), "raw"),
+			expErr: true,
+		},
+		"self_reference": {
+			builder: NewIbaProbeBuilder("self").
+				Processor("average", average(), "avg", "avg"),
+			expErr: true,
+		},
+		"cycle": {
+			builder: NewIbaProbeBuilder("cycle").
+				Processor("a", average(), "a_out", "b_out").
+				Processor("b", average(), "b_out", "a_out"),
+			expErr: true,
+		},
+		"stage_without_producer": {
+			builder: NewIbaProbeBuilder("stage").
+				Processor("collect", collector(), "raw").
+				Stage(IbaProbeStage{Name: "bogus"}),
+			expErr: true,
+		},
+	}
+
+	for tName, tCase := range testCases {
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+
+			request, err := tCase.builder.Build()
+			if tCase.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			raw, err := request.raw()
+			require.NoError(t, err)
+			require.Equal(t, len(request.Processors), len(raw.Processors))
+		})
+	}
+}
+
+func TestIbaProbeRequestRoundTrip(t *testing.T) {
+	probeJson := `{
+  "id": "abc",
+  "label": "round trip",
+  "disabled": true,
+  "stages": [{"name": "avg", "units": {"value": "bps"}}],
+  "processors": [
+    {
+      "name": "collect",
+      "type": "extensible_data_collector",
+      "inputs": {},
+      "outputs": {"out": "raw"},
+      "properties": {
+        "service_name": "lldp",
+        "service_interval": "120",
+        "data_type": "dynamic",
+        "graph_query": ["node('system', name='system')"],
+        "query_group_by": [],
+        "system_id": "system.system_id",
+        "keys": [],
+        "enable_streaming": false,
+        "role": "system.role"
+      }
+    },
+    {
+      "name": "average",
+      "type": "periodic_average",
+      "inputs": {"in": {"stage": "raw", "column": "value"}},
+      "outputs": {"out": "avg"},
+      "properties": {"period": 60, "graph_query": [], "enable_streaming": false}
+    },
+    {
+      "name": "exotic",
+      "type": "std_dev",
+      "inputs": {"in": {"stage": "avg", "column": "value"}},
+      "outputs": {"out": "dev"},
+      "properties": {"ddof": 0}
+    }
+  ]
+}`
+
+	var probe IbaProbe
+	require.NoError(t, json.Unmarshal([]byte(probeJson), &probe))
+
+	request, err := probe.Request()
+	require.NoError(t, err)
+	require.NoError(t, request.Validate())
+	require.True(t, request.Disabled)
+	require.Len(t, request.Processors, 3)
+
+	collector, ok := request.Processors[0].Properties.(*IbaProbeProcessorPropertiesExtensibleDataCollector)
+	require.True(t, ok)
+	require.NotNil(t, collector.ServiceInterval)
+	require.Equal(t, 2*time.Minute, *collector.ServiceInterval)
+	require.Equal(t, "system.role", collector.Extra["role"])
+
+	average, ok := request.Processors[1].Properties.(*IbaProbeProcessorPropertiesPeriodicAverage)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, average.Period)
+
+	other, ok := request.Processors[2].Properties.(*IbaProbeProcessorPropertiesOther)
+	require.True(t, ok)
+	require.Equal(t, "std_dev", other.ProcessorType())
+
+	raw, err := request.raw()
+	require.NoError(t, err)
+
+	var properties map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw.Processors[0].Properties, &properties))
+	require.Equal(t, "system.role", properties["role"])
+	require.Equal(t, "120", properties["service_interval"])
+	require.Equal(t, "std_dev", raw.Processors[2].Type)
+	require.True(t, raw.Disabled)
+}
+
+func TestIbaProbeRequestRoundTripPredefined(t *testing.T) {
+	probeJson := `{
+  "id": "abc",
+  "label": "predefined",
+  "predefined_probe": "bgp_session",
+  "stages": [{"name": "history", "retention_size": 1024, "retention_duration": 86400}],
+  "processors": [
+    {
+      "name": "collect",
+      "type": "extensible_data_collector",
+      "inputs": {},
+      "outputs": {"out": "raw"},
+      "properties": {
+        "service_name": "bgp",
+        "service_interval": "{{ collection_interval }}",
+        "data_type": "dynamic",
+        "graph_query": [],
+        "query_group_by": [],
+        "system_id": "system.system_id",
+        "keys": [],
+        "enable_streaming": false
+      }
+    },
+    {
+      "name": "accumulate",
+      "type": "accumulate",
+      "inputs": {"in": {"stage": "raw", "column": "value"}},
+      "outputs": {"out": "history"},
+      "properties": {"total_duration": "{{ history_duration }}", "enable_streaming": false}
+    }
+  ]
+}`
+
+	var probe IbaProbe
+	require.NoError(t, json.Unmarshal([]byte(probeJson), &probe))
+
+	request, err := probe.Request()
+	require.NoError(t, err)
+	require.Equal(t, "bgp_session", request.PredefinedProbe)
+	require.Equal(t, float64(1024), request.Stages[0].Extra["retention_size"])
+
+	collector := request.Processors[0].Properties.(*IbaProbeProcessorPropertiesExtensibleDataCollector)
+	require.Nil(t, collector.ServiceInterval)
+	require.Equal(t, "{{ collection_interval }}", collector.ServiceIntervalExpression)
+
+	accumulate := request.Processors[1].Properties.(*IbaProbeProcessorPropertiesAccumulate)
+	require.Nil(t, accumulate.TotalDuration)
+	require.Equal(t, "{{ history_duration }}", accumulate.TotalDurationExpression)
+
+	raw, err := request.raw()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(raw)
+	require.NoError(t, err)
+
+	var sent struct {
+		PredefinedProbe string                   `json:"predefined_probe"`
+		Stages          []map[string]interface{} `json:"stages"`
+		Processors      []struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"processors"`
+	}
+	require.NoError(t, json.Unmarshal(data, &sent))
+	require.Equal(t, "bgp_session", sent.PredefinedProbe)
+	require.Equal(t, float64(1024), sent.Stages[0]["retention_size"])
+	require.Equal(t, float64(86400), sent.Stages[0]["retention_duration"])
+	require.Equal(t, "{{ collection_interval }}", sent.Processors[0].Properties["service_interval"])
+	require.Equal(t, "{{ history_duration }}", sent.Processors[1].Properties["total_duration"])
+
+	accumulate.TotalDuration = new(time.Duration)
+	_, err = request.raw()
+	require.Error(t, err) // duration and expression are mutually exclusive
+}
+
+func TestIbaProbeDurationSeconds(t *testing.T) {
+	accumulate := func(d time.Duration) *IbaProbeProcessorPropertiesAccumulate {
+		return &IbaProbeProcessorPropertiesAccumulate{TotalDuration: &d}
+	}
+
+	raw, err := accumulate(90 * time.Second).raw()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"total_duration": 90, "enable_streaming": false}`, string(raw))
+
+	_, err = accumulate(500 * time.Millisecond).raw()
+	require.Error(t, err)
+
+	_, err = accumulate(1500 * time.Millisecond).raw()
+	require.Error(t, err)
+
+	_, err = (&IbaProbeProcessorPropertiesPeriodicAverage{Period: 90500 * time.Millisecond}).raw()
+	require.Error(t, err)
+}
@@ -11,13 +11,15 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"time"
 )
 
 const (
-	apiUrlIbaProbes       = apiUrlBlueprintById + apiUrlPathDelim + "probes"
-	apiUrlIbaProbesPrefix = apiUrlIbaProbes + apiUrlPathDelim
-	apiUrlIbaProbesById   = apiUrlIbaProbesPrefix + "%s"
+	apiUrlIbaProbes         = apiUrlBlueprintById + apiUrlPathDelim + "probes"
+	apiUrlIbaProbesPrefix   = apiUrlIbaProbes + apiUrlPathDelim
+	apiUrlIbaProbesById     = apiUrlIbaProbesPrefix + "%s"
+	apiUrlIbaProbeStageData = apiUrlIbaProbesById + apiUrlPathDelim + "stages" + apiUrlPathDelim + "%s" + apiUrlPathDelim + "data"
 )
 
 type IbaProbe struct {
@@ -28,6 +30,7 @@ type IbaProbe struct {
 	Processors      []map[string]interface{} `json:"processors"`
 	PredefinedProbe string                   `json:"predefined_probe"`
 	Description     string                   `json:"description"`
+	Disabled        bool                     `json:"disabled"`
 }
 
 type IbaProbeState struct {
@@ -59,6 +62,23 @@ func (o *IbaProbeState) IbaProbe() IbaProbe {
 	}
 }
 
+// IbaProbeStageData is the output of a single probe stage.
+type IbaProbeStageData struct {
+	Items      []IbaProbeStageDataItem `json:"items"`
+	TotalCount int                     `json:"total_count"`
+}
+
+// IbaProbeStageDataItem is a single item (row) of probe stage output. Value is
+// populated for single-column stages, Values for stages with multiple columns.
+type IbaProbeStageDataItem struct {
+	Properties  map[string]interface{} `json:"properties"`
+	Value       interface{}            `json:"value,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty"`
+	Timestamp   string                 `json:"timestamp,omitempty"`
+	Anomalous   interface{}            `json:"anomalous,omitempty"`
+	ActualValue interface{}            `json:"actual_value,omitempty"`
+}
+
 func (o *Client) getAllIbaProbes(ctx context.Context, bpId ObjectId) ([]IbaProbe, error) {
 	response := &struct {
 		Items []IbaProbe `json:"items"`
@@ -138,6 +158,28 @@ func (o *Client) deleteIbaProbe(ctx context.Context, bpId ObjectId, id ObjectId)
 	}))
 }
 
+func (o *Client) updateIbaProbe(ctx context.Context, bpId ObjectId, id ObjectId, in *rawIbaProbeRequest) error {
+	return convertTtaeToAceWherePossible(o.talkToApstra(ctx, &talkToApstraIn{
+		method:   http.MethodPut,
+		urlStr:   fmt.Sprintf(apiUrlIbaProbesById, bpId, id),
+		apiInput: in,
+	}))
+}
+
+func (o *Client) getIbaProbeStageData(ctx context.Context, bpId ObjectId, id ObjectId, stage string) (*IbaProbeStageData, error) {
+	response := &IbaProbeStageData{}
+
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      fmt.Sprintf(apiUrlIbaProbeStageData, bpId, id, url.PathEscape(stage)),
+		apiResponse: response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+	return response, nil
+}
+
 func (o *Client) createIbaProbeFromJson(ctx context.Context, bpId ObjectId, probeJson json.RawMessage) (ObjectId, error) {
 	var response objectIdResponse
 	err := o.talkToApstra(ctx, &talkToApstraIn{
@@ -40,6 +40,9 @@ func (o PoolStatus) String() string {
 	case PoolStatusCreating:
 		return string(poolStatusCreating)
 	default:
+		if s, ok := lenientEnumString[poolStatus](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(poolStatusUnknown, o)
 	}
 }
@@ -59,6 +62,9 @@ func (o poolStatus) parse() (int, error) {
 	case poolStatusCreating:
 		return int(PoolStatusCreating), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(PoolStatusUnknown, o)
 	}
 }
@@ -126,6 +126,9 @@ func (o AgentJobState) String() string {
 	case AgentJobStateFailed:
 		return string(agentJobStateFailed)
 	default:
+		if s, ok := lenientEnumString[rawAgentJobState](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(agentJobStateUnknown, o)
 	}
 }
@@ -165,6 +168,9 @@ func (o rawAgentJobState) parse() int {
 	case agentJobStateFailed:
 		return int(AgentJobStateFailed)
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i
+		}
 		return int(AgentJobStateUnknown)
 	}
 }
@@ -245,6 +251,9 @@ func (o AgentPlatform) String() string {
 	case AgentPlatformNXOS:
 		return string(agentPlatformNXOS)
 	default:
+		if s, ok := lenientEnumString[rawAgentPlatform](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(agentPlatformUnknown, o)
 	}
 }
@@ -275,6 +284,9 @@ func (o rawAgentPlatform) parse() int {
 	case agentPlatformNXOS:
 		return int(AgentPlatformNXOS)
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i
+		}
 		return int(AgentPlatformUnknown)
 	}
 }
@@ -296,6 +308,9 @@ func (o AgentCxnState) String() string {
 	case AgentCxnStateAuthFail:
 		return string(agentCxnStateAuthFail)
 	default:
+		if s, ok := lenientEnumString[rawAgentCxnState](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(agentCxnStateUnknown, o)
 	}
 }
@@ -317,6 +332,9 @@ func (o rawAgentCxnState) parse() int {
 	case agentCxnStateAuthFail:
 		return int(AgentCxnStateAuthFail)
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i
+		}
 		return int(AgentCxnStateUnknown)
 	}
 }
@@ -0,0 +1,42 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const (
+	apiUrlSystemLldpData = apiUrlSystemsById + apiUrlPathDelim + "services" + apiUrlPathDelim + "lldp" + apiUrlPathDelim + "data"
+)
+
+// LldpNeighbor is a single LLDP neighbor entry as reported by a managed
+// system's LLDP telemetry service.
+type LldpNeighbor struct {
+	InterfaceName             string `json:"interface_name"`
+	NeighborInterfaceName     string `json:"neighbor_interface_name"`
+	NeighborSystemId          string `json:"neighbor_system_id"`
+	NeighborSystemName        string `json:"neighbor_system_name"`
+	NeighborSystemDescription string `json:"neighbor_system_description"`
+}
+
+func (o *Client) getSystemLldpNeighbors(ctx context.Context, id SystemId) ([]LldpNeighbor, error) {
+	var response struct {
+		Items []LldpNeighbor `json:"items"`
+	}
+
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      fmt.Sprintf(apiUrlSystemLldpData, id),
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+
+	return response.Items, nil
+}
@@ -54,6 +54,9 @@ func (o SystemAdminState) String() string {
 	case SystemAdminStateMaint:
 		return string(systemAdminStateMaint)
 	default:
+		if s, ok := lenientEnumString[rawSystemAdminState](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(systemAdminStateUnknown, o)
 	}
 }
@@ -79,6 +82,9 @@ func (o rawSystemAdminState) parse() (int, error) {
 	case systemAdminStateMaint:
 		return int(SystemAdminStateMaint), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(SystemAdminStateUnknown, o)
 	}
 }
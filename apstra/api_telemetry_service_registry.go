@@ -52,7 +52,7 @@ func (o *TelemetryServiceRegistryEntry) UnmarshalJSON(data []byte) error {
 	o.Builtin = raw.Builtin
 	o.Description = raw.Description
 	o.Version = raw.Version
-	err = o.StorageSchemaPath.FromString(raw.StorageSchemaPath)
+	o.StorageSchemaPath, err = apiEnumFromString[enum.StorageSchemaPath](raw.StorageSchemaPath)
 	if err != nil {
 		return err
 	}
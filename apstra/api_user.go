@@ -45,13 +45,18 @@ func (o *Client) stopTaskMonitor() {
 }
 
 func (o *Client) login(ctx context.Context) error {
+	user, pass, err := o.credentials(ctx)
+	if err != nil {
+		return err
+	}
+
 	response := &userLoginResponse{}
-	err := o.talkToApstra(ctx, &talkToApstraIn{
+	err = o.talkToApstra(ctx, &talkToApstraIn{
 		method: http.MethodPost,
 		urlStr: apiUrlUserLogin,
 		apiInput: &userLoginRequest{
-			Username: o.cfg.User,
-			Password: o.cfg.Pass,
+			Username: user,
+			Password: pass,
 		},
 		doNotLogin:  true,
 		apiResponse: response,
@@ -68,6 +73,7 @@ func (o *Client) login(ctx context.Context) error {
 
 	o.id = response.Id
 	o.startTaskMonitor()
+	o.storeCachedToken(response.Token, response.Id)
 	return nil
 }
 
@@ -85,6 +91,7 @@ func (o *Client) logout(ctx context.Context) error {
 
 	defer func() {
 		o.Log(1, "deleting auth token")
+		o.deleteCachedToken()
 		o.lock(mutexKeyHttpHeaders)
 		delete(o.httpHeaders, apstraAuthHeader)
 		o.unlock(mutexKeyHttpHeaders)
@@ -0,0 +1,197 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+var (
+	_ json.Marshaler   = new(BlueprintGraph)
+	_ json.Unmarshaler = new(BlueprintGraph)
+)
+
+// BlueprintGraph is a point-in-time copy of every node and relationship in a
+// blueprint. It can be written to disk with json.Marshal, read back with
+// json.Unmarshal (the format is that of the blueprint API, so a saved API
+// response may be loaded too), and queried offline with Query.
+//
+// Node and relationship attributes are decoded with json.Number in place of
+// float64 so that large integers survive the round trip.
+type BlueprintGraph struct {
+	Id             ObjectId
+	Version        int
+	Design         RefDesign
+	Label          string
+	LastModifiedAt time.Time
+	Nodes          map[ObjectId]map[string]any
+	Relationships  map[ObjectId]map[string]any
+
+	out map[ObjectId][]ObjectId // node ID -> IDs of relationships with the node as source
+	in  map[ObjectId][]ObjectId // node ID -> IDs of relationships with the node as target
+}
+
+// GetGraph downloads every node and relationship in the blueprint.
+func (o *TwoStageL3ClosClient) GetGraph(ctx context.Context) (*BlueprintGraph, error) {
+	return o.client.getBlueprintGraph(ctx, o.blueprintId)
+}
+
+// GetGraph downloads every node and relationship in the blueprint.
+func (o *FreeformClient) GetGraph(ctx context.Context) (*BlueprintGraph, error) {
+	return o.client.getBlueprintGraph(ctx, o.blueprintId)
+}
+
+func (o *Client) getBlueprintGraph(ctx context.Context, id ObjectId) (*BlueprintGraph, error) {
+	var response rawBlueprint
+	err := o.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      fmt.Sprintf(apiUrlBlueprintById, id),
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+
+	return response.graph()
+}
+
+// graph converts the blueprint API response into a BlueprintGraph.
+func (o *rawBlueprint) graph() (*BlueprintGraph, error) {
+	design, err := o.Design.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	result := BlueprintGraph{
+		Id:             o.Id,
+		Version:        o.Version,
+		Design:         design,
+		Label:          o.Label,
+		LastModifiedAt: o.LastModifiedAt,
+		Nodes:          make(map[ObjectId]map[string]any, len(o.Nodes)),
+		Relationships:  make(map[ObjectId]map[string]any, len(o.Relationships)),
+	}
+
+	for id, raw := range o.Nodes {
+		node, err := decodeGraphObject(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding node %q - %w", id, err)
+		}
+		if _, ok := node["id"]; !ok {
+			node["id"] = id
+		}
+		result.Nodes[ObjectId(id)] = node
+	}
+
+	for id, raw := range o.Relationships {
+		relationship, err := decodeGraphObject(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding relationship %q - %w", id, err)
+		}
+		if _, ok := relationship["id"]; !ok {
+			relationship["id"] = id
+		}
+		result.Relationships[ObjectId(id)] = relationship
+	}
+
+	err = result.index()
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func decodeGraphObject(raw json.RawMessage) (map[string]any, error) {
+	var result map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	err := decoder.Decode(&result)
+	return result, err
+}
+
+// index populates the in/out relationship indexes, ordered by relationship
+// ID so that query results are deterministic.
+func (o *BlueprintGraph) index() error {
+	o.out = make(map[ObjectId][]ObjectId)
+	o.in = make(map[ObjectId][]ObjectId)
+
+	for _, id := range sortedKeys(o.Relationships) {
+		relationship := o.Relationships[id]
+		sourceId, _ := relationship["source_id"].(string)
+		targetId, _ := relationship["target_id"].(string)
+		if _, ok := o.Nodes[ObjectId(sourceId)]; !ok {
+			return fmt.Errorf("relationship %q source node %q not found", id, sourceId)
+		}
+		if _, ok := o.Nodes[ObjectId(targetId)]; !ok {
+			return fmt.Errorf("relationship %q target node %q not found", id, targetId)
+		}
+		o.out[ObjectId(sourceId)] = append(o.out[ObjectId(sourceId)], id)
+		o.in[ObjectId(targetId)] = append(o.in[ObjectId(targetId)], id)
+	}
+
+	return nil
+}
+
+// NodeIds returns the IDs of nodes of the specified type (all nodes when
+// nodeType is empty), sorted.
+func (o *BlueprintGraph) NodeIds(nodeType string) []ObjectId {
+	var result []ObjectId
+	for id, node := range o.Nodes {
+		if nodeType == "" || node["type"] == nodeType {
+			result = append(result, id)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+func (o *BlueprintGraph) MarshalJSON() ([]byte, error) {
+	raw := rawBlueprint{
+		Id:             o.Id,
+		Version:        o.Version,
+		Design:         refDesign(o.Design.String()),
+		LastModifiedAt: o.LastModifiedAt,
+		Label:          o.Label,
+		Relationships:  make(map[string]json.RawMessage, len(o.Relationships)),
+		Nodes:          make(map[string]json.RawMessage, len(o.Nodes)),
+	}
+
+	var err error
+	for id, node := range o.Nodes {
+		if raw.Nodes[id.String()], err = json.Marshal(node); err != nil {
+			return nil, fmt.Errorf("failed encoding node %q - %w", id, err)
+		}
+	}
+	for id, relationship := range o.Relationships {
+		if raw.Relationships[id.String()], err = json.Marshal(relationship); err != nil {
+			return nil, fmt.Errorf("failed encoding relationship %q - %w", id, err)
+		}
+	}
+
+	return json.Marshal(&raw)
+}
+
+func (o *BlueprintGraph) UnmarshalJSON(b []byte) error {
+	var raw rawBlueprint
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+
+	graph, err := raw.graph()
+	if err != nil {
+		return err
+	}
+
+	*o = *graph
+	return nil
+}
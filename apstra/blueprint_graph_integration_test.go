@@ -0,0 +1,90 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetGraph compares the results of a query evaluated by Apstra with the
+// results of the same query evaluated against the downloaded graph.
+func TestGetGraph(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bp := testBlueprintA(ctx, t, client.client)
+
+			log.Printf("testing GetGraph() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			graph, err := bp.GetGraph(ctx)
+			require.NoError(t, err)
+			require.Equal(t, bp.Id(), graph.Id)
+
+			// round trip through JSON, as when saved to disk
+			b, err := json.Marshal(graph)
+			require.NoError(t, err)
+			graph = new(BlueprintGraph)
+			require.NoError(t, json.Unmarshal(b, graph))
+
+			newQuery := func() *MatchQuery {
+				return new(MatchQuery).
+					Match(new(PathQuery).
+						Node([]QEEAttribute{
+							NodeTypeSystem.QEEAttribute(),
+							{"name", QEStringVal("n_system")},
+							{"role", QEStringValIsIn{"spine", "leaf"}},
+						}).
+						Out([]QEEAttribute{RelationshipTypeLogicalDevice.QEEAttribute()}).
+						Node([]QEEAttribute{
+							NodeTypeLogicalDevice.QEEAttribute(),
+							{"name", QEStringVal("n_logical_device")},
+						}))
+			}
+
+			type queryItem struct {
+				System struct {
+					Id ObjectId `json:"id"`
+				} `json:"n_system"`
+				LogicalDevice struct {
+					Id ObjectId `json:"id"`
+				} `json:"n_logical_device"`
+			}
+
+			var online, offline struct {
+				Items []queryItem `json:"items"`
+			}
+
+			require.NoError(t, newQuery().SetClient(client.client).SetBlueprintId(bp.Id()).Do(ctx, &online))
+			require.NoError(t, graph.Query(newQuery(), &offline))
+
+			pairs := func(items []queryItem) []string {
+				var result []string
+				for _, item := range items {
+					result = append(result, item.System.Id.String()+"/"+item.LogicalDevice.Id.String())
+				}
+				sort.Strings(result)
+				return result
+			}
+
+			require.NotEmpty(t, online.Items)
+			require.Equal(t, pairs(online.Items), pairs(offline.Items))
+		})
+	}
+}
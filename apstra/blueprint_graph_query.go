@@ -0,0 +1,434 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// graphBinding maps the names given to query elements (the "name" attribute)
+// to the nodes and relationships they matched. A nil value represents an
+// element of an optional query which didn't match.
+type graphBinding map[string]map[string]any
+
+// Query evaluates a PathQuery or MatchQuery against the graph and unpacks the
+// result into response, which has the same shape as the response to
+// QEQuery.Do: {"count": N, "items": [{"<name>": {<node or relationship>}}]}.
+// The JSON result is also made available via the query's RawResult method.
+//
+// Where clauses (python lambdas) and RawQuery can't be evaluated offline, and
+// produce an error. Items are ordered by node and relationship ID.
+func (o *BlueprintGraph) Query(query QEQuery, response interface{}) error {
+	bindings, err := o.evaluate(query)
+	if err != nil {
+		return err
+	}
+
+	if bindings == nil {
+		bindings = []graphBinding{}
+	}
+
+	rawResult, err := json.Marshal(&struct {
+		Count int            `json:"count"`
+		Items []graphBinding `json:"items"`
+	}{
+		Count: len(bindings),
+		Items: bindings,
+	})
+	if err != nil {
+		return fmt.Errorf("failed encoding query result - %w", err)
+	}
+
+	query.setRawResult(rawResult)
+
+	if response != nil {
+		err = json.Unmarshal(rawResult, response)
+		if err != nil {
+			return fmt.Errorf("error while decoding query result %q - %w", string(rawResult), err)
+		}
+	}
+
+	return nil
+}
+
+func (o *BlueprintGraph) evaluate(query QEQuery) ([]graphBinding, error) {
+	switch query := query.(type) {
+	case *PathQuery:
+		return o.evaluatePath(query)
+	case *MatchQuery:
+		return o.evaluateMatch(query)
+	default:
+		return nil, fmt.Errorf("%T cannot be evaluated offline", query)
+	}
+}
+
+func (o *BlueprintGraph) evaluatePath(query *PathQuery) ([]graphBinding, error) {
+	if len(query.where) > 0 {
+		return nil, fmt.Errorf("where() clauses cannot be evaluated offline: %s", query.String())
+	}
+
+	// Split the path into node filters and relationship filters. Nodes
+	// between consecutive relationships, and at the end of the path, may be
+	// omitted, in which case they match any node.
+	var nodes, relationships []*graphFilter
+	for e := query.firstElement; e != nil; e = e.getNext() {
+		filter, err := newGraphFilter(e)
+		if err != nil {
+			return nil, err
+		}
+
+		switch e.qeeType {
+		case qEETypeNode:
+			if len(nodes) > len(relationships) {
+				return nil, fmt.Errorf("consecutive node() elements in path query: %s", query.String())
+			}
+			nodes = append(nodes, filter)
+		case qEETypeIn, qEETypeOut:
+			if len(nodes) == 0 {
+				return nil, fmt.Errorf("path query must begin with node(): %s", query.String())
+			}
+			if len(nodes) == len(relationships) {
+				nodes = append(nodes, new(graphFilter))
+			}
+			relationships = append(relationships, filter)
+		default:
+			return nil, fmt.Errorf("unsupported path query element %q", e.qeeType)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, errors.New("path query has no elements")
+	}
+	if len(nodes) == len(relationships) {
+		nodes = append(nodes, new(graphFilter))
+	}
+
+	var result []graphBinding
+
+	var walk func(i int, nodeId ObjectId, binding graphBinding)
+	walk = func(i int, nodeId ObjectId, binding graphBinding) {
+		binding, ok := nodes[i].apply(o.Nodes[nodeId], binding)
+		if !ok {
+			return
+		}
+
+		if i == len(relationships) {
+			result = append(result, binding)
+			return
+		}
+
+		relationshipIds, nextNodeKey := o.out[nodeId], "target_id"
+		if relationships[i].inbound {
+			relationshipIds, nextNodeKey = o.in[nodeId], "source_id"
+		}
+
+		for _, relationshipId := range relationshipIds {
+			relationship := o.Relationships[relationshipId]
+			relationshipBinding, ok := relationships[i].apply(relationship, binding)
+			if !ok {
+				continue
+			}
+			nextNodeId, _ := relationship[nextNodeKey].(string)
+			walk(i+1, ObjectId(nextNodeId), relationshipBinding)
+		}
+	}
+
+	for _, nodeId := range o.NodeIds(nodes[0].nodeType) {
+		walk(0, nodeId, graphBinding{})
+	}
+
+	return result, nil
+}
+
+func (o *BlueprintGraph) evaluateMatch(query *MatchQuery) ([]graphBinding, error) {
+	if len(query.where) > 0 {
+		return nil, fmt.Errorf("where() clauses cannot be evaluated offline: %s", query.String())
+	}
+
+	if len(query.match) == 0 {
+		return nil, errors.New("match query has no queries")
+	}
+
+	rows := []graphBinding{{}}
+	for _, subQuery := range query.match {
+		subRows, err := o.evaluate(subQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		optional := qeQueryOptional(subQuery)
+
+		var joinedRows []graphBinding
+		for _, row := range rows {
+			var matched bool
+			for _, subRow := range subRows {
+				if joined, ok := row.join(subRow); ok {
+					joinedRows = append(joinedRows, joined)
+					matched = true
+				}
+			}
+
+			if !matched && optional {
+				joined := row.copy()
+				for _, name := range qeQueryNames(subQuery) {
+					if _, ok := joined[name]; !ok {
+						joined[name] = nil
+					}
+				}
+				joinedRows = append(joinedRows, joined)
+			}
+		}
+
+		rows = joinedRows
+	}
+
+	for e := query.firstElement; e != nil; e = e.getNext() {
+		switch value := e.value.(type) {
+		case MatchQueryDistinct:
+			rows = distinctGraphBindings(rows, value)
+		default:
+			return nil, fmt.Errorf("match query element %q cannot be evaluated offline", e.String())
+		}
+	}
+
+	return rows, nil
+}
+
+// qeQueryOptional returns true if the query was added to a MatchQuery with
+// Optional.
+func qeQueryOptional(query QEQuery) bool {
+	switch query := query.(type) {
+	case *PathQuery:
+		return query.optional
+	case *MatchQuery:
+		return query.optional
+	case *RawQuery:
+		return query.optional
+	}
+	return false
+}
+
+// qeQueryNames returns the names given to elements of the query.
+func qeQueryNames(query QEQuery) []string {
+	var result []string
+	switch query := query.(type) {
+	case *PathQuery:
+		for e := query.firstElement; e != nil; e = e.getNext() {
+			for _, attribute := range e.attributes {
+				if name, ok := attribute.Value.(QEStringVal); ok && attribute.Key == "name" {
+					result = append(result, string(name))
+				}
+			}
+		}
+	case *MatchQuery:
+		for _, subQuery := range query.match {
+			result = append(result, qeQueryNames(subQuery)...)
+		}
+	}
+	return result
+}
+
+// distinctGraphBindings projects rows onto the named elements (all elements
+// when names is empty) and drops duplicates.
+func distinctGraphBindings(rows []graphBinding, names []string) []graphBinding {
+	var result []graphBinding
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		projected := row
+		if len(names) > 0 {
+			projected = make(graphBinding, len(names))
+			for _, name := range names {
+				projected[name] = row[name]
+			}
+		}
+
+		key := projected.key()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, projected)
+	}
+	return result
+}
+
+// copy returns a shallow copy of o
+func (o graphBinding) copy() graphBinding {
+	result := make(graphBinding, len(o)+1)
+	for k, v := range o {
+		result[k] = v
+	}
+	return result
+}
+
+// bind returns a copy of o with name bound to object, or false if name is
+// already bound to a different object.
+func (o graphBinding) bind(name string, object map[string]any) (graphBinding, bool) {
+	if existing, ok := o[name]; ok && existing != nil {
+		return o, existing["id"] == object["id"]
+	}
+	result := o.copy()
+	result[name] = object
+	return result, true
+}
+
+// join returns the union of o and other, or false if they bind a name to
+// different objects. Names bound to nil (unmatched optional elements) don't
+// conflict.
+func (o graphBinding) join(other graphBinding) (graphBinding, bool) {
+	result := o
+	for name, object := range other {
+		if object == nil {
+			if _, ok := result[name]; !ok {
+				result = result.copy()
+				result[name] = nil
+			}
+			continue
+		}
+
+		var ok bool
+		if result, ok = result.bind(name, object); !ok {
+			return nil, false
+		}
+	}
+
+	return result, true
+}
+
+// key uniquely identifies the objects bound by o
+func (o graphBinding) key() string {
+	var sb strings.Builder
+	for _, name := range sortedKeys(o) {
+		var id any
+		if o[name] != nil {
+			id = o[name]["id"]
+		}
+		sb.WriteString(fmt.Sprintf("%q=%v;", name, id))
+	}
+	return sb.String()
+}
+
+// graphFilter is the compiled form of a PathQueryElement.
+type graphFilter struct {
+	name     string                      // from the "name" attribute
+	nodeType string                      // from the "type" attribute of node elements, used to select starting nodes
+	inbound  bool                        // in_() rather than out()
+	matchers []func(map[string]any) bool // one per attribute other than "name"
+}
+
+func newGraphFilter(element *PathQueryElement) (*graphFilter, error) {
+	result := graphFilter{inbound: element.qeeType == qEETypeIn}
+
+	for _, attribute := range element.attributes {
+		if attribute.Key == "name" {
+			name, ok := attribute.Value.(QEStringVal)
+			if !ok {
+				return nil, fmt.Errorf("%s: name must be a string", element.String())
+			}
+			result.name = string(name)
+			continue
+		}
+
+		if value, ok := attribute.Value.(QEStringVal); ok && attribute.Key == "type" && element.qeeType == qEETypeNode {
+			result.nodeType = string(value)
+		}
+
+		matcher, err := newGraphMatcher(attribute)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", element.String(), err)
+		}
+		result.matchers = append(result.matchers, matcher)
+	}
+
+	return &result, nil
+}
+
+// apply returns binding extended with object (when the filter has a name) if
+// object satisfies the filter.
+func (o *graphFilter) apply(object map[string]any, binding graphBinding) (graphBinding, bool) {
+	for _, matcher := range o.matchers {
+		if !matcher(object) {
+			return nil, false
+		}
+	}
+
+	if o.name == "" {
+		return binding, true
+	}
+
+	return binding.bind(o.name, object)
+}
+
+func newGraphMatcher(attribute QEEAttribute) (func(map[string]any) bool, error) {
+	key := attribute.Key
+
+	numeric := func(test func(float64) bool) func(map[string]any) bool {
+		return func(object map[string]any) bool {
+			f, ok := graphNumber(object[key])
+			return ok && test(f)
+		}
+	}
+
+	switch value := attribute.Value.(type) {
+	case QEStringVal:
+		return func(object map[string]any) bool {
+			s, ok := graphString(object[key])
+			return ok && s == string(value)
+		}, nil
+	case QEStringValIsIn:
+		return func(object map[string]any) bool {
+			s, ok := graphString(object[key])
+			return ok && itemInSlice(s, value)
+		}, nil
+	case QEStringValNotIn:
+		return func(object map[string]any) bool {
+			s, ok := graphString(object[key])
+			return !ok || !itemInSlice(s, value)
+		}, nil
+	case QEBoolVal:
+		return func(object map[string]any) bool {
+			b, ok := object[key].(bool)
+			return ok && b == bool(value)
+		}, nil
+	case QEIntVal:
+		return numeric(func(f float64) bool { return f == float64(value) }), nil
+	case QEIntGreater:
+		return numeric(func(f float64) bool { return f > float64(value) }), nil
+	case QEIntGreaterEqual:
+		return numeric(func(f float64) bool { return f >= float64(value) }), nil
+	case QEIntLessThan:
+		return numeric(func(f float64) bool { return f < float64(value) }), nil
+	case QEIntLessThanEqual:
+		return numeric(func(f float64) bool { return f <= float64(value) }), nil
+	case QENone:
+		return func(object map[string]any) bool {
+			return (object[key] == nil) == bool(value)
+		}, nil
+	default:
+		return nil, fmt.Errorf("attribute %q: value type %T cannot be evaluated offline", key, value)
+	}
+}
+
+func graphString(in any) (string, bool) {
+	switch in := in.(type) {
+	case string:
+		return in, true
+	case json.Number:
+		return in.String(), true
+	}
+	return "", false
+}
+
+func graphNumber(in any) (float64, bool) {
+	n, ok := in.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
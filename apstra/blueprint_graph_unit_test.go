@@ -0,0 +1,178 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testBlueprintGraphJson = `{
+  "id": "bp1",
+  "version": 7,
+  "design": "two_stage_l3clos",
+  "label": "test",
+  "last_modified_at": "2024-01-02T03:04:05Z",
+  "nodes": {
+    "spine1": {"id": "spine1", "type": "system", "role": "spine", "label": "spine1", "external": false, "asn": 64512},
+    "leaf1":  {"id": "leaf1",  "type": "system", "role": "leaf",  "label": "leaf1",  "external": false, "asn": 65001},
+    "leaf2":  {"id": "leaf2",  "type": "system", "role": "leaf",  "label": "leaf2",  "external": false, "asn": 65002, "deploy_mode": null},
+    "ld1":    {"id": "ld1",    "type": "logical_device", "label": "AOS-48x10-1"},
+    "tag_a":  {"id": "tag_a",  "type": "tag", "label": "a"}
+  },
+  "relationships": {
+    "r1": {"id": "r1", "type": "logical_device", "source_id": "spine1", "target_id": "ld1"},
+    "r2": {"id": "r2", "type": "logical_device", "source_id": "leaf1",  "target_id": "ld1"},
+    "r3": {"id": "r3", "type": "logical_device", "source_id": "leaf2",  "target_id": "ld1"},
+    "r4": {"id": "r4", "type": "tag", "source_id": "tag_a", "target_id": "leaf1"}
+  }
+}`
+
+func testBlueprintGraph(t testing.TB) *BlueprintGraph {
+	t.Helper()
+
+	var graph BlueprintGraph
+	require.NoError(t, json.Unmarshal([]byte(testBlueprintGraphJson), &graph))
+	return &graph
+}
+
+type testGraphQueryResult struct {
+	Count int `json:"count"`
+	Items []map[string]*struct {
+		Id    string `json:"id"`
+		Label string `json:"label"`
+	} `json:"items"`
+}
+
+// labels returns the label of the named element in each item, "<nil>" for
+// unmatched optional elements
+func (o testGraphQueryResult) labels(name string) []string {
+	var result []string
+	for _, item := range o.Items {
+		if item[name] == nil {
+			result = append(result, "<nil>")
+			continue
+		}
+		result = append(result, item[name].Label)
+	}
+	return result
+}
+
+func TestBlueprintGraphRoundTrip(t *testing.T) {
+	graph := testBlueprintGraph(t)
+	require.Equal(t, RefDesignTwoStageL3Clos, graph.Design)
+	require.Equal(t, 7, graph.Version)
+	require.Equal(t, []ObjectId{"leaf1", "leaf2", "spine1"}, graph.NodeIds("system"))
+
+	b, err := json.Marshal(graph)
+	require.NoError(t, err)
+
+	var graph2 BlueprintGraph
+	require.NoError(t, json.Unmarshal(b, &graph2))
+	require.Equal(t, graph, &graph2)
+	require.Equal(t, json.Number("65001"), graph2.Nodes["leaf1"]["asn"])
+}
+
+func TestBlueprintGraphQuery(t *testing.T) {
+	graph := testBlueprintGraph(t)
+
+	type testCase struct {
+		query    QEQuery
+		name     string
+		expected []string
+		errors   bool
+	}
+
+	testCases := map[string]testCase{
+		"path": {
+			query: new(PathQuery).
+				Node([]QEEAttribute{
+					NodeTypeSystem.QEEAttribute(),
+					{"name", QEStringVal("n_system")},
+					{"role", QEStringValIsIn{"spine", "leaf"}},
+					{"external", QEBoolVal(false)},
+				}).
+				Out([]QEEAttribute{RelationshipTypeLogicalDevice.QEEAttribute()}).
+				Node([]QEEAttribute{NodeTypeLogicalDevice.QEEAttribute(), {"name", QEStringVal("n_ld")}}),
+			name:     "n_system",
+			expected: []string{"leaf1", "leaf2", "spine1"},
+		},
+		"path_in_implicit_node": {
+			query: new(PathQuery).
+				Node([]QEEAttribute{NodeTypeLogicalDevice.QEEAttribute()}).
+				In([]QEEAttribute{{"name", QEStringVal("rel")}}),
+			name:     "rel",
+			expected: []string{"", "", ""}, // relationships have no label
+		},
+		"path_int_and_none": {
+			query: new(PathQuery).
+				Node([]QEEAttribute{
+					{"name", QEStringVal("n")},
+					{"asn", QEIntGreater(65000)},
+					{"deploy_mode", QENone(true)},
+				}),
+			name:     "n",
+			expected: []string{"leaf1", "leaf2"},
+		},
+		"path_not_in": {
+			query: new(PathQuery).
+				Node([]QEEAttribute{
+					NodeTypeSystem.QEEAttribute(),
+					{"name", QEStringVal("n")},
+					{"role", QEStringValNotIn{"leaf"}},
+				}),
+			name:     "n",
+			expected: []string{"spine1"},
+		},
+		"match_optional": {
+			query: new(MatchQuery).
+				Match(new(PathQuery).Node([]QEEAttribute{NodeTypeSystem.QEEAttribute(), {"name", QEStringVal("n_system")}})).
+				Optional(new(PathQuery).
+					Node([]QEEAttribute{NodeTypeSystem.QEEAttribute(), {"name", QEStringVal("n_system")}}).
+					In([]QEEAttribute{RelationshipTypeTag.QEEAttribute()}).
+					Node([]QEEAttribute{NodeTypeTag.QEEAttribute(), {"name", QEStringVal("n_tag")}})),
+			name:     "n_tag",
+			expected: []string{"a", "<nil>", "<nil>"},
+		},
+		"match_distinct": {
+			query: new(MatchQuery).
+				Match(new(PathQuery).
+					Node([]QEEAttribute{NodeTypeSystem.QEEAttribute()}).
+					Out([]QEEAttribute{RelationshipTypeLogicalDevice.QEEAttribute()}).
+					Node([]QEEAttribute{{"name", QEStringVal("n_ld")}})).
+				Distinct(MatchQueryDistinct{"n_ld"}),
+			name:     "n_ld",
+			expected: []string{"AOS-48x10-1"},
+		},
+		"where": {
+			query:  new(PathQuery).Node([]QEEAttribute{NodeTypeSystem.QEEAttribute()}).Where("lambda system: True"),
+			errors: true,
+		},
+		"raw": {
+			query:  new(RawQuery).SetQuery("node('system')"),
+			errors: true,
+		},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+
+			var result testGraphQueryResult
+			err := graph.Query(tCase.query, &result)
+			if tCase.errors {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(tCase.expected), result.Count)
+			require.Equal(t, tCase.expected, result.labels(tCase.name))
+			require.NotEmpty(t, tCase.query.RawResult())
+		})
+	}
+}
@@ -56,6 +56,7 @@ const (
 
 	mutexKeySeparator   = ":"
 	mutexKeyHttpHeaders = "http headers"
+	mutexKeyCredentials = "credentials"
 )
 
 type ErrCtAssignedToLinkDetail struct {
@@ -107,6 +108,12 @@ type apstraHttpClient interface {
 // DefaultTimeout value, positive values are used directly.
 // ErrChan, when not nil, is used by async operations to deliver any errors to
 // the caller's code.
+// CredentialProvider, when not nil, is consulted for the username and password
+// at each login, and User and Pass must be empty.
+// SessionCache, when not nil, lets Clients share auth tokens and discovery
+// results, avoiding a login and discovery API calls when a Client is created.
+// Cached discovery results are trusted for DiscoveryCacheTtl (0/default uses
+// DefaultDiscoveryCacheTtl).
 type ClientCfg struct {
 	Url          string         // URL to access Apstra
 	User         string         // Apstra API/UI username
@@ -119,6 +126,15 @@ type ClientCfg struct {
 	Experimental bool           // used to enable experimental features
 	UserAgent    string         // may used to set a custom user-agent
 	tuningParams map[string]int // various tunable parameters keyed by name
+
+	CredentialProvider CredentialProvider // optional alternative to User and Pass
+	SessionCache       SessionCache       // optional, shares auth tokens and discovery results between Clients
+	DiscoveryCacheTtl  time.Duration      // 0 = DefaultDiscoveryCacheTtl
+
+	LenientEnumDecoding  bool                 // preserve API response values unknown to the SDK rather than failing
+	UnknownEnumValueHook UnknownEnumValueHook // optional, reports values preserved by LenientEnumDecoding
+
+	DropUnknownJsonFields bool // don't send API object fields unknown to the SDK back with Update operations
 }
 
 // TaskId represents outstanding tasks on an Apstra server
@@ -155,6 +171,9 @@ type Client struct {
 	sync        map[string]*sync.Mutex   // some client operations are not concurrency safe. Their locks live here.
 	syncLock    sync.Mutex               // control access to the 'sync' map
 	features    map[enum.ApiFeature]bool // true/false indicate feature enabled/disabled status
+
+	unknownEnumsSeen     map[string]struct{} // unknown enum values already reported, keyed by "<type>:<value>"
+	unknownEnumsSeenLock sync.Mutex          // control access to the 'unknownEnumsSeen' map
 }
 
 // GetTuningParam returns a named timer value from the client configuration if one has been configured.
@@ -212,7 +231,7 @@ func (o *Client) NewTwoStageL3ClosClient(ctx context.Context, blueprintId Object
 		blueprintId:   blueprintId,
 		nodeIdsByType: make(map[NodeType][]ObjectId),
 	}
-	result.Mutex = &TwoStageL3ClosMutex{client: result}
+	result.Mutex = newTwoStageL3ClosMutex(result)
 
 	return result, nil
 }
@@ -255,6 +274,7 @@ func (o *Client) NewFreeformClient(ctx context.Context, blueprintId ObjectId) (*
 	return &FreeformClient{
 		client:      o,
 		blueprintId: blueprintId,
+		Mutex:       newBlueprintMutex(o, blueprintId),
 	}, nil
 }
 
@@ -262,6 +282,10 @@ func (o ClientCfg) validate() error {
 	switch {
 	case o.Url == "":
 		return errors.New("error Url for Apstra Service cannot be empty")
+	case o.CredentialProvider != nil && (o.User != "" || o.Pass != ""):
+		return errors.New("error username and password must be empty when a credential provider is used")
+	case o.CredentialProvider != nil:
+		return nil
 	case o.User == "":
 		return errors.New("error username for Apstra service cannot be empty")
 	case o.Pass == "":
@@ -316,15 +340,21 @@ func (o ClientCfg) NewClient(ctx context.Context) (*Client, error) {
 		ctx:         context.Background(),
 	}
 
-	err = c.getFeatures(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed getting features from new client - %w", err)
-	}
+	c.loadCachedToken()
 
-	// must call getApiVersion() before apiVersionSupported()
-	_, err = c.getApiVersion(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed getting API version from new client - %w", err)
+	if !c.loadCachedDiscovery() {
+		err = c.getFeatures(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting features from new client - %w", err)
+		}
+
+		// must call getApiVersion() before apiVersionSupported()
+		_, err = c.getApiVersion(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting API version from new client - %w", err)
+		}
+
+		c.storeCachedDiscovery()
 	}
 
 	if !c.apiVersionSupported() {
@@ -802,6 +832,12 @@ func (o *Client) GetSystemInfo(ctx context.Context, id SystemId) (*ManagedSystem
 	return raw.polish()
 }
 
+// GetSystemLldpNeighbors returns the LLDP neighbors reported by the LLDP
+// telemetry service of the specified managed system.
+func (o *Client) GetSystemLldpNeighbors(ctx context.Context, id SystemId) ([]LldpNeighbor, error) {
+	return o.getSystemLldpNeighbors(ctx, id)
+}
+
 // UpdateSystem deletes the supplied SystemId
 func (o *Client) UpdateSystem(ctx context.Context, id SystemId, cfg *SystemUserConfig) error {
 	return o.updateSystem(ctx, id, cfg)
@@ -853,7 +889,7 @@ func (o *Client) CreateBlueprintFromTemplate(ctx context.Context, req *CreateBlu
 	var id ObjectId
 	var err error
 	switch {
-	case compatibility.EqApstra420.Check(o.apiVersion):
+	case o.Supports("Client.CreateBlueprintFromTemplate.FabricAddressingPolicy"):
 		id, err = o.createBlueprintFromTemplate420(ctx, req.raw420())
 		if err != nil {
 			return id, fmt.Errorf("failed while creating new blueprint - %w", err)
@@ -1438,6 +1474,23 @@ func (o *Client) CreateInterfaceMap(ctx context.Context, in *InterfaceMapData) (
 	return o.createInterfaceMap(ctx, in)
 }
 
+// GenerateInterfaceMap fetches the logical device and device profile
+// identified by ldId and dpId and returns an InterfaceMapData suitable for use
+// with CreateInterfaceMap. See GenerateInterfaceMapData for details.
+func (o *Client) GenerateInterfaceMap(ctx context.Context, ldId ObjectId, dpId ObjectId) (*InterfaceMapData, error) {
+	ld, err := o.GetLogicalDevice(ctx, ldId)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching logical device %q - %w", ldId, err)
+	}
+
+	dp, err := o.GetDeviceProfile(ctx, dpId)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching device profile %q - %w", dpId, err)
+	}
+
+	return GenerateInterfaceMapData(ldId, ld.Data, dpId, dp.Data)
+}
+
 // UpdateInterfaceMap updates the interface map represented by id, with the details in ifMap
 func (o *Client) UpdateInterfaceMap(ctx context.Context, id ObjectId, ifMap *InterfaceMapData) error {
 	return o.updateInterfaceMap(ctx, id, ifMap)
@@ -1602,7 +1655,9 @@ func (o *Client) CreateDeviceProfile(ctx context.Context, profile *DeviceProfile
 
 // UpdateDeviceProfile updates existing device profile
 func (o *Client) UpdateDeviceProfile(ctx context.Context, id ObjectId, profile *DeviceProfileData) error {
-	return o.updateDeviceProfile(ctx, id, profile.raw())
+	raw := profile.raw()
+	raw.unknownFields = o.unknownJsonFieldsForUpdate(profile.unknownFields)
+	return o.updateDeviceProfile(ctx, id, raw)
 }
 
 // DeleteDeviceProfile deletes existing device profile
@@ -1649,8 +1704,10 @@ func (o *Client) GetSystemAgentManagerConfig(ctx context.Context) (*SystemAgentM
 // SetSystemAgentManagerConfig uses a *SystemAgentManagerConfig object to configure the Advanced Settings
 // found on the Managed Devices page of the Web UI.
 func (o *Client) SetSystemAgentManagerConfig(ctx context.Context, cfg *SystemAgentManagerConfig) error {
-	if !compatibility.SystemManagerHasSkipInterfaceShutdownOnUpgrade.Check(o.apiVersion) && cfg.SkipInterfaceShutdownOnUpgrade {
-		return fmt.Errorf("SkipInterfaceShutdownOnUpgrade may only be used with apstra %s", compatibility.SystemManagerHasSkipInterfaceShutdownOnUpgrade)
+	if cfg.SkipInterfaceShutdownOnUpgrade {
+		if err := o.checkSupports("SystemAgentManagerConfig.SkipInterfaceShutdownOnUpgrade"); err != nil {
+			return err
+		}
 	}
 
 	return o.setSystemAgentManagerConfig(ctx, cfg)
@@ -1857,7 +1914,7 @@ func (o *Client) GetLastDeployedRevision(ctx context.Context, id ObjectId) (*Blu
 func (o *Client) BlueprintOverlayControlProtocol(ctx context.Context, id ObjectId) (OverlayControlProtocol, error) {
 	nodeAttributes := []QEEAttribute{{"name", QEStringVal("node")}}
 	switch {
-	case compatibility.BpHasVirtualNetworkPolicyNode.Check(o.apiVersion):
+	case o.Supports("Client.BlueprintOverlayControlProtocol.PolicyNodes"):
 		nodeAttributes = append(nodeAttributes, NodeTypeVirtualNetworkPolicy.QEEAttribute())
 	default:
 		nodeAttributes = append(nodeAttributes, NodeTypeFabricPolicy.QEEAttribute())
@@ -11,6 +11,9 @@ import (
 )
 
 var (
+	ApiTokensSupported = Constraint{
+		constraints: version.MustConstraints(version.NewConstraint(">=" + apstra500)),
+	}
 	BpHasFabricAddressingPolicyNode = Constraint{
 		constraints: version.MustConstraints(version.NewConstraint("<=" + apstra420)),
 	}
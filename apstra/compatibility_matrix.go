@@ -0,0 +1,159 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/compatibility"
+	"github.com/hashicorp/go-version"
+)
+
+// featureConstraints maps SDK features which are not available with every
+// Apstra release to the versions which support them. Methods are named
+// "<receiver type>.<method>", versioned request fields "<type>.<field>".
+// Version-specific variants of a method or field append a qualifier: the
+// "FabricSettingsApi", "FabricAddressingPolicy" and "PolicyNodes" variants
+// name the API or graph nodes each release uses, and "Required" marks a field
+// which must be set with the matching versions. Features not listed here are
+// expected to work with every supported version.
+var featureConstraints = func() map[string]compatibility.Constraint {
+	result := map[string]compatibility.Constraint{
+		"Client.BlueprintOverlayControlProtocol.PolicyNodes":         compatibility.BpHasVirtualNetworkPolicyNode,
+		"Client.CreateApiToken":                                      compatibility.ApiTokensSupported,
+		"Client.CreateBlueprintFromTemplate.FabricAddressingPolicy":  compatibility.BpHasFabricAddressingPolicyNode,
+		"Client.DeleteApiToken":                                      compatibility.ApiTokensSupported,
+		"Client.GetAllApiTokens":                                     compatibility.ApiTokensSupported,
+		"Client.GetApiToken":                                         compatibility.ApiTokensSupported,
+		"CreatePodBasedTemplateRequest.AntiAffinityPolicy.Required":  compatibility.TemplateRequestRequiresAntiAffinityPolicy,
+		"CreateRackBasedTemplateRequest.AntiAffinityPolicy.Required": compatibility.TemplateRequestRequiresAntiAffinityPolicy,
+		"DcRoutingExportPolicy.L3EdgeServerLinks":                    compatibility.RoutingPolicyExportHasL3EdgeLinks,
+		"SystemAgentManagerConfig.SkipInterfaceShutdownOnUpgrade":    compatibility.SystemManagerHasSkipInterfaceShutdownOnUpgrade,
+		"TwoStageL3ClosClient.CreateIbaDashboard":                    compatibility.IbaDashboardSupported,
+		"TwoStageL3ClosClient.CreateIbaProbe":                        compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.CreateIbaProbeFromJson":                compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.CreateIbaWidget":                       compatibility.IbaWidgetSupported,
+		"TwoStageL3ClosClient.DeleteIbaDashboard":                    compatibility.IbaDashboardSupported,
+		"TwoStageL3ClosClient.DeleteIbaProbe":                        compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.DeleteIbaWidget":                       compatibility.IbaWidgetSupported,
+		"TwoStageL3ClosClient.GetAllIbaDashboards":                   compatibility.IbaDashboardSupported,
+		"TwoStageL3ClosClient.GetAllIbaPredefinedProbes":             compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.GetAllIbaWidgets":                      compatibility.IbaWidgetSupported,
+		"TwoStageL3ClosClient.GetFabricSettings.FabricSettingsApi":   compatibility.FabricSettingsApiOk,
+		"TwoStageL3ClosClient.GetFabricSettings.PolicyNodes":         compatibility.BpHasVirtualNetworkPolicyNode,
+		"TwoStageL3ClosClient.GetIbaDashboard":                       compatibility.IbaDashboardSupported,
+		"TwoStageL3ClosClient.GetIbaDashboardByLabel":                compatibility.IbaDashboardSupported,
+		"TwoStageL3ClosClient.GetIbaPredefinedProbeByName":           compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.GetIbaProbe":                           compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.GetIbaProbeStageData":                  compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.GetIbaProbeState":                      compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.GetIbaWidget":                          compatibility.IbaWidgetSupported,
+		"TwoStageL3ClosClient.GetIbaWidgetByLabel":                   compatibility.IbaWidgetSupported,
+		"TwoStageL3ClosClient.GetIbaWidgetsByLabel":                  compatibility.IbaWidgetSupported,
+		"TwoStageL3ClosClient.InstantiateIbaPredefinedProbe":         compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.SetFabricSettings.FabricSettingsApi":   compatibility.FabricSettingsApiOk,
+		"TwoStageL3ClosClient.SetFabricSettings.PolicyNodes":         compatibility.BpHasVirtualNetworkPolicyNode,
+		"TwoStageL3ClosClient.UpdateIbaDashboard":                    compatibility.IbaDashboardSupported,
+		"TwoStageL3ClosClient.UpdateIbaProbe":                        compatibility.IbaProbeSupported,
+		"TwoStageL3ClosClient.UpdateIbaWidget":                       compatibility.IbaWidgetSupported,
+	}
+
+	for field, constraint := range fabricSettingsFieldConstraints {
+		result["FabricSettings."+field] = constraint
+	}
+
+	return result
+}()
+
+// Supports returns false when the named feature (see GetCompatibilityReport
+// for the list) is known not to work with the Apstra server's version. Features
+// which are not version-dependent are always supported.
+func (o *Client) Supports(feature string) bool {
+	return featureSupported(feature, o.apiVersion)
+}
+
+func featureSupported(feature string, v *version.Version) bool {
+	constraint, ok := featureConstraints[feature]
+	if !ok {
+		return true
+	}
+
+	return constraint.Check(v)
+}
+
+// checkSupports returns an ErrCompatibility error when the named feature
+// cannot be used with the Apstra server's version.
+func (o *Client) checkSupports(feature string) error {
+	if o.Supports(feature) {
+		return nil
+	}
+
+	return ClientErr{
+		errType: ErrCompatibility,
+		err: fmt.Errorf("%s supported only with apstra version %s, server is running %s",
+			feature, featureConstraints[feature], o.apiVersion),
+	}
+}
+
+// CompatibilityReportFeature describes the Apstra versions which support an
+// SDK feature. Versions is keyed by the entries of compatibility.SupportedApiVersions().
+type CompatibilityReportFeature struct {
+	Feature    string
+	Constraint string
+	Versions   map[string]bool
+}
+
+// CompatibilityReport describes every version-dependent SDK feature, sorted
+// by feature name.
+type CompatibilityReport []CompatibilityReportFeature
+
+// GetCompatibilityReport evaluates each version-dependent SDK feature against
+// each version in compatibility.SupportedApiVersions().
+func GetCompatibilityReport() CompatibilityReport {
+	result := make(CompatibilityReport, 0, len(featureConstraints))
+	for _, feature := range sortedKeys(featureConstraints) {
+		f := CompatibilityReportFeature{
+			Feature:    feature,
+			Constraint: featureConstraints[feature].String(),
+			Versions:   make(map[string]bool),
+		}
+		for _, v := range compatibility.SupportedApiVersions() {
+			f.Versions[v] = featureSupported(feature, version.Must(version.NewVersion(v)))
+		}
+		result = append(result, f)
+	}
+
+	return result
+}
+
+// Markdown renders the report as a markdown table with one row per feature
+// and one column per supported Apstra version.
+func (o CompatibilityReport) Markdown() string {
+	versions := compatibility.SupportedApiVersions()
+	sort.SliceStable(versions, func(i, j int) bool {
+		return version.Must(version.NewVersion(versions[i])).LessThan(version.Must(version.NewVersion(versions[j])))
+	})
+
+	sb := new(strings.Builder)
+	sb.WriteString("# Apstra version compatibility\n\n")
+	sb.WriteString("SDK features not listed here work with every supported Apstra version.\n\n")
+	sb.WriteString("| Feature | Constraint | " + strings.Join(versions, " | ") + " |\n")
+	sb.WriteString("|---|---|" + strings.Repeat("---|", len(versions)) + "\n")
+	for _, f := range o {
+		sb.WriteString("| " + f.Feature + " | `" + f.Constraint + "` |")
+		for _, v := range versions {
+			if f.Versions[v] {
+				sb.WriteString(" yes |")
+			} else {
+				sb.WriteString(" no |")
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
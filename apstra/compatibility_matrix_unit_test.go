@@ -0,0 +1,93 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureConstraintNames(t *testing.T) {
+	types := map[string]reflect.Type{
+		"Client":                         reflect.TypeOf(&Client{}),
+		"CreatePodBasedTemplateRequest":  reflect.TypeOf(CreatePodBasedTemplateRequest{}),
+		"CreateRackBasedTemplateRequest": reflect.TypeOf(CreateRackBasedTemplateRequest{}),
+		"DcRoutingExportPolicy":          reflect.TypeOf(DcRoutingExportPolicy{}),
+		"TwoStageL3ClosClient":           reflect.TypeOf(&TwoStageL3ClosClient{}),
+		"FabricSettings":                 reflect.TypeOf(FabricSettings{}),
+		"SystemAgentManagerConfig":       reflect.TypeOf(SystemAgentManagerConfig{}),
+	}
+
+	for feature := range featureConstraints {
+		typeName, member, ok := strings.Cut(feature, ".")
+		require.Truef(t, ok, "feature %q is not named <type>.<member>", feature)
+
+		member, qualifier, _ := strings.Cut(member, ".")
+		require.NotContainsf(t, qualifier, ".", "feature %q has more than one qualifier", feature)
+
+		typ, ok := types[typeName]
+		require.Truef(t, ok, "feature %q refers to unexpected type %q", feature, typeName)
+
+		if typ.Kind() == reflect.Pointer {
+			_, ok = typ.MethodByName(member)
+		} else {
+			_, ok = typ.FieldByName(member)
+		}
+		require.Truef(t, ok, "feature %q refers to a member which does not exist", feature)
+	}
+}
+
+func TestCheckSupports(t *testing.T) {
+	client := &Client{apiVersion: version.Must(version.NewVersion("4.2.0"))}
+
+	require.True(t, client.Supports("TwoStageL3ClosClient.GetAllIbaWidgets"))
+	require.True(t, client.Supports("TwoStageL3ClosClient.GetFabricSettings.PolicyNodes"))
+	require.False(t, client.Supports("TwoStageL3ClosClient.GetFabricSettings.FabricSettingsApi"))
+	require.True(t, client.Supports("CreateRackBasedTemplateRequest.AntiAffinityPolicy.Required"))
+	require.True(t, client.Supports("Client.GetVersion")) // not version-dependent
+	require.False(t, client.Supports("Client.GetAllApiTokens"))
+	require.True(t, client.Supports("Client.CreateBlueprintFromTemplate.FabricAddressingPolicy"))
+	require.True(t, client.Supports("Client.BlueprintOverlayControlProtocol.PolicyNodes"))
+	require.True(t, client.Supports("DcRoutingExportPolicy.L3EdgeServerLinks"))
+	require.NoError(t, client.checkSupports("TwoStageL3ClosClient.GetAllIbaWidgets"))
+
+	err := client.checkSupports("Client.GetAllApiTokens")
+	var ace ClientErr
+	require.ErrorAs(t, err, &ace)
+	require.Equal(t, ErrCompatibility, ace.Type())
+
+	client.apiVersion = version.Must(version.NewVersion("5.0.0"))
+	require.True(t, client.Supports("Client.GetAllApiTokens"))
+	require.False(t, client.Supports("TwoStageL3ClosClient.GetAllIbaWidgets"))
+	require.False(t, client.Supports("TwoStageL3ClosClient.GetIbaDashboard"))
+	require.False(t, client.Supports("TwoStageL3ClosClient.SetFabricSettings.PolicyNodes"))
+	require.True(t, client.Supports("TwoStageL3ClosClient.SetFabricSettings.FabricSettingsApi"))
+	require.False(t, client.Supports("CreateRackBasedTemplateRequest.AntiAffinityPolicy.Required"))
+	require.False(t, client.Supports("Client.CreateBlueprintFromTemplate.FabricAddressingPolicy"))
+	require.False(t, client.Supports("Client.BlueprintOverlayControlProtocol.PolicyNodes"))
+	require.False(t, client.Supports("DcRoutingExportPolicy.L3EdgeServerLinks"))
+}
+
+func TestCompatibilityReport(t *testing.T) {
+	report := GetCompatibilityReport()
+	require.Len(t, report, len(featureConstraints))
+
+	for _, f := range report {
+		if f.Feature == "Client.GetAllApiTokens" {
+			require.False(t, f.Versions["4.2.2"])
+			require.True(t, f.Versions["5.0.0"])
+		}
+	}
+
+	// COMPATIBILITY.md is generated by "make compatibility-report"
+	expected, err := os.ReadFile("../COMPATIBILITY.md")
+	require.NoError(t, err)
+	require.Equal(t, string(expected), report.Markdown(), "COMPATIBILITY.md is stale, run 'make compatibility-report'")
+}
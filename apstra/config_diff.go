@@ -0,0 +1,324 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ConfigDiffOp int
+
+const (
+	ConfigDiffEqual = ConfigDiffOp(iota)
+	ConfigDiffDelete
+	ConfigDiffInsert
+)
+
+func (o ConfigDiffOp) prefix() string {
+	switch o {
+	case ConfigDiffDelete:
+		return "-"
+	case ConfigDiffInsert:
+		return "+"
+	default:
+		return " "
+	}
+}
+
+// ConfigDiffLine is one line of a ConfigDiff. OldLine and NewLine are 1-based
+// line numbers; OldLine is zero for inserted lines, NewLine is zero for
+// deleted lines.
+type ConfigDiffLine struct {
+	Op      ConfigDiffOp
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// ConfigDiff is a line-based edit script which transforms one configuration
+// into another.
+type ConfigDiff []ConfigDiffLine
+
+// DiffConfig compares configurations line by line (Myers' algorithm, so the
+// edit script is minimal). Trailing whitespace is significant.
+func DiffConfig(oldConfig, newConfig string) ConfigDiff {
+	a, b := configLines(oldConfig), configLines(newConfig)
+
+	// strip the common prefix and suffix, which are frequently most of it
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make(ConfigDiff, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		result = append(result, ConfigDiffLine{Op: ConfigDiffEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	for _, line := range myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if line.OldLine != 0 {
+			line.OldLine += prefix
+		}
+		if line.NewLine != 0 {
+			line.NewLine += prefix
+		}
+		result = append(result, line)
+	}
+
+	for i := suffix; i > 0; i-- {
+		result = append(result, ConfigDiffLine{Op: ConfigDiffEqual, Text: a[len(a)-i], OldLine: len(a) - i + 1, NewLine: len(b) - i + 1})
+	}
+
+	return result
+}
+
+// Changed returns true if the configurations differ.
+func (o ConfigDiff) Changed() bool {
+	for _, line := range o {
+		if line.Op != ConfigDiffEqual {
+			return true
+		}
+	}
+	return false
+}
+
+// Unified renders the diff in unified format with the specified number of
+// lines of context around each change. Returns an empty string when the
+// configurations are identical.
+func (o ConfigDiff) Unified(oldName, newName string, context int) string {
+	if !o.Changed() {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- " + oldName + "\n")
+	sb.WriteString("+++ " + newName + "\n")
+
+	for start := 0; start < len(o); {
+		// find the next change
+		first := start
+		for first < len(o) && o[first].Op == ConfigDiffEqual {
+			first++
+		}
+		if first == len(o) {
+			break
+		}
+
+		// extend the hunk until the gap between changes exceeds 2*context
+		last := first
+		for i := first; i < len(o); i++ {
+			if o[i].Op != ConfigDiffEqual {
+				last = i
+			} else if i-last > 2*context {
+				break
+			}
+		}
+
+		hunkStart, hunkEnd := max(first-context, start), min(last+context+1, len(o))
+		o.writeHunk(&sb, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+
+	return sb.String()
+}
+
+func (o ConfigDiff) writeHunk(sb *strings.Builder, start, end int) {
+	// line numbers preceding the hunk, for hunks which have no lines on one side
+	var oldStart, newStart int
+	for i := start - 1; i >= 0 && (oldStart == 0 || newStart == 0); i-- {
+		if oldStart == 0 && o[i].OldLine != 0 {
+			oldStart = o[i].OldLine
+		}
+		if newStart == 0 && o[i].NewLine != 0 {
+			newStart = o[i].NewLine
+		}
+	}
+
+	var oldCount, newCount int
+	for _, line := range o[start:end] {
+		if line.OldLine != 0 {
+			if oldCount == 0 {
+				oldStart = line.OldLine
+			}
+			oldCount++
+		}
+		if line.NewLine != 0 {
+			if newCount == 0 {
+				newStart = line.NewLine
+			}
+			newCount++
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+	for _, line := range o[start:end] {
+		sb.WriteString(line.Op.prefix() + line.Text + "\n")
+	}
+}
+
+// configLines splits a configuration into lines, ignoring the final newline.
+func configLines(config string) []string {
+	if config == "" {
+		return nil
+	}
+	config = strings.ReplaceAll(config, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(config, "\n"), "\n")
+}
+
+// myersDiff returns the shortest edit script transforming a into b, with line
+// numbers relative to a and b. It uses the linear space refinement of Myers'
+// algorithm: rather than keeping the furthest reaching paths of every edit
+// distance for backtracking (quadratic in the number of differences), it
+// finds a point on an optimal path by searching from both ends at once, and
+// recurses on either side of it.
+func myersDiff(a, b []string) ConfigDiff {
+	d := myersDiffer{a: a, b: b, result: make(ConfigDiff, 0, len(a)+len(b))}
+	d.diff(0, len(a), 0, len(b))
+	return d.result
+}
+
+type myersDiffer struct {
+	a, b   []string
+	result ConfigDiff
+}
+
+func (o *myersDiffer) equal(x, y int) {
+	o.result = append(o.result, ConfigDiffLine{Op: ConfigDiffEqual, Text: o.a[x], OldLine: x + 1, NewLine: y + 1})
+}
+
+func (o *myersDiffer) delete(x int) {
+	o.result = append(o.result, ConfigDiffLine{Op: ConfigDiffDelete, Text: o.a[x], OldLine: x + 1})
+}
+
+func (o *myersDiffer) insert(y int) {
+	o.result = append(o.result, ConfigDiffLine{Op: ConfigDiffInsert, Text: o.b[y], NewLine: y + 1})
+}
+
+// diff appends the edit script transforming a[aLo:aHi] into b[bLo:bHi]
+func (o *myersDiffer) diff(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && o.a[aLo] == o.b[bLo] {
+		o.equal(aLo, bLo)
+		aLo, bLo = aLo+1, bLo+1
+	}
+	suffix := 0
+	for aHi-suffix > aLo && bHi-suffix > bLo && o.a[aHi-suffix-1] == o.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch x, y, ok := o.bisect(aLo, aHi, bLo, bHi); {
+	case ok:
+		o.diff(aLo, x, bLo, y)
+		o.diff(x, aHi, y, bHi)
+	default:
+		// nothing in common
+		for x := aLo; x < aHi; x++ {
+			o.delete(x)
+		}
+		for y := bLo; y < bHi; y++ {
+			o.insert(y)
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		o.equal(aHi+i, bHi+i)
+	}
+}
+
+// bisect finds the point at which the forward and reverse searches for the
+// shortest edit script transforming a[aLo:aHi] into b[bLo:bHi] meet. It
+// returns false when the ranges have nothing in common. The ranges must not
+// share a common prefix or suffix.
+func (o *myersDiffer) bisect(aLo, aHi, bLo, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	// v1[offset+k] (v2[offset+k]) is the furthest x reached on diagonal k
+	// by the forward (reverse) search, or -1.
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	v1 := make([]int, 2*maxD+2)
+	v2 := make([]int, 2*maxD+2)
+	for i := range v1 {
+		v1[i], v2[i] = -1, -1
+	}
+	v1[offset+1], v2[offset+1] = 0, 0
+
+	delta := n - m
+	// when delta is odd, the forward search is the one which detects overlap
+	front := delta%2 != 0
+
+	// diagonals which have run off the edge of the grid needn't be explored
+	k1Start, k1End, k2Start, k2End := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k1 := -d + k1Start; k1 <= d-k1End; k1 += 2 {
+			k1Offset := offset + k1
+			var x1 int
+			if k1 == -d || (k1 != d && v1[k1Offset-1] < v1[k1Offset+1]) {
+				x1 = v1[k1Offset+1]
+			} else {
+				x1 = v1[k1Offset-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && o.a[aLo+x1] == o.b[bLo+y1] {
+				x1, y1 = x1+1, y1+1
+			}
+			v1[k1Offset] = x1
+
+			switch {
+			case x1 > n:
+				k1End += 2 // ran off the right of the grid
+			case y1 > m:
+				k1Start += 2 // ran off the bottom of the grid
+			case front:
+				k2Offset := offset + delta - k1
+				if k2Offset >= 0 && k2Offset < len(v2) && v2[k2Offset] != -1 && x1 >= n-v2[k2Offset] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+
+		for k2 := -d + k2Start; k2 <= d-k2End; k2 += 2 {
+			k2Offset := offset + k2
+			var x2 int
+			if k2 == -d || (k2 != d && v2[k2Offset-1] < v2[k2Offset+1]) {
+				x2 = v2[k2Offset+1]
+			} else {
+				x2 = v2[k2Offset-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && o.a[aHi-x2-1] == o.b[bHi-y2-1] {
+				x2, y2 = x2+1, y2+1
+			}
+			v2[k2Offset] = x2
+
+			switch {
+			case x2 > n:
+				k2End += 2
+			case y2 > m:
+				k2Start += 2
+			case !front:
+				k1Offset := offset + delta - k2
+				if k1Offset >= 0 && k1Offset < len(v1) && v1[k1Offset] != -1 {
+					x1 := v1[k1Offset]
+					y1 := offset + x1 - k1Offset
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
@@ -0,0 +1,133 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfig(t *testing.T) {
+	type testCase struct {
+		old      string
+		new      string
+		context  int
+		expected string
+	}
+
+	testCases := map[string]testCase{
+		"identical": {
+			old:      "a\nb\n",
+			new:      "a\nb",
+			expected: "",
+		},
+		"change_in_middle": {
+			old:     "a\nb\nc\nd\ne\nf\ng\n",
+			new:     "a\nb\nc\nX\ne\nf\ng\n",
+			context: 1,
+			expected: "" +
+				"--- expected\n" +
+				"+++ actual\n" +
+				"@@ -3,3 +3,3 @@\n" +
+				" c\n" +
+				"-d\n" +
+				"+X\n" +
+				" e\n",
+		},
+		"two_hunks": {
+			old:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:     "0\n1\n2\n3\n4\n5\n6\n7\n9\n",
+			context: 1,
+			expected: "" +
+				"--- expected\n" +
+				"+++ actual\n" +
+				"@@ -1,1 +1,2 @@\n" +
+				"+0\n" +
+				" 1\n" +
+				"@@ -7,3 +8,2 @@\n" +
+				" 7\n" +
+				"-8\n" +
+				" 9\n",
+		},
+		"from_empty": {
+			old:     "",
+			new:     "a\nb\n",
+			context: 3,
+			expected: "" +
+				"--- expected\n" +
+				"+++ actual\n" +
+				"@@ -0,0 +1,2 @@\n" +
+				"+a\n" +
+				"+b\n",
+		},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tCase.expected, DiffConfig(tCase.old, tCase.new).Unified("expected", "actual", tCase.context))
+		})
+	}
+}
+
+// lcsLen returns the length of the longest common subsequence of a and b
+func lcsLen(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				dp[i+1][j+1] = dp[i][j] + 1
+			} else {
+				dp[i+1][j+1] = max(dp[i][j+1], dp[i+1][j])
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
+
+// TestDiffConfigReconstruct checks that the edit script transforms the old
+// config into the new one, and that it's minimal.
+func TestDiffConfigReconstruct(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randomConfig := func() []string {
+		result := make([]string, r.Intn(40))
+		for i := range result {
+			result[i] = string(rune('a' + r.Intn(4)))
+		}
+		return result
+	}
+
+	for i := 0; i < 200; i++ {
+		a, b := randomConfig(), randomConfig()
+		diff := DiffConfig(strings.Join(a, "\n"), strings.Join(b, "\n"))
+
+		var old, new []string
+		var edits int
+		for _, line := range diff {
+			if line.Op != ConfigDiffInsert {
+				old = append(old, line.Text)
+				require.Equal(t, len(old), line.OldLine)
+			}
+			if line.Op != ConfigDiffDelete {
+				new = append(new, line.Text)
+				require.Equal(t, len(new), line.NewLine)
+			}
+			if line.Op != ConfigDiffEqual {
+				edits++
+			}
+		}
+
+		require.Equal(t, strings.Join(a, "\n"), strings.Join(old, "\n"))
+		require.Equal(t, strings.Join(b, "\n"), strings.Join(new, "\n"))
+		require.Equal(t, len(a)+len(b)-2*lcsLen(a, b), edits, "edit script is not minimal")
+	}
+}
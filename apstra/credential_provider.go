@@ -0,0 +1,315 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+const (
+	EnvApstraUser = "APSTRA_USER"
+	EnvApstraPass = "APSTRA_PASS"
+
+	kubernetesSecretUsernameKey = "username"
+	kubernetesSecretPasswordKey = "password"
+
+	credentialJsonUsernameKey = "username"
+	credentialJsonPasswordKey = "password"
+)
+
+// CredentialProvider supplies the username and password used by the Client
+// when logging in. It is consulted at every login, including the login which
+// follows an HTTP 401 from an expired session, so implementations which read
+// from an external source pick up rotated passwords without the Client being
+// restarted. Implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (username, password string, err error)
+}
+
+var (
+	_ CredentialProvider = StaticCredentials{}
+	_ CredentialProvider = EnvCredentials{}
+	_ CredentialProvider = FileCredentials{}
+	_ CredentialProvider = CommandCredentials{}
+	_ CredentialProvider = AwsSecretsManagerCredentials{}
+)
+
+// StaticCredentials is a CredentialProvider which always returns the same
+// username and password. It's useful where a provider is selected at runtime;
+// otherwise setting ClientCfg.User and ClientCfg.Pass is equivalent.
+type StaticCredentials struct {
+	User string
+	Pass string
+}
+
+func (o StaticCredentials) Credentials(_ context.Context) (string, string, error) {
+	return o.User, o.Pass, nil
+}
+
+// EnvCredentials is a CredentialProvider which reads the username and password
+// from environment variables. Empty UserVar and PassVar default to
+// EnvApstraUser and EnvApstraPass.
+type EnvCredentials struct {
+	UserVar string
+	PassVar string
+}
+
+func (o EnvCredentials) Credentials(_ context.Context) (string, string, error) {
+	userVar, passVar := o.UserVar, o.PassVar
+	if userVar == "" {
+		userVar = EnvApstraUser
+	}
+	if passVar == "" {
+		passVar = EnvApstraPass
+	}
+
+	user, ok := os.LookupEnv(userVar)
+	if !ok {
+		return "", "", fmt.Errorf("environment variable %q is not set", userVar)
+	}
+	pass, ok := os.LookupEnv(passVar)
+	if !ok {
+		return "", "", fmt.Errorf("environment variable %q is not set", passVar)
+	}
+
+	return user, pass, nil
+}
+
+// FileCredentials is a CredentialProvider which reads the username and
+// password from files each time they're required. A single trailing newline
+// is removed from each. When UserFile is empty, User is used as the username.
+type FileCredentials struct {
+	User     string
+	UserFile string
+	PassFile string
+}
+
+// NewKubernetesSecretCredentials returns a FileCredentials which reads a
+// kubernetes.io/basic-auth Secret mounted as a volume at dir. The kubelet
+// updates the mounted files when the Secret changes.
+func NewKubernetesSecretCredentials(dir string) FileCredentials {
+	return FileCredentials{
+		UserFile: filepath.Join(dir, kubernetesSecretUsernameKey),
+		PassFile: filepath.Join(dir, kubernetesSecretPasswordKey),
+	}
+}
+
+func (o FileCredentials) Credentials(_ context.Context) (string, string, error) {
+	if o.PassFile == "" {
+		return "", "", errors.New("password file not specified")
+	}
+
+	user := o.User
+	if o.UserFile != "" {
+		var err error
+		user, err = readCredentialFile(o.UserFile)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	pass, err := readCredentialFile(o.PassFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	return user, pass, nil
+}
+
+func readCredentialFile(name string) (string, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return "", fmt.Errorf("failed reading credential file - %w", err)
+	}
+
+	s := strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// CommandCredentials is a CredentialProvider which runs an external command
+// (Command[0] with arguments Command[1:], no shell) each time credentials are
+// required. When User is set, the command's output, less a trailing newline,
+// is the password. Otherwise, the output must be a JSON object with
+// "username" and "password" keys.
+type CommandCredentials struct {
+	User    string
+	Command []string
+}
+
+func (o CommandCredentials) Credentials(ctx context.Context) (string, string, error) {
+	if len(o.Command) == 0 {
+		return "", "", errors.New("credential command not specified")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, o.Command[0], o.Command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", "", fmt.Errorf("credential command %q failed: %s - %w",
+			o.Command[0], strings.TrimSpace(stderr.String()), err)
+	}
+
+	if o.User != "" {
+		pass := strings.TrimSuffix(strings.TrimSuffix(stdout.String(), "\n"), "\r")
+		return o.User, pass, nil
+	}
+
+	user, pass, err := parseCredentialJson(stdout.Bytes(), credentialJsonUsernameKey, credentialJsonPasswordKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed parsing output of credential command %q - %w", o.Command[0], err)
+	}
+
+	return user, pass, nil
+}
+
+// AwsSecretsManagerCredentials is a CredentialProvider which fetches a JSON
+// secret from AWS Secrets Manager each time credentials are required. Empty
+// UserKey and PassKey default to "username" and "password". When AwsConfig is
+// nil, the default AWS configuration (environment, shared config files,
+// instance role, etc...) is loaded.
+type AwsSecretsManagerCredentials struct {
+	SecretId  string
+	UserKey   string
+	PassKey   string
+	AwsConfig *aws.Config
+}
+
+func (o AwsSecretsManagerCredentials) Credentials(ctx context.Context) (string, string, error) {
+	if o.SecretId == "" {
+		return "", "", errors.New("AWS secret ID not specified")
+	}
+
+	userKey, passKey := o.UserKey, o.PassKey
+	if userKey == "" {
+		userKey = credentialJsonUsernameKey
+	}
+	if passKey == "" {
+		passKey = credentialJsonPasswordKey
+	}
+
+	var awsCfg aws.Config
+	if o.AwsConfig != nil {
+		awsCfg = *o.AwsConfig
+	} else {
+		var err error
+		awsCfg, err = config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return "", "", fmt.Errorf("error loading default AWS config - %w", err)
+		}
+	}
+
+	gsvo, err := secretsmanager.NewFromConfig(awsCfg).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(o.SecretId),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("error getting secret %q value - %w", o.SecretId, err)
+	}
+	if gsvo.SecretString == nil {
+		return "", "", fmt.Errorf("secret %q has no string value", o.SecretId)
+	}
+
+	user, pass, err := parseCredentialJson([]byte(*gsvo.SecretString), userKey, passKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed parsing secret %q - %w", o.SecretId, err)
+	}
+
+	return user, pass, nil
+}
+
+// parseCredentialJson extracts the username and password from the string
+// values at userKey and passKey of a JSON object.
+func parseCredentialJson(b []byte, userKey, passKey string) (string, string, error) {
+	var m map[string]json.RawMessage
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return "", "", err
+	}
+
+	var user, pass string
+	for key, target := range map[string]*string{userKey: &user, passKey: &pass} {
+		raw, ok := m[key]
+		if !ok {
+			return "", "", fmt.Errorf("key %q not found", key)
+		}
+		err = json.Unmarshal(raw, target)
+		if err != nil {
+			return "", "", fmt.Errorf("value at key %q is not a string - %w", key, err)
+		}
+	}
+
+	return user, pass, nil
+}
+
+// credentials returns the username and password to be used at login
+func (o *Client) credentials(ctx context.Context) (string, string, error) {
+	if o.cfg.CredentialProvider == nil {
+		// ChangeUserPassword may update the password concurrently
+		o.lock(mutexKeyCredentials)
+		defer o.unlock(mutexKeyCredentials)
+		return o.cfg.User, o.cfg.Pass, nil
+	}
+
+	user, pass, err := o.cfg.CredentialProvider.Credentials(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed fetching credentials - %w", err)
+	}
+	if user == "" || pass == "" {
+		return "", "", errors.New("credential provider returned an empty username or password")
+	}
+
+	return user, pass, nil
+}
+
+// credentialIdentity identifies the user the client logs in as, for keying
+// the session cache, without invoking the CredentialProvider (which may run a
+// command or call AWS). Provider configuration is hashed because it may
+// include secrets.
+func (o *Client) credentialIdentity() string {
+	var identity string
+	switch p := o.cfg.CredentialProvider.(type) {
+	case nil:
+		return o.cfg.User
+	case StaticCredentials:
+		return p.User
+	case EnvCredentials:
+		// environment variables differ between processes, so use the value
+		userVar := p.UserVar
+		if userVar == "" {
+			userVar = EnvApstraUser
+		}
+		return os.Getenv(userVar)
+	case FileCredentials:
+		identity = fmt.Sprintf("file %q %q %q", p.User, p.UserFile, p.PassFile)
+	case CommandCredentials:
+		identity = fmt.Sprintf("command %q %q", p.User, p.Command)
+	case AwsSecretsManagerCredentials:
+		var region string
+		if p.AwsConfig != nil {
+			region = p.AwsConfig.Region
+		}
+		identity = fmt.Sprintf("aws %q %q %q", region, p.SecretId, p.UserKey)
+	default:
+		identity = fmt.Sprintf("%T %+v", p, p)
+	}
+
+	sum := sha256.Sum256([]byte(identity))
+	return "provider " + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	t.Setenv(EnvApstraUser, "admin")
+	t.Setenv(EnvApstraPass, "secret")
+	user, pass, err := EnvCredentials{}.Credentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "admin", user)
+	require.Equal(t, "secret", pass)
+
+	t.Setenv("TEST_CRED_USER", "other")
+	t.Setenv("TEST_CRED_PASS", "other-secret")
+	user, pass, err = EnvCredentials{UserVar: "TEST_CRED_USER", PassVar: "TEST_CRED_PASS"}.Credentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "other", user)
+	require.Equal(t, "other-secret", pass)
+
+	_, _, err = EnvCredentials{PassVar: "TEST_CRED_UNSET_" + randString(8, "hex")}.Credentials(ctx)
+	require.Error(t, err)
+}
+
+func TestFileCredentials(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "username"), []byte("admin\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("secret"), 0o600))
+
+	provider := NewKubernetesSecretCredentials(dir)
+	user, pass, err := provider.Credentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "admin", user)
+	require.Equal(t, "secret", pass)
+
+	// rotation is picked up without a new provider
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("rotated\r\n"), 0o600))
+	_, pass, err = provider.Credentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "rotated", pass)
+
+	user, pass, err = FileCredentials{User: "fixed", PassFile: filepath.Join(dir, "password")}.Credentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "fixed", user)
+	require.Equal(t, "rotated", pass)
+
+	_, _, err = FileCredentials{User: "fixed", PassFile: filepath.Join(dir, "missing")}.Credentials(ctx)
+	require.Error(t, err)
+}
+
+func TestCommandCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	type testCase struct {
+		provider CommandCredentials
+		user     string
+		pass     string
+		errors   bool
+	}
+
+	testCases := map[string]testCase{
+		"password_only": {
+			provider: CommandCredentials{User: "admin", Command: []string{"echo", "secret"}},
+			user:     "admin",
+			pass:     "secret",
+		},
+		"json": {
+			provider: CommandCredentials{Command: []string{"echo", `{"username": "admin", "password": "secret", "ttl": 60}`}},
+			user:     "admin",
+			pass:     "secret",
+		},
+		"json_missing_password": {
+			provider: CommandCredentials{Command: []string{"echo", `{"username": "admin"}`}},
+			errors:   true,
+		},
+		"not_json": {
+			provider: CommandCredentials{Command: []string{"echo", "secret"}},
+			errors:   true,
+		},
+		"command_fails": {
+			provider: CommandCredentials{User: "admin", Command: []string{"false"}},
+			errors:   true,
+		},
+		"no_command": {
+			provider: CommandCredentials{User: "admin"},
+			errors:   true,
+		},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+
+			user, pass, err := tCase.provider.Credentials(ctx)
+			if tCase.errors {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tCase.user, user)
+			require.Equal(t, tCase.pass, pass)
+		})
+	}
+}
+
+func TestClientCfgValidateCredentials(t *testing.T) {
+	url := "https://apstra.example.com"
+
+	require.NoError(t, ClientCfg{Url: url, User: "admin", Pass: "secret"}.validate())
+	require.NoError(t, ClientCfg{Url: url, CredentialProvider: EnvCredentials{}}.validate())
+	require.Error(t, ClientCfg{Url: url, User: "admin"}.validate())
+	require.Error(t, ClientCfg{Url: url, User: "admin", CredentialProvider: EnvCredentials{}}.validate())
+}
+
+func TestClientCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	client := &Client{cfg: ClientCfg{User: "admin", Pass: "secret"}, sync: make(map[string]*sync.Mutex)}
+	user, pass, err := client.credentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "admin", user)
+	require.Equal(t, "secret", pass)
+
+	client = &Client{cfg: ClientCfg{CredentialProvider: StaticCredentials{User: "other", Pass: "other-secret"}}}
+	user, pass, err = client.credentials(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "other", user)
+	require.Equal(t, "other-secret", pass)
+
+	client = &Client{cfg: ClientCfg{CredentialProvider: StaticCredentials{User: "other"}}}
+	_, _, err = client.credentials(ctx)
+	require.Error(t, err)
+}
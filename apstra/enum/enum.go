@@ -49,6 +49,20 @@ var (
 		IbaWidgetTypeAnomalyHeatmap,
 	)
 
+	_                                            enum = new(IbaProbeProcessorType)
+	IbaProbeProcessorTypeAccumulate                   = IbaProbeProcessorType{Value: "accumulate"}
+	IbaProbeProcessorTypeExtensibleDataCollector      = IbaProbeProcessorType{Value: "extensible_data_collector"}
+	IbaProbeProcessorTypeMatchString                  = IbaProbeProcessorType{Value: "match_string"}
+	IbaProbeProcessorTypePeriodicAverage              = IbaProbeProcessorType{Value: "periodic_average"}
+	IbaProbeProcessorTypeRangeCheck                   = IbaProbeProcessorType{Value: "range_check"}
+	IbaProbeProcessorTypes                            = oenum.New(
+		IbaProbeProcessorTypeAccumulate,
+		IbaProbeProcessorTypeExtensibleDataCollector,
+		IbaProbeProcessorTypeMatchString,
+		IbaProbeProcessorTypePeriodicAverage,
+		IbaProbeProcessorTypeRangeCheck,
+	)
+
 	_                          enum = new(JunosEvpnIrbMode)
 	JunosEvpnIrbModeSymmetric       = JunosEvpnIrbMode{Value: "symmetric"}
 	JunosEvpnIrbModeAsymmetric      = JunosEvpnIrbMode{Value: "asymmetric"}
@@ -294,6 +308,21 @@ func (o *IbaWidgetType) FromString(s string) error {
 	return nil
 }
 
+type IbaProbeProcessorType oenum.Member[string]
+
+func (o IbaProbeProcessorType) String() string {
+	return o.Value
+}
+
+func (o *IbaProbeProcessorType) FromString(s string) error {
+	t := IbaProbeProcessorTypes.Parse(s)
+	if t == nil {
+		return newEnumParseError(o, s)
+	}
+	o.Value = t.Value
+	return nil
+}
+
 type JunosEvpnIrbMode oenum.Member[string]
 
 func (o JunosEvpnIrbMode) String() string {
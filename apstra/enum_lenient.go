@@ -0,0 +1,259 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// maxUnknownEnumValues limits the number of unknown values preserved for any
+// one raw enum type.
+const maxUnknownEnumValues = 64
+
+// UnknownEnumValueHook is invoked the first time a Client with
+// ClientCfg.LenientEnumDecoding enabled finds a value which isn't known to
+// the SDK in an API response.
+type UnknownEnumValueHook func(typeName, value string)
+
+// rawEnum is implemented by the raw (string) side of the iota-based enum types
+// in this package.
+type rawEnum interface {
+	parse() (int, error)
+}
+
+// apiEnum is implemented by the types in the enum package.
+type apiEnum interface {
+	String() string
+	FromString(string) error
+}
+
+// unknownApiEnums counts the unknown enum package values which have been kept
+// by apiEnumFromString.
+var unknownApiEnums atomic.Int64
+
+// apiEnumFromString parses s, a value found in an API response, into the enum
+// package type T. Unknown values are kept rather than rejected, so that they
+// don't fail json.Unmarshal() of the whole response. Client.checkResponseEnums
+// rejects them once decoding is complete, unless lenient decoding is enabled.
+// Empty strings aren't unknown values, and are rejected immediately.
+func apiEnumFromString[T ~struct{ Value string }, PT interface {
+	*T
+	apiEnum
+}](s string) (T, error) {
+	var result T
+	err := PT(&result).FromString(s)
+	if err == nil || s == "" {
+		return result, err
+	}
+
+	unknownApiEnums.Add(1)
+	return T(struct{ Value string }{Value: s}), nil
+}
+
+// unknownEnums holds the API values which aren't known to the iota-based enum
+// types in this package, but which have been preserved by lenient decoding.
+// Each value is assigned a negative code, unique within its raw type, which
+// the type's String() and raw() methods map back to the original value, so
+// that it survives a round trip through the SDK.
+var unknownEnums struct {
+	mutex  sync.RWMutex
+	values map[reflect.Type][]string // code -1 is values[0], code -2 is values[1], etc...
+}
+
+// unknownEnumCode returns the code assigned to an unknown value of the raw enum
+// type T, assigning a new code if necessary. The returned bool is false when
+// T has run out of codes.
+func unknownEnumCode(t reflect.Type, value string) (int, bool) {
+	unknownEnums.mutex.Lock()
+	defer unknownEnums.mutex.Unlock()
+
+	for i, v := range unknownEnums.values[t] {
+		if v == value {
+			return -1 - i, true
+		}
+	}
+
+	if len(unknownEnums.values[t]) >= maxUnknownEnumValues {
+		return 0, false
+	}
+
+	if unknownEnums.values == nil {
+		unknownEnums.values = make(map[reflect.Type][]string)
+	}
+	unknownEnums.values[t] = append(unknownEnums.values[t], value)
+
+	return -len(unknownEnums.values[t]), true
+}
+
+// lenientEnumCode returns the code assigned to a value of a raw enum type
+// which was preserved by lenient decoding of an API response. Values which
+// no Client has preserved return false.
+func lenientEnumCode[T ~string](o T) (int, bool) {
+	unknownEnums.mutex.RLock()
+	defer unknownEnums.mutex.RUnlock()
+
+	for i, v := range unknownEnums.values[reflect.TypeOf(o)] {
+		if v == string(o) {
+			return -1 - i, true
+		}
+	}
+
+	return 0, false
+}
+
+// lenientEnumString returns the original API value represented by a code
+// assigned to a value of the raw enum type T.
+func lenientEnumString[T ~string](code int) (string, bool) {
+	if code >= 0 {
+		return "", false
+	}
+
+	unknownEnums.mutex.RLock()
+	defer unknownEnums.mutex.RUnlock()
+
+	values := unknownEnums.values[reflect.TypeFor[T]()]
+	if -1-code >= len(values) {
+		return "", false
+	}
+
+	return values[-1-code], true
+}
+
+// parseKnown parses o like o.parse(), but rejects values preserved by lenient
+// decoding. It's used by FromString(), which parses caller input rather than
+// API responses.
+func parseKnown[T interface {
+	~string
+	rawEnum
+}](o T) (int, error) {
+	i, err := o.parse()
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("unknown %T value %q", o, string(o))
+	}
+
+	return i, nil
+}
+
+// checkResponseEnums walks an API response looking for raw enum values and
+// enum package values which aren't known to the SDK. With lenient decoding
+// enabled those values are preserved and reported. Otherwise, values preserved
+// on behalf of another Client, and enum package values kept by
+// apiEnumFromString, are rejected, so that they produce the same error as any
+// other unknown value.
+func (o *Client) checkResponseEnums(response any) error {
+	if !o.cfg.LenientEnumDecoding && unknownApiEnums.Load() == 0 {
+		unknownEnums.mutex.RLock()
+		empty := len(unknownEnums.values) == 0
+		unknownEnums.mutex.RUnlock()
+		if empty {
+			return nil // nothing has been preserved, so nothing to reject
+		}
+	}
+
+	return o.checkEnumValue(reflect.ValueOf(response))
+}
+
+func (o *Client) checkEnumValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return o.checkEnumValue(v.Elem())
+	case reflect.Struct:
+		if e, ok := reflect.New(v.Type()).Interface().(apiEnum); ok {
+			return o.checkApiEnum(v.Type(), v.Interface().(fmt.Stringer).String(), e)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := o.checkEnumValue(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := o.checkEnumValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := o.checkEnumValue(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if e, ok := v.Interface().(rawEnum); ok {
+			return o.checkEnum(v.Type(), v.String(), e)
+		}
+	}
+
+	return nil
+}
+
+func (o *Client) checkEnum(t reflect.Type, value string, e rawEnum) error {
+	i, err := e.parse()
+	switch {
+	case err == nil && i >= 0:
+		return nil // known value
+	case !o.cfg.LenientEnumDecoding && err == nil:
+		return fmt.Errorf("unknown %s value %q", t.Name(), value)
+	case !o.cfg.LenientEnumDecoding:
+		return nil // parse error will be returned when the response is polished
+	}
+
+	if _, ok := unknownEnumCode(t, value); !ok {
+		return fmt.Errorf("cannot preserve %s value %q: more than %d unknown values", t.Name(), value, maxUnknownEnumValues)
+	}
+
+	o.reportUnknownEnum(t.Name(), value)
+	return nil
+}
+
+func (o *Client) checkApiEnum(t reflect.Type, value string, e apiEnum) error {
+	err := e.FromString(value)
+	switch {
+	case err == nil || value == "":
+		return nil // known value, or one which was never parsed
+	case !o.cfg.LenientEnumDecoding:
+		return err
+	}
+
+	o.reportUnknownEnum(t.Name(), value)
+	return nil
+}
+
+// reportUnknownEnum reports each unknown value once per Client, either to
+// ClientCfg.UnknownEnumValueHook, or to the Client's logger.
+func (o *Client) reportUnknownEnum(typeName, value string) {
+	key := typeName + ":" + value
+
+	o.unknownEnumsSeenLock.Lock()
+	if _, ok := o.unknownEnumsSeen[key]; ok {
+		o.unknownEnumsSeenLock.Unlock()
+		return
+	}
+	if o.unknownEnumsSeen == nil {
+		o.unknownEnumsSeen = make(map[string]struct{})
+	}
+	o.unknownEnumsSeen[key] = struct{}{}
+	o.unknownEnumsSeenLock.Unlock()
+
+	if o.cfg.UnknownEnumValueHook != nil {
+		o.cfg.UnknownEnumValueHook(typeName, value)
+		return
+	}
+
+	o.logStrf(1, "preserving unknown %s value %q", typeName, value)
+}
@@ -0,0 +1,148 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/enum"
+	"github.com/stretchr/testify/require"
+)
+
+// testLenientClient returns a Client with lenient enum decoding enabled, and
+// the unknown values it reports.
+func testLenientClient() (*Client, *[][2]string) {
+	var reported [][2]string
+	return &Client{cfg: ClientCfg{
+		LenientEnumDecoding: true,
+		UnknownEnumValueHook: func(typeName, value string) {
+			reported = append(reported, [2]string{typeName, value})
+		},
+	}}, &reported
+}
+
+func TestLenientEnumDecodingCablingMap(t *testing.T) {
+	data := `{
+		"role": "leaf_hyperspine", "type": "ethernet", "speed": "10G", "id": "link1",
+		"endpoints": [{
+			"interface": {"if_type": "ethernet_v2", "operation_state": "up", "id": "if1", "lag_mode": null},
+			"system": {"role": "hyperspine", "id": "sys1", "label": "hyperspine1"}
+		}]
+	}`
+
+	var raw rawCablingMapLink
+	require.NoError(t, json.Unmarshal([]byte(data), &raw))
+
+	// a strict client leaves the unknown values alone, so polish fails
+	require.NoError(t, (&Client{}).checkResponseEnums(&raw))
+	_, err := raw.polish()
+	require.Error(t, err)
+
+	lenient, reported := testLenientClient()
+	require.NoError(t, lenient.checkResponseEnums(&raw))
+	require.NoError(t, lenient.checkResponseEnums(&raw)) // each value is reported once
+	require.ElementsMatch(t, [][2]string{
+		{"linkRole", "leaf_hyperspine"},
+		{"interfaceType", "ethernet_v2"},
+		{"systemNodeRole", "hyperspine"},
+	}, *reported)
+
+	link, err := raw.polish()
+	require.NoError(t, err)
+	require.Equal(t, "leaf_hyperspine", link.Role.String())
+	require.Equal(t, linkRole("leaf_hyperspine"), link.Role.raw())
+	require.Less(t, link.Role.Int(), 0)
+	require.Equal(t, LinkTypeEthernet, link.Type)
+	require.Equal(t, "ethernet_v2", link.Endpoints[0].Interface.IfType.String())
+	require.Equal(t, "hyperspine", link.Endpoints[0].System.Role.String())
+
+	// a strict client rejects values preserved on behalf of the lenient client
+	require.Error(t, (&Client{}).checkResponseEnums(&raw))
+}
+
+func TestLenientEnumFromString(t *testing.T) {
+	lenient, _ := testLenientClient()
+	require.NoError(t, lenient.checkResponseEnums(&struct{ Role linkRole }{Role: "spine_hyperspine"}))
+
+	// caller input is never lenient, even when the value has been preserved
+	var role LinkRole
+	require.Error(t, role.FromString("spine_hyperspine"))
+	require.NoError(t, role.FromString("spine_leaf"))
+	require.Equal(t, LinkRoleSpineLeaf, role)
+}
+
+func TestLenientEnumCodesPerType(t *testing.T) {
+	lenient, _ := testLenientClient()
+	require.NoError(t, lenient.checkResponseEnums(&struct {
+		VnType vnType
+		Role   linkRole
+	}{VnType: "vxlan_v2", Role: "vxlan_v2_link"}))
+
+	i, err := vnType("vxlan_v2").parse()
+	require.NoError(t, err)
+	require.Equal(t, vnType("vxlan_v2"), VnType(i).raw())
+
+	j, err := linkRole("vxlan_v2_link").parse()
+	require.NoError(t, err)
+	require.Equal(t, "vxlan_v2_link", LinkRole(j).String())
+
+	// the vnType code means nothing to other types
+	_, err = linkRole("vxlan_v2").parse()
+	require.Error(t, err)
+}
+
+func TestLenientApiEnumDecoding(t *testing.T) {
+	const resourceId = "r1"
+	stub := &stubHttpClient{responses: map[string]string{
+		"/api/blueprints/bp1/ra-resources/" + resourceId: `{
+			"id": "r1", "resource_type": "asn_v2", "label": "foo", "group_id": "g1"
+		}`,
+	}}
+
+	// the unknown value doesn't fail json.Unmarshal(), but a strict client rejects it
+	strict := &FreeformClient{client: testStubClient(t, stub, ClientCfg{}), blueprintId: "bp1"}
+	_, err := strict.GetRaResource(context.Background(), resourceId)
+	var eErr enum.Error
+	require.ErrorAs(t, err, &eErr)
+	require.Equal(t, enum.ErrorTypeParsingFailed, eErr.Type())
+
+	var reported [][2]string
+	lenient := &FreeformClient{client: testStubClient(t, stub, ClientCfg{
+		LenientEnumDecoding: true,
+		UnknownEnumValueHook: func(typeName, value string) {
+			reported = append(reported, [2]string{typeName, value})
+		},
+	}), blueprintId: "bp1"}
+	resource, err := lenient.GetRaResource(context.Background(), resourceId)
+	require.NoError(t, err)
+	require.Equal(t, [][2]string{{"FFResourceType", "asn_v2"}}, reported)
+	require.Equal(t, "asn_v2", resource.Data.ResourceType.String())
+
+	// the unknown value survives a round trip
+	payload, err := json.Marshal(resource.Data)
+	require.NoError(t, err)
+	require.Contains(t, string(payload), `"resource_type":"asn_v2"`)
+
+	// caller input is never lenient
+	var resourceType enum.FFResourceType
+	require.Error(t, resourceType.FromString("asn_v2"))
+}
+
+func TestLenientFeatureSwitchDecoding(t *testing.T) {
+	raw := rawFabricSettings{AntiAffinity: (&AntiAffinityPolicy{}).raw(), JunosGracefulRestart: toPtr("auto")}
+	settings, err := raw.polish()
+	require.NoError(t, err)
+	require.NotNil(t, settings.JunosGracefulRestart)
+	require.Equal(t, "auto", settings.JunosGracefulRestart.String())
+
+	require.Error(t, (&Client{}).checkResponseEnums(settings))
+
+	lenient, reported := testLenientClient()
+	require.NoError(t, lenient.checkResponseEnums(settings))
+	require.Equal(t, [][2]string{{"FeatureSwitch", "auto"}}, *reported)
+	require.Equal(t, toPtr("auto"), settings.raw().JunosGracefulRestart)
+}
@@ -0,0 +1,719 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fftopology
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+
+	"github.com/Juniper/apstra-go-sdk/apstra"
+)
+
+// Client is the subset of *apstra.FreeformClient used by Apply.
+type Client interface {
+	GetAllAllocGroups(context.Context) ([]apstra.FreeformAllocGroup, error)
+	CreateAllocGroup(context.Context, *apstra.FreeformAllocGroupData) (apstra.ObjectId, error)
+	UpdateAllocGroup(context.Context, apstra.ObjectId, *apstra.FreeformAllocGroupData) error
+
+	GetAllConfigTemplates(context.Context) ([]apstra.ConfigTemplate, error)
+	CreateConfigTemplate(context.Context, *apstra.ConfigTemplateData) (apstra.ObjectId, error)
+	UpdateConfigTemplate(context.Context, apstra.ObjectId, *apstra.ConfigTemplateData) error
+
+	GetAllSystems(context.Context) ([]apstra.FreeformSystem, error)
+	CreateSystem(context.Context, *apstra.FreeformSystemData) (apstra.ObjectId, error)
+	UpdateSystem(context.Context, apstra.ObjectId, *apstra.FreeformSystemData) error
+
+	GetAllPropertySets(context.Context) ([]apstra.FreeformPropertySet, error)
+	CreatePropertySet(context.Context, *apstra.FreeformPropertySetData) (apstra.ObjectId, error)
+	UpdatePropertySet(context.Context, apstra.ObjectId, *apstra.FreeformPropertySetData) error
+
+	GetAllLinks(context.Context) ([]apstra.FreeformLink, error)
+	CreateLink(context.Context, *apstra.FreeformLinkRequest) (apstra.ObjectId, error)
+	UpdateLink(context.Context, apstra.ObjectId, *apstra.FreeformLinkRequest) error
+
+	GetAllRaGroups(context.Context) ([]apstra.FreeformRaGroup, error)
+	CreateRaGroup(context.Context, *apstra.FreeformRaGroupData) (apstra.ObjectId, error)
+	UpdateRaGroup(context.Context, apstra.ObjectId, *apstra.FreeformRaGroupData) error
+
+	GetAllRaResources(context.Context) ([]apstra.FreeformRaResource, error)
+	CreateRaResource(context.Context, *apstra.FreeformRaResourceData) (apstra.ObjectId, error)
+	UpdateRaResource(context.Context, apstra.ObjectId, *apstra.FreeformRaResourceData) error
+
+	ListResourceAssignments(context.Context, apstra.ObjectId) ([]apstra.ObjectId, error)
+	UpdateResourceAssignments(context.Context, apstra.ObjectId, []apstra.ObjectId) error
+
+	ListConfigTemplateAssignments(context.Context) (map[apstra.ObjectId]*apstra.ObjectId, error)
+	UpdateConfigTemplateAssignments(context.Context, map[apstra.ObjectId]*apstra.ObjectId) error
+}
+
+var _ Client = new(apstra.FreeformClient)
+
+// Options control the behavior of Apply.
+type Options struct {
+	// DryRun computes the Report without creating or updating anything.
+	// Objects which would be created have no ID in the Report.
+	DryRun bool
+}
+
+// Apply validates the topology and then creates or updates the blueprint's
+// objects in dependency order: allocation groups, config templates,
+// systems, property sets, links, resource groups (parents first), resources
+// (those allocated from other resources last), resource assignments and
+// config template assignments.
+//
+// If an API call fails, the returned Report describes the changes made
+// before the failure.
+func Apply(ctx context.Context, client Client, topology *Topology, options Options) (*Report, error) {
+	if err := topology.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid topology - %w", err)
+	}
+
+	a := applier{
+		ctx:           ctx,
+		client:        client,
+		topology:      topology,
+		dryRun:        options.DryRun,
+		report:        &Report{DryRun: options.DryRun},
+		allocGroupIds: make(map[string]apstra.ObjectId),
+		templateIds:   make(map[string]apstra.ObjectId),
+		systemIds:     make(map[string]apstra.ObjectId),
+		linkIds:       make(map[string]apstra.ObjectId),
+		interfaceIds:  make(map[[2]string]apstra.ObjectId),
+		groupIds:      make(map[string]apstra.ObjectId),
+		resourceIds:   make(map[string]apstra.ObjectId),
+	}
+
+	for _, step := range []func() error{
+		a.allocationGroups,
+		a.configTemplates,
+		a.systems,
+		a.propertySets,
+		a.links,
+		a.resourceGroups,
+		a.resources,
+		a.resourceAssignments,
+		a.configTemplateAssignments,
+	} {
+		if err := step(); err != nil {
+			return a.report, err
+		}
+	}
+
+	return a.report, nil
+}
+
+type applier struct {
+	ctx      context.Context
+	client   Client
+	topology *Topology
+	dryRun   bool
+	report   *Report
+
+	// IDs of blueprint objects, keyed by the topology's names for them
+	allocGroupIds map[string]apstra.ObjectId
+	templateIds   map[string]apstra.ObjectId
+	systemIds     map[string]apstra.ObjectId
+	linkIds       map[string]apstra.ObjectId
+	interfaceIds  map[[2]string]apstra.ObjectId // system label, if_name
+	groupIds      map[string]apstra.ObjectId
+	resourceIds   map[string]apstra.ObjectId // keyed by resourceKey()
+}
+
+// placeholder stands in for the ID of an object which a dry run would have
+// created. It can't match any real object ID.
+func placeholder(kind Kind, name string) apstra.ObjectId {
+	return apstra.ObjectId(fmt.Sprintf("<new %s %s>", kind, name))
+}
+
+// apply records a change and performs it (unless this is a dry run). create
+// and update are called with the API client; update is only called when
+// diffs is non-empty. Returns the object's ID.
+func (o *applier) apply(kind Kind, name string, id apstra.ObjectId, exists bool, diffs []string,
+	create func() (apstra.ObjectId, error), update func() error,
+) (apstra.ObjectId, error) {
+	change := Change{Kind: kind, Name: name, Id: id, Fields: diffs}
+	switch {
+	case !exists:
+		change.Action = ActionCreate
+		change.Id = ""
+		if o.dryRun {
+			id = placeholder(kind, name)
+			break
+		}
+		var err error
+		if id, err = create(); err != nil {
+			return "", fmt.Errorf("failed creating %s %q - %w", kind, name, err)
+		}
+		change.Id = id
+	case len(diffs) > 0:
+		change.Action = ActionUpdate
+		if o.dryRun {
+			break
+		}
+		if err := update(); err != nil {
+			return "", fmt.Errorf("failed updating %s %q - %w", kind, name, err)
+		}
+	default:
+		change.Action = ActionNone
+		change.Fields = nil
+	}
+
+	o.report.Changes = append(o.report.Changes, change)
+	return id, nil
+}
+
+func (o *applier) allocationGroups() error {
+	existing, err := o.client.GetAllAllocGroups(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching allocation groups - %w", err)
+	}
+
+	for _, ag := range o.topology.AllocationGroups {
+		var desired apstra.FreeformAllocGroupData
+		desired.Name = ag.Name
+		desired.PoolIds = ag.PoolIds
+		_ = desired.Type.FromString(ag.Type) // validated
+
+		var current *apstra.FreeformAllocGroup
+		for i, e := range existing {
+			if e.Data != nil && e.Data.Name == ag.Name && e.Data.Type == desired.Type {
+				current = &existing[i]
+				break
+			}
+		}
+
+		var id apstra.ObjectId
+		var diffs []string
+		if current != nil {
+			id = current.Id
+			diffs = diff(diffs, "pool_ids", sortedIds(current.Data.PoolIds), sortedIds(desired.PoolIds))
+		}
+
+		o.allocGroupIds[ag.Name], err = o.apply(KindAllocationGroup, ag.Name, id, current != nil, diffs,
+			func() (apstra.ObjectId, error) { return o.client.CreateAllocGroup(o.ctx, &desired) },
+			func() error { return o.client.UpdateAllocGroup(o.ctx, id, &desired) },
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *applier) configTemplates() error {
+	existing, err := o.client.GetAllConfigTemplates(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching config templates - %w", err)
+	}
+
+	for _, ct := range o.topology.ConfigTemplates {
+		desired := apstra.ConfigTemplateData{
+			Label:      ct.Label,
+			Text:       ct.Text,
+			Tags:       ct.Tags,
+			TemplateId: apstra.ObjectId(ct.TemplateId),
+		}
+		if desired.TemplateId == "" {
+			desired.TemplateId = apstra.ObjectId(ct.Label + ".jinja")
+		}
+
+		var current *apstra.ConfigTemplate
+		for i, e := range existing {
+			if e.Data != nil && e.Data.Label == ct.Label {
+				current = &existing[i]
+				break
+			}
+		}
+
+		var id apstra.ObjectId
+		var diffs []string
+		if current != nil {
+			id = current.Id
+			diffs = diff(diffs, "text", current.Data.Text, desired.Text)
+			diffs = diff(diffs, "tags", sortedStrings(current.Data.Tags), sortedStrings(desired.Tags))
+			diffs = diff(diffs, "template_id", current.Data.TemplateId, desired.TemplateId)
+		}
+
+		o.templateIds[ct.Label], err = o.apply(KindConfigTemplate, ct.Label, id, current != nil, diffs,
+			func() (apstra.ObjectId, error) { return o.client.CreateConfigTemplate(o.ctx, &desired) },
+			func() error { return o.client.UpdateConfigTemplate(o.ctx, id, &desired) },
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *applier) systems() error {
+	existing, err := o.client.GetAllSystems(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching systems - %w", err)
+	}
+
+	for _, s := range o.topology.Systems {
+		desired := apstra.FreeformSystemData{
+			SystemId:        s.SystemId,
+			Label:           s.Label,
+			Hostname:        s.Hostname,
+			Tags:            s.Tags,
+			DeviceProfileId: s.DeviceProfileId,
+		}
+		desired.Type, _ = systemType(s.Type) // validated
+
+		var current *apstra.FreeformSystem
+		for i, e := range existing {
+			if e.Data != nil && e.Data.Label == s.Label {
+				current = &existing[i]
+				break
+			}
+		}
+
+		var id apstra.ObjectId
+		var diffs []string
+		if current != nil {
+			id = current.Id
+			diffs = diff(diffs, "hostname", current.Data.Hostname, desired.Hostname)
+			diffs = diff(diffs, "type", current.Data.Type, desired.Type)
+			diffs = diff(diffs, "device_profile_id", current.Data.DeviceProfileId, desired.DeviceProfileId)
+			diffs = diff(diffs, "system_id", current.Data.SystemId, desired.SystemId)
+			diffs = diff(diffs, "tags", sortedStrings(current.Data.Tags), sortedStrings(desired.Tags))
+		}
+
+		o.systemIds[s.Label], err = o.apply(KindSystem, s.Label, id, current != nil, diffs,
+			func() (apstra.ObjectId, error) { return o.client.CreateSystem(o.ctx, &desired) },
+			func() error { return o.client.UpdateSystem(o.ctx, id, &desired) },
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *applier) propertySets() error {
+	existing, err := o.client.GetAllPropertySets(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching property sets - %w", err)
+	}
+
+	for _, ps := range o.topology.PropertySets {
+		values, err := json.Marshal(ps.Values)
+		if err != nil {
+			return fmt.Errorf("failed marshaling property set %q values - %w", ps.Label, err)
+		}
+		desired := apstra.FreeformPropertySetData{Label: ps.Label, Values: values}
+		name := ps.Label
+		if ps.System != "" {
+			systemId := o.systemIds[ps.System]
+			desired.SystemId = &systemId
+			name = ps.System + "/" + ps.Label
+		}
+
+		var current *apstra.FreeformPropertySet
+		for i, e := range existing {
+			if e.Data != nil && e.Data.Label == ps.Label && reflect.DeepEqual(e.Data.SystemId, desired.SystemId) {
+				current = &existing[i]
+				break
+			}
+		}
+
+		var id apstra.ObjectId
+		var diffs []string
+		if current != nil {
+			id = current.Id
+			diffs = diff(diffs, "values", jsonValue(current.Data.Values), jsonValue(desired.Values))
+		}
+
+		_, err = o.apply(KindPropertySet, name, id, current != nil, diffs,
+			func() (apstra.ObjectId, error) { return o.client.CreatePropertySet(o.ctx, &desired) },
+			func() error { return o.client.UpdatePropertySet(o.ctx, id, &desired) },
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func endpointData(ep Endpoint) (*apstra.FreeformInterfaceData, error) {
+	result := apstra.FreeformInterfaceData{
+		TransformationId: ep.TransformationId,
+		Tags:             ep.Tags,
+	}
+	if ep.IfName != "" {
+		ifName := ep.IfName
+		result.IfName = &ifName
+	}
+
+	for _, a := range []struct {
+		s    string
+		dst  **net.IPNet
+		ipv4 bool
+	}{
+		{s: ep.Ipv4Address, dst: &result.Ipv4Address, ipv4: true},
+		{s: ep.Ipv6Address, dst: &result.Ipv6Address},
+	} {
+		if a.s == "" {
+			continue
+		}
+		ip, ipNet, err := net.ParseCIDR(a.s)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing %s:%s address %q - %w", ep.System, ep.IfName, a.s, err)
+		}
+		if (ip.To4() != nil) != a.ipv4 {
+			return nil, fmt.Errorf("%s:%s address %q is in the wrong address family", ep.System, ep.IfName, a.s)
+		}
+		ipNet.IP = ip
+		*a.dst = ipNet
+	}
+
+	return &result, nil
+}
+
+func (o *applier) links() error {
+	existing, err := o.client.GetAllLinks(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching links - %w", err)
+	}
+
+	var changed bool
+	for _, l := range o.topology.Links {
+		desired := apstra.FreeformLinkRequest{Label: l.Label, Tags: l.Tags}
+		for i, ep := range l.Endpoints {
+			desired.Endpoints[i].SystemId = o.systemIds[ep.System]
+			desired.Endpoints[i].Interface.Data, _ = endpointData(ep) // validated
+		}
+
+		var current *apstra.FreeformLink
+		for i, e := range existing {
+			if e.Data != nil && e.Data.Label == l.Label {
+				current = &existing[i]
+				break
+			}
+		}
+
+		var id apstra.ObjectId
+		var diffs []string
+		if current != nil {
+			id = current.Id
+			diffs = diff(diffs, "tags", sortedStrings(current.Data.Tags), sortedStrings(desired.Tags))
+			for i := range desired.Endpoints {
+				// the API may return the endpoints in either order
+				want := &desired.Endpoints[i]
+				have := current.Data.Endpoints[i]
+				if have.SystemId != want.SystemId {
+					have = current.Data.Endpoints[1-i]
+				}
+				want.Interface.Id = have.Interface.Id
+				diffs = append(diffs, interfaceDiffs(fmt.Sprintf("endpoints[%d].", i), have, *want)...)
+			}
+		}
+
+		o.linkIds[l.Label], err = o.apply(KindLink, l.Label, id, current != nil, diffs,
+			func() (apstra.ObjectId, error) { return o.client.CreateLink(o.ctx, &desired) },
+			func() error { return o.client.UpdateLink(o.ctx, id, &desired) },
+		)
+		if err != nil {
+			return err
+		}
+		changed = changed || current == nil || len(diffs) > 0
+	}
+
+	// interface IDs are needed for resource assignments
+	if changed && !o.dryRun {
+		if existing, err = o.client.GetAllLinks(o.ctx); err != nil {
+			return fmt.Errorf("failed fetching links - %w", err)
+		}
+	}
+	systemLabels := make(map[apstra.ObjectId]string, len(o.systemIds))
+	for label, id := range o.systemIds {
+		systemLabels[id] = label
+	}
+	for _, link := range existing {
+		if link.Data == nil {
+			continue
+		}
+		for _, ep := range link.Data.Endpoints {
+			label, ok := systemLabels[ep.SystemId]
+			if !ok || ep.Interface.Id == nil || ep.Interface.Data == nil || ep.Interface.Data.IfName == nil {
+				continue
+			}
+			o.interfaceIds[[2]string{label, *ep.Interface.Data.IfName}] = *ep.Interface.Id
+		}
+	}
+
+	return nil
+}
+
+func interfaceDiffs(prefix string, have, want apstra.FreeformEndpoint) []string {
+	var result []string
+	result = diff(result, prefix+"system", have.SystemId, want.SystemId)
+
+	h, w := have.Interface.Data, want.Interface.Data
+	if h == nil {
+		h = new(apstra.FreeformInterfaceData)
+	}
+	ipString := func(n *net.IPNet) string {
+		if n == nil {
+			return ""
+		}
+		return n.String()
+	}
+	result = diff(result, prefix+"if_name", h.IfName, w.IfName)
+	if w.TransformationId != nil {
+		result = diff(result, prefix+"transformation_id", h.TransformationId, w.TransformationId)
+	}
+	result = diff(result, prefix+"ipv4_address", ipString(h.Ipv4Address), ipString(w.Ipv4Address))
+	result = diff(result, prefix+"ipv6_address", ipString(h.Ipv6Address), ipString(w.Ipv6Address))
+	result = diff(result, prefix+"tags", sortedStrings(h.Tags), sortedStrings(w.Tags))
+	return result
+}
+
+func (o *applier) resourceGroups() error {
+	existing, err := o.client.GetAllRaGroups(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching resource groups - %w", err)
+	}
+
+	groups := make(map[string]ResourceGroup, len(o.topology.ResourceGroups))
+	deps := make(map[string][]string)
+	for _, g := range o.topology.ResourceGroups {
+		groups[g.Label] = g
+		if g.Parent != "" {
+			deps[g.Label] = []string{g.Parent}
+		}
+	}
+	order, _ := dependencyOrder(labels(o.topology.ResourceGroups, func(g ResourceGroup) string { return g.Label }), deps) // validated
+
+	for _, label := range order {
+		g := groups[label]
+		desired := apstra.FreeformRaGroupData{Label: g.Label, Tags: g.Tags}
+		if g.Parent != "" {
+			parentId := o.groupIds[g.Parent]
+			desired.ParentId = &parentId
+		}
+		if g.Data != nil {
+			if desired.Data, err = json.Marshal(g.Data); err != nil {
+				return fmt.Errorf("failed marshaling resource group %q data - %w", g.Label, err)
+			}
+		}
+
+		var current *apstra.FreeformRaGroup
+		for i, e := range existing {
+			if e.Data != nil && e.Data.Label == g.Label && reflect.DeepEqual(e.Data.ParentId, desired.ParentId) {
+				current = &existing[i]
+				break
+			}
+		}
+
+		var id apstra.ObjectId
+		var diffs []string
+		if current != nil {
+			id = current.Id
+			diffs = diff(diffs, "tags", sortedStrings(current.Data.Tags), sortedStrings(desired.Tags))
+			if g.Data != nil {
+				diffs = diff(diffs, "data", jsonValue(current.Data.Data), jsonValue(desired.Data))
+			}
+		}
+
+		o.groupIds[g.Label], err = o.apply(KindResourceGroup, g.Label, id, current != nil, diffs,
+			func() (apstra.ObjectId, error) { return o.client.CreateRaGroup(o.ctx, &desired) },
+			func() error { return o.client.UpdateRaGroup(o.ctx, id, &desired) },
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *applier) resources() error {
+	existing, err := o.client.GetAllRaResources(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching resources - %w", err)
+	}
+
+	resources := make(map[string]Resource, len(o.topology.Resources))
+	deps := make(map[string][]string)
+	for _, r := range o.topology.Resources {
+		key := resourceKey(r.Group, r.Label)
+		resources[key] = r
+		if _, ok := o.allocGroupIds[r.AllocatedFrom]; !ok && r.AllocatedFrom != "" {
+			parent := o.topology.resourceByLabel(r.AllocatedFrom)
+			deps[key] = []string{resourceKey(parent.Group, parent.Label)}
+		}
+	}
+	order, _ := dependencyOrder(labels(o.topology.Resources, func(r Resource) string { return resourceKey(r.Group, r.Label) }), deps) // validated
+
+	for _, key := range order {
+		r := resources[key]
+		desired := apstra.FreeformRaResourceData{
+			Label:           r.Label,
+			Value:           r.Value,
+			GroupId:         o.groupIds[r.Group],
+			SubnetPrefixLen: r.SubnetPrefixLen,
+		}
+		_ = desired.ResourceType.FromString(r.Type) // validated
+		if r.AllocatedFrom != "" {
+			from, ok := o.allocGroupIds[r.AllocatedFrom]
+			if !ok {
+				parent := o.topology.resourceByLabel(r.AllocatedFrom)
+				from = o.resourceIds[resourceKey(parent.Group, parent.Label)]
+			}
+			desired.AllocatedFrom = &from
+		}
+
+		var current *apstra.FreeformRaResource
+		for i, e := range existing {
+			if e.Data != nil && e.Data.Label == r.Label && e.Data.GroupId == desired.GroupId {
+				current = &existing[i]
+				break
+			}
+		}
+
+		var id apstra.ObjectId
+		var diffs []string
+		if current != nil {
+			id = current.Id
+			diffs = diff(diffs, "type", current.Data.ResourceType.String(), desired.ResourceType.String())
+			if desired.Value != nil {
+				// resources without a value are allocated by Apstra
+				diffs = diff(diffs, "value", current.Data.Value, desired.Value)
+			}
+			diffs = diff(diffs, "allocated_from", current.Data.AllocatedFrom, desired.AllocatedFrom)
+			diffs = diff(diffs, "subnet_prefix_len", current.Data.SubnetPrefixLen, desired.SubnetPrefixLen)
+		}
+
+		o.resourceIds[key], err = o.apply(KindResource, key, id, current != nil, diffs,
+			func() (apstra.ObjectId, error) { return o.client.CreateRaResource(o.ctx, &desired) },
+			func() error { return o.client.UpdateRaResource(o.ctx, id, &desired) },
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *applier) resourceAssignments() error {
+	for _, r := range o.topology.Resources {
+		if r.AssignedTo == nil {
+			continue // assignments not managed by the topology
+		}
+
+		key := resourceKey(r.Group, r.Label)
+		resourceId := o.resourceIds[key]
+
+		desired := make([]apstra.ObjectId, 0, len(r.AssignedTo))
+		for _, target := range r.AssignedTo {
+			var id apstra.ObjectId
+			switch {
+			case target.Link != "":
+				id = o.linkIds[target.Link]
+			case target.IfName != "":
+				var ok bool
+				if id, ok = o.interfaceIds[[2]string{target.System, target.IfName}]; !ok {
+					id = placeholder(KindInterface, target.System+":"+target.IfName)
+				}
+			default:
+				id = o.systemIds[target.System]
+			}
+			desired = append(desired, id)
+		}
+
+		var current []apstra.ObjectId
+		if resourceId != placeholder(KindResource, key) {
+			var err error
+			if current, err = o.client.ListResourceAssignments(o.ctx, resourceId); err != nil {
+				return fmt.Errorf("failed fetching resource %q assignments - %w", key, err)
+			}
+		}
+
+		diffs := diff(nil, "assigned_to", sortedIds(current), sortedIds(desired))
+		_, err := o.apply(KindResourceAssignment, key, resourceId, true, diffs,
+			nil,
+			func() error { return o.client.UpdateResourceAssignments(o.ctx, resourceId, desired) },
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *applier) configTemplateAssignments() error {
+	current, err := o.client.ListConfigTemplateAssignments(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching config template assignments - %w", err)
+	}
+
+	changes := make(map[apstra.ObjectId]*apstra.ObjectId)
+	for _, s := range o.topology.Systems {
+		if s.ConfigTemplate == "" {
+			continue
+		}
+
+		systemId := o.systemIds[s.Label]
+		templateId := o.templateIds[s.ConfigTemplate]
+		diffs := diff(nil, "config_template", current[systemId], &templateId)
+		_, err = o.apply(KindConfigTemplateAssignment, s.Label, systemId, true, diffs,
+			nil,
+			func() error {
+				changes[systemId] = &templateId
+				return nil
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(changes) > 0 {
+		if err = o.client.UpdateConfigTemplateAssignments(o.ctx, changes); err != nil {
+			return fmt.Errorf("failed updating config template assignments - %w", err)
+		}
+	}
+
+	return nil
+}
+
+// diff appends field to diffs if have and want differ
+func diff(diffs []string, field string, have, want any) []string {
+	if !reflect.DeepEqual(have, want) {
+		return append(diffs, field)
+	}
+	return diffs
+}
+
+// jsonValue unmarshals raw for comparison purposes
+func jsonValue(raw json.RawMessage) any {
+	var result any
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &result)
+	}
+	if result == nil {
+		result = map[string]any{}
+	}
+	return result
+}
+
+func sortedIds(in []apstra.ObjectId) []string {
+	result := make([]string, len(in))
+	for i := range in {
+		result[i] = in[i].String()
+	}
+	return sortedStrings(result)
+}
@@ -0,0 +1,431 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fftopology
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Juniper/apstra-go-sdk/apstra"
+	"github.com/stretchr/testify/require"
+)
+
+const testTopology = `
+allocation_groups:
+  - name: asns
+    type: asn
+    pool_ids: [pool1]
+config_templates:
+  - label: leaf
+    text: "hostname {{ hostname }}"
+systems:
+  - label: leaf1
+    hostname: leaf1
+    device_profile_id: dp1
+    config_template: leaf
+  - label: spine1
+    hostname: spine1
+    device_profile_id: dp1
+    tags: [spine]
+property_sets:
+  - label: global
+    values:
+      mtu: 9216
+  - label: local
+    system: leaf1
+    values:
+      role: leaf
+links:
+  - label: leaf1_spine1
+    endpoints:
+      - system: leaf1
+        if_name: xe-0/0/0
+        ipv4_address: 10.0.0.1/31
+      - system: spine1
+        if_name: xe-0/0/1
+        ipv4_address: 10.0.0.0/31
+resource_groups:
+  - label: child
+    parent: root
+  - label: root
+resources:
+  - label: host
+    group: child
+    type: host_ip
+    allocated_from: subnet
+    assigned_to:
+      - system: leaf1
+        if_name: xe-0/0/0
+  - label: subnet
+    group: root
+    type: ip
+    value: 192.168.0.0/24
+  - label: leaf1_asn
+    group: root
+    type: asn
+    allocated_from: asns
+    assigned_to:
+      - system: leaf1
+`
+
+func TestValidate(t *testing.T) {
+	type testCase struct {
+		topology string
+		expected []string
+	}
+
+	testCases := map[string]testCase{
+		"ok": {topology: testTopology},
+		"dangling_references": {
+			topology: `
+systems:
+  - label: a
+    config_template: nope
+links:
+  - label: l
+    endpoints: [{system: a}, {system: b}]
+resources:
+  - {label: r, group: g, type: asn, allocated_from: x, assigned_to: [{link: m}]}
+`,
+			expected: []string{
+				`system "a" refers to unknown config template "nope"`,
+				`link "l" refers to unknown system "b"`,
+				`resource "r" refers to unknown resource group "g"`,
+				`resource "r" is assigned to unknown link "m"`,
+				`resource "r" is allocated from unknown allocation group or resource "x"`,
+			},
+		},
+		"duplicates_and_bad_values": {
+			topology: `
+allocation_groups:
+  - {name: a, type: bogus}
+systems:
+  - {label: a, type: router}
+  - {label: a}
+links:
+  - label: l
+    endpoints: [{system: a, ipv4_address: "2001:db8::1/64"}, {system: a, ipv6_address: junk}]
+`,
+			expected: []string{
+				`allocation group "a" has invalid type "bogus"`,
+				`duplicate system "a"`,
+				`invalid system type "router"`,
+				`address "2001:db8::1/64" is in the wrong address family`,
+				`failed parsing a: address "junk"`,
+			},
+		},
+		"cycles": {
+			topology: `
+resource_groups:
+  - {label: a, parent: b}
+  - {label: b, parent: a}
+`,
+			expected: []string{"dependency cycle: a -> b -> a"},
+		},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			topology, err := Parse(strings.NewReader(tCase.topology))
+			require.NoError(t, err)
+			err = topology.Validate()
+			if len(tCase.expected) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, e := range tCase.expected {
+				require.Contains(t, err.Error(), e)
+			}
+		})
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	_, err := Parse(strings.NewReader(`{"systems": [{"label": "a", "hostnmae": "typo"}]}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hostnmae")
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	topology, err := Parse(strings.NewReader(testTopology))
+	require.NoError(t, err)
+
+	client := newFakeClient()
+
+	// dry run against an empty blueprint changes nothing
+	report, err := Apply(ctx, client, topology, Options{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, report.Changed(), len(report.Changes))
+	require.Zero(t, client.calls)
+
+	// create everything
+	report, err = Apply(ctx, client, topology, Options{})
+	require.NoError(t, err)
+	var summary []string
+	for _, change := range report.Changes {
+		require.Equal(t, ActionCreate == change.Action, change.Kind != KindResourceAssignment && change.Kind != KindConfigTemplateAssignment, change.String())
+		summary = append(summary, fmt.Sprintf("%s %s %s", change.Action, change.Kind, change.Name))
+	}
+	require.Equal(t, []string{
+		"create allocation group asns",
+		"create config template leaf",
+		"create system leaf1",
+		"create system spine1",
+		"create property set global",
+		"create property set leaf1/local",
+		"create link leaf1_spine1",
+		"create resource group root",
+		"create resource group child",
+		"create resource root/subnet",
+		"create resource child/host",
+		"create resource root/leaf1_asn",
+		"update resource assignment child/host",
+		"update resource assignment root/leaf1_asn",
+		"update config template assignment leaf1",
+	}, summary)
+
+	// dependencies were resolved to IDs
+	leaf1 := client.systems[0]
+	host := client.resources[1]
+	require.Equal(t, "host", host.Data.Label)
+	require.Equal(t, client.resources[0].Id, *host.Data.AllocatedFrom)
+	require.Equal(t, client.groups[0].Id, *client.groups[1].Data.ParentId) // root was created before child
+	require.Equal(t, []apstra.ObjectId{*client.links[0].Data.Endpoints[0].Interface.Id}, client.assignments[host.Id])
+	require.Equal(t, []apstra.ObjectId{leaf1.Id}, client.assignments[client.resources[2].Id])
+	require.Equal(t, client.templates[0].Id, *client.templateAssignments[leaf1.Id])
+	require.Equal(t, leaf1.Id, *client.propertySets[1].Data.SystemId)
+
+	// applying again is a no-op
+	calls := client.calls
+	report, err = Apply(ctx, client, topology, Options{})
+	require.NoError(t, err)
+	require.Empty(t, report.Changed(), report.String())
+	require.Equal(t, calls, client.calls)
+
+	// a modified topology results in updates of only what changed
+	topology.Systems[1].Tags = []string{"spine", "pod1"}
+	topology.Links[0].Endpoints[1].Ipv4Address = "10.0.0.2/31"
+	topology.PropertySets[0].Values["mtu"] = 1500
+	report, err = Apply(ctx, client, topology, Options{})
+	require.NoError(t, err)
+	require.Equal(t, "3 changes, 12 objects unchanged\n"+
+		fmt.Sprintf("update system \"spine1\" (%s): tags\n", client.systems[1].Id)+
+		fmt.Sprintf("update property set \"global\" (%s): values\n", client.propertySets[0].Id)+
+		fmt.Sprintf("update link \"leaf1_spine1\" (%s): endpoints[1].ipv4_address\n", client.links[0].Id),
+		report.String())
+	require.Equal(t, "10.0.0.2/31", client.links[0].Data.Endpoints[1].Interface.Data.Ipv4Address.String())
+}
+
+// fakeClient is an in-memory freeform blueprint
+type fakeClient struct {
+	nextId              int
+	calls               int
+	allocGroups         []apstra.FreeformAllocGroup
+	templates           []apstra.ConfigTemplate
+	systems             []apstra.FreeformSystem
+	propertySets        []apstra.FreeformPropertySet
+	links               []apstra.FreeformLink
+	groups              []apstra.FreeformRaGroup
+	resources           []apstra.FreeformRaResource
+	assignments         map[apstra.ObjectId][]apstra.ObjectId
+	templateAssignments map[apstra.ObjectId]*apstra.ObjectId
+}
+
+var _ Client = new(fakeClient)
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		assignments:         make(map[apstra.ObjectId][]apstra.ObjectId),
+		templateAssignments: make(map[apstra.ObjectId]*apstra.ObjectId),
+	}
+}
+
+func (o *fakeClient) id(prefix string) apstra.ObjectId {
+	o.nextId++
+	o.calls++
+	return apstra.ObjectId(fmt.Sprintf("%s_%d", prefix, o.nextId))
+}
+
+func find[T any](items []T, id apstra.ObjectId, getId func(T) apstra.ObjectId) (int, error) {
+	for i := range items {
+		if getId(items[i]) == id {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%s not found", id)
+}
+
+func (o *fakeClient) GetAllAllocGroups(_ context.Context) ([]apstra.FreeformAllocGroup, error) {
+	return o.allocGroups, nil
+}
+
+func (o *fakeClient) CreateAllocGroup(_ context.Context, in *apstra.FreeformAllocGroupData) (apstra.ObjectId, error) {
+	id := o.id("rag_" + in.Type.String())
+	o.allocGroups = append(o.allocGroups, apstra.FreeformAllocGroup{Id: id, Data: in})
+	return id, nil
+}
+
+func (o *fakeClient) UpdateAllocGroup(_ context.Context, id apstra.ObjectId, in *apstra.FreeformAllocGroupData) error {
+	o.calls++
+	i, err := find(o.allocGroups, id, func(v apstra.FreeformAllocGroup) apstra.ObjectId { return v.Id })
+	if err == nil {
+		o.allocGroups[i].Data = in
+	}
+	return err
+}
+
+func (o *fakeClient) GetAllConfigTemplates(_ context.Context) ([]apstra.ConfigTemplate, error) {
+	return o.templates, nil
+}
+
+func (o *fakeClient) CreateConfigTemplate(_ context.Context, in *apstra.ConfigTemplateData) (apstra.ObjectId, error) {
+	id := o.id("ct")
+	o.templates = append(o.templates, apstra.ConfigTemplate{Id: id, Data: in})
+	return id, nil
+}
+
+func (o *fakeClient) UpdateConfigTemplate(_ context.Context, id apstra.ObjectId, in *apstra.ConfigTemplateData) error {
+	o.calls++
+	i, err := find(o.templates, id, func(v apstra.ConfigTemplate) apstra.ObjectId { return v.Id })
+	if err == nil {
+		o.templates[i].Data = in
+	}
+	return err
+}
+
+func (o *fakeClient) GetAllSystems(_ context.Context) ([]apstra.FreeformSystem, error) {
+	return o.systems, nil
+}
+
+func (o *fakeClient) CreateSystem(_ context.Context, in *apstra.FreeformSystemData) (apstra.ObjectId, error) {
+	id := o.id("system")
+	o.systems = append(o.systems, apstra.FreeformSystem{Id: id, Data: in})
+	return id, nil
+}
+
+func (o *fakeClient) UpdateSystem(_ context.Context, id apstra.ObjectId, in *apstra.FreeformSystemData) error {
+	o.calls++
+	i, err := find(o.systems, id, func(v apstra.FreeformSystem) apstra.ObjectId { return v.Id })
+	if err == nil {
+		o.systems[i].Data = in
+	}
+	return err
+}
+
+func (o *fakeClient) GetAllPropertySets(_ context.Context) ([]apstra.FreeformPropertySet, error) {
+	return o.propertySets, nil
+}
+
+func (o *fakeClient) CreatePropertySet(_ context.Context, in *apstra.FreeformPropertySetData) (apstra.ObjectId, error) {
+	id := o.id("ps")
+	o.propertySets = append(o.propertySets, apstra.FreeformPropertySet{Id: id, Data: in})
+	return id, nil
+}
+
+func (o *fakeClient) UpdatePropertySet(_ context.Context, id apstra.ObjectId, in *apstra.FreeformPropertySetData) error {
+	o.calls++
+	i, err := find(o.propertySets, id, func(v apstra.FreeformPropertySet) apstra.ObjectId { return v.Id })
+	if err == nil {
+		o.propertySets[i].Data = in
+	}
+	return err
+}
+
+func (o *fakeClient) GetAllLinks(_ context.Context) ([]apstra.FreeformLink, error) {
+	return o.links, nil
+}
+
+func (o *fakeClient) linkData(in *apstra.FreeformLinkRequest) *apstra.FreeformLinkData {
+	result := apstra.FreeformLinkData{Label: in.Label, Tags: in.Tags, Endpoints: in.Endpoints}
+	for i := range result.Endpoints {
+		if result.Endpoints[i].Interface.Id == nil {
+			id := o.id("intf")
+			o.calls--
+			result.Endpoints[i].Interface.Id = &id
+		}
+	}
+	return &result
+}
+
+func (o *fakeClient) CreateLink(_ context.Context, in *apstra.FreeformLinkRequest) (apstra.ObjectId, error) {
+	id := o.id("link")
+	o.links = append(o.links, apstra.FreeformLink{Id: id, Data: o.linkData(in)})
+	return id, nil
+}
+
+func (o *fakeClient) UpdateLink(_ context.Context, id apstra.ObjectId, in *apstra.FreeformLinkRequest) error {
+	o.calls++
+	i, err := find(o.links, id, func(v apstra.FreeformLink) apstra.ObjectId { return v.Id })
+	if err == nil {
+		o.links[i].Data = o.linkData(in)
+	}
+	return err
+}
+
+func (o *fakeClient) GetAllRaGroups(_ context.Context) ([]apstra.FreeformRaGroup, error) {
+	return o.groups, nil
+}
+
+func (o *fakeClient) CreateRaGroup(_ context.Context, in *apstra.FreeformRaGroupData) (apstra.ObjectId, error) {
+	id := o.id("group")
+	o.groups = append(o.groups, apstra.FreeformRaGroup{Id: id, Data: in})
+	return id, nil
+}
+
+func (o *fakeClient) UpdateRaGroup(_ context.Context, id apstra.ObjectId, in *apstra.FreeformRaGroupData) error {
+	o.calls++
+	i, err := find(o.groups, id, func(v apstra.FreeformRaGroup) apstra.ObjectId { return v.Id })
+	if err == nil {
+		o.groups[i].Data = in
+	}
+	return err
+}
+
+func (o *fakeClient) GetAllRaResources(_ context.Context) ([]apstra.FreeformRaResource, error) {
+	return o.resources, nil
+}
+
+func (o *fakeClient) CreateRaResource(_ context.Context, in *apstra.FreeformRaResourceData) (apstra.ObjectId, error) {
+	id := o.id("resource")
+	o.resources = append(o.resources, apstra.FreeformRaResource{Id: id, Data: in})
+	return id, nil
+}
+
+func (o *fakeClient) UpdateRaResource(_ context.Context, id apstra.ObjectId, in *apstra.FreeformRaResourceData) error {
+	o.calls++
+	i, err := find(o.resources, id, func(v apstra.FreeformRaResource) apstra.ObjectId { return v.Id })
+	if err == nil {
+		o.resources[i].Data = in
+	}
+	return err
+}
+
+func (o *fakeClient) ListResourceAssignments(_ context.Context, id apstra.ObjectId) ([]apstra.ObjectId, error) {
+	return o.assignments[id], nil
+}
+
+func (o *fakeClient) UpdateResourceAssignments(_ context.Context, id apstra.ObjectId, targets []apstra.ObjectId) error {
+	o.calls++
+	o.assignments[id] = targets
+	return nil
+}
+
+func (o *fakeClient) ListConfigTemplateAssignments(_ context.Context) (map[apstra.ObjectId]*apstra.ObjectId, error) {
+	return o.templateAssignments, nil
+}
+
+func (o *fakeClient) UpdateConfigTemplateAssignments(_ context.Context, assignments map[apstra.ObjectId]*apstra.ObjectId) error {
+	o.calls++
+	for k, v := range assignments {
+		o.templateAssignments[k] = v
+	}
+	return nil
+}
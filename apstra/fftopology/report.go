@@ -0,0 +1,91 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package fftopology
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Juniper/apstra-go-sdk/apstra"
+)
+
+// Kind identifies the type of blueprint object in a Change.
+type Kind string
+
+const (
+	KindAllocationGroup          = Kind("allocation group")
+	KindConfigTemplate           = Kind("config template")
+	KindSystem                   = Kind("system")
+	KindPropertySet              = Kind("property set")
+	KindLink                     = Kind("link")
+	KindInterface                = Kind("interface")
+	KindResourceGroup            = Kind("resource group")
+	KindResource                 = Kind("resource")
+	KindResourceAssignment       = Kind("resource assignment")
+	KindConfigTemplateAssignment = Kind("config template assignment")
+)
+
+// Action is what Apply did (or, in a dry run, would do) with an object.
+type Action string
+
+const (
+	ActionNone   = Action("unchanged")
+	ActionCreate = Action("create")
+	ActionUpdate = Action("update")
+)
+
+// Change describes the outcome of Apply for a single object.
+type Change struct {
+	Kind   Kind
+	Name   string          // the object's label (or name) in the topology
+	Id     apstra.ObjectId // empty for objects created by a dry run
+	Action Action
+	Fields []string // names of the fields which differed, for updates
+}
+
+func (o Change) String() string {
+	result := fmt.Sprintf("%s %s %q", o.Action, o.Kind, o.Name)
+	if o.Id != "" {
+		result += fmt.Sprintf(" (%s)", o.Id)
+	}
+	if len(o.Fields) > 0 {
+		result += ": " + strings.Join(o.Fields, ", ")
+	}
+	return result
+}
+
+// Report lists the outcome of Apply for every object in the topology, in
+// the order the objects were processed.
+type Report struct {
+	DryRun  bool
+	Changes []Change
+}
+
+// Changed returns the changes which created or updated something.
+func (o *Report) Changed() []Change {
+	var result []Change
+	for _, change := range o.Changes {
+		if change.Action != ActionNone {
+			result = append(result, change)
+		}
+	}
+	return result
+}
+
+// String summarizes the changes, one per line. Unchanged objects are
+// omitted.
+func (o *Report) String() string {
+	var sb strings.Builder
+	changed := o.Changed()
+	if o.DryRun {
+		sb.WriteString("dry run: ")
+	}
+	sb.WriteString(fmt.Sprintf("%d changes, %d objects unchanged\n", len(changed), len(o.Changes)-len(changed)))
+	for _, change := range changed {
+		sb.WriteString(change.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
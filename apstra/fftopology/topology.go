@@ -0,0 +1,412 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fftopology builds freeform blueprints from a declarative topology
+// document. The document (YAML or JSON) describes systems, links, property
+// sets, config templates, resource groups, resources and allocation groups.
+// Apply creates the objects which don't exist, updates those which differ,
+// and returns a Report describing what changed.
+//
+// Objects in the document refer to one another by label (or name, in the
+// case of allocation groups). Existing blueprint objects are matched the
+// same way, so Apply is idempotent. Objects in the blueprint which aren't
+// mentioned by the document are left alone.
+package fftopology
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Juniper/apstra-go-sdk/apstra"
+	"github.com/Juniper/apstra-go-sdk/apstra/enum"
+	"gopkg.in/yaml.v3"
+)
+
+// Topology is the desired state of a freeform blueprint.
+type Topology struct {
+	AllocationGroups []AllocationGroup `yaml:"allocation_groups" json:"allocation_groups"`
+	ConfigTemplates  []ConfigTemplate  `yaml:"config_templates" json:"config_templates"`
+	Systems          []System          `yaml:"systems" json:"systems"`
+	PropertySets     []PropertySet     `yaml:"property_sets" json:"property_sets"`
+	Links            []Link            `yaml:"links" json:"links"`
+	ResourceGroups   []ResourceGroup   `yaml:"resource_groups" json:"resource_groups"`
+	Resources        []Resource        `yaml:"resources" json:"resources"`
+}
+
+// AllocationGroup is a freeform allocation group, which makes resource pools
+// available to the blueprint. Name and Type identify it.
+type AllocationGroup struct {
+	Name    string            `yaml:"name" json:"name"`
+	Type    string            `yaml:"type" json:"type"` // asn, integer, ip, ipv6, vlan, vni
+	PoolIds []apstra.ObjectId `yaml:"pool_ids" json:"pool_ids"`
+}
+
+// ConfigTemplate is a freeform config template. Text may be given inline, or
+// loaded from File (relative to the topology file, when loaded with
+// LoadFile).
+type ConfigTemplate struct {
+	Label      string   `yaml:"label" json:"label"`
+	TemplateId string   `yaml:"template_id" json:"template_id"` // defaults to Label + ".jinja"
+	Text       string   `yaml:"text" json:"text"`
+	File       string   `yaml:"file" json:"file"`
+	Tags       []string `yaml:"tags" json:"tags"`
+}
+
+// System is a freeform system.
+type System struct {
+	Label           string           `yaml:"label" json:"label"`
+	Hostname        string           `yaml:"hostname" json:"hostname"`
+	Type            string           `yaml:"type" json:"type"` // internal (default) or external
+	DeviceProfileId apstra.ObjectId  `yaml:"device_profile_id" json:"device_profile_id"`
+	SystemId        *apstra.ObjectId `yaml:"system_id" json:"system_id"` // device key of the managed device
+	Tags            []string         `yaml:"tags" json:"tags"`
+	ConfigTemplate  string           `yaml:"config_template" json:"config_template"` // config template label
+}
+
+// PropertySet is a freeform property set. Property sets with a System are
+// specific to that system, others are global.
+type PropertySet struct {
+	Label  string         `yaml:"label" json:"label"`
+	System string         `yaml:"system" json:"system"` // system label
+	Values map[string]any `yaml:"values" json:"values"`
+}
+
+// Link is a freeform link between two systems.
+type Link struct {
+	Label     string      `yaml:"label" json:"label"`
+	Tags      []string    `yaml:"tags" json:"tags"`
+	Endpoints [2]Endpoint `yaml:"endpoints" json:"endpoints"`
+}
+
+// Endpoint is one end of a Link.
+type Endpoint struct {
+	System           string   `yaml:"system" json:"system"` // system label
+	IfName           string   `yaml:"if_name" json:"if_name"`
+	TransformationId *int     `yaml:"transformation_id" json:"transformation_id"`
+	Ipv4Address      string   `yaml:"ipv4_address" json:"ipv4_address"` // CIDR notation
+	Ipv6Address      string   `yaml:"ipv6_address" json:"ipv6_address"` // CIDR notation
+	Tags             []string `yaml:"tags" json:"tags"`
+}
+
+// ResourceGroup is a freeform resource group. Groups may be nested by naming
+// the Parent group's label.
+type ResourceGroup struct {
+	Label  string         `yaml:"label" json:"label"`
+	Parent string         `yaml:"parent" json:"parent"` // resource group label
+	Tags   []string       `yaml:"tags" json:"tags"`
+	Data   map[string]any `yaml:"data" json:"data"`
+}
+
+// Resource is a freeform resource. Resources without a Value are allocated
+// by Apstra from AllocatedFrom, which names either an allocation group or
+// (e.g. for host addresses within a subnet) another resource.
+type Resource struct {
+	Label           string             `yaml:"label" json:"label"`
+	Group           string             `yaml:"group" json:"group"` // resource group label
+	Type            string             `yaml:"type" json:"type"`   // asn, host_ip, host_ipv6, integer, ip, ipv6, vlan, vni
+	Value           *string            `yaml:"value" json:"value"`
+	AllocatedFrom   string             `yaml:"allocated_from" json:"allocated_from"`
+	SubnetPrefixLen *int               `yaml:"subnet_prefix_len" json:"subnet_prefix_len"`
+	AssignedTo      []AssignmentTarget `yaml:"assigned_to" json:"assigned_to"`
+}
+
+// AssignmentTarget identifies the node to which a resource is assigned: a
+// system, an interface (System and IfName), or a link.
+type AssignmentTarget struct {
+	System string `yaml:"system" json:"system"`
+	IfName string `yaml:"if_name" json:"if_name"`
+	Link   string `yaml:"link" json:"link"`
+}
+
+func (o AssignmentTarget) String() string {
+	switch {
+	case o.Link != "":
+		return "link " + o.Link
+	case o.IfName != "":
+		return "interface " + o.System + ":" + o.IfName
+	}
+	return "system " + o.System
+}
+
+// Parse reads a topology document. JSON documents are valid YAML, so either
+// format is accepted. Unknown fields are an error.
+func Parse(r io.Reader) (*Topology, error) {
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+
+	var result Topology
+	err := d.Decode(&result)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed parsing topology - %w", err)
+	}
+
+	return &result, nil
+}
+
+// LoadFile reads a topology document from a file. Config template File paths
+// are resolved relative to the topology file, and their content is loaded
+// into Text.
+func LoadFile(name string) (*Topology, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading topology file - %w", err)
+	}
+
+	result, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing topology file %q - %w", name, err)
+	}
+
+	for i, ct := range result.ConfigTemplates {
+		if ct.File == "" {
+			continue
+		}
+		path := ct.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(name), path)
+		}
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading config template %q file - %w", ct.Label, err)
+		}
+		result.ConfigTemplates[i].Text = string(text)
+	}
+
+	return result, nil
+}
+
+// Validate checks the topology for duplicate labels, dangling references,
+// bad values and dependency cycles without contacting Apstra.
+func (o *Topology) Validate() error {
+	var errs []error
+	errorf := func(format string, a ...any) {
+		errs = append(errs, fmt.Errorf(format, a...))
+	}
+
+	unique := func(kind string, labels []string) map[string]bool {
+		result := make(map[string]bool, len(labels))
+		for _, label := range labels {
+			switch {
+			case label == "":
+				errorf("%s with empty label", kind)
+			case result[label]:
+				errorf("duplicate %s %q", kind, label)
+			}
+			result[label] = true
+		}
+		return result
+	}
+
+	allocGroups := make(map[string]bool)
+	for _, ag := range o.AllocationGroups {
+		var t enum.ResourcePoolType
+		if err := t.FromString(ag.Type); err != nil {
+			errorf("allocation group %q has invalid type %q", ag.Name, ag.Type)
+		}
+		if ag.Name == "" {
+			errorf("allocation group with empty name")
+		}
+		allocGroups[ag.Name] = true
+	}
+
+	templates := unique("config template", labels(o.ConfigTemplates, func(ct ConfigTemplate) string { return ct.Label }))
+	for _, ct := range o.ConfigTemplates {
+		if ct.Text == "" {
+			errorf("config template %q has no text", ct.Label)
+		}
+	}
+
+	systems := unique("system", labels(o.Systems, func(s System) string { return s.Label }))
+	for _, s := range o.Systems {
+		if _, err := systemType(s.Type); err != nil {
+			errorf("system %q: %w", s.Label, err)
+		}
+		if s.ConfigTemplate != "" && !templates[s.ConfigTemplate] {
+			errorf("system %q refers to unknown config template %q", s.Label, s.ConfigTemplate)
+		}
+	}
+
+	propertySets := make(map[[2]string]bool)
+	for _, ps := range o.PropertySets {
+		key := [2]string{ps.Label, ps.System}
+		switch {
+		case ps.Label == "":
+			errorf("property set with empty label")
+		case propertySets[key]:
+			errorf("duplicate property set %q (system %q)", ps.Label, ps.System)
+		}
+		propertySets[key] = true
+		if ps.System != "" && !systems[ps.System] {
+			errorf("property set %q refers to unknown system %q", ps.Label, ps.System)
+		}
+	}
+
+	links := unique("link", labels(o.Links, func(l Link) string { return l.Label }))
+	interfaces := make(map[[2]string]string) // system label, if_name -> link label
+	for _, l := range o.Links {
+		for _, ep := range l.Endpoints {
+			if !systems[ep.System] {
+				errorf("link %q refers to unknown system %q", l.Label, ep.System)
+			}
+			if ep.IfName != "" {
+				key := [2]string{ep.System, ep.IfName}
+				if other, ok := interfaces[key]; ok {
+					errorf("interface %s:%s is used by links %q and %q", ep.System, ep.IfName, other, l.Label)
+				}
+				interfaces[key] = l.Label
+			}
+			if _, err := endpointData(ep); err != nil {
+				errorf("link %q: %w", l.Label, err)
+			}
+		}
+	}
+
+	groups := unique("resource group", labels(o.ResourceGroups, func(g ResourceGroup) string { return g.Label }))
+	groupDeps := make(map[string][]string)
+	for _, g := range o.ResourceGroups {
+		if g.Parent != "" {
+			if !groups[g.Parent] {
+				errorf("resource group %q refers to unknown parent %q", g.Label, g.Parent)
+			}
+			groupDeps[g.Label] = []string{g.Parent}
+		}
+	}
+	if _, err := dependencyOrder(labels(o.ResourceGroups, func(g ResourceGroup) string { return g.Label }), groupDeps); err != nil {
+		errorf("resource groups: %w", err)
+	}
+
+	resources := make(map[string]bool)
+	resourceDeps := make(map[string][]string)
+	for _, r := range o.Resources {
+		if r.Label == "" {
+			errorf("resource with empty label")
+		}
+		key := resourceKey(r.Group, r.Label)
+		if resources[key] {
+			errorf("duplicate resource %q in group %q", r.Label, r.Group)
+		}
+		resources[key] = true
+		if !groups[r.Group] {
+			errorf("resource %q refers to unknown resource group %q", r.Label, r.Group)
+		}
+		var t enum.FFResourceType
+		if err := t.FromString(r.Type); err != nil {
+			errorf("resource %q has invalid type %q", r.Label, r.Type)
+		}
+		for _, target := range r.AssignedTo {
+			switch {
+			case target.Link != "" && !links[target.Link]:
+				errorf("resource %q is assigned to unknown link %q", r.Label, target.Link)
+			case target.Link == "" && !systems[target.System]:
+				errorf("resource %q is assigned to unknown system %q", r.Label, target.System)
+			case target.Link == "" && target.IfName != "" && interfaces[[2]string{target.System, target.IfName}] == "":
+				errorf("resource %q is assigned to unknown interface %s:%s", r.Label, target.System, target.IfName)
+			}
+		}
+	}
+	for _, r := range o.Resources {
+		if r.AllocatedFrom == "" || allocGroups[r.AllocatedFrom] {
+			continue
+		}
+		parent := o.resourceByLabel(r.AllocatedFrom)
+		if parent == nil {
+			errorf("resource %q is allocated from unknown allocation group or resource %q", r.Label, r.AllocatedFrom)
+			continue
+		}
+		key := resourceKey(r.Group, r.Label)
+		resourceDeps[key] = append(resourceDeps[key], resourceKey(parent.Group, parent.Label))
+	}
+	if _, err := dependencyOrder(labels(o.Resources, func(r Resource) string { return resourceKey(r.Group, r.Label) }), resourceDeps); err != nil {
+		errorf("resources: %w", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// resourceByLabel returns the resource with the given label, or nil. A
+// "group/label" reference disambiguates resources which share a label.
+func (o *Topology) resourceByLabel(label string) *Resource {
+	for i, r := range o.Resources {
+		if r.Label == label || resourceKey(r.Group, r.Label) == label {
+			return &o.Resources[i]
+		}
+	}
+	return nil
+}
+
+func resourceKey(group, label string) string {
+	return group + "/" + label
+}
+
+func labels[T any](in []T, f func(T) string) []string {
+	result := make([]string, len(in))
+	for i := range in {
+		result[i] = f(in[i])
+	}
+	return result
+}
+
+// dependencyOrder sorts items so that each appears after everything it
+// depends upon. Items are otherwise kept in their original order. An error
+// is returned if the dependencies are cyclic.
+func dependencyOrder(items []string, deps map[string][]string) ([]string, error) {
+	known := make(map[string]bool, len(items))
+	for _, item := range items {
+		known[item] = true
+	}
+
+	result := make([]string, 0, len(items))
+	state := make(map[string]int) // 0: unvisited, 1: in progress, 2: done
+	var visit func(item string, path []string) error
+	visit = func(item string, path []string) error {
+		switch state[item] {
+		case 1:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, item), " -> "))
+		case 2:
+			return nil
+		}
+		state[item] = 1
+		for _, dep := range deps[item] {
+			if !known[dep] {
+				continue // dangling references are reported elsewhere
+			}
+			if err := visit(dep, append(path, item)); err != nil {
+				return err
+			}
+		}
+		state[item] = 2
+		result = append(result, item)
+		return nil
+	}
+
+	for _, item := range items {
+		if err := visit(item, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func systemType(s string) (apstra.SystemType, error) {
+	switch s {
+	case "", "internal":
+		return apstra.SystemTypeInternal, nil
+	case "external":
+		return apstra.SystemTypeExternal, nil
+	}
+	return 0, fmt.Errorf("invalid system type %q, expected \"internal\" or \"external\"", s)
+}
+
+func sortedStrings(in []string) []string {
+	result := append([]string{}, in...)
+	sort.Strings(result)
+	return result
+}
@@ -7,6 +7,7 @@ package apstra
 type FreeformClient struct {
 	client      *Client
 	blueprintId ObjectId
+	Mutex       RWMutex
 }
 
 // Id returns the ID of the Freeform Blueprint associated with this client.
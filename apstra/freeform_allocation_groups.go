@@ -47,7 +47,8 @@ func (o *FreeformAllocGroup) UnmarshalJSON(bytes []byte) error {
 	o.Data.Name = raw.Name
 	o.Data.PoolIds = raw.PoolIds
 
-	return o.Data.Type.FromString(raw.Type)
+	o.Data.Type, err = apiEnumFromString[enum.ResourcePoolType](raw.Type)
+	return err
 }
 
 type FreeformAllocGroupData struct {
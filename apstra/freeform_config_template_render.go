@@ -0,0 +1,369 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/enum"
+	"github.com/Juniper/apstra-go-sdk/apstra/jinja"
+)
+
+// FreeformRenderInput is the freeform blueprint data from which config
+// template render contexts are built. It can be collected from a live
+// blueprint with FreeformClient.GetRenderInput, or assembled by hand (or
+// loaded from a fixture) to unit test config templates without Apstra.
+type FreeformRenderInput struct {
+	Systems                   []FreeformSystem
+	Links                     []FreeformLink
+	PropertySets              []FreeformPropertySet
+	Resources                 []FreeformRaResource
+	ResourceAssignments       map[ObjectId][]ObjectId // resource ID -> IDs of nodes to which it is assigned
+	ConfigTemplates           []ConfigTemplate
+	ConfigTemplateAssignments map[ObjectId]*ObjectId // system ID -> config template ID
+}
+
+// GetRenderInput collects everything needed to render the blueprint's config
+// templates locally. Resource assignments require one API call per resource.
+func (o *FreeformClient) GetRenderInput(ctx context.Context) (*FreeformRenderInput, error) {
+	var result FreeformRenderInput
+	var err error
+
+	if result.Systems, err = o.GetAllSystems(ctx); err != nil {
+		return nil, fmt.Errorf("failed fetching freeform systems - %w", err)
+	}
+	if result.Links, err = o.GetAllLinks(ctx); err != nil {
+		return nil, fmt.Errorf("failed fetching freeform links - %w", err)
+	}
+	if result.PropertySets, err = o.GetAllPropertySets(ctx); err != nil {
+		return nil, fmt.Errorf("failed fetching freeform property sets - %w", err)
+	}
+	if result.Resources, err = o.GetAllRaResources(ctx); err != nil {
+		return nil, fmt.Errorf("failed fetching freeform resources - %w", err)
+	}
+	if result.ConfigTemplates, err = o.GetAllConfigTemplates(ctx); err != nil {
+		return nil, fmt.Errorf("failed fetching freeform config templates - %w", err)
+	}
+	if result.ConfigTemplateAssignments, err = o.ListConfigTemplateAssignments(ctx); err != nil {
+		return nil, fmt.Errorf("failed fetching freeform config template assignments - %w", err)
+	}
+
+	result.ResourceAssignments = make(map[ObjectId][]ObjectId, len(result.Resources))
+	for _, resource := range result.Resources {
+		if result.ResourceAssignments[resource.Id], err = o.ListResourceAssignments(ctx, resource.Id); err != nil {
+			return nil, fmt.Errorf("failed fetching assignments of freeform resource %q - %w", resource.Id, err)
+		}
+	}
+
+	return &result, nil
+}
+
+// PreviewSystemConfig renders the config template assigned to the given
+// system locally, approximating the configuration Apstra would render.
+func (o *FreeformClient) PreviewSystemConfig(ctx context.Context, systemId ObjectId) (string, error) {
+	in, err := o.GetRenderInput(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return in.Render(systemId)
+}
+
+// PreviewConfigs renders the config template assigned to each system which
+// has one. The result is keyed by system ID.
+func (o *FreeformClient) PreviewConfigs(ctx context.Context) (map[ObjectId]string, error) {
+	in, err := o.GetRenderInput(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[ObjectId]string)
+	for systemId, templateId := range in.ConfigTemplateAssignments {
+		if templateId == nil {
+			continue
+		}
+		if result[systemId], err = in.Render(systemId); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Render renders the config template assigned to the given system.
+func (o *FreeformRenderInput) Render(systemId ObjectId) (string, error) {
+	templateId := o.ConfigTemplateAssignments[systemId]
+	if templateId == nil {
+		return "", ClientErr{
+			errType: ErrNotfound,
+			err:     fmt.Errorf("no config template assigned to system %q", systemId),
+		}
+	}
+
+	vars, err := o.Context(systemId)
+	if err != nil {
+		return "", err
+	}
+
+	return o.RenderTemplate(*templateId, vars)
+}
+
+// RenderTemplate renders the config template with the given ID using vars
+// as its context. The context may come from Context, or may be a fixture
+// written for a template unit test.
+func (o *FreeformRenderInput) RenderTemplate(templateId ObjectId, vars map[string]any) (string, error) {
+	env := jinja.Environment{
+		Templates:    make(map[string]string, len(o.ConfigTemplates)),
+		TrimBlocks:   true,
+		LstripBlocks: true,
+	}
+
+	// templates include one another using the template_id (file name)
+	var name string
+	for _, ct := range o.ConfigTemplates {
+		if ct.Data == nil {
+			continue
+		}
+		env.Templates[ct.Data.TemplateId.String()] = ct.Data.Text
+		if ct.Id == templateId || ct.Data.TemplateId == templateId {
+			name = ct.Data.TemplateId.String()
+		}
+	}
+	if name == "" {
+		return "", ClientErr{
+			errType: ErrNotfound,
+			err:     fmt.Errorf("config template %q not found", templateId),
+		}
+	}
+
+	result, err := env.Render(name, vars)
+	if err != nil {
+		return "", fmt.Errorf("failed rendering config template %q - %w", name, err)
+	}
+
+	return result, nil
+}
+
+// Context builds the template render context for the given system. It
+// contains:
+//   - hostname, label: the system's hostname and label
+//   - system: id, label, hostname, type, tags, device_profile_id, system_id
+//   - interfaces: keyed by interface name, each with id, if_name,
+//     ipv4_address, ipv6_address, tags, link (id, label, speed, tags,
+//     aggregate_link_id) and neighbor (system_id, label, hostname, if_name,
+//     ipv4_address, ipv6_address)
+//   - property_sets: the values of global property sets and property sets
+//     belonging to the system, keyed by label. The values are also available
+//     as top level variables, unless they collide with the names above.
+//   - resources: resources assigned to the system, its interfaces or its
+//     links, keyed by label, each with id, value, type and group_id. Integer
+//     resources (ASN, VLAN, VNI, integer) have integer values. When labels
+//     collide, the resource with the lowest ID wins.
+func (o *FreeformRenderInput) Context(systemId ObjectId) (map[string]any, error) {
+	systems := make(map[ObjectId]*FreeformSystemData, len(o.Systems))
+	for _, system := range o.Systems {
+		if system.Data != nil {
+			systems[system.Id] = system.Data
+		}
+	}
+
+	system, ok := systems[systemId]
+	if !ok {
+		return nil, ClientErr{
+			errType: ErrNotfound,
+			err:     fmt.Errorf("system %q not found", systemId),
+		}
+	}
+
+	result := map[string]any{
+		"hostname": system.Hostname,
+		"label":    system.Label,
+		"system": map[string]any{
+			"id":                systemId,
+			"label":             system.Label,
+			"hostname":          system.Hostname,
+			"type":              system.Type.String(),
+			"tags":              nonNilTags(system.Tags),
+			"device_profile_id": system.DeviceProfileId,
+			"system_id":         system.SystemId,
+		},
+	}
+
+	// nodes related to this system, for resource assignment purposes
+	related := map[ObjectId]bool{systemId: true}
+
+	interfaces := make(map[string]any)
+	for _, link := range o.Links {
+		if link.Data == nil {
+			continue
+		}
+		for i, ep := range link.Data.Endpoints {
+			if ep.SystemId != systemId {
+				continue
+			}
+			related[link.Id] = true
+			if ep.Interface.Id != nil {
+				related[*ep.Interface.Id] = true
+			}
+
+			intf := freeformInterfaceContext(ep.Interface)
+			intf["link"] = map[string]any{
+				"id":                link.Id,
+				"label":             link.Data.Label,
+				"speed":             link.Data.Speed,
+				"tags":              nonNilTags(link.Data.Tags),
+				"aggregate_link_id": link.Data.AggregateLinkId,
+			}
+
+			peer := link.Data.Endpoints[1-i]
+			neighbor := freeformInterfaceContext(peer.Interface)
+			delete(neighbor, "id")
+			neighbor["system_id"] = peer.SystemId
+			if peerSystem, ok := systems[peer.SystemId]; ok {
+				neighbor["label"] = peerSystem.Label
+				neighbor["hostname"] = peerSystem.Hostname
+			}
+			intf["neighbor"] = neighbor
+
+			name, _ := intf["if_name"].(string)
+			if name == "" {
+				// interfaces without a name (e.g. LAGs awaiting assignment) are keyed by ID
+				name = link.Id.String()
+			}
+			interfaces[name] = intf
+		}
+	}
+	result["interfaces"] = interfaces
+
+	propertySets, err := o.propertySetsContext(systemId)
+	if err != nil {
+		return nil, err
+	}
+	result["property_sets"] = propertySets
+
+	result["resources"] = o.resourcesContext(related)
+
+	// property set values are also exposed at the top level
+	for _, label := range sortedKeys(propertySets) {
+		values, ok := propertySets[label].(map[string]any)
+		if !ok {
+			continue
+		}
+		for k, v := range values {
+			if _, ok := result[k]; !ok {
+				result[k] = v
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func freeformInterfaceContext(in FreeformInterface) map[string]any {
+	result := map[string]any{
+		"id":   in.Id,
+		"tags": []string{},
+	}
+	if in.Data == nil {
+		return result
+	}
+	if in.Data.IfName != nil {
+		result["if_name"] = *in.Data.IfName
+	}
+	if in.Data.TransformationId != nil {
+		result["transformation_id"] = *in.Data.TransformationId
+	}
+	if in.Data.Ipv4Address != nil {
+		result["ipv4_address"] = in.Data.Ipv4Address.String()
+	}
+	if in.Data.Ipv6Address != nil {
+		result["ipv6_address"] = in.Data.Ipv6Address.String()
+	}
+	result["tags"] = nonNilTags(in.Data.Tags)
+	return result
+}
+
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// propertySetsContext returns the values of global property sets, overlaid
+// with those belonging to the system, keyed by property set label.
+func (o *FreeformRenderInput) propertySetsContext(systemId ObjectId) (map[string]any, error) {
+	result := make(map[string]any)
+	for _, global := range []bool{true, false} {
+		for _, ps := range o.PropertySets {
+			if ps.Data == nil || (ps.Data.SystemId == nil) != global {
+				continue
+			}
+			if !global && *ps.Data.SystemId != systemId {
+				continue
+			}
+
+			var values any = map[string]any{}
+			if len(ps.Data.Values) > 0 {
+				if err := json.Unmarshal(ps.Data.Values, &values); err != nil {
+					return nil, fmt.Errorf("failed unmarshaling values of property set %q - %w", ps.Data.Label, err)
+				}
+			}
+			result[ps.Data.Label] = values
+		}
+	}
+	return result, nil
+}
+
+// resourcesContext returns the resources assigned to any of the related
+// nodes, keyed by label.
+func (o *FreeformRenderInput) resourcesContext(related map[ObjectId]bool) map[string]any {
+	resources := append([]FreeformRaResource{}, o.Resources...)
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Id < resources[j].Id })
+
+	result := make(map[string]any)
+	for _, resource := range resources {
+		if resource.Data == nil {
+			continue
+		}
+		if _, ok := result[resource.Data.Label]; ok {
+			continue
+		}
+
+		var assigned bool
+		for _, id := range o.ResourceAssignments[resource.Id] {
+			if related[id] {
+				assigned = true
+				break
+			}
+		}
+		if !assigned {
+			continue
+		}
+
+		var value any
+		if resource.Data.Value != nil {
+			value = *resource.Data.Value
+			switch resource.Data.ResourceType {
+			case enum.FFResourceTypeAsn, enum.FFResourceTypeInt, enum.FFResourceTypeVlan, enum.FFResourceTypeVni:
+				if i, err := strconv.ParseInt(*resource.Data.Value, 10, 64); err == nil {
+					value = i
+				}
+			}
+		}
+
+		result[resource.Data.Label] = map[string]any{
+			"id":       resource.Id,
+			"value":    value,
+			"type":     resource.Data.ResourceType.String(),
+			"group_id": resource.Data.GroupId,
+		}
+	}
+	return result
+}
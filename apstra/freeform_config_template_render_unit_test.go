@@ -0,0 +1,117 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/enum"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeformRenderInput(t *testing.T) {
+	ifName := func(s string) *string { return &s }
+	ipNet := func(s string) *net.IPNet {
+		ip, n, err := net.ParseCIDR(s)
+		require.NoError(t, err)
+		n.IP = ip
+		return n
+	}
+	id := func(s ObjectId) *ObjectId { return &s }
+	value := func(s string) *string { return &s }
+
+	in := FreeformRenderInput{
+		Systems: []FreeformSystem{
+			{Id: "leaf1", Data: &FreeformSystemData{Type: SystemTypeInternal, Label: "leaf1", Hostname: "leaf1.example.com", Tags: []string{"leaf"}}},
+			{Id: "spine1", Data: &FreeformSystemData{Type: SystemTypeInternal, Label: "spine1", Hostname: "spine1.example.com"}},
+		},
+		Links: []FreeformLink{{
+			Id: "link1",
+			Data: &FreeformLinkData{
+				Label: "leaf1_spine1",
+				Speed: "10G",
+				Endpoints: [2]FreeformEndpoint{
+					{SystemId: "leaf1", Interface: FreeformInterface{Id: id("if1"), Data: &FreeformInterfaceData{IfName: ifName("xe-0/0/0"), Ipv4Address: ipNet("10.0.0.1/31")}}},
+					{SystemId: "spine1", Interface: FreeformInterface{Id: id("if2"), Data: &FreeformInterfaceData{IfName: ifName("xe-0/0/7"), Ipv4Address: ipNet("10.0.0.0/31")}}},
+				},
+			},
+		}},
+		PropertySets: []FreeformPropertySet{
+			{Id: "ps1", Data: &FreeformPropertySetData{Label: "global", Values: json.RawMessage(`{"ntp_server": "10.1.1.1", "mtu": 1500}`)}},
+			{Id: "ps2", Data: &FreeformPropertySetData{Label: "global", SystemId: id("leaf1"), Values: json.RawMessage(`{"ntp_server": "10.1.1.1", "mtu": 9216}`)}},
+			{Id: "ps3", Data: &FreeformPropertySetData{Label: "spine_only", SystemId: id("spine1"), Values: json.RawMessage(`{"spine": true}`)}},
+		},
+		Resources: []FreeformRaResource{
+			{Id: "r1", Data: &FreeformRaResourceData{ResourceType: enum.FFResourceTypeAsn, Label: "local_asn", Value: value("65001"), GroupId: "g1"}},
+			{Id: "r2", Data: &FreeformRaResourceData{ResourceType: enum.FFResourceTypeHostIpv4, Label: "loopback", Value: value("192.168.0.1/32"), GroupId: "g1"}},
+			{Id: "r3", Data: &FreeformRaResourceData{ResourceType: enum.FFResourceTypeAsn, Label: "local_asn", Value: value("65100"), GroupId: "g2"}},
+		},
+		ResourceAssignments: map[ObjectId][]ObjectId{
+			"r1": {"leaf1"},
+			"r2": {"if1"},
+			"r3": {"spine1"},
+		},
+		ConfigTemplates: []ConfigTemplate{
+			{Id: "ct1", Data: &ConfigTemplateData{Label: "main", TemplateId: "main.jinja", Text: "" +
+				"hostname {{ hostname }}\n" +
+				"{% include 'interfaces.jinja' %}\n" +
+				"set protocols bgp local-as {{ resources.local_asn.value }}\n" +
+				"set system ntp server {{ ntp_server }}\n"}},
+			{Id: "ct2", Data: &ConfigTemplateData{Label: "interfaces", TemplateId: "interfaces.jinja", Text: "" +
+				"{% for name, intf in interfaces.items() %}\n" +
+				"set interfaces {{ name }} mtu {{ property_sets.global.mtu }} description \"to {{ intf.neighbor.label }}:{{ intf.neighbor.if_name }}\"\n" +
+				"{% endfor %}"}},
+		},
+		ConfigTemplateAssignments: map[ObjectId]*ObjectId{"leaf1": id("ct1"), "spine1": nil},
+	}
+
+	t.Run("context", func(t *testing.T) {
+		ctx, err := in.Context("leaf1")
+		require.NoError(t, err)
+		require.Equal(t, "leaf1.example.com", ctx["hostname"])
+		require.Equal(t, "10.1.1.1", ctx["ntp_server"])
+		require.Equal(t, map[string]any{"ntp_server": "10.1.1.1", "mtu": float64(9216)}, ctx["property_sets"].(map[string]any)["global"])
+		require.NotContains(t, ctx["property_sets"], "spine_only")
+
+		resources := ctx["resources"].(map[string]any)
+		require.Len(t, resources, 2)
+		require.Equal(t, int64(65001), resources["local_asn"].(map[string]any)["value"])
+		require.Equal(t, "192.168.0.1/32", resources["loopback"].(map[string]any)["value"])
+
+		intf := ctx["interfaces"].(map[string]any)["xe-0/0/0"].(map[string]any)
+		require.Equal(t, "10.0.0.1/31", intf["ipv4_address"])
+		require.Equal(t, "spine1.example.com", intf["neighbor"].(map[string]any)["hostname"])
+	})
+
+	t.Run("render", func(t *testing.T) {
+		result, err := in.Render("leaf1")
+		require.NoError(t, err)
+		require.Equal(t, ""+
+			"hostname leaf1.example.com\n"+
+			"set interfaces xe-0/0/0 mtu 9216 description \"to spine1:xe-0/0/7\"\n"+
+			"set protocols bgp local-as 65001\n"+
+			"set system ntp server 10.1.1.1\n", result)
+	})
+
+	t.Run("render_fixture_context", func(t *testing.T) {
+		result, err := in.RenderTemplate("interfaces.jinja", map[string]any{
+			"property_sets": map[string]any{"global": map[string]any{"mtu": 1500}},
+			"interfaces": map[string]any{
+				"et-0/0/1": map[string]any{"neighbor": map[string]any{"label": "a", "if_name": "b"}},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "set interfaces et-0/0/1 mtu 1500 description \"to a:b\"\n", result)
+	})
+
+	t.Run("no_template", func(t *testing.T) {
+		_, err := in.Render("spine1")
+		var ace ClientErr
+		require.ErrorAs(t, err, &ace)
+		require.Equal(t, ErrNotfound, ace.Type())
+	})
+}
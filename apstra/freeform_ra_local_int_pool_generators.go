@@ -51,7 +51,7 @@ func (o *FreeformRaLocalIntPoolGenerator) UnmarshalJSON(bytes []byte) error {
 	o.Data.Label = raw.Label
 	o.Data.Scope = raw.Scope
 	o.Data.Chunks = raw.Definition.Chunks
-	err = o.Data.ResourceType.FromString(raw.ResourceType)
+	o.Data.ResourceType, err = apiEnumFromString[enum.FFResourceType](raw.ResourceType)
 	if err != nil {
 		return err
 	}
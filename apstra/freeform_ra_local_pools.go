@@ -46,7 +46,7 @@ func (o *FreeformRaLocalIntPool) UnmarshalJSON(bytes []byte) error {
 	o.Id = raw.Id
 	o.Data = new(FreeformRaLocalIntPoolData)
 	o.Data.Label = raw.Label
-	err = o.Data.ResourceType.FromString(raw.ResourceType)
+	o.Data.ResourceType, err = apiEnumFromString[enum.FFResourceType](raw.ResourceType)
 	if err != nil {
 		return err
 	}
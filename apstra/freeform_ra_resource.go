@@ -53,7 +53,7 @@ func (o *FreeformRaResource) UnmarshalJSON(bytes []byte) error {
 	o.Data.GroupId = raw.GroupId
 	o.Data.SubnetPrefixLen = raw.SubnetPrefixLen
 	o.Data.GeneratorId = raw.GeneratorId
-	err = o.Data.ResourceType.FromString(raw.ResourceType)
+	o.Data.ResourceType, err = apiEnumFromString[enum.FFResourceType](raw.ResourceType)
 	if err != nil {
 		return err
 	}
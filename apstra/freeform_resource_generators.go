@@ -50,7 +50,7 @@ func (o *FreeformResourceGenerator) UnmarshalJSON(bytes []byte) error {
 	o.Data.ScopeNodePoolLabel = raw.ScopeNodePoolLabel
 	o.Data.ContainerId = raw.ContainerId
 	o.Data.SubnetPrefixLen = raw.SubnetPrefixLen
-	err = o.Data.ResourceType.FromString(raw.ResourceType)
+	o.Data.ResourceType, err = apiEnumFromString[enum.FFResourceType](raw.ResourceType)
 	if err != nil {
 		return err
 	}
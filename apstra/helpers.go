@@ -10,6 +10,7 @@ import (
 	"io"
 	"net"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -88,6 +89,16 @@ func toPtr[A any](a A) *A {
 	return &a
 }
 
+// sortedKeys returns the keys of m in order, for predictable iteration.
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	result := make([]K, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
 func stringerPtrToStringPtr(in fmt.Stringer) *string {
 	if in == nil {
 		return nil
@@ -99,11 +110,19 @@ func stringerPtrToStringPtr(in fmt.Stringer) *string {
 	return toPtr(in.String())
 }
 
+// featureSwitchEnumFromStringPtr parses in, a value found in an API response.
+// Unknown values are kept, as with apiEnumFromString.
 func featureSwitchEnumFromStringPtr(in *string) *enum.FeatureSwitch {
 	if in == nil {
 		return nil
 	}
-	return enum.FeatureSwitches.Parse(*in)
+
+	result, err := apiEnumFromString[enum.FeatureSwitch](*in)
+	if err != nil {
+		return nil
+	}
+
+	return &result
 }
 
 func isv4(ip net.IP) bool {
@@ -0,0 +1,784 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jinja
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type filterFunc func(v any, args []any, kwargs map[string]any) (any, error)
+
+type testFunc func(v any, args []any) (bool, error)
+
+// arg returns the i-th positional argument, or the named keyword argument,
+// or dflt if neither was supplied.
+func arg(args []any, kwargs map[string]any, i int, name string, dflt any) any {
+	if i < len(args) {
+		return args[i]
+	}
+	if v, ok := kwargs[name]; ok {
+		return v
+	}
+	return dflt
+}
+
+var filters map[string]filterFunc
+
+func init() {
+	filters = map[string]filterFunc{
+		"abs":        filterAbs,
+		"capitalize": stringFilter(func(s string) string { return capitalize(s) }),
+		"count":      filterLength,
+		"d":          filterDefault,
+		"default":    filterDefault,
+		"dictsort":   filterDictsort,
+		"first":      filterFirst,
+		"float":      filterFloat,
+		"format":     filterFormat,
+		"indent":     filterIndent,
+		"int":        filterInt,
+		"join":       filterJoin,
+		"last":       filterLast,
+		"length":     filterLength,
+		"list":       filterList,
+		"lower":      stringFilter(strings.ToLower),
+		"map":        filterMap,
+		"max":        minMaxFilter(1),
+		"min":        minMaxFilter(-1),
+		"reject":     selectFilter(false, false),
+		"rejectattr": selectFilter(true, false),
+		"replace":    filterReplace,
+		"reverse":    filterReverse,
+		"round":      filterRound,
+		"select":     selectFilter(false, true),
+		"selectattr": selectFilter(true, true),
+		"sort":       filterSort,
+		"string":     func(v any, _ []any, _ map[string]any) (any, error) { return toString(v), nil },
+		"sum":        filterSum,
+		"title":      stringFilter(title),
+		"tojson":     filterTojson,
+		"trim":       stringFilter(strings.TrimSpace),
+		"unique":     filterUnique,
+		"upper":      stringFilter(strings.ToUpper),
+	}
+}
+
+func stringFilter(f func(string) string) filterFunc {
+	return func(v any, _ []any, _ map[string]any) (any, error) {
+		return f(toString(v)), nil
+	}
+}
+
+func capitalize(s string) string {
+	r := []rune(strings.ToLower(s))
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+	return string(r)
+}
+
+func title(s string) string {
+	r := []rune(strings.ToLower(s))
+	start := true
+	for i := range r {
+		if start {
+			r[i] = unicode.ToUpper(r[i])
+		}
+		start = !unicode.IsLetter(r[i]) && !unicode.IsDigit(r[i])
+	}
+	return string(r)
+}
+
+func filterAbs(v any, _ []any, _ map[string]any) (any, error) {
+	switch v := v.(type) {
+	case int64:
+		if v < 0 {
+			return -v, nil
+		}
+		return v, nil
+	case float64:
+		return math.Abs(v), nil
+	}
+	return nil, fmt.Errorf("bad operand type for abs(): %s", typeName(v))
+}
+
+func filterDefault(v any, args []any, kwargs map[string]any) (any, error) {
+	dflt := arg(args, kwargs, 0, "default_value", "")
+	boolean := truthy(arg(args, kwargs, 1, "boolean", false))
+	if isUndefined(v) || (boolean && !truthy(v)) {
+		return dflt, nil
+	}
+	return v, nil
+}
+
+func filterDictsort(v any, args []any, kwargs map[string]any) (any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("dictsort requires a dict, got %s", typeName(v))
+	}
+	byValue := toString(arg(args, kwargs, 1, "by", "key")) == "value"
+	reverse := truthy(arg(args, kwargs, 2, "reverse", false))
+
+	result := make([]any, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		result = append(result, []any{k, m[k]})
+	}
+	if byValue {
+		var err error
+		sort.SliceStable(result, func(i, j int) bool {
+			c, e := compare(result[i].([]any)[1], result[j].([]any)[1])
+			if e != nil {
+				err = e
+			}
+			return c < 0
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if reverse {
+		reverseSlice(result)
+	}
+	return result, nil
+}
+
+func filterFirst(v any, _ []any, _ map[string]any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return Undefined{name: "first item"}, nil
+	}
+	return items[0], nil
+}
+
+func filterLast(v any, _ []any, _ map[string]any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return Undefined{name: "last item"}, nil
+	}
+	return items[len(items)-1], nil
+}
+
+func filterFloat(v any, args []any, kwargs map[string]any) (any, error) {
+	if f, ok := toFloat(v); ok {
+		return f, nil
+	}
+	if f, err := strconv.ParseFloat(strings.TrimSpace(toString(v)), 64); err == nil {
+		return f, nil
+	}
+	return arg(args, kwargs, 0, "default", 0.0), nil
+}
+
+func filterInt(v any, args []any, kwargs map[string]any) (any, error) {
+	if i, ok := toInt(v); ok {
+		return i, nil
+	}
+	s := strings.TrimSpace(toString(v))
+	base := arg(args, kwargs, 1, "base", int64(10))
+	if b, ok := base.(int64); ok {
+		if i, err := strconv.ParseInt(s, int(b), 64); err == nil {
+			return i, nil
+		}
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return int64(f), nil
+	}
+	return arg(args, kwargs, 0, "default", int64(0)), nil
+}
+
+func filterFormat(v any, args []any, kwargs map[string]any) (any, error) {
+	if len(args) > 0 && len(kwargs) > 0 {
+		return nil, fmt.Errorf("can't handle positional and keyword arguments at the same time")
+	}
+	if len(kwargs) > 0 {
+		return percentFormat(toString(v), kwargs)
+	}
+	return percentFormat(toString(v), args)
+}
+
+func filterIndent(v any, args []any, kwargs map[string]any) (any, error) {
+	var indent string
+	switch w := arg(args, kwargs, 0, "width", int64(4)).(type) {
+	case int64:
+		var err error
+		if indent, err = repeat(" ", w); err != nil {
+			return nil, err
+		}
+	default:
+		indent = toString(w)
+	}
+	first := truthy(arg(args, kwargs, 1, "first", false))
+	blank := truthy(arg(args, kwargs, 2, "blank", false))
+
+	lines := strings.Split(toString(v), "\n")
+	for i := range lines {
+		if i == 0 && !first {
+			continue
+		}
+		if lines[i] == "" && !blank {
+			continue
+		}
+		lines[i] = indent + lines[i]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func filterJoin(v any, args []any, kwargs map[string]any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	items, err = attributes(items, arg(args, kwargs, 1, "attribute", nil))
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(items))
+	for i := range items {
+		strs[i] = toString(items[i])
+	}
+	return strings.Join(strs, toString(arg(args, kwargs, 0, "d", ""))), nil
+}
+
+func filterLength(v any, _ []any, _ map[string]any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return int64(len([]rune(v))), nil
+	case []any:
+		return int64(len(v)), nil
+	case map[string]any:
+		return int64(len(v)), nil
+	case Undefined:
+		return int64(0), nil
+	}
+	return nil, fmt.Errorf("object of type %s has no len()", typeName(v))
+}
+
+func filterList(v any, _ []any, _ map[string]any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]any{}, items...), nil
+}
+
+// attribute looks up a (possibly dotted) attribute path on v
+func attribute(v any, path string) any {
+	for _, part := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = t[part]; !ok {
+				return Undefined{name: part}
+			}
+		case []any:
+			i, ok := parseIndex(part)
+			if n := index(i, len(t)); !ok || n < 0 || n >= len(t) {
+				return Undefined{name: part}
+			}
+			v = t[index(i, len(t))]
+		default:
+			return Undefined{name: part}
+		}
+	}
+	return v
+}
+
+// attributes replaces each item with the named attribute, if attr is not nil
+func attributes(items []any, attr any) ([]any, error) {
+	if attr == nil {
+		return items, nil
+	}
+	result := make([]any, len(items))
+	for i := range items {
+		result[i] = attribute(items[i], toString(attr))
+	}
+	return result, nil
+}
+
+func filterMap(v any, args []any, kwargs map[string]any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if attr, ok := kwargs["attribute"]; ok {
+		result, _ := attributes(items, attr)
+		if dflt, ok := kwargs["default"]; ok {
+			for i := range result {
+				if isUndefined(result[i]) {
+					result[i] = dflt
+				}
+			}
+		}
+		return result, nil
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("map requires a filter name or attribute= argument")
+	}
+	name := toString(args[0])
+	f, ok := filters[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported filter %q", name)
+	}
+	result := make([]any, len(items))
+	for i := range items {
+		if result[i], err = f(items[i], args[1:], kwargs); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func minMaxFilter(sign int) filterFunc {
+	return func(v any, args []any, kwargs map[string]any) (any, error) {
+		items, err := iterate(v)
+		if err != nil {
+			return nil, err
+		}
+		keys, _ := attributes(items, arg(args, kwargs, 1, "attribute", nil))
+		if len(items) == 0 {
+			return Undefined{name: "empty sequence"}, nil
+		}
+		best := 0
+		for i := 1; i < len(items); i++ {
+			c, err := compare(keys[i], keys[best])
+			if err != nil {
+				return nil, err
+			}
+			if c*sign > 0 {
+				best = i
+			}
+		}
+		return items[best], nil
+	}
+}
+
+func filterReplace(v any, args []any, kwargs map[string]any) (any, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("replace requires old and new arguments")
+	}
+	count := -1
+	if c, ok := arg(args, kwargs, 2, "count", nil).(int64); ok {
+		count = int(c)
+	}
+	return strings.Replace(toString(v), toString(args[0]), toString(args[1]), count), nil
+}
+
+func reverseSlice(s []any) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+func filterReverse(v any, _ []any, _ map[string]any) (any, error) {
+	if s, ok := v.(string); ok {
+		r := []rune(s)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	}
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	result := append([]any{}, items...)
+	reverseSlice(result)
+	return result, nil
+}
+
+func filterRound(v any, args []any, kwargs map[string]any) (any, error) {
+	f, ok := toFloat(v)
+	if !ok {
+		return nil, fmt.Errorf("round requires a number, got %s", typeName(v))
+	}
+	precision, _ := toInt(arg(args, kwargs, 0, "precision", int64(0)))
+	scale := math.Pow(10, float64(precision))
+	switch toString(arg(args, kwargs, 1, "method", "common")) {
+	case "ceil":
+		return math.Ceil(f*scale) / scale, nil
+	case "floor":
+		return math.Floor(f*scale) / scale, nil
+	}
+	return math.Round(f*scale) / scale, nil
+}
+
+func selectFilter(byAttribute, keep bool) filterFunc {
+	return func(v any, args []any, kwargs map[string]any) (any, error) {
+		items, err := iterate(v)
+		if err != nil {
+			return nil, err
+		}
+
+		values := items
+		if byAttribute {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("attribute name required")
+			}
+			values, _ = attributes(items, args[0])
+			args = args[1:]
+		}
+
+		test := func(v any, _ []any) (bool, error) { return truthy(v), nil }
+		if len(args) > 0 {
+			name := toString(args[0])
+			var ok bool
+			if test, ok = tests[name]; !ok {
+				return nil, fmt.Errorf("unsupported test %q", name)
+			}
+			args = args[1:]
+		}
+
+		result := make([]any, 0, len(items))
+		for i := range items {
+			ok, err := test(values[i], args)
+			if err != nil {
+				return nil, err
+			}
+			if ok == keep {
+				result = append(result, items[i])
+			}
+		}
+		return result, nil
+	}
+}
+
+func filterSort(v any, args []any, kwargs map[string]any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	reverse := truthy(arg(args, kwargs, 0, "reverse", false))
+	caseSensitive := truthy(arg(args, kwargs, 1, "case_sensitive", false))
+	keys, _ := attributes(items, arg(args, kwargs, 2, "attribute", nil))
+	if !caseSensitive {
+		for i := range keys {
+			if s, ok := keys[i].(string); ok {
+				keys[i] = strings.ToLower(s)
+			}
+		}
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		c, e := compare(keys[order[i]], keys[order[j]])
+		if e != nil && err == nil {
+			err = e
+		}
+		if reverse {
+			return c > 0
+		}
+		return c < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, len(items))
+	for i, o := range order {
+		result[i] = items[o]
+	}
+	return result, nil
+}
+
+func filterSum(v any, args []any, kwargs map[string]any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	items, _ = attributes(items, arg(args, kwargs, 0, "attribute", nil))
+	result := arg(args, kwargs, 1, "start", int64(0))
+	for _, item := range items {
+		if result, err = arithmetic("+", result, item); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func filterTojson(v any, args []any, kwargs map[string]any) (any, error) {
+	if isUndefined(v) {
+		v = nil
+	}
+	var b []byte
+	var err error
+	if indent, ok := arg(args, kwargs, 0, "indent", nil).(int64); ok {
+		var prefix string
+		if prefix, err = repeat(" ", indent); err != nil {
+			return nil, err
+		}
+		b, err = json.MarshalIndent(v, "", prefix)
+	} else {
+		b, err = json.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func filterUnique(v any, args []any, kwargs map[string]any) (any, error) {
+	items, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	keys, _ := attributes(items, arg(args, kwargs, 1, "attribute", nil))
+	var result, seen []any
+	for i := range items {
+		found, _ := contains(seen, keys[i])
+		if !found {
+			seen = append(seen, keys[i])
+			result = append(result, items[i])
+		}
+	}
+	return result, nil
+}
+
+var tests = map[string]testFunc{
+	"defined":   func(v any, _ []any) (bool, error) { return !isUndefined(v), nil },
+	"undefined": func(v any, _ []any) (bool, error) { return isUndefined(v), nil },
+	"none":      func(v any, _ []any) (bool, error) { return v == nil, nil },
+	"boolean":   func(v any, _ []any) (bool, error) { return isBool(v), nil },
+	"true":      func(v any, _ []any) (bool, error) { return v == true, nil },
+	"false":     func(v any, _ []any) (bool, error) { return v == false, nil },
+	"string":    func(v any, _ []any) (bool, error) { _, ok := v.(string); return ok, nil },
+	"mapping":   func(v any, _ []any) (bool, error) { _, ok := v.(map[string]any); return ok, nil },
+	"number": func(v any, _ []any) (bool, error) {
+		switch v.(type) {
+		case int64, float64:
+			return true, nil
+		}
+		return false, nil
+	},
+	"integer": func(v any, _ []any) (bool, error) { _, ok := v.(int64); return ok, nil },
+	"float":   func(v any, _ []any) (bool, error) { _, ok := v.(float64); return ok, nil },
+	"iterable": func(v any, _ []any) (bool, error) {
+		switch v.(type) {
+		case string, []any, map[string]any:
+			return true, nil
+		}
+		return false, nil
+	},
+	"sequence": func(v any, _ []any) (bool, error) {
+		switch v.(type) {
+		case string, []any, map[string]any:
+			return true, nil
+		}
+		return false, nil
+	},
+	"even": func(v any, _ []any) (bool, error) {
+		i, ok := v.(int64)
+		return ok && i%2 == 0, nil
+	},
+	"odd": func(v any, _ []any) (bool, error) {
+		i, ok := v.(int64)
+		return ok && i%2 != 0, nil
+	},
+	"divisibleby": func(v any, args []any) (bool, error) {
+		i, ok1 := v.(int64)
+		if len(args) != 1 {
+			return false, fmt.Errorf("divisibleby requires one argument")
+		}
+		d, ok2 := args[0].(int64)
+		if !ok1 || !ok2 || d == 0 {
+			return false, nil
+		}
+		return i%d == 0, nil
+	},
+	"eq":      equalTest,
+	"equalto": equalTest,
+	"==":      equalTest,
+	"sameas":  equalTest,
+	"ne": func(v any, args []any) (bool, error) {
+		eq, err := equalTest(v, args)
+		return !eq, err
+	},
+	"in": func(v any, args []any) (bool, error) {
+		if len(args) != 1 {
+			return false, fmt.Errorf("in requires one argument")
+		}
+		return contains(args[0], v)
+	},
+}
+
+func equalTest(v any, args []any) (bool, error) {
+	if len(args) != 1 {
+		return false, fmt.Errorf("equality test requires one argument")
+	}
+	return equal(v, args[0]), nil
+}
+
+// globals are functions available to every template
+var globals = map[string]callable{
+	"range": func(args []any, _ map[string]any) (any, error) {
+		var start, stop, step int64 = 0, 0, 1
+		ints := make([]int64, len(args))
+		for i := range args {
+			var ok bool
+			if ints[i], ok = args[i].(int64); !ok {
+				return nil, fmt.Errorf("range() arguments must be integers, not %s", typeName(args[i]))
+			}
+		}
+		switch len(ints) {
+		case 1:
+			stop = ints[0]
+		case 2:
+			start, stop = ints[0], ints[1]
+		case 3:
+			start, stop, step = ints[0], ints[1], ints[2]
+		default:
+			return nil, fmt.Errorf("range() expects 1 to 3 arguments, got %d", len(args))
+		}
+		if step == 0 {
+			return nil, fmt.Errorf("range() step must not be zero")
+		}
+		var result []any
+		for i := start; (step > 0 && i < stop) || (step < 0 && i > stop); i += step {
+			if len(result) >= maxRange {
+				return nil, fmt.Errorf("range() would produce more than %d items", maxRange)
+			}
+			result = append(result, i)
+			if (step > 0 && i > math.MaxInt64-step) || (step < 0 && i < math.MinInt64-step) {
+				break // i += step would overflow
+			}
+		}
+		return result, nil
+	},
+	"dict": func(_ []any, kwargs map[string]any) (any, error) {
+		return kwargs, nil
+	},
+}
+
+// method returns a bound method for the commonly used Python str and dict
+// methods, or nil.
+func method(target any, name string) callable {
+	switch t := target.(type) {
+	case map[string]any:
+		switch name {
+		case "items":
+			return func(_ []any, _ map[string]any) (any, error) {
+				return filterDictsort(t, nil, nil)
+			}
+		case "keys":
+			return func(_ []any, _ map[string]any) (any, error) {
+				return iterate(t)
+			}
+		case "values":
+			return func(_ []any, _ map[string]any) (any, error) {
+				result := make([]any, 0, len(t))
+				for _, k := range sortedKeys(t) {
+					result = append(result, t[k])
+				}
+				return result, nil
+			}
+		case "get":
+			return func(args []any, _ map[string]any) (any, error) {
+				if len(args) == 0 {
+					return nil, fmt.Errorf("get expected at least 1 argument")
+				}
+				if v, ok := t[toString(args[0])]; ok {
+					return v, nil
+				}
+				if len(args) > 1 {
+					return args[1], nil
+				}
+				return nil, nil
+			}
+		}
+	case string:
+		strArgs := func(args []any) []string {
+			result := make([]string, len(args))
+			for i := range args {
+				result[i] = toString(args[i])
+			}
+			return result
+		}
+		simple := map[string]func(string) string{
+			"upper":      strings.ToUpper,
+			"lower":      strings.ToLower,
+			"title":      title,
+			"capitalize": capitalize,
+		}
+		if f, ok := simple[name]; ok {
+			return func(_ []any, _ map[string]any) (any, error) { return f(t), nil }
+		}
+		switch name {
+		case "strip", "lstrip", "rstrip":
+			return func(args []any, _ map[string]any) (any, error) {
+				cutset := " \t\r\n"
+				if len(args) > 0 && args[0] != nil {
+					cutset = toString(args[0])
+				}
+				switch name {
+				case "lstrip":
+					return strings.TrimLeft(t, cutset), nil
+				case "rstrip":
+					return strings.TrimRight(t, cutset), nil
+				}
+				return strings.Trim(t, cutset), nil
+			}
+		case "split":
+			return func(args []any, _ map[string]any) (any, error) {
+				var parts []string
+				if len(args) == 0 || args[0] == nil {
+					parts = strings.Fields(t)
+				} else {
+					parts = strings.Split(t, toString(args[0]))
+				}
+				result := make([]any, len(parts))
+				for i := range parts {
+					result[i] = parts[i]
+				}
+				return result, nil
+			}
+		case "startswith":
+			return func(args []any, _ map[string]any) (any, error) {
+				for _, prefix := range strArgs(args) {
+					if strings.HasPrefix(t, prefix) {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+		case "endswith":
+			return func(args []any, _ map[string]any) (any, error) {
+				for _, suffix := range strArgs(args) {
+					if strings.HasSuffix(t, suffix) {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+		case "replace":
+			return func(args []any, kwargs map[string]any) (any, error) {
+				return filterReplace(t, args, kwargs)
+			}
+		case "format":
+			return func(args []any, _ map[string]any) (any, error) {
+				result := t
+				for _, a := range args {
+					result = strings.Replace(result, "{}", toString(a), 1)
+				}
+				return result, nil
+			}
+		}
+	}
+	return nil
+}
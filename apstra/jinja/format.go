@@ -0,0 +1,194 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jinja
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// percentFormat implements Python's printf-style string formatting
+// ('%s-%d' % (a, 1)), which is also used by the "format" filter. args may
+// be a list (a tuple in Python), a dict for "%(name)s" conversions, or a
+// single value.
+func percentFormat(format string, args any) (string, error) {
+	positional, ok := args.([]any)
+	if !ok {
+		positional = []any{args}
+	}
+	mapping, _ := args.(map[string]any)
+
+	next := func() (any, error) {
+		if len(positional) == 0 {
+			return nil, fmt.Errorf("not enough arguments for format string")
+		}
+		v := positional[0]
+		positional = positional[1:]
+		return v, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i >= len(format) {
+			return "", fmt.Errorf("incomplete format")
+		}
+
+		// mapping key
+		var v any
+		haveValue := false
+		if format[i] == '(' {
+			end := strings.IndexByte(format[i:], ')')
+			if end < 0 {
+				return "", fmt.Errorf("incomplete format key")
+			}
+			if mapping == nil {
+				return "", fmt.Errorf("format requires a mapping")
+			}
+			key := format[i+1 : i+end]
+			var ok bool
+			if v, ok = mapping[key]; !ok {
+				return "", fmt.Errorf("format key %q not found", key)
+			}
+			haveValue = true
+			i += end + 1
+		}
+
+		// flags
+		spec := "%"
+		for ; i < len(format) && strings.IndexByte("-+ #0", format[i]) >= 0; i++ {
+			spec += string(format[i])
+		}
+
+		// width and precision, either of which may be "*"
+		precision := -1
+		for _, part := range []string{"width", "precision"} {
+			if part == "precision" {
+				if i >= len(format) || format[i] != '.' {
+					break
+				}
+				spec += "."
+				i++
+			}
+			if i < len(format) && format[i] == '*' {
+				a, err := next()
+				if err != nil {
+					return "", err
+				}
+				n, ok := a.(int64)
+				if !ok {
+					return "", fmt.Errorf("* wants int, not %s", typeName(a))
+				}
+				spec += strconv.FormatInt(n, 10)
+				if part == "precision" {
+					precision = int(n)
+				}
+				i++
+				continue
+			}
+			start := i
+			for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+			}
+			spec += format[start:i]
+			if part == "precision" {
+				precision, _ = strconv.Atoi(format[start:i])
+			}
+		}
+
+		// length modifiers are accepted and ignored, as in Python
+		for ; i < len(format) && strings.IndexByte("hlL", format[i]) >= 0; i++ {
+		}
+		if i >= len(format) {
+			return "", fmt.Errorf("incomplete format")
+		}
+
+		conversion := format[i]
+		if conversion == '%' {
+			sb.WriteByte('%')
+			continue
+		}
+
+		if !haveValue {
+			var err error
+			if v, err = next(); err != nil {
+				return "", err
+			}
+		}
+
+		s, err := formatConversion(spec, precision, conversion, v)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(s)
+	}
+
+	// like Python, unused arguments are only an error when not a mapping
+	if len(positional) > 0 && mapping == nil {
+		return "", fmt.Errorf("not all arguments converted during string formatting")
+	}
+
+	return sb.String(), nil
+}
+
+// formatConversion renders v according to a single printf-style conversion.
+// spec holds the "%", flags, width and precision, which Go's fmt package
+// interprets the same way Python does.
+func formatConversion(spec string, precision int, conversion byte, v any) (string, error) {
+	switch conversion {
+	case 's':
+		return fmt.Sprintf(spec+"s", toString(v)), nil
+	case 'r', 'a':
+		return fmt.Sprintf(spec+"s", repr(v)), nil
+	case 'd', 'i', 'u', 'x', 'X', 'o':
+		i, ok := toInt(v)
+		if !ok {
+			return "", fmt.Errorf("%%%c format: a real number is required, not %s", conversion, typeName(v))
+		}
+		switch conversion {
+		case 'x', 'X':
+			return fmt.Sprintf(spec+string(conversion), i), nil
+		case 'o':
+			if strings.Contains(spec, "#") {
+				// Python's alternate octal form is "0o17", like Go's %O
+				return fmt.Sprintf(strings.Replace(spec, "#", "", 1)+"O", i), nil
+			}
+			return fmt.Sprintf(spec+"o", i), nil
+		}
+		return fmt.Sprintf(spec+"d", i), nil
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		f, ok := toFloat(v)
+		if !ok {
+			return "", fmt.Errorf("%%%c format: a real number is required, not %s", conversion, typeName(v))
+		}
+		if precision < 0 {
+			// Python defaults to 6 digits of precision, Go to the fewest
+			// digits needed to represent the value
+			spec += ".6"
+		}
+		if conversion == 'F' {
+			conversion = 'f'
+		}
+		return fmt.Sprintf(spec+string(conversion), f), nil
+	case 'c':
+		switch v := v.(type) {
+		case int64:
+			return fmt.Sprintf(spec+"c", rune(v)), nil
+		case string:
+			if len([]rune(v)) == 1 {
+				return fmt.Sprintf(spec+"s", v), nil
+			}
+		}
+		return "", fmt.Errorf("%%c requires int or char")
+	}
+
+	return "", fmt.Errorf("unsupported format character %q", conversion)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jinja renders the subset of the Jinja2 template language used by
+// Apstra freeform config templates, so that templates can be previewed and
+// tested without a running Apstra server.
+//
+// Supported: {{ }} expressions, {% if/elif/else %}, {% for %} (with else,
+// inline if, and the loop variable), {% set %}, {% include %}, {% macro %},
+// {% raw %}, comments, whitespace control ("-", TrimBlocks, LstripBlocks),
+// printf-style string formatting ('%s' % x and the "format" filter), and the
+// common filters and tests. Template inheritance ({% extends %}, {% block %})
+// and {% call %} are not supported. Integers are limited to 64 bits;
+// arithmetic which overflows produces an error.
+package jinja
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Environment is a collection of named templates which may include one
+// another.
+type Environment struct {
+	Templates       map[string]string // template source keyed by template name
+	TrimBlocks      bool              // remove the first newline after a block tag
+	LstripBlocks    bool              // strip leading whitespace before a block tag
+	StrictUndefined bool              // error rather than render undefined values as ""
+}
+
+// Render renders the named template with vars as its context. vars values
+// may be any data which can be marshaled to JSON.
+func (o *Environment) Render(name string, vars map[string]any) (string, error) {
+	nodes, err := o.parse(name)
+	if err != nil {
+		return "", err
+	}
+
+	root := &scope{vars: make(map[string]any, len(vars))}
+	for k, v := range vars {
+		if root.vars[k], err = normalize(v); err != nil {
+			return "", fmt.Errorf("failed normalizing template variable %q - %w", k, err)
+		}
+	}
+
+	var sb strings.Builder
+	r := renderer{env: o, name: name, macroDepth: new(int)}
+	if err = r.renderNodes(&sb, nodes, root.child()); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// RenderString renders src (which may include templates from the
+// environment) with vars as its context.
+func (o *Environment) RenderString(src string, vars map[string]any) (string, error) {
+	env := Environment{
+		Templates:       map[string]string{"<string>": src},
+		TrimBlocks:      o.TrimBlocks,
+		LstripBlocks:    o.LstripBlocks,
+		StrictUndefined: o.StrictUndefined,
+	}
+	for k, v := range o.Templates {
+		if k != "<string>" {
+			env.Templates[k] = v
+		}
+	}
+	return env.Render("<string>", vars)
+}
+
+func (o *Environment) parse(name string) ([]node, error) {
+	src, ok := o.Templates[name]
+	if !ok {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+
+	segments, err := split(name, src, o.TrimBlocks, o.LstripBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(name, segments)
+}
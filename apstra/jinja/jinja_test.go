@@ -0,0 +1,141 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jinja
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	vars := map[string]any{
+		"hostname": "leaf1",
+		"asn":      65001,
+		"mtu":      9216.0,
+		"enabled":  true,
+		"vlans":    []int{10, 20, 30},
+		"interfaces": map[string]any{
+			"xe-0/0/1": map[string]any{"description": "to spine1", "ipv4": "10.0.0.1/31"},
+			"xe-0/0/0": map[string]any{"description": "to spine2", "ipv4": "10.0.0.3/31"},
+		},
+		"servers": []map[string]any{
+			{"name": "b", "rack": 2},
+			{"name": "a", "rack": 1},
+			{"name": "c", "rack": 2},
+		},
+	}
+
+	type testCase struct {
+		template string
+		expected string
+	}
+
+	testCases := map[string]testCase{
+		"variable":              {template: "hostname {{ hostname }}", expected: "hostname leaf1"},
+		"arithmetic":            {template: "{{ asn + 1 }} {{ 7 // 2 }} {{ 7 / 2 }} {{ -7 % 3 }} {{ 2 ** 10 }}", expected: "65002 3 3.5 2 1024"},
+		"float":                 {template: "{{ mtu }} {{ mtu | int }}", expected: "9216.0 9216"},
+		"bool":                  {template: "{{ enabled }} {{ not enabled }} {{ none }}", expected: "True False None"},
+		"list":                  {template: "{{ vlans }} {{ ['a', 1] }}", expected: "[10, 20, 30] ['a', 1]"},
+		"concat":                {template: "{{ hostname ~ '-' ~ asn }}", expected: "leaf1-65001"},
+		"ternary":               {template: "{{ 'up' if enabled else 'down' }}", expected: "up"},
+		"attr_and_index":        {template: "{{ interfaces['xe-0/0/0'].description }} {{ vlans[-1] }} {{ vlans[1:] }}", expected: "to spine2 30 [20, 30]"},
+		"undefined":             {template: "[{{ missing }}][{{ missing.attr }}][{{ missing | default('x') }}]", expected: "[][][x]"},
+		"tests":                 {template: "{{ missing is defined }} {{ asn is odd }} {{ 9 is divisibleby 3 }} {{ hostname is not none }}", expected: "False True True True"},
+		"in":                    {template: "{{ 20 in vlans }} {{ 'leaf' in hostname }} {{ 'xe-0/0/9' not in interfaces }}", expected: "True True True"},
+		"filters":               {template: "{{ vlans | join(',') }} {{ hostname | upper }} {{ vlans | length }} {{ vlans | sum }} {{ vlans | reverse | first }}", expected: "10,20,30 LEAF1 3 60 30"},
+		"sort_attribute":        {template: "{{ servers | sort(attribute='name') | map(attribute='name') | join }}", expected: "abc"},
+		"selectattr":            {template: "{{ servers | selectattr('rack', 'equalto', 2) | map(attribute='name') | list }}", expected: "['b', 'c']"},
+		"unique":                {template: "{{ servers | map(attribute='rack') | unique | list }}", expected: "[2, 1]"},
+		"string_methods":        {template: "{{ ' a b '.strip().split(' ') }} {{ hostname.startswith('leaf') }} {{ 'x-y'.replace('-', '_') }}", expected: "['a', 'b'] True x_y"},
+		"dict_items":            {template: "{% for k, v in interfaces.items() %}{{ k }}={{ v.ipv4 }};{% endfor %}", expected: "xe-0/0/0=10.0.0.3/31;xe-0/0/1=10.0.0.1/31;"},
+		"dict_iteration_sorted": {template: "{% for k in interfaces %}{{ k }} {% endfor %}", expected: "xe-0/0/0 xe-0/0/1 "},
+		"loop_variable":         {template: "{% for v in vlans %}{{ loop.index }}:{{ v }}{% if not loop.last %},{% endif %}{% endfor %}", expected: "1:10,2:20,3:30"},
+		"loop_filter_else":      {template: "{% for v in vlans if v > 100 %}{{ v }}{% else %}none{% endfor %}", expected: "none"},
+		"if_elif_else":          {template: "{% if asn < 100 %}a{% elif asn < 70000 %}b{% else %}c{% endif %}", expected: "b"},
+		"set_scope":             {template: "{% set x = 1 %}{% for v in vlans %}{% set x = v %}{% endfor %}{{ x }}", expected: "1"},
+		"set_unpack":            {template: "{% set a, b = [1, 2] %}{{ b }}{{ a }}", expected: "21"},
+		"range":                 {template: "{% for i in range(1, 4) %}{{ i }}{% endfor %}", expected: "123"},
+		"repeat":                {template: "{{ 'ab' * 3 }}|{{ 'ab' * -1 }}|{{ range(100000) | length }}", expected: "ababab||100000"},
+		"macro":                 {template: "{% macro intf(name, unit=0) %}{{ name }}.{{ unit }}{% endmacro %}{{ intf('ae1') }} {{ intf('ae2', unit=5) }}", expected: "ae1.0 ae2.5"},
+		"comment":               {template: "a{# comment {{ not rendered }} #}b", expected: "ab"},
+		"raw":                   {template: "{% raw %}{{ hostname }}{% endraw %}", expected: "{{ hostname }}"},
+		"whitespace_control":    {template: "a  {{- hostname -}}  \n b", expected: "aleaf1b"},
+		"indent":                {template: "{{ 'a\nb\n\nc' | indent(2) }}", expected: "a\n  b\n\n  c"},
+		"dictsort":              {template: "{{ {'b': 1, 'a': 2} | dictsort }}", expected: "[['a', 2], ['b', 1]]"},
+		"percent_format":        {template: "{{ '%s-%03d' % (hostname, 7) }} {{ '%.2f%%' % 12.5 }} {{ '%(a)s' % {'a': asn} }} {{ '%x|%-4s|' % (255, 'ab') }}", expected: "leaf1-007 12.50% 65001 ff|ab  |"},
+		"format_filter":         {template: "{{ '%s:%s' | format(hostname, asn) }} {{ '%(x)s' | format(x='y') }}", expected: "leaf1:65001 y"},
+		"tojson":                {template: "{{ {'b': [1, 'x'], 'a': none} | tojson }}", expected: `{"a":null,"b":[1,"x"]}`},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			env := Environment{}
+			result, err := env.RenderString(tCase.template, vars)
+			require.NoError(t, err)
+			require.Equal(t, tCase.expected, result)
+		})
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	env := Environment{
+		Templates: map[string]string{
+			"main.jinja": "" +
+				"hostname {{ hostname }}\n" +
+				"{% for v in vlans %}\n" +
+				"  {% include 'vlan.jinja' %}\n" +
+				"{% endfor %}\n",
+			"vlan.jinja": "vlan {{ v }}\n",
+		},
+		TrimBlocks:   true,
+		LstripBlocks: true,
+	}
+
+	result, err := env.Render("main.jinja", map[string]any{"hostname": "leaf1", "vlans": []int{10, 20}})
+	require.NoError(t, err)
+	require.Equal(t, "hostname leaf1\nvlan 10\nvlan 20\n", result)
+}
+
+func TestRenderErrors(t *testing.T) {
+	type testCase struct {
+		template string
+		strict   bool
+		expected string
+	}
+
+	testCases := map[string]testCase{
+		"unclosed_tag":       {template: "line 1\n{{ hostname", expected: "<string>:2: unclosed \"{{\""},
+		"missing_endif":      {template: "{% if true %}\nx", expected: "<string>:1: missing {% endif %}"},
+		"unexpected_end":     {template: "x\n{% endfor %}", expected: "<string>:2: unexpected {% endfor %}"},
+		"bad_expression":     {template: "{{ a + }}", expected: "<string>:1: unexpected end of expression"},
+		"unsupported_filter": {template: "{{ a | nonexistent }}", expected: "unsupported filter \"nonexistent\""},
+		"missing_include":    {template: "{% include 'nope' %}", expected: "template \"nope\" not found"},
+		"strict_undefined":   {template: "\n{{ system.hostname }}", strict: true, expected: "<string>:2: \"system\" is undefined"},
+		"recursive_include":  {template: "{% include '<string>' %}", expected: "recursive include"},
+		"division_by_zero":   {template: "{{ 1 // 0 }}", expected: "division by zero"},
+		"recursive_macro":    {template: "{% macro f(n) %}{{ f(n) }}{% endmacro %}{{ f(1) }}", expected: "macro call depth exceeds"},
+		"pow_overflow":       {template: "{{ 2 ** 100000 }}", expected: "integer overflow"},
+		"mul_overflow":       {template: "{{ 4611686018427387904 * 2 }}", expected: "integer overflow"},
+		"format_type":        {template: "{{ '%d' % 'x' }}", expected: "a real number is required"},
+		"format_arguments":   {template: "{{ '%s %s' % ['a'] }}", expected: "not enough arguments"},
+		"repeat_too_long":    {template: "{{ 'x' * 1000000000000 }}", expected: "repeated string would exceed"},
+		"indent_too_wide":    {template: "{{ 'a\nb' | indent(1000000000000) }}", expected: "repeated string would exceed"},
+		"range_too_long":     {template: "{{ range(1000000000) | length }}", expected: "range() would produce more than"},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			env := Environment{StrictUndefined: tCase.strict}
+			_, err := env.RenderString(tCase.template, nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tCase.expected)
+		})
+	}
+}
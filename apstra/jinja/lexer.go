@@ -0,0 +1,284 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jinja
+
+import (
+	"fmt"
+	"strings"
+)
+
+type segmentType int
+
+const (
+	segmentText segmentType = iota
+	segmentVariable
+	segmentBlock
+)
+
+// segment is a run of template text, or the content of a {{ }} or {% %} tag.
+type segment struct {
+	typ  segmentType
+	text string
+	line int
+}
+
+// split breaks template source into segments, applying whitespace control
+// ("{%-", "-%}", etc...) and the trimBlocks / lstripBlocks options. Comments
+// are discarded.
+func split(name, src string, trimBlocks, lstripBlocks bool) ([]segment, error) {
+	var result []segment
+	line := 1
+	pos := 0
+	rstripNext := false // a "-" closed the previous tag
+	trimNext := false   // trimBlocks applies to the text following the previous tag
+
+	for pos < len(src) {
+		// find the next tag opener
+		next := -1
+		for _, opener := range []string{"{{", "{%", "{#"} {
+			if i := strings.Index(src[pos:], opener); i >= 0 && (next < 0 || i < next) {
+				next = i
+			}
+		}
+
+		text := src[pos:]
+		if next >= 0 {
+			text = src[pos : pos+next]
+		}
+
+		if rstripNext {
+			text = strings.TrimLeft(text, " \t\r\n")
+		} else if trimNext && strings.HasPrefix(text, "\n") {
+			text = text[1:]
+		} else if trimNext && strings.HasPrefix(text, "\r\n") {
+			text = text[2:]
+		}
+		rstripNext, trimNext = false, false
+
+		if next < 0 {
+			result = appendText(result, text, line)
+			break
+		}
+
+		opener := src[pos+next : pos+next+2]
+		tagStart := pos + next + 2
+		tagLine := line + strings.Count(src[pos:pos+next], "\n")
+
+		lstrip := strings.HasPrefix(src[tagStart:], "-")
+		if lstrip {
+			text = strings.TrimRight(text, " \t\r\n")
+			tagStart++
+		} else if lstripBlocks && opener != "{{" {
+			// strip spaces and tabs between the start of the line and the tag
+			lineStart := strings.LastIndexByte(src[:pos+next], '\n') + 1
+			if strings.Trim(src[lineStart:pos+next], " \t") == "" {
+				text = strings.TrimRight(text, " \t")
+			}
+		}
+		result = appendText(result, text, line)
+
+		closer := map[string]string{"{{": "}}", "{%": "%}", "{#": "#}"}[opener]
+		end := findCloser(src, tagStart, closer, opener == "{#")
+		if end < 0 {
+			return nil, fmt.Errorf("%s:%d: unclosed %q", name, tagLine, opener)
+		}
+
+		content := src[tagStart:end]
+		if strings.HasSuffix(content, "-") {
+			content = content[:len(content)-1]
+			rstripNext = true
+		}
+
+		switch opener {
+		case "{{":
+			result = append(result, segment{typ: segmentVariable, text: strings.TrimSpace(content), line: tagLine})
+		case "{%":
+			content = strings.TrimSpace(content)
+			if content == "raw" {
+				// everything up to {% endraw %} is literal text
+				rawEnd, afterEnd, ok := findEndRaw(src, end+2)
+				if !ok {
+					return nil, fmt.Errorf("%s:%d: unclosed raw block", name, tagLine)
+				}
+				result = appendText(result, src[end+2:rawEnd], tagLine)
+				line = tagLine + strings.Count(src[tagStart:afterEnd], "\n")
+				pos = afterEnd
+				trimNext = trimBlocks
+				continue
+			}
+			result = append(result, segment{typ: segmentBlock, text: content, line: tagLine})
+			trimNext = trimBlocks
+		case "{#":
+			trimNext = trimBlocks
+		}
+
+		line = tagLine + strings.Count(src[pos+next:end+2], "\n")
+		pos = end + 2
+	}
+
+	return result, nil
+}
+
+func appendText(segments []segment, text string, line int) []segment {
+	if text == "" {
+		return segments
+	}
+	return append(segments, segment{typ: segmentText, text: text, line: line})
+}
+
+// findCloser returns the index of closer in src at or after start, skipping
+// over quoted strings unless ignoreQuotes is set (comments may contain
+// unbalanced quotes). Returns -1 if not found.
+func findCloser(src string, start int, closer string, ignoreQuotes bool) int {
+	var quote byte
+	for i := start; i < len(src)-1; i++ {
+		c := src[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case !ignoreQuotes && (c == '\'' || c == '"'):
+			quote = c
+		case src[i:i+2] == closer:
+			return i
+		}
+	}
+	return -1
+}
+
+// findEndRaw locates the "{% endraw %}" tag after start, returning the
+// offset of the tag and the offset just past it.
+func findEndRaw(src string, start int) (int, int, bool) {
+	for i := start; i < len(src); {
+		j := strings.Index(src[i:], "{%")
+		if j < 0 {
+			return 0, 0, false
+		}
+		tagStart := i + j
+		end := findCloser(src, tagStart+2, "%}", false)
+		if end < 0 {
+			return 0, 0, false
+		}
+		if strings.Trim(src[tagStart+2:end], " \t\r\n-") == "endraw" {
+			return tagStart, end + 2, true
+		}
+		i = end + 2
+	}
+	return 0, 0, false
+}
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenName
+	tokenString
+	tokenInt
+	tokenFloat
+	tokenOperator
+)
+
+type token struct {
+	typ   tokenType
+	value string
+}
+
+func (o token) String() string {
+	if o.typ == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", o.value)
+}
+
+var operators = []string{
+	"//", "**", "==", "!=", "<=", ">=",
+	"+", "-", "*", "/", "%", "~", "<", ">", "=",
+	"(", ")", "[", "]", "{", "}", ".", ",", ":", "|",
+}
+
+// tokenize breaks the content of a tag into expression tokens.
+func tokenize(src string) ([]token, error) {
+	var result []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '_' || isLetter(c):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || isLetter(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			result = append(result, token{typ: tokenName, value: src[i:j]})
+			i = j
+		case isDigit(c):
+			j := i + 1
+			typ := tokenInt
+			for j < len(src) && (isDigit(src[j]) || src[j] == '_') {
+				j++
+			}
+			if j+1 < len(src) && src[j] == '.' && isDigit(src[j+1]) {
+				typ = tokenFloat
+				j++
+				for j < len(src) && isDigit(src[j]) {
+					j++
+				}
+			}
+			result = append(result, token{typ: typ, value: strings.ReplaceAll(src[i:j], "_", "")})
+			i = j
+		case c == '\'' || c == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+					switch src[j] {
+					case 'n':
+						sb.WriteByte('\n')
+					case 't':
+						sb.WriteByte('\t')
+					case 'r':
+						sb.WriteByte('\r')
+					default:
+						sb.WriteByte(src[j])
+					}
+					continue
+				}
+				sb.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			result = append(result, token{typ: tokenString, value: sb.String()})
+			i = j + 1
+		default:
+			var found bool
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					result = append(result, token{typ: tokenOperator, value: op})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+		}
+	}
+
+	return append(result, token{typ: tokenEOF}), nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,744 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jinja
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// expression nodes
+
+type expr interface{}
+
+type (
+	literalExpr struct{ value any }
+	nameExpr    struct{ name string }
+	listExpr    struct{ items []expr }
+	dictExpr    struct{ keys, values []expr }
+	attrExpr    struct {
+		target expr
+		name   string
+	}
+	indexExpr struct{ target, index expr }
+	sliceExpr struct{ target, start, stop expr }
+	callExpr  struct {
+		target expr
+		args   []expr
+		kwargs map[string]expr
+	}
+	filterExpr struct {
+		target expr
+		name   string
+		args   []expr
+		kwargs map[string]expr
+	}
+	testExpr struct {
+		target expr
+		name   string
+		args   []expr
+		negate bool
+	}
+	unaryExpr struct {
+		op      string
+		operand expr
+	}
+	binaryExpr struct {
+		op          string
+		left, right expr
+	}
+	condExpr struct{ cond, then, otherwise expr }
+)
+
+// statement nodes
+
+type node interface{}
+
+type (
+	textNode   struct{ text string }
+	outputNode struct {
+		expr expr
+		line int
+	}
+	ifNode struct {
+		conds    []expr
+		branches [][]node
+		line     int
+	}
+	forNode struct {
+		targets   []string
+		iter      expr
+		filter    expr
+		body      []node
+		otherwise []node
+		line      int
+	}
+	setNode struct {
+		targets []string
+		value   expr
+		line    int
+	}
+	includeNode struct {
+		name          expr
+		ignoreMissing bool
+		line          int
+	}
+	macroNode struct {
+		name     string
+		params   []string
+		defaults map[string]expr
+		body     []node
+	}
+)
+
+// parser builds a node tree from template segments
+type parser struct {
+	name     string
+	segments []segment
+	pos      int
+}
+
+func parse(name string, segments []segment) ([]node, error) {
+	p := parser{name: name, segments: segments}
+	nodes, end, err := p.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	if end != nil {
+		return nil, p.errorf(end.line, "unexpected {%% %s %%}", end.text)
+	}
+	return nodes, nil
+}
+
+func (o *parser) errorf(line int, format string, a ...any) error {
+	return fmt.Errorf("%s:%d: %s", o.name, line, fmt.Sprintf(format, a...))
+}
+
+// parseBody parses nodes until it reaches a block tag it doesn't handle
+// (endif, else, etc...), which is returned to the caller, or the end of input
+// (nil segment).
+func (o *parser) parseBody() ([]node, *segment, error) {
+	var result []node
+	for o.pos < len(o.segments) {
+		seg := o.segments[o.pos]
+		o.pos++
+
+		switch seg.typ {
+		case segmentText:
+			result = append(result, &textNode{text: seg.text})
+		case segmentVariable:
+			e, err := o.parseExprString(seg.text, seg.line)
+			if err != nil {
+				return nil, nil, err
+			}
+			result = append(result, &outputNode{expr: e, line: seg.line})
+		case segmentBlock:
+			keyword, _, _ := strings.Cut(seg.text, " ")
+			var n node
+			var err error
+			switch keyword {
+			case "if":
+				n, err = o.parseIf(seg)
+			case "for":
+				n, err = o.parseFor(seg)
+			case "set":
+				n, err = o.parseSet(seg)
+			case "include":
+				n, err = o.parseInclude(seg)
+			case "macro":
+				n, err = o.parseMacro(seg)
+			default:
+				return result, &seg, nil
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			result = append(result, n)
+		}
+	}
+
+	return result, nil, nil
+}
+
+func (o *parser) parseExprString(src string, line int) (expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, o.errorf(line, "%s", err)
+	}
+	ep := exprParser{tokens: tokens}
+	e, err := ep.parseExpr()
+	if err == nil && ep.peek().typ != tokenEOF {
+		err = fmt.Errorf("unexpected %s", ep.peek())
+	}
+	if err != nil {
+		return nil, o.errorf(line, "%s in %q", err, src)
+	}
+	return e, nil
+}
+
+func keywordArgs(seg segment, keyword string) string {
+	return strings.TrimSpace(strings.TrimPrefix(seg.text, keyword))
+}
+
+func (o *parser) parseIf(seg segment) (node, error) {
+	result := ifNode{line: seg.line}
+	cond, err := o.parseExprString(keywordArgs(seg, "if"), seg.line)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		body, end, err := o.parseBody()
+		if err != nil {
+			return nil, err
+		}
+		if end == nil {
+			return nil, o.errorf(seg.line, "missing {%% endif %%}")
+		}
+		result.conds = append(result.conds, cond)
+		result.branches = append(result.branches, body)
+
+		keyword, rest, _ := strings.Cut(end.text, " ")
+		switch keyword {
+		case "elif":
+			cond, err = o.parseExprString(rest, end.line)
+			if err != nil {
+				return nil, err
+			}
+		case "else":
+			cond = &literalExpr{value: true}
+		case "endif":
+			return &result, nil
+		default:
+			return nil, o.errorf(end.line, "unexpected {%% %s %%} in if block", end.text)
+		}
+	}
+}
+
+func (o *parser) parseFor(seg segment) (node, error) {
+	result := forNode{line: seg.line}
+
+	tokens, err := tokenize(keywordArgs(seg, "for"))
+	if err != nil {
+		return nil, o.errorf(seg.line, "%s", err)
+	}
+	ep := exprParser{tokens: tokens}
+	result.targets, err = ep.parseTargets()
+	if err != nil {
+		return nil, o.errorf(seg.line, "%s", err)
+	}
+	if !ep.acceptName("in") {
+		return nil, o.errorf(seg.line, "expected 'in' in for loop, got %s", ep.peek())
+	}
+	// the iterable must not swallow a trailing "if" filter as a conditional expression
+	result.iter, err = ep.parseOr()
+	if err == nil && ep.acceptName("if") {
+		result.filter, err = ep.parseOr()
+	}
+	if err == nil && ep.peek().typ != tokenEOF {
+		err = fmt.Errorf("unexpected %s", ep.peek())
+	}
+	if err != nil {
+		return nil, o.errorf(seg.line, "%s in for loop", err)
+	}
+
+	body, end, err := o.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	if end != nil && end.text == "else" {
+		result.otherwise, end, err = o.parseBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if end == nil || end.text != "endfor" {
+		return nil, o.errorf(seg.line, "missing {%% endfor %%}")
+	}
+	result.body = body
+
+	return &result, nil
+}
+
+func (o *parser) parseSet(seg segment) (node, error) {
+	tokens, err := tokenize(keywordArgs(seg, "set"))
+	if err != nil {
+		return nil, o.errorf(seg.line, "%s", err)
+	}
+	ep := exprParser{tokens: tokens}
+	targets, err := ep.parseTargets()
+	if err != nil {
+		return nil, o.errorf(seg.line, "%s", err)
+	}
+	if !ep.acceptOp("=") {
+		return nil, o.errorf(seg.line, "block assignment ({%% set %%} without '=') is not supported")
+	}
+	value, err := ep.parseExpr()
+	if err == nil && ep.peek().typ != tokenEOF {
+		err = fmt.Errorf("unexpected %s", ep.peek())
+	}
+	if err != nil {
+		return nil, o.errorf(seg.line, "%s in set", err)
+	}
+	return &setNode{targets: targets, value: value, line: seg.line}, nil
+}
+
+func (o *parser) parseInclude(seg segment) (node, error) {
+	args := keywordArgs(seg, "include")
+	result := includeNode{line: seg.line}
+	if trimmed := strings.TrimSuffix(args, " ignore missing"); trimmed != args {
+		result.ignoreMissing = true
+		args = trimmed
+	}
+	var err error
+	result.name, err = o.parseExprString(args, seg.line)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (o *parser) parseMacro(seg segment) (node, error) {
+	tokens, err := tokenize(keywordArgs(seg, "macro"))
+	if err != nil {
+		return nil, o.errorf(seg.line, "%s", err)
+	}
+	ep := exprParser{tokens: tokens}
+	result := macroNode{defaults: make(map[string]expr)}
+
+	name := ep.next()
+	if name.typ != tokenName || !ep.acceptOp("(") {
+		return nil, o.errorf(seg.line, "malformed macro declaration")
+	}
+	result.name = name.value
+	for !ep.acceptOp(")") {
+		param := ep.next()
+		if param.typ != tokenName {
+			return nil, o.errorf(seg.line, "expected macro parameter name, got %s", param)
+		}
+		result.params = append(result.params, param.value)
+		if ep.acceptOp("=") {
+			d, err := ep.parseExpr()
+			if err != nil {
+				return nil, o.errorf(seg.line, "%s in macro default", err)
+			}
+			result.defaults[param.value] = d
+		}
+		if !ep.acceptOp(",") && ep.peek().value != ")" {
+			return nil, o.errorf(seg.line, "expected ',' or ')' in macro declaration, got %s", ep.peek())
+		}
+	}
+
+	body, end, err := o.parseBody()
+	if err != nil {
+		return nil, err
+	}
+	if end == nil || end.text != "endmacro" {
+		return nil, o.errorf(seg.line, "missing {%% endmacro %%}")
+	}
+	result.body = body
+
+	return &result, nil
+}
+
+// exprParser is a recursive descent parser for Jinja expressions
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (o *exprParser) peek() token {
+	return o.tokens[o.pos]
+}
+
+func (o *exprParser) next() token {
+	t := o.tokens[o.pos]
+	if t.typ != tokenEOF {
+		o.pos++
+	}
+	return t
+}
+
+func (o *exprParser) acceptOp(op string) bool {
+	if t := o.peek(); t.typ == tokenOperator && t.value == op {
+		o.pos++
+		return true
+	}
+	return false
+}
+
+func (o *exprParser) acceptName(name string) bool {
+	if t := o.peek(); t.typ == tokenName && t.value == name {
+		o.pos++
+		return true
+	}
+	return false
+}
+
+func (o *exprParser) expectOp(op string) error {
+	if !o.acceptOp(op) {
+		return fmt.Errorf("expected %q, got %s", op, o.peek())
+	}
+	return nil
+}
+
+// parseTargets parses the comma separated variable names on the left side
+// of a for loop or set statement.
+func (o *exprParser) parseTargets() ([]string, error) {
+	var result []string
+	parens := o.acceptOp("(")
+	for {
+		t := o.next()
+		if t.typ != tokenName {
+			return nil, fmt.Errorf("expected variable name, got %s", t)
+		}
+		result = append(result, t.value)
+		if !o.acceptOp(",") {
+			break
+		}
+	}
+	if parens {
+		if err := o.expectOp(")"); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (o *exprParser) parseExpr() (expr, error) {
+	e, err := o.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.acceptName("if") {
+		cond, err := o.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		var otherwise expr = &literalExpr{value: Undefined{name: "else branch"}}
+		if o.acceptName("else") {
+			otherwise, err = o.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &condExpr{cond: cond, then: e, otherwise: otherwise}, nil
+	}
+
+	return e, nil
+}
+
+func (o *exprParser) parseOr() (expr, error) {
+	left, err := o.parseAnd()
+	for err == nil && o.acceptName("or") {
+		var right expr
+		right, err = o.parseAnd()
+		left = &binaryExpr{op: "or", left: left, right: right}
+	}
+	return left, err
+}
+
+func (o *exprParser) parseAnd() (expr, error) {
+	left, err := o.parseNot()
+	for err == nil && o.acceptName("and") {
+		var right expr
+		right, err = o.parseNot()
+		left = &binaryExpr{op: "and", left: left, right: right}
+	}
+	return left, err
+}
+
+func (o *exprParser) parseNot() (expr, error) {
+	if o.acceptName("not") {
+		operand, err := o.parseNot()
+		return &unaryExpr{op: "not", operand: operand}, err
+	}
+	return o.parseCompare()
+}
+
+func (o *exprParser) parseCompare() (expr, error) {
+	left, err := o.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := o.peek()
+		var op string
+		switch {
+		case t.typ == tokenOperator && (t.value == "==" || t.value == "!=" || t.value == "<" || t.value == ">" || t.value == "<=" || t.value == ">="):
+			op = t.value
+			o.pos++
+		case t.typ == tokenName && t.value == "in":
+			op = "in"
+			o.pos++
+		case t.typ == tokenName && t.value == "not" && o.tokens[o.pos+1].typ == tokenName && o.tokens[o.pos+1].value == "in":
+			op = "not in"
+			o.pos += 2
+		case t.typ == tokenName && t.value == "is":
+			o.pos++
+			test := testExpr{target: left, negate: o.acceptName("not")}
+			name := o.next()
+			if name.typ != tokenName {
+				return nil, fmt.Errorf("expected test name after 'is', got %s", name)
+			}
+			test.name = name.value
+			if o.acceptOp("(") {
+				test.args, _, err = o.parseArgs()
+				if err != nil {
+					return nil, err
+				}
+			} else if arg := o.peek(); arg.typ == tokenString || arg.typ == tokenInt || arg.typ == tokenFloat {
+				// e.g. "x is divisibleby 3"
+				e, err := o.parsePrimary()
+				if err != nil {
+					return nil, err
+				}
+				test.args = []expr{e}
+			}
+			left = &test
+			continue
+		default:
+			return left, nil
+		}
+
+		right, err := o.parseConcat()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (o *exprParser) parseConcat() (expr, error) {
+	left, err := o.parseAdd()
+	for err == nil && o.acceptOp("~") {
+		var right expr
+		right, err = o.parseAdd()
+		left = &binaryExpr{op: "~", left: left, right: right}
+	}
+	return left, err
+}
+
+func (o *exprParser) parseAdd() (expr, error) {
+	left, err := o.parseMul()
+	for err == nil {
+		t := o.peek()
+		if t.typ != tokenOperator || (t.value != "+" && t.value != "-") {
+			break
+		}
+		o.pos++
+		var right expr
+		right, err = o.parseMul()
+		left = &binaryExpr{op: t.value, left: left, right: right}
+	}
+	return left, err
+}
+
+func (o *exprParser) parseMul() (expr, error) {
+	left, err := o.parseUnary()
+	for err == nil {
+		t := o.peek()
+		if t.typ != tokenOperator || (t.value != "*" && t.value != "/" && t.value != "//" && t.value != "%" && t.value != "**") {
+			break
+		}
+		o.pos++
+		var right expr
+		right, err = o.parseUnary()
+		left = &binaryExpr{op: t.value, left: left, right: right}
+	}
+	return left, err
+}
+
+func (o *exprParser) parseUnary() (expr, error) {
+	if o.acceptOp("-") {
+		operand, err := o.parseUnary()
+		return &unaryExpr{op: "-", operand: operand}, err
+	}
+	if o.acceptOp("+") {
+		return o.parseUnary()
+	}
+	return o.parseFiltered()
+}
+
+func (o *exprParser) parseFiltered() (expr, error) {
+	e, err := o.parsePostfix()
+	for err == nil && o.acceptOp("|") {
+		name := o.next()
+		if name.typ != tokenName {
+			return nil, fmt.Errorf("expected filter name, got %s", name)
+		}
+		f := filterExpr{target: e, name: name.value}
+		if o.acceptOp("(") {
+			f.args, f.kwargs, err = o.parseArgs()
+		}
+		e = &f
+	}
+	return e, err
+}
+
+func (o *exprParser) parsePostfix() (expr, error) {
+	e, err := o.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case o.acceptOp("."):
+			name := o.next()
+			if name.typ != tokenName && name.typ != tokenInt {
+				return nil, fmt.Errorf("expected attribute name, got %s", name)
+			}
+			e = &attrExpr{target: e, name: name.value}
+		case o.acceptOp("["):
+			var start expr
+			if o.peek().value != ":" {
+				start, err = o.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+			}
+			if o.acceptOp(":") {
+				var stop expr
+				if o.peek().value != "]" {
+					stop, err = o.parseExpr()
+					if err != nil {
+						return nil, err
+					}
+				}
+				e = &sliceExpr{target: e, start: start, stop: stop}
+			} else {
+				e = &indexExpr{target: e, index: start}
+			}
+			if err = o.expectOp("]"); err != nil {
+				return nil, err
+			}
+		case o.acceptOp("("):
+			c := callExpr{target: e}
+			c.args, c.kwargs, err = o.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			e = &c
+		default:
+			return e, nil
+		}
+	}
+}
+
+// parseArgs parses call arguments following an opening parenthesis,
+// consuming the closing parenthesis.
+func (o *exprParser) parseArgs() ([]expr, map[string]expr, error) {
+	var args []expr
+	kwargs := make(map[string]expr)
+	for !o.acceptOp(")") {
+		if t := o.peek(); t.typ == tokenName && o.tokens[o.pos+1].value == "=" && o.tokens[o.pos+1].typ == tokenOperator {
+			o.pos += 2
+			value, err := o.parseExpr()
+			if err != nil {
+				return nil, nil, err
+			}
+			kwargs[t.value] = value
+		} else {
+			value, err := o.parseExpr()
+			if err != nil {
+				return nil, nil, err
+			}
+			args = append(args, value)
+		}
+		if !o.acceptOp(",") && o.peek().value != ")" {
+			return nil, nil, fmt.Errorf("expected ',' or ')', got %s", o.peek())
+		}
+	}
+	return args, kwargs, nil
+}
+
+func (o *exprParser) parsePrimary() (expr, error) {
+	t := o.next()
+	switch t.typ {
+	case tokenString:
+		// adjacent string literals are concatenated
+		s := t.value
+		for o.peek().typ == tokenString {
+			s += o.next().value
+		}
+		return &literalExpr{value: s}, nil
+	case tokenInt:
+		i, err := strconv.ParseInt(t.value, 10, 64)
+		return &literalExpr{value: i}, err
+	case tokenFloat:
+		f, err := strconv.ParseFloat(t.value, 64)
+		return &literalExpr{value: f}, err
+	case tokenName:
+		switch t.value {
+		case "true", "True":
+			return &literalExpr{value: true}, nil
+		case "false", "False":
+			return &literalExpr{value: false}, nil
+		case "none", "None":
+			return &literalExpr{value: nil}, nil
+		}
+		return &nameExpr{name: t.value}, nil
+	case tokenOperator:
+		switch t.value {
+		case "(":
+			e, err := o.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if o.peek().value == "," {
+				// tuple, treated as a list
+				items := []expr{e}
+				for o.acceptOp(",") && o.peek().value != ")" {
+					item, err := o.parseExpr()
+					if err != nil {
+						return nil, err
+					}
+					items = append(items, item)
+				}
+				e = &listExpr{items: items}
+			}
+			return e, o.expectOp(")")
+		case "[":
+			var result listExpr
+			for !o.acceptOp("]") {
+				item, err := o.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				result.items = append(result.items, item)
+				if !o.acceptOp(",") && o.peek().value != "]" {
+					return nil, fmt.Errorf("expected ',' or ']', got %s", o.peek())
+				}
+			}
+			return &result, nil
+		case "{":
+			var result dictExpr
+			for !o.acceptOp("}") {
+				key, err := o.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				if err = o.expectOp(":"); err != nil {
+					return nil, err
+				}
+				value, err := o.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				result.keys = append(result.keys, key)
+				result.values = append(result.values, value)
+				if !o.acceptOp(",") && o.peek().value != "}" {
+					return nil, fmt.Errorf("expected ',' or '}', got %s", o.peek())
+				}
+			}
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
@@ -0,0 +1,809 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jinja
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// maxIncludeDepth guards against templates which include each other
+const maxIncludeDepth = 32
+
+// maxMacroDepth guards against runaway macro recursion, which would
+// otherwise exhaust the goroutine stack.
+const maxMacroDepth = 256
+
+// maxRange limits the number of items produced by range(), as does the
+// MAX_RANGE setting of Jinja's sandbox.
+const maxRange = 100000
+
+// maxRepeatLength limits the length of strings produced by repetition (string
+// multiplication, indentation, etc...). Exhausting memory isn't recoverable,
+// so templates must not be able to request arbitrarily large allocations.
+const maxRepeatLength = 1 << 20
+
+// scope is a level of variable bindings. Lookups fall through to the parent.
+type scope struct {
+	vars   map[string]any
+	parent *scope
+}
+
+func (o *scope) lookup(name string) (any, bool) {
+	for s := o; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (o *scope) child() *scope {
+	return &scope{vars: make(map[string]any), parent: o}
+}
+
+// renderer holds the state of a single Render() call
+type renderer struct {
+	env        *Environment
+	name       string
+	depth      int
+	macroDepth *int // shared with included templates
+}
+
+// positionError is an error which already identifies the template and line
+// at which it occurred, e.g. within a macro body.
+type positionError struct {
+	error
+}
+
+func (o *renderer) errorf(line int, format string, a ...any) error {
+	for _, v := range a {
+		if err, ok := v.(positionError); ok {
+			return err
+		}
+	}
+	return positionError{fmt.Errorf("%s:%d: %s", o.name, line, fmt.Sprintf(format, a...))}
+}
+
+func (o *renderer) renderNodes(sb *strings.Builder, nodes []node, s *scope) error {
+	for _, n := range nodes {
+		if err := o.renderNode(sb, n, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *renderer) renderNode(sb *strings.Builder, n node, s *scope) error {
+	switch n := n.(type) {
+	case *textNode:
+		sb.WriteString(n.text)
+	case *outputNode:
+		v, err := o.eval(n.expr, s)
+		if err != nil {
+			return o.errorf(n.line, "%s", err)
+		}
+		if u, ok := v.(Undefined); ok && o.env.StrictUndefined {
+			return o.errorf(n.line, "%q is undefined", u.name)
+		}
+		sb.WriteString(toString(v))
+	case *ifNode:
+		for i, cond := range n.conds {
+			v, err := o.eval(cond, s)
+			if err != nil {
+				return o.errorf(n.line, "%s", err)
+			}
+			if truthy(v) {
+				return o.renderNodes(sb, n.branches[i], s)
+			}
+		}
+	case *forNode:
+		return o.renderFor(sb, n, s)
+	case *setNode:
+		v, err := o.eval(n.value, s)
+		if err != nil {
+			return o.errorf(n.line, "%s", err)
+		}
+		if err = assign(s, n.targets, v); err != nil {
+			return o.errorf(n.line, "%s", err)
+		}
+	case *includeNode:
+		v, err := o.eval(n.name, s)
+		if err != nil {
+			return o.errorf(n.line, "%s", err)
+		}
+		name := toString(v)
+		if _, ok := o.env.Templates[name]; !ok && n.ignoreMissing {
+			return nil
+		}
+		if o.depth >= maxIncludeDepth {
+			return o.errorf(n.line, "include depth exceeds %d, recursive include of %q?", maxIncludeDepth, name)
+		}
+		nodes, err := o.env.parse(name)
+		if err != nil {
+			return o.errorf(n.line, "%s", err)
+		}
+		inner := renderer{env: o.env, name: name, depth: o.depth + 1, macroDepth: o.macroDepth}
+		// included templates see (and may modify) the includer's variables
+		return inner.renderNodes(sb, nodes, s)
+	case *macroNode:
+		s.vars[n.name] = o.macro(n, s)
+	}
+	return nil
+}
+
+func assign(s *scope, targets []string, v any) error {
+	if len(targets) == 1 {
+		s.vars[targets[0]] = v
+		return nil
+	}
+
+	items, err := iterate(v)
+	if err != nil {
+		return err
+	}
+	if len(items) != len(targets) {
+		return fmt.Errorf("cannot unpack %d values into %d variables", len(items), len(targets))
+	}
+	for i, target := range targets {
+		s.vars[target] = items[i]
+	}
+	return nil
+}
+
+func (o *renderer) renderFor(sb *strings.Builder, n *forNode, s *scope) error {
+	v, err := o.eval(n.iter, s)
+	if err != nil {
+		return o.errorf(n.line, "%s", err)
+	}
+	if u, ok := v.(Undefined); ok && o.env.StrictUndefined {
+		return o.errorf(n.line, "%q is undefined", u.name)
+	}
+	items, err := iterate(v)
+	if err != nil {
+		return o.errorf(n.line, "%s", err)
+	}
+
+	if n.filter != nil {
+		var filtered []any
+		for _, item := range items {
+			inner := s.child()
+			if err = assign(inner, n.targets, item); err != nil {
+				return o.errorf(n.line, "%s", err)
+			}
+			keep, err := o.eval(n.filter, inner)
+			if err != nil {
+				return o.errorf(n.line, "%s", err)
+			}
+			if truthy(keep) {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	if len(items) == 0 {
+		return o.renderNodes(sb, n.otherwise, s)
+	}
+
+	for i, item := range items {
+		// loop variables and assignments made within the loop body don't
+		// escape the loop
+		inner := s.child()
+		if err = assign(inner, n.targets, item); err != nil {
+			return o.errorf(n.line, "%s", err)
+		}
+		loop := map[string]any{
+			"index":     int64(i + 1),
+			"index0":    int64(i),
+			"revindex":  int64(len(items) - i),
+			"revindex0": int64(len(items) - i - 1),
+			"first":     i == 0,
+			"last":      i == len(items)-1,
+			"length":    int64(len(items)),
+			"previtem":  Undefined{name: "loop.previtem"},
+			"nextitem":  Undefined{name: "loop.nextitem"},
+		}
+		if i > 0 {
+			loop["previtem"] = items[i-1]
+		}
+		if i < len(items)-1 {
+			loop["nextitem"] = items[i+1]
+		}
+		inner.vars["loop"] = loop
+		if err = o.renderNodes(sb, n.body, inner); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// macro returns a callable which renders the macro body with its arguments
+// bound on top of the scope in which it was defined.
+func (o *renderer) macro(n *macroNode, defined *scope) callable {
+	return func(args []any, kwargs map[string]any) (any, error) {
+		if len(args) > len(n.params) {
+			return nil, fmt.Errorf("macro %q takes %d arguments, %d given", n.name, len(n.params), len(args))
+		}
+
+		if *o.macroDepth >= maxMacroDepth {
+			return nil, fmt.Errorf("macro call depth exceeds %d, recursive macro %q?", maxMacroDepth, n.name)
+		}
+		*o.macroDepth++
+		defer func() { *o.macroDepth-- }()
+
+		inner := defined.child()
+		for i, param := range n.params {
+			switch v, ok := kwargs[param]; {
+			case i < len(args):
+				inner.vars[param] = args[i]
+			case ok:
+				inner.vars[param] = v
+			case n.defaults[param] != nil:
+				d, err := o.eval(n.defaults[param], inner)
+				if err != nil {
+					return nil, err
+				}
+				inner.vars[param] = d
+			default:
+				inner.vars[param] = Undefined{name: param}
+			}
+		}
+		for k := range kwargs {
+			if _, ok := inner.vars[k]; !ok {
+				return nil, fmt.Errorf("macro %q has no parameter %q", n.name, k)
+			}
+		}
+
+		var sb strings.Builder
+		if err := o.renderNodes(&sb, n.body, inner); err != nil {
+			return nil, err
+		}
+		return sb.String(), nil
+	}
+}
+
+func (o *renderer) eval(e expr, s *scope) (any, error) {
+	switch e := e.(type) {
+	case *literalExpr:
+		return e.value, nil
+	case *nameExpr:
+		if v, ok := s.lookup(e.name); ok {
+			return v, nil
+		}
+		return Undefined{name: e.name}, nil
+	case *listExpr:
+		result := make([]any, len(e.items))
+		for i, item := range e.items {
+			v, err := o.eval(item, s)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = v
+		}
+		return result, nil
+	case *dictExpr:
+		result := make(map[string]any, len(e.keys))
+		for i := range e.keys {
+			k, err := o.eval(e.keys[i], s)
+			if err != nil {
+				return nil, err
+			}
+			v, err := o.eval(e.values[i], s)
+			if err != nil {
+				return nil, err
+			}
+			result[toString(k)] = v
+		}
+		return result, nil
+	case *attrExpr:
+		target, err := o.eval(e.target, s)
+		if err != nil {
+			return nil, err
+		}
+		return o.getAttr(target, e.name, exprName(e))
+	case *indexExpr:
+		target, err := o.eval(e.target, s)
+		if err != nil {
+			return nil, err
+		}
+		idx, err := o.eval(e.index, s)
+		if err != nil {
+			return nil, err
+		}
+		return o.getItem(target, idx, exprName(e))
+	case *sliceExpr:
+		return o.evalSlice(e, s)
+	case *callExpr:
+		return o.evalCall(e, s)
+	case *filterExpr:
+		return o.evalFilter(e, s)
+	case *testExpr:
+		return o.evalTest(e, s)
+	case *unaryExpr:
+		v, err := o.eval(e.operand, s)
+		if err != nil {
+			return nil, err
+		}
+		if e.op == "not" {
+			return !truthy(v), nil
+		}
+		switch v := v.(type) {
+		case int64:
+			if v == math.MinInt64 {
+				return nil, fmt.Errorf("%w: -(%d)", errIntegerOverflow, v)
+			}
+			return -v, nil
+		case float64:
+			return -v, nil
+		}
+		return nil, fmt.Errorf("bad operand type for unary -: %s", typeName(v))
+	case *condExpr:
+		cond, err := o.eval(e.cond, s)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(cond) {
+			return o.eval(e.then, s)
+		}
+		return o.eval(e.otherwise, s)
+	case *binaryExpr:
+		return o.evalBinary(e, s)
+	}
+	return nil, fmt.Errorf("unhandled expression type %T", e)
+}
+
+// exprName renders a variable reference expression for use in error messages
+func exprName(e expr) string {
+	switch e := e.(type) {
+	case *nameExpr:
+		return e.name
+	case *attrExpr:
+		return exprName(e.target) + "." + e.name
+	case *indexExpr:
+		if l, ok := e.index.(*literalExpr); ok {
+			return exprName(e.target) + "[" + repr(l.value) + "]"
+		}
+		return exprName(e.target) + "[...]"
+	}
+	return "expression"
+}
+
+func (o *renderer) getAttr(target any, name, fullName string) (any, error) {
+	switch t := target.(type) {
+	case map[string]any:
+		if v, ok := t[name]; ok {
+			return v, nil
+		}
+	case []any:
+		if i, ok := parseIndex(name); ok {
+			return o.getItem(target, i, fullName)
+		}
+	case Undefined:
+		if o.env.StrictUndefined {
+			return nil, fmt.Errorf("%q is undefined", t.name)
+		}
+		return Undefined{name: fullName}, nil
+	}
+	if m := method(target, name); m != nil {
+		return m, nil
+	}
+	return Undefined{name: fullName}, nil
+}
+
+func parseIndex(s string) (int64, bool) {
+	var i int64
+	if _, err := fmt.Sscanf(s, "%d", &i); err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+func (o *renderer) getItem(target, idx any, fullName string) (any, error) {
+	switch t := target.(type) {
+	case map[string]any:
+		if v, ok := t[toString(idx)]; ok {
+			return v, nil
+		}
+		if s, ok := idx.(string); ok {
+			if m := method(target, s); m != nil {
+				return m, nil
+			}
+		}
+		return Undefined{name: fullName}, nil
+	case []any:
+		i, ok := idx.(int64)
+		if !ok {
+			return nil, fmt.Errorf("list indices must be integers, not %s", typeName(idx))
+		}
+		if n := index(i, len(t)); n >= 0 && n < len(t) {
+			return t[n], nil
+		}
+		return Undefined{name: fullName}, nil
+	case string:
+		i, ok := idx.(int64)
+		if !ok {
+			return nil, fmt.Errorf("string indices must be integers, not %s", typeName(idx))
+		}
+		r := []rune(t)
+		if n := index(i, len(r)); n >= 0 && n < len(r) {
+			return string(r[n]), nil
+		}
+		return Undefined{name: fullName}, nil
+	case Undefined:
+		if o.env.StrictUndefined {
+			return nil, fmt.Errorf("%q is undefined", t.name)
+		}
+		return Undefined{name: fullName}, nil
+	}
+	return nil, fmt.Errorf("%s object is not subscriptable", typeName(target))
+}
+
+func (o *renderer) evalSlice(e *sliceExpr, s *scope) (any, error) {
+	target, err := o.eval(e.target, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var length int
+	switch t := target.(type) {
+	case []any:
+		length = len(t)
+	case string:
+		length = len([]rune(t))
+	default:
+		return nil, fmt.Errorf("%s object is not subscriptable", typeName(target))
+	}
+
+	bound := func(be expr, dflt int) (int, error) {
+		if be == nil {
+			return dflt, nil
+		}
+		v, err := o.eval(be, s)
+		if err != nil {
+			return 0, err
+		}
+		i, ok := v.(int64)
+		if !ok {
+			return 0, fmt.Errorf("slice indices must be integers, not %s", typeName(v))
+		}
+		return min(max(index(i, length), 0), length), nil
+	}
+	start, err := bound(e.start, 0)
+	if err != nil {
+		return nil, err
+	}
+	stop, err := bound(e.stop, length)
+	if err != nil {
+		return nil, err
+	}
+	stop = max(start, stop)
+
+	if t, ok := target.([]any); ok {
+		return append([]any{}, t[start:stop]...), nil
+	}
+	return string([]rune(target.(string))[start:stop]), nil
+}
+
+func (o *renderer) evalArgs(args []expr, kwargs map[string]expr, s *scope) ([]any, map[string]any, error) {
+	argValues := make([]any, len(args))
+	for i, arg := range args {
+		v, err := o.eval(arg, s)
+		if err != nil {
+			return nil, nil, err
+		}
+		argValues[i] = v
+	}
+	kwargValues := make(map[string]any, len(kwargs))
+	for k, kwarg := range kwargs {
+		v, err := o.eval(kwarg, s)
+		if err != nil {
+			return nil, nil, err
+		}
+		kwargValues[k] = v
+	}
+	return argValues, kwargValues, nil
+}
+
+func (o *renderer) evalCall(e *callExpr, s *scope) (any, error) {
+	target, err := o.eval(e.target, s)
+	if err != nil {
+		return nil, err
+	}
+
+	args, kwargs, err := o.evalArgs(e.args, e.kwargs, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := target.(type) {
+	case callable:
+		return t(args, kwargs)
+	case Undefined:
+		if n, ok := e.target.(*nameExpr); ok {
+			if f, ok := globals[n.name]; ok {
+				return f(args, kwargs)
+			}
+		}
+		return nil, fmt.Errorf("%q is undefined", t.name)
+	}
+	return nil, fmt.Errorf("%s object is not callable", typeName(target))
+}
+
+func (o *renderer) evalFilter(e *filterExpr, s *scope) (any, error) {
+	f, ok := filters[e.name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported filter %q", e.name)
+	}
+
+	target, err := o.eval(e.target, s)
+	if err != nil {
+		return nil, err
+	}
+
+	args, kwargs, err := o.evalArgs(e.args, e.kwargs, s)
+	if err != nil {
+		return nil, err
+	}
+
+	// only "default" and the type tests deal with undefined values
+	if u, ok := target.(Undefined); ok && o.env.StrictUndefined && e.name != "default" && e.name != "d" {
+		return nil, fmt.Errorf("%q is undefined", u.name)
+	}
+
+	result, err := f(target, args, kwargs)
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", e.name, err)
+	}
+	return result, nil
+}
+
+func (o *renderer) evalTest(e *testExpr, s *scope) (any, error) {
+	t, ok := tests[e.name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported test %q", e.name)
+	}
+
+	target, err := o.eval(e.target, s)
+	if err != nil {
+		return nil, err
+	}
+
+	args, _, err := o.evalArgs(e.args, nil, s)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := t(target, args)
+	if err != nil {
+		return nil, fmt.Errorf("test %q: %w", e.name, err)
+	}
+	return result != e.negate, nil
+}
+
+func (o *renderer) evalBinary(e *binaryExpr, s *scope) (any, error) {
+	left, err := o.eval(e.left, s)
+	if err != nil {
+		return nil, err
+	}
+
+	// short circuit evaluation returns the deciding operand, as Python does
+	switch e.op {
+	case "and":
+		if !truthy(left) {
+			return left, nil
+		}
+		return o.eval(e.right, s)
+	case "or":
+		if truthy(left) {
+			return left, nil
+		}
+		return o.eval(e.right, s)
+	}
+
+	right, err := o.eval(e.right, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.env.StrictUndefined {
+		for _, v := range []any{left, right} {
+			if u, ok := v.(Undefined); ok {
+				return nil, fmt.Errorf("%q is undefined", u.name)
+			}
+		}
+	}
+
+	switch e.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", ">", "<=", ">=":
+		c, err := compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]bool{"<": c < 0, ">": c > 0, "<=": c <= 0, ">=": c >= 0}[e.op], nil
+	case "in":
+		return contains(right, left)
+	case "not in":
+		found, err := contains(right, left)
+		return !found, err
+	case "~":
+		return toString(left) + toString(right), nil
+	}
+
+	return arithmetic(e.op, left, right)
+}
+
+var (
+	errZeroDivision    = errors.New("division by zero")
+	errIntegerOverflow = errors.New("integer overflow")
+)
+
+// repeat is strings.Repeat, with negative counts treated as zero (like
+// Python) and results limited to maxRepeatLength.
+func repeat(s string, count int64) (string, error) {
+	if count <= 0 || s == "" {
+		return "", nil
+	}
+	if count > maxRepeatLength/int64(len(s)) {
+		return "", fmt.Errorf("repeated string would exceed %d characters", maxRepeatLength)
+	}
+	return strings.Repeat(s, int(count)), nil
+}
+
+func arithmetic(op string, left, right any) (any, error) {
+	switch op {
+	case "+":
+		switch l := left.(type) {
+		case string:
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		case []any:
+			if r, ok := right.([]any); ok {
+				return append(append([]any{}, l...), r...), nil
+			}
+		}
+	case "*":
+		if l, ok := left.(string); ok {
+			if r, ok := right.(int64); ok {
+				return repeat(l, r)
+			}
+		}
+	case "%":
+		if l, ok := left.(string); ok {
+			return percentFormat(l, right)
+		}
+	}
+
+	li, lIsInt := left.(int64)
+	ri, rIsInt := right.(int64)
+	lf, lOk := toFloat(left)
+	rf, rOk := toFloat(right)
+	if !lOk || !rOk {
+		return nil, fmt.Errorf("unsupported operand types for %s: %s and %s", op, typeName(left), typeName(right))
+	}
+	lIsInt = lIsInt || isBool(left)
+	rIsInt = rIsInt || isBool(right)
+	if isBool(left) {
+		li = int64(lf)
+	}
+	if isBool(right) {
+		ri = int64(rf)
+	}
+	ints := lIsInt && rIsInt
+
+	switch op {
+	case "+":
+		if ints {
+			return checkedAdd(li, ri)
+		}
+		return lf + rf, nil
+	case "-":
+		if ints {
+			if ri == math.MinInt64 {
+				return nil, fmt.Errorf("%w: %d - %d", errIntegerOverflow, li, ri)
+			}
+			return checkedAdd(li, -ri)
+		}
+		return lf - rf, nil
+	case "*":
+		if ints {
+			return checkedMul(li, ri)
+		}
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, errZeroDivision
+		}
+		return lf / rf, nil
+	case "//":
+		if rf == 0 {
+			return nil, errZeroDivision
+		}
+		if ints {
+			if li == math.MinInt64 && ri == -1 {
+				return nil, fmt.Errorf("%w: %d // %d", errIntegerOverflow, li, ri)
+			}
+			return floorDiv(li, ri), nil
+		}
+		return math.Floor(lf / rf), nil
+	case "%":
+		if rf == 0 {
+			return nil, errZeroDivision
+		}
+		if ints {
+			return li - ri*floorDiv(li, ri), nil
+		}
+		return lf - rf*math.Floor(lf/rf), nil
+	case "**":
+		if ints && ri >= 0 {
+			return checkedPow(li, ri)
+		}
+		return math.Pow(lf, rf), nil
+	}
+	return nil, fmt.Errorf("unsupported operator %q", op)
+}
+
+func isBool(v any) bool {
+	_, ok := v.(bool)
+	return ok
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// checkedAdd returns a + b, or an error rather than a wrapped-around result.
+// Python integers have arbitrary precision; ours are limited to 64 bits.
+func checkedAdd(a, b int64) (any, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return nil, fmt.Errorf("%w: %d + %d", errIntegerOverflow, a, b)
+	}
+	return a + b, nil
+}
+
+func checkedMul(a, b int64) (any, error) {
+	if a == 0 || b == 0 {
+		return int64(0), nil
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return nil, fmt.Errorf("%w: %d * %d", errIntegerOverflow, a, b)
+	}
+	return c, nil
+}
+
+// checkedPow computes base ** exp by squaring
+func checkedPow(base, exp int64) (any, error) {
+	result, b := int64(1), base
+	for e := exp; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r, err := checkedMul(result, b)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %d ** %d", errIntegerOverflow, base, exp)
+			}
+			result = r.(int64)
+		}
+		if e > 1 {
+			sq, err := checkedMul(b, b)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %d ** %d", errIntegerOverflow, base, exp)
+			}
+			b = sq.(int64)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,347 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package jinja
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Undefined is the value of a variable or attribute which does not exist.
+// It renders as an empty string unless Environment.StrictUndefined is set.
+type Undefined struct {
+	name string
+}
+
+// callable is implemented by macros and bound methods
+type callable func(args []any, kwargs map[string]any) (any, error)
+
+// normalize converts arbitrary Go data to the value types used by the
+// renderer (nil, bool, int64, float64, string, []any and map[string]any) by
+// way of a JSON round trip.
+func normalize(in any) (any, error) {
+	switch in := in.(type) {
+	case nil, bool, int64, float64, string:
+		return in, nil
+	case int:
+		return int64(in), nil
+	}
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshaling template context - %w", err)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var out any
+	if err = d.Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed unmarshaling template context - %w", err)
+	}
+
+	return fromJSON(out), nil
+}
+
+// fromJSON replaces json.Number values with int64 or float64
+func fromJSON(in any) any {
+	switch in := in.(type) {
+	case json.Number:
+		if i, err := in.Int64(); err == nil {
+			return i
+		}
+		f, _ := in.Float64()
+		return f
+	case []any:
+		for i := range in {
+			in[i] = fromJSON(in[i])
+		}
+	case map[string]any:
+		for k := range in {
+			in[k] = fromJSON(in[k])
+		}
+	}
+	return in
+}
+
+func isUndefined(v any) bool {
+	_, ok := v.(Undefined)
+	return ok
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil, Undefined:
+		return false
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []any:
+		return len(v) != 0
+	case map[string]any:
+		return len(v) != 0
+	}
+	return true
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func toInt(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// toString renders a value the way Python's str() would
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "None"
+	case Undefined:
+		return ""
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return formatFloat(v)
+	case string:
+		return v
+	case []any, map[string]any:
+		return repr(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// repr renders a value the way Python's repr() would
+func repr(v any) string {
+	switch v := v.(type) {
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`).Replace(v) + "'"
+	case []any:
+		items := make([]string, len(v))
+		for i := range v {
+			items[i] = repr(v[i])
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		items := make([]string, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			items = append(items, repr(k)+": "+repr(v[k]))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return toString(v)
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return strings.ToLower(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+func sortedKeys(m map[string]any) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// iterate returns the items produced by iterating over v: list elements,
+// sorted map keys, or string characters.
+func iterate(v any) ([]any, error) {
+	switch v := v.(type) {
+	case Undefined:
+		return nil, nil
+	case []any:
+		return v, nil
+	case map[string]any:
+		result := make([]any, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			result = append(result, k)
+		}
+		return result, nil
+	case string:
+		result := make([]any, 0, len(v))
+		for _, r := range v {
+			result = append(result, string(r))
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("%s object is not iterable", typeName(v))
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "NoneType"
+	case Undefined:
+		return "Undefined"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "float"
+	case string:
+		return "str"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "dict"
+	case callable:
+		return "function"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func equal(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			return fa == fb
+		}
+		return false
+	}
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case Undefined:
+		return isUndefined(b)
+	case string:
+		bs, ok := b.(string)
+		return ok && a == bs
+	case []any:
+		bl, ok := b.([]any)
+		if !ok || len(a) != len(bl) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], bl[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bm, ok := b.(map[string]any)
+		if !ok || len(a) != len(bm) {
+			return false
+		}
+		for k := range a {
+			if bv, ok := bm[k]; !ok || !equal(a[k], bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compare returns -1, 0 or 1, or an error if a and b aren't orderable
+func compare(a, b any) (int, error) {
+	if fa, ok := toFloat(a); ok {
+		if fb, ok := toFloat(b); ok {
+			switch {
+			case fa < fb:
+				return -1, nil
+			case fa > fb:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if sa, ok := a.(string); ok {
+		if sb, ok := b.(string); ok {
+			return strings.Compare(sa, sb), nil
+		}
+	}
+	if la, ok := a.([]any); ok {
+		if lb, ok := b.([]any); ok {
+			for i := 0; i < len(la) && i < len(lb); i++ {
+				if c, err := compare(la[i], lb[i]); err != nil || c != 0 {
+					return c, err
+				}
+			}
+			return compare(int64(len(la)), int64(len(lb)))
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s with %s", typeName(a), typeName(b))
+}
+
+func contains(container, item any) (bool, error) {
+	switch c := container.(type) {
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("'in <string>' requires string as left operand, not %s", typeName(item))
+		}
+		return strings.Contains(c, s), nil
+	case []any:
+		for _, v := range c {
+			if equal(v, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]any:
+		s, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+		_, found := c[s]
+		return found, nil
+	case Undefined:
+		return false, nil
+	}
+	return false, fmt.Errorf("argument of type %s is not iterable", typeName(container))
+}
+
+// index converts a possibly negative Python index into a slice offset
+func index(i int64, length int) int {
+	if i < 0 {
+		i += int64(length)
+	}
+	return int(i)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// unknownJsonFields holds JSON object members which aren't modelled by a raw
+// struct, keyed by member name. They're captured whenever an API object is
+// decoded, and sent back to the API by update operations unless the Client is
+// configured with ClientCfg.DropUnknownJsonFields. Preservation applies
+// to the top-level fields of virtual networks, security zones, routing
+// policies, templates, rack types, interface maps and device profiles.
+type unknownJsonFields map[string]json.RawMessage
+
+// unknownJsonFieldsForUpdate returns the unknown fields which should be sent
+// along with an update operation.
+func (o *Client) unknownJsonFieldsForUpdate(in unknownJsonFields) unknownJsonFields {
+	if o.cfg.DropUnknownJsonFields {
+		return nil
+	}
+	return in
+}
+
+var jsonFieldNamesCache sync.Map // reflect.Type -> map[string]bool
+
+// jsonFieldNames returns the lower-cased JSON member names which
+// encoding/json would decode into struct type t. Names are lower-cased
+// because encoding/json matches member names without regard to case.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if cached, ok := jsonFieldNamesCache.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+
+	result := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" && field.Anonymous {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k := range jsonFieldNames(embedded) {
+					result[k] = true
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		result[strings.ToLower(name)] = true
+	}
+
+	jsonFieldNamesCache.Store(t, result)
+	return result
+}
+
+// unmarshalUnknownJsonFields returns the members of the JSON object in data
+// which would not be decoded into v, which must be a struct or a pointer to
+// one. Members named in ignore (read-only fields, typically) are omitted. It
+// returns nil when every member is known or when data isn't an object.
+func unmarshalUnknownJsonFields(data []byte, v any, ignore ...string) (unknownJsonFields, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return nil, nil
+	}
+
+	var members map[string]json.RawMessage
+	err := json.Unmarshal(data, &members)
+	if err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	known := jsonFieldNames(t)
+
+	var result unknownJsonFields
+	for k, member := range members {
+		if known[strings.ToLower(k)] || slices.Contains(ignore, k) {
+			continue
+		}
+		if result == nil {
+			result = make(unknownJsonFields)
+		}
+		result[k] = member
+	}
+
+	return result, nil
+}
+
+// marshalWithUnknownJsonFields marshals v, which must marshal to a JSON
+// object, and adds the unknown members which it doesn't already contain.
+func marshalWithUnknownJsonFields(v any, unknown unknownJsonFields) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(unknown) == 0 {
+		return data, err
+	}
+
+	var members map[string]json.RawMessage
+	err = json.Unmarshal(data, &members)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, member := range unknown {
+		if _, ok := members[k]; !ok {
+			members[k] = member
+		}
+	}
+
+	return json.Marshal(members)
+}
@@ -0,0 +1,487 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// payloads recorded from the API, with members added to represent settings
+// introduced by a future Apstra release.
+const (
+	testUnknownFieldsVirtualNetwork = `{
+		"id": "vn1", "label": "vn1", "vn_type": "vxlan", "vn_id": "10001", "security_zone_id": "sz1",
+		"dhcp_service": "dhcpServiceDisabled", "ipv4_enabled": true, "ipv4_subnet": "10.0.0.0/24",
+		"ipv6_enabled": false, "virtual_gateway_ipv4": "10.0.0.1", "virtual_gateway_ipv4_enabled": true,
+		"virtual_gateway_ipv6_enabled": false, "rt_policy": null, "svi_ips": [], "bound_to": [],
+		"floating_ips": [], "endpoints": [], "description": "from the future",
+		"l3_connectivity": {"mode": "l3Enabled", "ttl": 7}
+	}`
+	testUnknownFieldsSecurityZone = `{
+		"id": "sz1", "label": "blue", "sz_type": "evpn", "vrf_name": "blue", "routing_policy_id": "rp1",
+		"route_target": "100:100", "vlan_id": 3, "vni_id": 20001, "junos_evpn_irb_mode": "asymmetric",
+		"vrf_description": "new setting", "tags": ["a", "b"]
+	}`
+	testUnknownFieldsRoutingPolicy = `{
+		"id": "rp1", "label": "rp1", "description": "", "policy_type": "user_defined", "import_policy": "all",
+		"export_policy": {"static_routes": false, "loopbacks": true, "spine_superspine_links": false,
+			"l3edge_server_links": false, "spine_leaf_links": false, "l2edge_subnets": true},
+		"expect_default_ipv4_route": true, "expect_default_ipv6_route": false,
+		"aggregate_prefixes": [], "extra_import_routes": [], "extra_export_routes": [],
+		"export_evpn_type5": true
+	}`
+	testUnknownFieldsInterfaceMap = `{
+		"id": "im1", "label": "im1", "logical_device_id": "ld1", "device_profile_id": "dp1",
+		"created_at": "2024-01-02T03:04:05Z", "last_modified_at": "2024-01-02T03:04:05Z",
+		"interfaces": [], "port_groups": [{"name": "pg1"}]
+	}`
+	testUnknownFieldsDeviceProfile = `{
+		"id": "dp1", "label": "dp1", "device_profile_type": "monolithic",
+		"created_at": "2024-01-02T03:04:05Z", "last_modified_at": "2024-01-02T03:04:05Z",
+		"ports": [], "linecards_info": [], "slot_configuration": [],
+		"reference_design_capabilities": {"datacenter": "full_support"}
+	}`
+	testUnknownFieldsRackType = `{
+		"id": "rt1", "display_name": "rt1", "description": "", "fabric_connectivity_design": "l3clos",
+		"created_at": "2024-01-02T03:04:05Z", "last_modified_at": "2024-01-02T03:04:05Z",
+		"rack_design_policy": {"spread": true}
+	}`
+	testUnknownFieldsLogicalDevice = `{
+		"id": "ld1", "display_name": "ld1",
+		"panels": [{
+			"panel_layout": {"row_count": 1, "column_count": 2},
+			"port_indexing": {"order": "T-B, L-R", "start_index": 1, "schema": "absolute"},
+			"port_groups": [{"count": 2, "speed": {"unit": "G", "value": 10}, "roles": ["leaf", "spine"]}]
+		}]
+	}`
+	testUnknownFieldsRackBasedTemplate = `{
+		"id": "rbt1", "type": "rack_based", "display_name": "rbt1", "capability": "blueprint",
+		"created_at": "2024-01-02T03:04:05Z", "last_modified_at": "2024-01-02T03:04:05Z",
+		"anti_affinity_policy": {"algorithm": "heuristic", "max_links_per_port": 0, "max_links_per_slot": 0,
+			"max_per_system_links_per_port": 0, "max_per_system_links_per_slot": 0, "mode": "disabled"},
+		"virtual_network_policy": {"overlay_control_protocol": "evpn"},
+		"asn_allocation_policy": {"spine_asn_scheme": "distinct"},
+		"spine": {"count": 2, "link_per_superspine_speed": null, "link_per_superspine_count": 0, "tags": [],
+			"logical_device": ` + testUnknownFieldsLogicalDevice + `},
+		"rack_types": [], "rack_type_counts": [], "dhcp_service_intent": {"active": true},
+		"fabric_addressing_policy": {"spine_leaf_links": "ipv4", "spine_superspine_links": "ipv4"}
+	}`
+	testUnknownFieldsPodBasedTemplate = `{
+		"id": "pbt1", "type": "pod_based", "display_name": "pbt1", "capability": "blueprint",
+		"created_at": "2024-01-02T03:04:05Z", "last_modified_at": "2024-01-02T03:04:05Z",
+		"anti_affinity_policy": {"algorithm": "heuristic", "max_links_per_port": 0, "max_links_per_slot": 0,
+			"max_per_system_links_per_port": 0, "max_per_system_links_per_slot": 0, "mode": "disabled"},
+		"superspine": {"plane_count": 1, "superspine_per_plane": 4, "tags": [],
+			"logical_device": ` + testUnknownFieldsLogicalDevice + `},
+		"rack_based_templates": [], "rack_based_template_counts": [],
+		"fabric_addressing_policy": {"spine_superspine_links": "ipv4_ipv6"}
+	}`
+	testUnknownFieldsL3CollapsedTemplate = `{
+		"id": "l3ct1", "type": "l3_collapsed", "display_name": "l3ct1", "capability": "blueprint",
+		"created_at": "2024-01-02T03:04:05Z", "last_modified_at": "2024-01-02T03:04:05Z",
+		"anti_affinity_policy": {"algorithm": "heuristic", "max_links_per_port": 0, "max_links_per_slot": 0,
+			"max_per_system_links_per_port": 0, "max_per_system_links_per_slot": 0, "mode": "disabled"},
+		"virtual_network_policy": {"overlay_control_protocol": "evpn"},
+		"mesh_link_speed": {"unit": "G", "value": 10}, "mesh_link_count": 1,
+		"rack_types": [], "rack_type_counts": [], "dhcp_service_intent": {"active": false},
+		"esi_mac_msb": 2
+	}`
+)
+
+// requireUnknownFields asserts that every member of payload named in fields
+// appears in data with the same value.
+func requireUnknownFields(t *testing.T, payload string, data []byte, fields ...string) {
+	t.Helper()
+
+	var expected, actual map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(payload), &expected))
+	require.NoError(t, json.Unmarshal(data, &actual))
+
+	for _, field := range fields {
+		require.Containsf(t, actual, field, "field %q was dropped", field)
+		require.JSONEq(t, string(expected[field]), string(actual[field]))
+	}
+}
+
+func TestUnknownJsonFieldsRoundTrip(t *testing.T) {
+	type testCase struct {
+		path     string // the stub API serves payload here, and expects updates here
+		payload  string
+		unknown  []string
+		readOnly []string
+		// raw decodes and re-encodes the payload using the raw type
+		raw func([]byte) ([]byte, error)
+		// update fetches the object with the SDK's Get method and sends it back
+		// with the matching Update method
+		update func(context.Context, *Client) error
+		// create fetches the object and uses it to create a new one
+		create func(context.Context, *Client) error
+	}
+
+	bpClient := func(client *Client) *TwoStageL3ClosClient {
+		return &TwoStageL3ClosClient{client: client, blueprintId: "bp1"}
+	}
+
+	testCases := map[string]testCase{
+		"virtual_network": {
+			path:     fmt.Sprintf(apiUrlVirtualNetworkById, "bp1", "vn1"),
+			payload:  testUnknownFieldsVirtualNetwork,
+			unknown:  []string{"description", "l3_connectivity"},
+			readOnly: []string{"floating_ips", "endpoints"},
+			raw: func(b []byte) ([]byte, error) {
+				var raw rawVirtualNetwork
+				if err := json.Unmarshal(b, &raw); err != nil {
+					return nil, err
+				}
+				return json.Marshal(raw)
+			},
+			update: func(ctx context.Context, client *Client) error {
+				vn, err := bpClient(client).GetVirtualNetwork(ctx, "vn1")
+				if err != nil {
+					return err
+				}
+				vn.Data.Label = "modified"
+				return bpClient(client).UpdateVirtualNetwork(ctx, vn.Id, vn.Data)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				vn, err := bpClient(client).GetVirtualNetwork(ctx, "vn1")
+				if err != nil {
+					return err
+				}
+				_, err = bpClient(client).CreateVirtualNetwork(ctx, vn.Data)
+				return err
+			},
+		},
+		"security_zone": {
+			path:    fmt.Sprintf(apiUrlBlueprintSecurityZoneById, "bp1", "sz1"),
+			payload: testUnknownFieldsSecurityZone,
+			unknown: []string{"vrf_description", "tags"},
+			raw: func(b []byte) ([]byte, error) {
+				var raw rawSecurityZone
+				if err := json.Unmarshal(b, &raw); err != nil {
+					return nil, err
+				}
+				return json.Marshal(raw)
+			},
+			update: func(ctx context.Context, client *Client) error {
+				sz, err := bpClient(client).GetSecurityZone(ctx, "sz1")
+				if err != nil {
+					return err
+				}
+				return bpClient(client).UpdateSecurityZone(ctx, sz.Id, sz.Data)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				sz, err := bpClient(client).GetSecurityZone(ctx, "sz1")
+				if err != nil {
+					return err
+				}
+				_, err = bpClient(client).CreateSecurityZone(ctx, sz.Data)
+				return err
+			},
+		},
+		"routing_policy": {
+			path:    fmt.Sprintf(apiUrlBlueprintRoutingPolicyById, "bp1", "rp1"),
+			payload: testUnknownFieldsRoutingPolicy,
+			unknown: []string{"export_evpn_type5"},
+			raw: func(b []byte) ([]byte, error) {
+				var raw rawDcRoutingPolicy
+				if err := json.Unmarshal(b, &raw); err != nil {
+					return nil, err
+				}
+				return json.Marshal(raw)
+			},
+			update: func(ctx context.Context, client *Client) error {
+				rp, err := bpClient(client).GetRoutingPolicy(ctx, "rp1")
+				if err != nil {
+					return err
+				}
+				return bpClient(client).UpdateRoutingPolicy(ctx, rp.Id, rp.Data)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				rp, err := bpClient(client).GetRoutingPolicy(ctx, "rp1")
+				if err != nil {
+					return err
+				}
+				_, err = bpClient(client).CreateRoutingPolicy(ctx, rp.Data)
+				return err
+			},
+		},
+		"interface_map": {
+			path:    fmt.Sprintf(apiUrlDesignInterfaceMapById, "im1"),
+			payload: testUnknownFieldsInterfaceMap,
+			unknown: []string{"port_groups"},
+			raw: func(b []byte) ([]byte, error) {
+				var raw rawInterfaceMap
+				if err := json.Unmarshal(b, &raw); err != nil {
+					return nil, err
+				}
+				return json.Marshal(raw)
+			},
+			update: func(ctx context.Context, client *Client) error {
+				im, err := client.GetInterfaceMap(ctx, "im1")
+				if err != nil {
+					return err
+				}
+				return client.UpdateInterfaceMap(ctx, im.Id, im.Data)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				im, err := client.GetInterfaceMap(ctx, "im1")
+				if err != nil {
+					return err
+				}
+				_, err = client.CreateInterfaceMap(ctx, im.Data)
+				return err
+			},
+		},
+		"device_profile": {
+			path:    fmt.Sprintf(apiUrlDeviceProfileById, "dp1"),
+			payload: testUnknownFieldsDeviceProfile,
+			unknown: []string{"reference_design_capabilities"},
+			update: func(ctx context.Context, client *Client) error {
+				dp, err := client.GetDeviceProfile(ctx, "dp1")
+				if err != nil {
+					return err
+				}
+				return client.UpdateDeviceProfile(ctx, dp.Id, dp.Data)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				dp, err := client.GetDeviceProfile(ctx, "dp1")
+				if err != nil {
+					return err
+				}
+				_, err = client.CreateDeviceProfile(ctx, dp.Data)
+				return err
+			},
+		},
+		"rack_type": {
+			path:    fmt.Sprintf(apiUrlDesignRackTypeById, "rt1"),
+			payload: testUnknownFieldsRackType,
+			unknown: []string{"rack_design_policy"},
+			update: func(ctx context.Context, client *Client) error {
+				// unknown fields travel from the caller's copy of the
+				// current object via CopyUnknownFields
+				rt, err := client.GetRackType(ctx, "rt1")
+				if err != nil {
+					return err
+				}
+				request := RackTypeRequest{DisplayName: "modified"}
+				request.CopyUnknownFields(rt.Data)
+				return client.UpdateRackType(ctx, rt.Id, &request)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				rt, err := client.GetRackType(ctx, "rt1")
+				if err != nil {
+					return err
+				}
+				request := RackTypeRequest{DisplayName: "copy"}
+				request.CopyUnknownFields(rt.Data)
+				_, err = client.CreateRackType(ctx, &request)
+				return err
+			},
+		},
+		"rack_based_template": {
+			path:    fmt.Sprintf(apiUrlDesignTemplateById, "rbt1"),
+			payload: testUnknownFieldsRackBasedTemplate,
+			unknown: []string{"fabric_addressing_policy"},
+			update: func(ctx context.Context, client *Client) error {
+				template, err := client.GetRackBasedTemplate(ctx, "rbt1")
+				if err != nil {
+					return err
+				}
+				request := testUnknownFieldsRackBasedTemplateRequest(template.Data)
+				request.CopyUnknownFields(template.Data)
+				return client.UpdateRackBasedTemplate(ctx, template.Id, request)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				template, err := client.GetRackBasedTemplate(ctx, "rbt1")
+				if err != nil {
+					return err
+				}
+				request := testUnknownFieldsRackBasedTemplateRequest(template.Data)
+				request.CopyUnknownFields(template.Data)
+				_, err = client.CreateRackBasedTemplate(ctx, request)
+				return err
+			},
+		},
+		"pod_based_template": {
+			path:    fmt.Sprintf(apiUrlDesignTemplateById, "pbt1"),
+			payload: testUnknownFieldsPodBasedTemplate,
+			unknown: []string{"fabric_addressing_policy"},
+			update: func(ctx context.Context, client *Client) error {
+				template, err := client.GetPodBasedTemplate(ctx, "pbt1")
+				if err != nil {
+					return err
+				}
+				request := testUnknownFieldsPodBasedTemplateRequest(template.Data)
+				request.CopyUnknownFields(template.Data)
+				return client.UpdatePodBasedTemplate(ctx, template.Id, request)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				template, err := client.GetPodBasedTemplate(ctx, "pbt1")
+				if err != nil {
+					return err
+				}
+				request := testUnknownFieldsPodBasedTemplateRequest(template.Data)
+				request.CopyUnknownFields(template.Data)
+				_, err = client.CreatePodBasedTemplate(ctx, request)
+				return err
+			},
+		},
+		"l3_collapsed_template": {
+			path:    fmt.Sprintf(apiUrlDesignTemplateById, "l3ct1"),
+			payload: testUnknownFieldsL3CollapsedTemplate,
+			unknown: []string{"esi_mac_msb"},
+			update: func(ctx context.Context, client *Client) error {
+				template, err := client.GetL3CollapsedTemplate(ctx, "l3ct1")
+				if err != nil {
+					return err
+				}
+				request := testUnknownFieldsL3CollapsedTemplateRequest(template.Data)
+				request.CopyUnknownFields(template.Data)
+				return client.UpdateL3CollapsedTemplate(ctx, template.Id, request)
+			},
+			create: func(ctx context.Context, client *Client) error {
+				template, err := client.GetL3CollapsedTemplate(ctx, "l3ct1")
+				if err != nil {
+					return err
+				}
+				request := testUnknownFieldsL3CollapsedTemplateRequest(template.Data)
+				request.CopyUnknownFields(template.Data)
+				_, err = client.CreateL3CollapsedTemplate(ctx, request)
+				return err
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			if tCase.raw != nil {
+				var expected map[string]json.RawMessage
+				require.NoError(t, json.Unmarshal([]byte(tCase.payload), &expected))
+				for _, field := range tCase.readOnly {
+					delete(expected, field)
+				}
+				expectedJson, err := json.Marshal(expected)
+				require.NoError(t, err)
+
+				data, err := tCase.raw([]byte(tCase.payload))
+				require.NoError(t, err)
+				require.JSONEq(t, string(expectedJson), string(data))
+			}
+
+			stub := &stubHttpClient{responses: map[string]string{
+				tCase.path: tCase.payload,
+				fmt.Sprintf(apiUrlDesignLogicalDeviceById, "ld1"): testUnknownFieldsLogicalDevice,
+			}}
+
+			// unknown fields are preserved by default
+			require.NoError(t, tCase.update(ctx, testStubClient(t, stub, ClientCfg{})))
+			request := stub.lastRequest(t)
+			require.Equal(t, tCase.path, request.path)
+			requireUnknownFields(t, tCase.payload, request.body, tCase.unknown...)
+			requireNoFields(t, request.body, tCase.readOnly...)
+
+			// preservation can be switched off
+			require.NoError(t, tCase.update(ctx, testStubClient(t, stub, ClientCfg{DropUnknownJsonFields: true})))
+			request = stub.lastRequest(t)
+			require.Equal(t, tCase.path, request.path)
+			requireNoFields(t, request.body, append(tCase.unknown, tCase.readOnly...)...)
+
+			// unknown fields are never sent when creating objects
+			require.NoError(t, tCase.create(ctx, testStubClient(t, stub, ClientCfg{})))
+			request = stub.lastRequest(t)
+			require.Equal(t, http.MethodPost, request.method)
+			requireNoFields(t, request.body, append(tCase.unknown, tCase.readOnly...)...)
+		})
+	}
+}
+
+func testUnknownFieldsRackBasedTemplateRequest(in *TemplateRackBasedData) *CreateRackBasedTemplateRequest {
+	return &CreateRackBasedTemplateRequest{
+		DisplayName:          "modified",
+		Spine:                &TemplateElementSpineRequest{Count: in.Spine.Count, LogicalDevice: "ld1"},
+		DhcpServiceIntent:    &in.DhcpServiceIntent,
+		AntiAffinityPolicy:   in.AntiAffinityPolicy,
+		AsnAllocationPolicy:  &in.AsnAllocationPolicy,
+		VirtualNetworkPolicy: &in.VirtualNetworkPolicy,
+	}
+}
+
+func testUnknownFieldsPodBasedTemplateRequest(in *TemplatePodBasedData) *CreatePodBasedTemplateRequest {
+	return &CreatePodBasedTemplateRequest{
+		DisplayName: "modified",
+		Superspine: &TemplateElementSuperspineRequest{
+			PlaneCount:         in.Superspine.PlaneCount,
+			SuperspinePerPlane: in.Superspine.SuperspinePerPlane,
+			LogicalDeviceId:    "ld1",
+		},
+		AntiAffinityPolicy: in.AntiAffinityPolicy,
+	}
+}
+
+func testUnknownFieldsL3CollapsedTemplateRequest(in *TemplateL3CollapsedData) *CreateL3CollapsedTemplateRequest {
+	return &CreateL3CollapsedTemplateRequest{
+		DisplayName:          "modified",
+		MeshLinkCount:        in.MeshLinkCount,
+		MeshLinkSpeed:        in.MeshLinkSpeed,
+		DhcpServiceIntent:    in.DhcpServiceIntent,
+		AntiAffinityPolicy:   in.AntiAffinityPolicy,
+		VirtualNetworkPolicy: in.VirtualNetworkPolicy,
+	}
+}
+
+// requireNoFields asserts that none of the named fields appear in data.
+func requireNoFields(t *testing.T, data []byte, fields ...string) {
+	t.Helper()
+
+	var actual map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &actual))
+
+	for _, field := range fields {
+		require.NotContainsf(t, actual, field, "field %q was sent", field)
+	}
+}
+
+func TestUnmarshalUnknownJsonFields(t *testing.T) {
+	type embedded struct {
+		B string `json:"b"`
+	}
+	type testStruct struct {
+		embedded
+		A       string `json:"a,omitempty"`
+		C       string
+		Ignored string `json:"-"`
+		private string //nolint:unused
+	}
+
+	unknown, err := unmarshalUnknownJsonFields([]byte(`{"a": 1, "B": 2, "c": 3, "Ignored": 4, "private": 5, "d": null}`), &testStruct{})
+	require.NoError(t, err)
+	require.Equal(t, unknownJsonFields{
+		"Ignored": json.RawMessage("4"),
+		"private": json.RawMessage("5"),
+		"d":       json.RawMessage("null"),
+	}, unknown)
+
+	unknown, err = unmarshalUnknownJsonFields([]byte(`{"a": 1, "d": null, "e": []}`), &testStruct{}, "e")
+	require.NoError(t, err)
+	require.Equal(t, unknownJsonFields{"d": json.RawMessage("null")}, unknown)
+
+	unknown, err = unmarshalUnknownJsonFields([]byte(`null`), testStruct{})
+	require.NoError(t, err)
+	require.Nil(t, unknown)
+
+	data, err := marshalWithUnknownJsonFields(testStruct{A: "x"}, unknownJsonFields{"a": json.RawMessage(`"y"`), "d": json.RawMessage("1")})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a": "x", "b": "", "C": "", "d": 1}`, string(data)) // known members win
+}
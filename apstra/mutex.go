@@ -28,3 +28,12 @@ type Mutex interface {
 	TryLock(context.Context) error
 	Unlock(context.Context) error
 }
+
+// RWMutex is a Mutex which also offers shared locks. Any number of shared
+// locks may be held at once, but not while the exclusive lock is held.
+type RWMutex interface {
+	Mutex
+	RLock(context.Context) error
+	TryRLock(context.Context) error
+	RUnlock(context.Context) error
+}
@@ -0,0 +1,129 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// mutexLeaseSkewAllowance is added to a lease's expiry before a waiter
+	// will break it, to tolerate clock differences between hosts.
+	mutexLeaseSkewAllowance = 5 * time.Second
+
+	// mutexLeaseVersion identifies lock tag descriptions which carry lease
+	// information. Descriptions without it were created by older versions of
+	// this package, and are treated as leases which never expire.
+	mutexLeaseVersion = 1
+)
+
+// MutexLease describes the holder of a locked mutex.
+type MutexLease struct {
+	Message  string    // the message set with SetMessage
+	Holder   string    // host, process and Apstra user which hold the lock; empty for legacy locks and locks without a lease
+	LeaseId  ObjectId  // unique to each Lock() operation; empty for legacy locks and locks without a lease
+	Acquired time.Time // zero for legacy locks and locks without a lease
+	Expires  time.Time // zero for legacy locks and locks without a lease, which never expire
+}
+
+type rawMutexLease struct {
+	Version  int        `json:"apstra_go_sdk_mutex"`
+	Message  string     `json:"message"`
+	Holder   string     `json:"holder"`
+	LeaseId  ObjectId   `json:"lease_id"`
+	Acquired time.Time  `json:"acquired"`
+	Expires  *time.Time `json:"expires,omitempty"`
+}
+
+// Expired returns true if the lease expired (with allowance for clock skew)
+// before now. Leases without an expiry never expire.
+func (o *MutexLease) Expired(now time.Time) bool {
+	return !o.Expires.IsZero() && now.After(o.Expires.Add(mutexLeaseSkewAllowance))
+}
+
+func (o *MutexLease) String() string {
+	if o.Holder == "" {
+		return fmt.Sprintf("legacy lock without lease: %q", o.Message)
+	}
+	result := fmt.Sprintf("held by %s since %s", o.Holder, o.Acquired.Format(time.RFC3339))
+	if !o.Expires.IsZero() {
+		result += fmt.Sprintf(", lease expires %s", o.Expires.Format(time.RFC3339))
+	}
+	if o.Message != "" {
+		result += fmt.Sprintf(": %q", o.Message)
+	}
+	return result
+}
+
+// description encodes the lease for storage in a lock tag's description.
+// Locks without a lease store only the message, exactly as versions of this
+// package which predate leases do, so that those versions display it as-is.
+func (o *MutexLease) description() string {
+	if o.Expires.IsZero() {
+		return o.Message
+	}
+
+	expires := o.Expires.UTC()
+	b, _ := json.Marshal(rawMutexLease{
+		Version:  mutexLeaseVersion,
+		Message:  o.Message,
+		Holder:   o.Holder,
+		LeaseId:  o.LeaseId,
+		Acquired: o.Acquired.UTC(),
+		Expires:  &expires,
+	})
+	return string(b)
+}
+
+// parseMutexLease decodes a lock tag description. Descriptions written by
+// older versions of this package contain only the lock message.
+func parseMutexLease(description string) *MutexLease {
+	var raw rawMutexLease
+	if json.Unmarshal([]byte(description), &raw) != nil || raw.Version != mutexLeaseVersion {
+		return &MutexLease{Message: description}
+	}
+
+	result := MutexLease{
+		Message:  raw.Message,
+		Holder:   raw.Holder,
+		LeaseId:  raw.LeaseId,
+		Acquired: raw.Acquired,
+	}
+	if raw.Expires != nil {
+		result.Expires = *raw.Expires
+	}
+
+	return &result
+}
+
+// newMutexLease creates a lease held by this process on behalf of the
+// given Apstra user.
+func newMutexLease(message string, userId ObjectId, duration time.Duration) (*MutexLease, error) {
+	leaseId, err := uuid1AsObjectId()
+	if err != nil {
+		return nil, fmt.Errorf("failed generating mutex lease ID - %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown host"
+	}
+
+	now := time.Now()
+	result := MutexLease{
+		Message:  message,
+		Holder:   fmt.Sprintf("%s pid %d user %s", hostname, os.Getpid(), userId),
+		LeaseId:  leaseId,
+		Acquired: now,
+	}
+	if duration > 0 {
+		result.Expires = now.Add(duration)
+	}
+
+	return &result, nil
+}
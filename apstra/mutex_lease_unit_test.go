@@ -0,0 +1,62 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutexLease(t *testing.T) {
+	now := time.Now()
+
+	t.Run("round_trip", func(t *testing.T) {
+		lease, err := newMutexLease("locked by test", "user1", time.Minute)
+		require.NoError(t, err)
+		require.NotEmpty(t, lease.LeaseId)
+		require.Contains(t, lease.Holder, "user user1")
+
+		parsed := parseMutexLease(lease.description())
+		require.Equal(t, lease.Message, parsed.Message)
+		require.Equal(t, lease.Holder, parsed.Holder)
+		require.Equal(t, lease.LeaseId, parsed.LeaseId)
+		require.True(t, lease.Expires.Equal(parsed.Expires))
+		require.False(t, parsed.Expired(now))
+		require.False(t, parsed.Expired(now.Add(time.Minute)), "skew allowance should apply")
+		require.True(t, parsed.Expired(now.Add(time.Minute+mutexLeaseSkewAllowance+time.Second)))
+	})
+
+	t.Run("no_lease", func(t *testing.T) {
+		lease, err := newMutexLease("locked by test", "user1", 0)
+		require.NoError(t, err)
+
+		// without a lease, the description is readable by older versions
+		require.Equal(t, "locked by test", lease.description())
+
+		parsed := parseMutexLease(lease.description())
+		require.Equal(t, "locked by test", parsed.Message)
+		require.True(t, parsed.Expires.IsZero())
+		require.False(t, parsed.Expired(now.Add(24*time.Hour)))
+	})
+
+	t.Run("opt_in", func(t *testing.T) {
+		require.Zero(t, newBlueprintMutex(nil, "bp1").leaseDuration)
+
+		mutex, err := (&Client{}).NewMutex("test")
+		require.NoError(t, err)
+		require.Zero(t, mutex.leaseDuration)
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		for _, description := range []string{"", "locked by terraform", `{"message": "json, but not ours"}`} {
+			parsed := parseMutexLease(description)
+			require.Equal(t, description, parsed.Message)
+			require.Empty(t, parsed.LeaseId)
+			require.False(t, parsed.Expired(now.Add(24*time.Hour)), "legacy locks never expire")
+		}
+	})
+}
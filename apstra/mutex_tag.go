@@ -0,0 +1,716 @@
+// Copyright (c) Juniper Networks, Inc., 2022-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tagNameLenMax          = 64                    // apstra 4.1.0 limit
+	lockTagName            = "blueprint %s locked" // BP GUID is 36 char, this should fit
+	sharedLockTagPrefix    = "blueprint %s shared "
+	namedLockTagName       = "mutex %s locked"
+	namedSharedLockTagName = "mutex %s shared "
+	sharedLockSuffixBytes  = 4 // hex encoded, so 8 characters appended to the shared lock tag prefix
+	lockPollInterval       = 500 * time.Millisecond
+)
+
+var _ RWMutex = new(TagMutex)
+
+// TagMutex is an RWMutex implemented with Apstra design tags. Because tag
+// labels must be unique, only one client can create the tag which represents
+// the exclusive lock. Shared locks are represented by tags with unique labels
+// which begin with a common prefix. Locks may carry a lease (see SetLease) so
+// that the locks of crashed clients can be recovered.
+//
+// Blueprint clients (TwoStageL3ClosClient, FreeformClient) come with a
+// TagMutex. Mutexes protecting other resources are created with
+// Client.NewMutex.
+type TagMutex struct {
+	client       *Client
+	blueprintId  ObjectId                                 // empty for mutexes which don't represent a blueprint
+	label        string                                   // label of the exclusive lock tag
+	sharedPrefix string                                   // prefix of shared lock tag labels
+	lockInfo     func(context.Context) (*LockInfo, error) // optional; exclusive locks wait for the blueprint to be unlocked in Apstra
+
+	tagId         ObjectId
+	tagLabel      string
+	shared        bool
+	readOnly      bool
+	message       string
+	leaseDuration time.Duration // zero disables the lease, the lock never expires
+	lease         *MutexLease   // the lease we hold, or (read-only mutex) the lease held by somebody else
+
+	heartbeatLock sync.Mutex    // protects the channels below
+	heartbeatStop chan struct{} // closed by Unlock to stop the heartbeat
+	heartbeatDone chan struct{} // closed by the heartbeat when it exits
+	leaseLost     chan struct{} // closed by the heartbeat if the lease can't be renewed
+}
+
+// newBlueprintMutex returns the mutex which protects the specified blueprint.
+// Mutexes of all blueprint types use the same tags, and are compatible with
+// versions of this package which predate shared locks.
+func newBlueprintMutex(client *Client, blueprintId ObjectId) *TagMutex {
+	return &TagMutex{
+		client:       client,
+		blueprintId:  blueprintId,
+		label:        fmt.Sprintf(lockTagName, blueprintId),
+		sharedPrefix: fmt.Sprintf(sharedLockTagPrefix, blueprintId),
+	}
+}
+
+// NewMutex returns a mutex which coordinates access to a named resource
+// among all clients of the Apstra server, e.g. "asn 65001", or
+// "ip pool <pool-id>". Clients which share a resource must agree on its name.
+// Names are limited to 42 characters.
+func (o *Client) NewMutex(name string) (*TagMutex, error) {
+	if name == "" {
+		return nil, errors.New("mutex name must not be empty")
+	}
+
+	result := TagMutex{
+		client:       o,
+		label:        fmt.Sprintf(namedLockTagName, name),
+		sharedPrefix: fmt.Sprintf(namedSharedLockTagName, name),
+	}
+
+	if len(result.sharedPrefix)+2*sharedLockSuffixBytes > tagNameLenMax {
+		return nil, fmt.Errorf("mutex name %q is too long, shared lock tag label would exceed %d characters",
+			name, tagNameLenMax)
+	}
+
+	return &result, nil
+}
+
+// GetMessage returns the message embedded in the mutex
+func (o *TagMutex) GetMessage() string {
+	return o.message
+}
+
+// SetMessage sets the lock message embedded in the mutex
+func (o *TagMutex) SetMessage(msg string) error {
+	if o.readOnly {
+		return ClientErr{
+			errType: ErrReadOnly,
+			err:     errors.New("attempt to set message of a read-only mutex"),
+		}
+	}
+	if o.tagId != "" {
+		return errors.New("attempt to set message of a locked mutex")
+	}
+	o.message = msg
+	return nil
+}
+
+// SetLease sets the duration of the lease taken by Lock and TryLock. While
+// the mutex is locked, a background heartbeat renews the lease every
+// duration/3. If the holder dies, other clients may break the lock once the
+// lease expires. Leases are disabled by default (zero duration): the lock
+// never expires, as with versions of this package which predate leases.
+// Lease-bearing locks record the holder and expiry as JSON in the lock tag
+// description, which those older versions display as the lock message.
+func (o *TagMutex) SetLease(duration time.Duration) error {
+	if o.readOnly {
+		return ClientErr{
+			errType: ErrReadOnly,
+			err:     errors.New("attempt to set lease of a read-only mutex"),
+		}
+	}
+	if o.tagId != "" {
+		return errors.New("attempt to set lease of a locked mutex")
+	}
+	if duration < 0 {
+		return fmt.Errorf("mutex lease duration must not be negative, got %s", duration)
+	}
+	o.leaseDuration = duration
+	return nil
+}
+
+// Lease returns the lease held by this mutex, or, in the case of a read-only
+// mutex found in a MutexErr, the lease held by whoever locked the resource.
+// Returns nil when the mutex is not locked.
+func (o *TagMutex) Lease() *MutexLease {
+	if o.lease == nil {
+		return nil
+	}
+	result := *o.lease
+	return &result
+}
+
+// LeaseLost returns a channel which is closed if the heartbeat fails to renew
+// the lease before it expires, or finds the lock has been broken (e.g. with
+// ForceUnlock). The channel is nil when the mutex is not locked.
+func (o *TagMutex) LeaseLost() <-chan struct{} {
+	o.heartbeatLock.Lock()
+	defer o.heartbeatLock.Unlock()
+	return o.leaseLost
+}
+
+// Shared returns true when the mutex holds (or, in the case of a read-only
+// mutex found in a MutexErr, represents) a shared lock.
+func (o *TagMutex) Shared() bool {
+	return o.shared
+}
+
+// BlueprintID returns the Blueprint ID, or an empty string for mutexes which
+// protect something other than a blueprint.
+func (o *TagMutex) BlueprintID() ObjectId {
+	return o.blueprintId
+}
+
+// Lock attempts to assert the exclusive lock, repeatedly trying until the
+// context.Context expires or it encounters an error.
+func (o *TagMutex) Lock(ctx context.Context) error {
+	return o.lock(ctx, false)
+}
+
+// TryLock attempts to assert the exclusive lock without blocking.
+func (o *TagMutex) TryLock(ctx context.Context) error {
+	return o.lock(ctx, true)
+}
+
+// RLock attempts to assert a shared lock, repeatedly trying until the
+// context.Context expires or it encounters an error. Any number of shared
+// locks may be held at once, but not while the exclusive lock is held.
+func (o *TagMutex) RLock(ctx context.Context) error {
+	return o.rlock(ctx, false)
+}
+
+// TryRLock attempts to assert a shared lock without blocking.
+func (o *TagMutex) TryRLock(ctx context.Context) error {
+	return o.rlock(ctx, true)
+}
+
+// Unlock releases the exclusive lock
+func (o *TagMutex) Unlock(ctx context.Context) error {
+	return o.unlock(ctx, false)
+}
+
+// RUnlock releases a shared lock
+func (o *TagMutex) RUnlock(ctx context.Context) error {
+	return o.unlock(ctx, true)
+}
+
+// lock's behavior is controlled by the nonBlocking boolean. When called with
+// nonBlocking == false, it will block until it asserts the mutex/tag, or an
+// error is encountered. When called with nonBlocking == true, it will return
+// a MutexErr populated with either a *LockInfo, indicating an Apstra blueprint
+// lock was in place, or a *Mutex indicating somebody else has asserted the
+// tag/mutex. In either case, the caller can inspect the MutexErr to learn
+// exactly what went wrong.
+func (o *TagMutex) lock(ctx context.Context, nonBlocking bool) error {
+	if o.readOnly {
+		return errors.New("attempt to lock read-only mutex")
+	}
+
+	if o.tagId != "" {
+		return fmt.Errorf("attempt to lock previously locked mutex - previous lock ID %q", o.tagId)
+	}
+
+	if len(o.label) > tagNameLenMax {
+		return fmt.Errorf("lock name %q exceeds limit (max %d characters)", o.label, tagNameLenMax)
+	}
+
+	if o.lockInfo != nil {
+		err := o.awaitBlueprintUnlocked(ctx, nonBlocking)
+		if err != nil {
+			return err
+		}
+	}
+
+	// loop until we acquire the lock or the context deadline (set by caller) expires.
+	tickerB := immediateTicker(lockPollInterval)
+	defer tickerB.Stop()
+	var ace ClientErr
+	var tagID ObjectId
+	var lease *MutexLease
+	var err error
+	for tagID == "" {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while trying to establish lock - %w", ctx.Err())
+		case <-tickerB.C:
+		}
+
+		lease, err = newMutexLease(o.message, o.client.ID(), o.leaseDuration)
+		if err != nil {
+			return err
+		}
+
+		tagID, err = o.client.createTag(ctx, &DesignTagRequest{
+			Label:       o.label,
+			Description: lease.description(),
+		})
+		if err != nil {
+			if errors.As(err, &ace) && ace.errType == ErrExists {
+				// mutex already exists
+				if !nonBlocking {
+					// caller specified blocking behavior. break the lock if
+					// its lease has expired, otherwise try again.
+					if err = o.breakExpiredLeaseByLabel(ctx, o.label); err != nil {
+						return err
+					}
+					continue
+				}
+
+				// retrieve the offending tag so we can inform the caller about it
+				tag, err := o.client.getTagByLabel(ctx, o.label)
+				if err != nil {
+					if errors.As(err, &ace) {
+						// offending tag deleted in the last few milliseconds? Try again.
+						continue
+					}
+					// error retrieving the offending tag. blow up in the caller's face.
+					return err
+				}
+
+				holder := o.readOnlyMutex(tag, false)
+				if holder.lease.Expired(time.Now()) {
+					// the holder failed to renew its lease. break the lock and try again.
+					if err = o.breakExpiredLease(ctx, tag.Id, holder.lease.LeaseId); err != nil {
+						return err
+					}
+					continue
+				}
+
+				return holder.mutexErr()
+			}
+			// some other tag creation error
+			return err
+		}
+	}
+
+	o.tagId = tagID
+	o.tagLabel = o.label
+	o.shared = false
+	o.lease = lease
+	o.startHeartbeat()
+
+	// We hold the exclusive lock tag, so no new shared locks will be granted.
+	// Wait for existing shared lock holders to finish.
+	for {
+		holders, err := o.sharedLockHolders(ctx)
+		if err != nil {
+			_ = o.unlock(context.WithoutCancel(ctx), false)
+			return err
+		}
+
+		if len(holders) == 0 {
+			return nil
+		}
+
+		if nonBlocking {
+			_ = o.unlock(context.WithoutCancel(ctx), false)
+			return MutexErr{
+				err: fmt.Errorf("unable to lock mutex %q: %d shared locks held, including %s",
+					o.label, len(holders), holders[0].lease.String()),
+				Mutex: holders[0],
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			_ = o.unlock(context.WithoutCancel(ctx), false)
+			return fmt.Errorf("context cancelled while waiting for shared locks to be released - %w", ctx.Err())
+		case <-tickerB.C:
+		}
+	}
+}
+
+// awaitBlueprintUnlocked waits for Apstra's own blueprint lock (uncommitted
+// changes by another user) to be released.
+func (o *TagMutex) awaitBlueprintUnlocked(ctx context.Context, nonBlocking bool) error {
+	// set initial LockStatus to bogus value b/c desired state is "0"
+	li := &LockInfo{LockStatus: -1}
+
+	var err error
+	tickerA := immediateTicker(lockPollInterval)
+	defer tickerA.Stop()
+
+	// loop until the blueprint is unlocked (no uncommitted changes by other users)
+	for li.LockStatus != LockStatusUnlocked {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while waiting for blueprint lock status to be %q - %w",
+				LockStatusUnlocked.String(), ctx.Err())
+		case <-tickerA.C:
+		}
+
+		li, err = o.lockInfo(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Pass when locked by our own ID.
+		if li.UserId == o.client.ID() {
+			break
+		}
+
+		if nonBlocking && li.LockStatus != LockStatusUnlocked {
+			return MutexErr{
+				LockInfo: li,
+				err:      fmt.Errorf("blueprint %q: %s", o.blueprintId, li.String()),
+			}
+		}
+	}
+
+	return nil
+}
+
+// rlock asserts a shared lock. Its behavior is controlled by nonBlocking in
+// the same way as lock.
+func (o *TagMutex) rlock(ctx context.Context, nonBlocking bool) error {
+	if o.readOnly {
+		return errors.New("attempt to lock read-only mutex")
+	}
+
+	if o.tagId != "" {
+		return fmt.Errorf("attempt to lock previously locked mutex - previous lock ID %q", o.tagId)
+	}
+
+	if len(o.sharedPrefix)+2*sharedLockSuffixBytes > tagNameLenMax {
+		return fmt.Errorf("shared lock name prefix %q exceeds limit (max %d characters)", o.sharedPrefix, tagNameLenMax)
+	}
+
+	ticker := immediateTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context cancelled while trying to establish shared lock - %w", ctx.Err())
+		case <-ticker.C:
+		}
+
+		// don't bother creating a shared lock tag while somebody holds the exclusive lock
+		holder, err := o.exclusiveLockHolder(ctx)
+		if err != nil {
+			return err
+		}
+		if holder != nil {
+			if nonBlocking {
+				return holder.mutexErr()
+			}
+			continue
+		}
+
+		lease, err := newMutexLease(o.message, o.client.ID(), o.leaseDuration)
+		if err != nil {
+			return err
+		}
+
+		suffix := make([]byte, sharedLockSuffixBytes)
+		if _, err = rand.Read(suffix); err != nil {
+			return fmt.Errorf("failed generating shared lock tag label - %w", err)
+		}
+		label := o.sharedPrefix + hex.EncodeToString(suffix)
+
+		tagId, err := o.client.createTag(ctx, &DesignTagRequest{
+			Label:       label,
+			Description: lease.description(),
+		})
+		if err != nil {
+			var ace ClientErr
+			if errors.As(err, &ace) && ace.errType == ErrExists {
+				continue // label collision. try again with a new suffix.
+			}
+			return err
+		}
+
+		// Somebody may have asserted the exclusive lock before our shared
+		// lock tag existed, in which case they're not waiting for us. Back off.
+		holder, err = o.exclusiveLockHolder(ctx)
+		if err != nil || holder != nil {
+			_ = o.client.deleteTag(context.WithoutCancel(ctx), tagId)
+			switch {
+			case err != nil:
+				return err
+			case nonBlocking:
+				return holder.mutexErr()
+			}
+			continue
+		}
+
+		o.tagId = tagId
+		o.tagLabel = label
+		o.shared = true
+		o.lease = lease
+		o.startHeartbeat()
+		return nil
+	}
+}
+
+// exclusiveLockHolder returns a read-only mutex representing the holder of
+// the exclusive lock, or nil if the exclusive lock is not held. Expired
+// exclusive locks are broken.
+func (o *TagMutex) exclusiveLockHolder(ctx context.Context) (*TagMutex, error) {
+	tag, err := o.client.getTagByLabel(ctx, o.label)
+	if err != nil {
+		var ace ClientErr
+		if errors.As(err, &ace) && ace.Type() == ErrNotfound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	holder := o.readOnlyMutex(tag, false)
+	if holder.lease.Expired(time.Now()) {
+		return nil, o.breakExpiredLease(ctx, tag.Id, holder.lease.LeaseId)
+	}
+
+	return holder, nil
+}
+
+// sharedLockHolders returns read-only mutexes representing holders of shared
+// locks other than o. Expired shared locks are broken.
+func (o *TagMutex) sharedLockHolders(ctx context.Context) ([]*TagMutex, error) {
+	tags, err := o.client.getAllTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*TagMutex
+	for i, tag := range tags {
+		if tag.Id == o.tagId || !strings.HasPrefix(tag.Label, o.sharedPrefix) {
+			continue
+		}
+
+		holder := o.readOnlyMutex(&tags[i], true)
+		if holder.lease.Expired(time.Now()) {
+			if err = o.breakExpiredLease(ctx, tag.Id, holder.lease.LeaseId); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		result = append(result, holder)
+	}
+
+	return result, nil
+}
+
+// readOnlyMutex returns a read-only copy of o representing the lock held via
+// the given tag.
+func (o *TagMutex) readOnlyMutex(tag *rawDesignTag, shared bool) *TagMutex {
+	lease := parseMutexLease(tag.Description)
+	return &TagMutex{
+		client:       o.client,
+		blueprintId:  o.blueprintId,
+		label:        o.label,
+		sharedPrefix: o.sharedPrefix,
+		tagId:        tag.Id,
+		tagLabel:     tag.Label,
+		shared:       shared,
+		readOnly:     true,
+		message:      lease.Message,
+		lease:        lease,
+	}
+}
+
+// mutexErr returns a MutexErr which informs the caller about the lock held
+// by read-only mutex o.
+func (o *TagMutex) mutexErr() MutexErr {
+	tagURL := fmt.Sprintf(apiUrlDesignTagById, o.tagId)
+	return MutexErr{
+		err:   fmt.Errorf("unable to lock mutex due to: %q (%s)", tagURL, o.lease.String()),
+		Mutex: o,
+	}
+}
+
+// startHeartbeat launches a goroutine which renews the lease every
+// leaseDuration/3 until the mutex is unlocked. It does nothing when the
+// mutex has no lease.
+func (o *TagMutex) startHeartbeat() {
+	if o.leaseDuration <= 0 {
+		return
+	}
+
+	o.heartbeatLock.Lock()
+	defer o.heartbeatLock.Unlock()
+
+	stop, done, lost := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	o.heartbeatStop, o.heartbeatDone, o.leaseLost = stop, done, lost
+
+	tagId, label, lease, interval := o.tagId, o.tagLabel, *o.lease, o.leaseDuration/3
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			renewed := lease
+			renewed.Expires = time.Now().Add(o.leaseDuration)
+
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := o.client.updateTag(ctx, tagId, &DesignTagRequest{
+				Label:       label,
+				Description: renewed.description(),
+			})
+			cancel()
+			if err == nil {
+				lease = renewed
+				continue
+			}
+
+			// The lock tag is gone (somebody broke the lock), or we've been
+			// unable to renew the lease before it expired. Either way, the
+			// lock is no longer ours.
+			var ace ClientErr
+			if (errors.As(err, &ace) && ace.Type() == ErrNotfound) || time.Now().After(lease.Expires) {
+				close(lost)
+				return
+			}
+		}
+	}()
+}
+
+// stopHeartbeat stops the heartbeat goroutine (if any) and waits for it to
+// exit.
+func (o *TagMutex) stopHeartbeat() {
+	o.heartbeatLock.Lock()
+	defer o.heartbeatLock.Unlock()
+
+	if o.heartbeatStop == nil {
+		return
+	}
+	close(o.heartbeatStop)
+	<-o.heartbeatDone
+	o.heartbeatStop, o.heartbeatDone, o.leaseLost = nil, nil, nil
+}
+
+// breakExpiredLeaseByLabel deletes the named lock tag if its lease has
+// expired.
+func (o *TagMutex) breakExpiredLeaseByLabel(ctx context.Context, label string) error {
+	tag, err := o.client.getTagByLabel(ctx, label)
+	if err != nil {
+		var ace ClientErr
+		if errors.As(err, &ace) && ace.Type() == ErrNotfound {
+			return nil // unlocked in the meantime
+		}
+		return err
+	}
+
+	lease := parseMutexLease(tag.Description)
+	if !lease.Expired(time.Now()) {
+		return nil
+	}
+
+	return o.breakExpiredLease(ctx, tag.Id, lease.LeaseId)
+}
+
+// breakExpiredLease deletes the lock tag with the given ID. The tag is
+// re-read immediately beforehand, and left alone if the lease has been
+// renewed or replaced (by a different lease ID) since the caller found it to
+// be expired. Because the tag is deleted by ID, a lock which somebody else
+// established after breaking the same lease is not affected.
+func (o *TagMutex) breakExpiredLease(ctx context.Context, tagId ObjectId, leaseId ObjectId) error {
+	var ace ClientErr
+
+	tag, err := o.client.getTag(ctx, tagId)
+	if err != nil {
+		if errors.As(convertTtaeToAceWherePossible(err), &ace) && ace.Type() == ErrNotfound {
+			return nil
+		}
+		return err
+	}
+
+	lease := parseMutexLease(tag.Description)
+	if lease.LeaseId != leaseId || !lease.Expired(time.Now()) {
+		return nil
+	}
+
+	err = convertTtaeToAceWherePossible(o.client.deleteTag(ctx, tagId))
+	if err != nil && !(errors.As(err, &ace) && ace.Type() == ErrNotfound) {
+		return fmt.Errorf("failed breaking expired lock %q (%s) - %w", tag.Label, lease.String(), err)
+	}
+
+	return nil
+}
+
+// unlock releases the exclusive (shared == false) or shared lock
+func (o *TagMutex) unlock(ctx context.Context, shared bool) error {
+	if o.readOnly {
+		return ClientErr{
+			errType: ErrReadOnly,
+			err:     errors.New("attempt to unlock read-only mutex"),
+		}
+	}
+
+	if o.tagId == "" {
+		return errors.New("attempt to unlock mutex which is not locked")
+	}
+
+	if o.shared != shared {
+		if o.shared {
+			return errors.New("attempt to Unlock a shared lock, use RUnlock")
+		}
+		return errors.New("attempt to RUnlock an exclusive lock, use Unlock")
+	}
+
+	o.stopHeartbeat()
+
+	err := convertTtaeToAceWherePossible(o.client.deleteTag(ctx, o.tagId))
+	if err != nil {
+		var ace ClientErr
+		if !errors.As(err, &ace) || ace.Type() != ErrNotfound {
+			return err
+		}
+	}
+
+	o.tagId = ""
+	o.tagLabel = ""
+	o.shared = false
+	o.lease = nil
+	return nil
+}
+
+// ForceUnlock is an administrative function which removes the exclusive lock
+// and all shared locks regardless of who holds them, e.g. to recover from a
+// crashed holder whose lock has no lease. It may be called on any mutex,
+// including the read-only mutex found in a MutexErr. A holder whose lock is
+// removed this way learns of it via LeaseLost.
+func (o *TagMutex) ForceUnlock(ctx context.Context) error {
+	tags, err := o.client.getAllTags(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		if tag.Label != o.label && !strings.HasPrefix(tag.Label, o.sharedPrefix) {
+			continue
+		}
+
+		err = convertTtaeToAceWherePossible(o.client.deleteTag(ctx, tag.Id))
+		if err != nil {
+			var ace ClientErr
+			if !errors.As(err, &ace) || ace.Type() != ErrNotfound {
+				return fmt.Errorf("failed deleting lock tag %q - %w", tag.Label, err)
+			}
+		}
+
+		if tag.Id == o.tagId && !o.readOnly {
+			o.stopHeartbeat()
+			o.tagId = ""
+			o.tagLabel = ""
+			o.shared = false
+			o.lease = nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSharedLocksFreeformBlueprintMutex checks that many shared locks may be
+// held at once, and that they exclude (and are excluded by) the exclusive
+// lock.
+func TestSharedLocksFreeformBlueprintMutex(t *testing.T) {
+	ctx := context.Background()
+
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bpA := testFFBlueprintA(ctx, t, client.client)
+			bpB, err := client.client.NewFreeformClient(ctx, bpA.Id())
+			require.NoError(t, err)
+			bpC, err := client.client.NewFreeformClient(ctx, bpA.Id())
+			require.NoError(t, err)
+
+			log.Printf("testing RLock() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, bpA.Mutex.RLock(ctx))
+			require.NoError(t, bpB.Mutex.TryRLock(ctx))
+
+			var mutexErr MutexErr
+			log.Printf("testing TryLock() against shared locks %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			err = bpC.Mutex.TryLock(ctx)
+			require.ErrorAs(t, err, &mutexErr)
+			require.True(t, mutexErr.Mutex.(*TagMutex).Shared())
+
+			require.Error(t, bpA.Mutex.Unlock(ctx), "Unlock of a shared lock should fail")
+			require.NoError(t, bpA.Mutex.RUnlock(ctx))
+			require.NoError(t, bpB.Mutex.RUnlock(ctx))
+
+			log.Printf("testing TryLock() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, bpC.Mutex.TryLock(ctx))
+
+			log.Printf("testing TryRLock() against exclusive lock %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			err = bpA.Mutex.TryRLock(ctx)
+			require.ErrorAs(t, err, &mutexErr)
+			require.False(t, mutexErr.Mutex.(*TagMutex).Shared())
+
+			require.NoError(t, bpC.Mutex.Unlock(ctx))
+		})
+	}
+}
+
+// TestNamedMutex checks that Lock() of a named mutex blocks until the
+// holder unlocks it.
+func TestNamedMutex(t *testing.T) {
+	ctx := context.Background()
+
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			name := fmt.Sprintf("asn pool %s", randString(6, "hex"))
+			mutexA, err := client.client.NewMutex(name)
+			require.NoError(t, err)
+			mutexB, err := client.client.NewMutex(name)
+			require.NoError(t, err)
+			require.Empty(t, mutexA.BlueprintID())
+
+			log.Printf("testing Lock() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, mutexA.Lock(ctx))
+
+			unlockAt := time.Now().Add(2 * time.Second)
+			go func() {
+				time.Sleep(time.Until(unlockAt))
+				require.NoError(t, mutexA.Unlock(ctx))
+			}()
+
+			log.Printf("testing blocking Lock() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			lockCtx, cancel := context.WithTimeout(ctx, time.Minute)
+			defer cancel()
+			require.NoError(t, mutexB.Lock(lockCtx))
+			require.False(t, time.Now().Before(unlockAt))
+			require.NoError(t, mutexB.Unlock(ctx))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMutexLabels(t *testing.T) {
+	client := &Client{}
+
+	_, err := client.NewMutex("")
+	require.Error(t, err)
+
+	_, err = client.NewMutex(strings.Repeat("x", 43))
+	require.Error(t, err)
+
+	m, err := client.NewMutex(strings.Repeat("x", 42))
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(m.label), tagNameLenMax)
+	require.Equal(t, tagNameLenMax, len(m.sharedPrefix)+2*sharedLockSuffixBytes)
+
+	bp := newBlueprintMutex(client, "8c8d8da3-3ff7-4a8c-b4a3-a0a4b1f8b1e5")
+	require.Equal(t, "blueprint 8c8d8da3-3ff7-4a8c-b4a3-a0a4b1f8b1e5 locked", bp.label)
+	require.LessOrEqual(t, len(bp.sharedPrefix)+2*sharedLockSuffixBytes, tagNameLenMax)
+}
+
+func TestTwoStageL3ClosClientRWMutex(t *testing.T) {
+	bp := &TwoStageL3ClosClient{client: &Client{}, blueprintId: "8c8d8da3-3ff7-4a8c-b4a3-a0a4b1f8b1e5"}
+	bp.Mutex = newTwoStageL3ClosMutex(bp)
+
+	rw, ok := bp.RWMutex()
+	require.True(t, ok)
+	require.Equal(t, bp.Mutex, rw)
+
+	// callers may supply their own Mutex, which might not offer shared locks
+	bp.Mutex = exclusiveOnlyMutex{Mutex: bp.Mutex}
+	_, ok = bp.RWMutex()
+	require.False(t, ok)
+}
+
+type exclusiveOnlyMutex struct {
+	Mutex
+}
@@ -0,0 +1,360 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const routingZoneDefaultVrfName = "default"
+
+type RoutingZoneConflictType int
+
+const (
+	RoutingZoneConflictRouteTarget = RoutingZoneConflictType(iota) // route target assigned to more than one object
+	RoutingZoneConflictVni                                         // VNI assigned to more than one object
+	RoutingZoneConflictVrfName                                     // VRF name used in more than one blueprint
+	RoutingZoneConflictRouteLeak                                   // route target imported from a different VRF
+)
+
+func (o RoutingZoneConflictType) String() string {
+	switch o {
+	case RoutingZoneConflictRouteTarget:
+		return "duplicate route target"
+	case RoutingZoneConflictVni:
+		return "duplicate VNI"
+	case RoutingZoneConflictVrfName:
+		return "duplicate VRF name"
+	case RoutingZoneConflictRouteLeak:
+		return "route leak"
+	default:
+		return fmt.Sprintf("unknown routing zone conflict type %d", o)
+	}
+}
+
+// RoutingZoneObject identifies a routing zone (security zone), or a virtual
+// network when VirtualNetworkId is set.
+type RoutingZoneObject struct {
+	BlueprintId      ObjectId
+	SecurityZoneId   ObjectId
+	VirtualNetworkId ObjectId
+	Label            string
+	VrfName          string // VRF of the routing zone, or of the virtual network's routing zone
+}
+
+func (o RoutingZoneObject) String() string {
+	if o.VirtualNetworkId != "" {
+		return fmt.Sprintf("virtual network %q (%s) in VRF %q of blueprint %s", o.Label, o.VirtualNetworkId, o.VrfName, o.BlueprintId)
+	}
+	return fmt.Sprintf("routing zone %q (%s) with VRF %q in blueprint %s", o.Label, o.SecurityZoneId, o.VrfName, o.BlueprintId)
+}
+
+// RoutingZoneConflict is a problem found by FindRoutingZoneConflicts. Value
+// is the route target, VNI or VRF name in question. For RoutingZoneConflictRouteLeak,
+// Objects[0] imports the route target Value, which is exported by each of
+// the remaining Objects from a different VRF.
+type RoutingZoneConflict struct {
+	Type    RoutingZoneConflictType
+	Value   string
+	Objects []RoutingZoneObject
+}
+
+func (o RoutingZoneConflict) String() string {
+	objects := make([]string, len(o.Objects))
+	for i, object := range o.Objects {
+		objects[i] = object.String()
+	}
+	return fmt.Sprintf("%s %q: %s", o.Type, o.Value, strings.Join(objects, ", "))
+}
+
+// RoutingZoneConflictOptions adjusts the checks made by
+// FindRoutingZoneConflicts. Objects in different blueprints with the same VRF
+// name are considered to belong to the same tenant, and DCI peers are expected
+// to share their tenants' VRF names, route targets and stretched VNIs. Those
+// duplicates are only reported when SharedTenantDuplicates is set.
+type RoutingZoneConflictOptions struct {
+	SharedTenantDuplicates bool
+}
+
+// RoutingZoneInventory is the routing zones and virtual networks of one
+// blueprint, as returned by GetAllSecurityZones and GetAllVirtualNetworks.
+type RoutingZoneInventory struct {
+	BlueprintId     ObjectId
+	SecurityZones   []SecurityZone
+	VirtualNetworks map[ObjectId]VirtualNetwork
+}
+
+// routingZoneEntry is a routing zone or virtual network, with the values which
+// may conflict.
+type routingZoneEntry struct {
+	object    RoutingZoneObject
+	vni       *int
+	exportRTs []string // includes the object's own route target
+	importRTs []string // includes the object's own route target
+}
+
+func newRoutingZoneEntry(object RoutingZoneObject, routeTarget *string, rtPolicy *RtPolicy, vni *int) routingZoneEntry {
+	result := routingZoneEntry{object: object, vni: vni}
+
+	add := func(rts []string, rt string) []string {
+		rt = strings.TrimSpace(rt)
+		if rt == "" || itemInSlice(rt, rts) {
+			return rts
+		}
+		return append(rts, rt)
+	}
+
+	if routeTarget != nil {
+		result.exportRTs = add(result.exportRTs, *routeTarget)
+		result.importRTs = add(result.importRTs, *routeTarget)
+	}
+	if rtPolicy != nil {
+		for _, rt := range rtPolicy.ExportRTs {
+			result.exportRTs = add(result.exportRTs, rt)
+		}
+		for _, rt := range rtPolicy.ImportRTs {
+			result.importRTs = add(result.importRTs, rt)
+		}
+	}
+
+	return result
+}
+
+func (o *routingZoneEntry) routeTarget() string {
+	if len(o.exportRTs) == 0 {
+		return ""
+	}
+	return o.exportRTs[0]
+}
+
+// routingZoneEntries flattens the inventories, sorted for stable output
+func routingZoneEntries(inventories []RoutingZoneInventory) []routingZoneEntry {
+	var result []routingZoneEntry
+	for _, inventory := range inventories {
+		vrfNames := make(map[ObjectId]string, len(inventory.SecurityZones))
+		for _, sz := range inventory.SecurityZones {
+			vrfNames[sz.Id] = sz.Data.VrfName
+			if sz.Data.SzType == SecurityZoneTypeL3Fabric || sz.Data.VrfName == routingZoneDefaultVrfName {
+				continue // every blueprint has a default routing zone
+			}
+			result = append(result, newRoutingZoneEntry(RoutingZoneObject{
+				BlueprintId:    inventory.BlueprintId,
+				SecurityZoneId: sz.Id,
+				Label:          sz.Data.Label,
+				VrfName:        sz.Data.VrfName,
+			}, sz.Data.RouteTarget, sz.Data.RtPolicy, sz.Data.VniId))
+		}
+
+		for _, vnId := range sortedKeys(inventory.VirtualNetworks) {
+			vn := inventory.VirtualNetworks[vnId]
+
+			var routeTarget *string
+			if vn.Data.RouteTarget != "" {
+				routeTarget = &vn.Data.RouteTarget
+			}
+
+			var vni *int
+			if vn.Data.VnId != nil {
+				i := int(*vn.Data.VnId)
+				vni = &i
+			}
+
+			result = append(result, newRoutingZoneEntry(RoutingZoneObject{
+				BlueprintId:      inventory.BlueprintId,
+				SecurityZoneId:   vn.Data.SecurityZoneId,
+				VirtualNetworkId: vnId,
+				Label:            vn.Data.Label,
+				VrfName:          vrfNames[vn.Data.SecurityZoneId],
+			}, routeTarget, vn.Data.RtPolicy, vni))
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i].object, result[j].object
+		if a.BlueprintId != b.BlueprintId {
+			return a.BlueprintId < b.BlueprintId
+		}
+		if a.SecurityZoneId != b.SecurityZoneId {
+			return a.SecurityZoneId < b.SecurityZoneId
+		}
+		return a.VirtualNetworkId < b.VirtualNetworkId
+	})
+
+	return result
+}
+
+// FindRoutingZoneConflicts checks the routing zones and virtual networks of
+// one or more blueprints (e.g. DCI peers) for:
+//   - route targets assigned to more than one routing zone or virtual network
+//   - VNIs assigned to more than one routing zone or virtual network
+//   - VRF names used in more than one blueprint (only with
+//     RoutingZoneConflictOptions.SharedTenantDuplicates)
+//   - route leaks: routing zones or virtual networks which import a route
+//     target exported from a different VRF.
+//
+// The default routing zone is not checked. See RoutingZoneConflictOptions
+// regarding values shared between blueprints by the same tenant.
+func FindRoutingZoneConflicts(inventories []RoutingZoneInventory, options RoutingZoneConflictOptions) []RoutingZoneConflict {
+	entries := routingZoneEntries(inventories)
+
+	var result []RoutingZoneConflict
+
+	// duplicate route targets and VNIs
+	byRouteTarget := make(map[string][]RoutingZoneObject)
+	byVni := make(map[string][]RoutingZoneObject)
+	for _, entry := range entries {
+		if rt := entry.routeTarget(); rt != "" {
+			byRouteTarget[rt] = append(byRouteTarget[rt], entry.object)
+		}
+		if entry.vni != nil {
+			vni := strconv.Itoa(*entry.vni)
+			byVni[vni] = append(byVni[vni], entry.object)
+		}
+	}
+	result = appendRoutingZoneDuplicates(result, RoutingZoneConflictRouteTarget, byRouteTarget, options)
+	result = appendRoutingZoneDuplicates(result, RoutingZoneConflictVni, byVni, options)
+
+	// VRF names used in more than one blueprint are, by definition, shared
+	// by a single tenant
+	if options.SharedTenantDuplicates {
+		byVrfName := make(map[string][]RoutingZoneObject)
+		for _, entry := range entries {
+			if entry.object.VirtualNetworkId == "" && entry.object.VrfName != "" {
+				byVrfName[entry.object.VrfName] = append(byVrfName[entry.object.VrfName], entry.object)
+			}
+		}
+		result = appendRoutingZoneDuplicates(result, RoutingZoneConflictVrfName, byVrfName, options)
+	}
+
+	// route leaks
+	exporters := make(map[string][]RoutingZoneObject)
+	for _, entry := range entries {
+		for _, rt := range entry.exportRTs {
+			exporters[rt] = append(exporters[rt], entry.object)
+		}
+	}
+	for _, entry := range entries {
+		for _, rt := range entry.importRTs {
+			var leakers []RoutingZoneObject
+			for _, exporter := range exporters[rt] {
+				if exporter.VrfName != entry.object.VrfName {
+					leakers = append(leakers, exporter)
+				}
+			}
+			if len(leakers) > 0 {
+				result = append(result, RoutingZoneConflict{
+					Type:    RoutingZoneConflictRouteLeak,
+					Value:   rt,
+					Objects: append([]RoutingZoneObject{entry.object}, leakers...),
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+// appendRoutingZoneDuplicates appends a conflict for each value with more than
+// one object, in value order.
+func appendRoutingZoneDuplicates(conflicts []RoutingZoneConflict, t RoutingZoneConflictType, objectsByValue map[string][]RoutingZoneObject, options RoutingZoneConflictOptions) []RoutingZoneConflict {
+	for _, value := range sortedKeys(objectsByValue) {
+		objects := objectsByValue[value]
+		if len(objects) < 2 {
+			continue
+		}
+		if t == RoutingZoneConflictVrfName && !routingZoneObjectsSpanBlueprints(objects) {
+			continue
+		}
+		if !options.SharedTenantDuplicates && routingZoneObjectsSharedByTenant(objects) {
+			continue
+		}
+		conflicts = append(conflicts, RoutingZoneConflict{Type: t, Value: value, Objects: objects})
+	}
+	return conflicts
+}
+
+func routingZoneObjectsSpanBlueprints(objects []RoutingZoneObject) bool {
+	for _, object := range objects[1:] {
+		if object.BlueprintId != objects[0].BlueprintId {
+			return true
+		}
+	}
+	return false
+}
+
+// routingZoneObjectsSharedByTenant returns true when the objects are copies of
+// a single routing zone or virtual network stretched across blueprints: each
+// is in a different blueprint, and all are the same kind of object in the same
+// VRF.
+func routingZoneObjectsSharedByTenant(objects []RoutingZoneObject) bool {
+	blueprints := make(map[ObjectId]struct{}, len(objects))
+	for _, object := range objects {
+		if _, ok := blueprints[object.BlueprintId]; ok {
+			return false
+		}
+		blueprints[object.BlueprintId] = struct{}{}
+
+		if object.VrfName != objects[0].VrfName || (object.VirtualNetworkId == "") != (objects[0].VirtualNetworkId == "") {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRoutingZoneInventory returns the routing zones and virtual networks of
+// the blueprint, for use with FindRoutingZoneConflicts.
+func (o *TwoStageL3ClosClient) GetRoutingZoneInventory(ctx context.Context) (*RoutingZoneInventory, error) {
+	szs, err := o.GetAllSecurityZones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching routing zones of blueprint %q - %w", o.blueprintId, err)
+	}
+
+	vns, err := o.GetAllVirtualNetworks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching virtual networks of blueprint %q - %w", o.blueprintId, err)
+	}
+
+	return &RoutingZoneInventory{
+		BlueprintId:     o.blueprintId,
+		SecurityZones:   szs,
+		VirtualNetworks: vns,
+	}, nil
+}
+
+// CheckRoutingZoneConflicts runs FindRoutingZoneConflicts against the given
+// blueprints, or against every datacenter blueprint when none are specified.
+func (o *Client) CheckRoutingZoneConflicts(ctx context.Context, options RoutingZoneConflictOptions, blueprintIds ...ObjectId) ([]RoutingZoneConflict, error) {
+	if len(blueprintIds) == 0 {
+		statuses, err := o.GetAllBlueprintStatus(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed fetching blueprints - %w", err)
+		}
+		for _, status := range statuses {
+			if status.Design == RefDesignTwoStageL3Clos {
+				blueprintIds = append(blueprintIds, status.Id)
+			}
+		}
+	}
+
+	inventories := make([]RoutingZoneInventory, len(blueprintIds))
+	for i, blueprintId := range blueprintIds {
+		bp, err := o.NewTwoStageL3ClosClient(ctx, blueprintId)
+		if err != nil {
+			return nil, fmt.Errorf("failed creating client for blueprint %q - %w", blueprintId, err)
+		}
+
+		inventory, err := bp.GetRoutingZoneInventory(ctx)
+		if err != nil {
+			return nil, err
+		}
+		inventories[i] = *inventory
+	}
+
+	return FindRoutingZoneConflicts(inventories, options), nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRoutingZoneConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bp := testBlueprintA(ctx, t, client.client)
+
+			redId := testSecurityZone(t, ctx, bp)
+			red, err := bp.GetSecurityZone(ctx, redId)
+			require.NoError(t, err)
+			require.NotNil(t, red.Data.RouteTarget)
+
+			blue := randString(6, "hex")
+			blueId, err := bp.CreateSecurityZone(ctx, &SecurityZoneData{
+				Label:    blue,
+				SzType:   SecurityZoneTypeEVPN,
+				VrfName:  blue,
+				RtPolicy: &RtPolicy{ImportRTs: []string{*red.Data.RouteTarget}},
+			})
+			require.NoError(t, err)
+
+			log.Printf("testing CheckRoutingZoneConflicts() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			conflicts, err := client.client.CheckRoutingZoneConflicts(ctx, RoutingZoneConflictOptions{}, bp.Id())
+			require.NoError(t, err)
+
+			var found bool
+			for _, conflict := range conflicts {
+				log.Println(conflict)
+				if conflict.Type == RoutingZoneConflictRouteLeak && conflict.Objects[0].SecurityZoneId == blueId {
+					require.Equal(t, *red.Data.RouteTarget, conflict.Value)
+					found = true
+				}
+			}
+			require.True(t, found)
+		})
+	}
+}
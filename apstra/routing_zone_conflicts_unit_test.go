@@ -0,0 +1,104 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRoutingZoneConflicts(t *testing.T) {
+	vni := func(i int) *int { return &i }
+	rt := func(s string) *string { return &s }
+	vnVni := VNI(20001)
+
+	inventories := []RoutingZoneInventory{
+		{
+			BlueprintId: "bp1",
+			SecurityZones: []SecurityZone{
+				{Id: "sz_default", Data: &SecurityZoneData{Label: "default", SzType: SecurityZoneTypeL3Fabric, VrfName: "default"}},
+				{Id: "sz_red", Data: &SecurityZoneData{Label: "red", VrfName: "red", RouteTarget: rt("65000:1"), VniId: vni(10001)}},
+				{Id: "sz_blue", Data: &SecurityZoneData{
+					Label: "blue", VrfName: "blue", RouteTarget: rt("65000:2"), VniId: vni(10002),
+					RtPolicy: &RtPolicy{ImportRTs: []string{"65000:1"}}, // leaks red into blue
+				}},
+			},
+			VirtualNetworks: map[ObjectId]VirtualNetwork{
+				"vn1": {Data: &VirtualNetworkData{Label: "vn1", SecurityZoneId: "sz_red", RouteTarget: "20001:1", VnId: &vnVni}},
+			},
+		},
+		{
+			BlueprintId: "bp2",
+			SecurityZones: []SecurityZone{
+				{Id: "sz_default", Data: &SecurityZoneData{Label: "default", SzType: SecurityZoneTypeL3Fabric, VrfName: "default"}},
+				// same tenant as bp1 (DCI): shared RT doesn't leak, and is only a duplicate when asked
+				{Id: "sz_red", Data: &SecurityZoneData{Label: "red", VrfName: "red", RouteTarget: rt("65000:1"), VniId: vni(20001)}},
+				// different tenant than bp1 with the same VNI
+				{Id: "sz_green", Data: &SecurityZoneData{Label: "green", VrfName: "green", RouteTarget: rt("65000:3"), VniId: vni(10002)}},
+			},
+		},
+	}
+
+	type summary struct {
+		Type    RoutingZoneConflictType
+		Value   string
+		Objects []string
+	}
+	summarize := func(conflicts []RoutingZoneConflict) []summary {
+		result := make([]summary, len(conflicts))
+		for i, conflict := range conflicts {
+			result[i] = summary{Type: conflict.Type, Value: conflict.Value}
+			for _, object := range conflict.Objects {
+				id := object.SecurityZoneId
+				if object.VirtualNetworkId != "" {
+					id = object.VirtualNetworkId
+				}
+				result[i].Objects = append(result[i].Objects, object.BlueprintId.String()+"/"+id.String())
+			}
+		}
+		return result
+	}
+
+	conflicts := FindRoutingZoneConflicts(inventories, RoutingZoneConflictOptions{})
+	require.Equal(t, []summary{
+		{Type: RoutingZoneConflictVni, Value: "10002", Objects: []string{"bp1/sz_blue", "bp2/sz_green"}},
+		{Type: RoutingZoneConflictVni, Value: "20001", Objects: []string{"bp1/vn1", "bp2/sz_red"}},
+		{Type: RoutingZoneConflictRouteLeak, Value: "65000:1", Objects: []string{"bp1/sz_blue", "bp1/sz_red", "bp2/sz_red"}},
+	}, summarize(conflicts))
+
+	require.Equal(t, "red", conflicts[1].Objects[0].VrfName) // VN inherits the VRF of its routing zone
+	require.Contains(t, conflicts[2].String(), "route leak")
+
+	// shared tenant values are reported when asked; the default routing zone never is
+	conflicts = FindRoutingZoneConflicts(inventories, RoutingZoneConflictOptions{SharedTenantDuplicates: true})
+	require.Equal(t, []summary{
+		{Type: RoutingZoneConflictRouteTarget, Value: "65000:1", Objects: []string{"bp1/sz_red", "bp2/sz_red"}},
+		{Type: RoutingZoneConflictVni, Value: "10002", Objects: []string{"bp1/sz_blue", "bp2/sz_green"}},
+		{Type: RoutingZoneConflictVni, Value: "20001", Objects: []string{"bp1/vn1", "bp2/sz_red"}},
+		{Type: RoutingZoneConflictVrfName, Value: "red", Objects: []string{"bp1/sz_red", "bp2/sz_red"}},
+		{Type: RoutingZoneConflictRouteLeak, Value: "65000:1", Objects: []string{"bp1/sz_blue", "bp1/sz_red", "bp2/sz_red"}},
+	}, summarize(conflicts))
+}
+
+func TestFindRoutingZoneConflictsClean(t *testing.T) {
+	rt := func(s string) *string { return &s }
+
+	bp := func(id ObjectId) RoutingZoneInventory {
+		return RoutingZoneInventory{
+			BlueprintId: id,
+			SecurityZones: []SecurityZone{
+				{Id: "sz_default", Data: &SecurityZoneData{Label: "default", VrfName: "default"}},
+				{Id: "sz_red", Data: &SecurityZoneData{Label: "red", VrfName: "red", RouteTarget: rt("65000:1")}},
+				{Id: "sz_blue", Data: &SecurityZoneData{Label: "blue", VrfName: "blue", RouteTarget: rt("65000:2")}},
+			},
+		}
+	}
+
+	require.Empty(t, FindRoutingZoneConflicts([]RoutingZoneInventory{bp("bp1")}, RoutingZoneConflictOptions{}))
+
+	// DCI peers with the same tenants
+	require.Empty(t, FindRoutingZoneConflicts([]RoutingZoneInventory{bp("bp1"), bp("bp2")}, RoutingZoneConflictOptions{}))
+}
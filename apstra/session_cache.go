@@ -0,0 +1,375 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/enum"
+	"github.com/hashicorp/go-version"
+)
+
+const (
+	DefaultDiscoveryCacheTtl = 15 * time.Minute
+
+	sessionCacheDirName       = "apstra-go-sdk"
+	sessionCacheFileSuffix    = ".json"
+	sessionCacheDirMode       = fs.FileMode(0o700)
+	sessionCacheFileMode      = fs.FileMode(0o600)
+	sessionCacheKeyToken      = "token"
+	sessionCacheKeyDiscovery  = "discovery"
+	sessionCacheKeyDelim      = "\x00"
+	sessionTokenExpiryMargin  = 30 * time.Second
+	sessionTokenJwtPartsCount = 3
+)
+
+// SessionCache stores data which Clients may share with each other, including
+// Clients in other processes: auth tokens (keyed by URL and username) and the
+// results of feature and version discovery (keyed by URL). Load returns nil
+// data and a nil error when the key is not found. Implementations must be safe
+// for concurrent use.
+//
+// A Client which finds a cached auth token uses it rather than logging in.
+// Clients which share tokens should not call Logout, as that invalidates the
+// token for every Client using it.
+type SessionCache interface {
+	Load(key string) ([]byte, error)
+	Store(key string, data []byte) error
+	Delete(key string) error
+}
+
+var (
+	_ SessionCache = new(FileSessionCache)
+	_ SessionCache = new(MemorySessionCache)
+)
+
+// FileSessionCache is a SessionCache which keeps each entry in a file within
+// Dir. The directory is created with mode 0700 and files with mode 0600.
+// Files readable or writable by other users are ignored.
+type FileSessionCache struct {
+	Dir string
+}
+
+// NewFileSessionCache returns a FileSessionCache which uses dir, or a
+// directory within the user's cache directory (os.UserCacheDir) when dir is
+// empty.
+func NewFileSessionCache(dir string) (*FileSessionCache, error) {
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed determining user cache directory - %w", err)
+		}
+		dir = filepath.Join(userCacheDir, sessionCacheDirName)
+	}
+
+	return &FileSessionCache{Dir: dir}, nil
+}
+
+func (o *FileSessionCache) fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(o.Dir, hex.EncodeToString(sum[:])+sessionCacheFileSuffix)
+}
+
+func (o *FileSessionCache) Load(key string) ([]byte, error) {
+	fileName := o.fileName(key)
+
+	info, err := os.Stat(fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed checking session cache file - %w", err)
+	}
+
+	if info.Mode().Perm()&^sessionCacheFileMode != 0 {
+		return nil, fmt.Errorf("ignoring session cache file %q with permissions %s", fileName, info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed reading session cache file - %w", err)
+	}
+
+	return data, nil
+}
+
+// Store writes the entry to a temporary file which is then renamed into
+// place, so concurrent readers never see a partial entry.
+func (o *FileSessionCache) Store(key string, data []byte) error {
+	err := os.MkdirAll(o.Dir, sessionCacheDirMode)
+	if err != nil {
+		return fmt.Errorf("failed creating session cache directory - %w", err)
+	}
+
+	f, err := os.CreateTemp(o.Dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed creating session cache file - %w", err)
+	}
+	tmpName := f.Name()
+	defer func() { _ = os.Remove(tmpName) }() // no-op after a successful rename
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(sessionCacheFileMode)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed writing session cache file - %w", err)
+	}
+
+	err = os.Rename(tmpName, o.fileName(key))
+	if err != nil {
+		return fmt.Errorf("failed renaming session cache file - %w", err)
+	}
+
+	return nil
+}
+
+func (o *FileSessionCache) Delete(key string) error {
+	err := os.Remove(o.fileName(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed deleting session cache file - %w", err)
+	}
+	return nil
+}
+
+// MemorySessionCache is a SessionCache which shares entries between Clients
+// within a single process. The zero value is ready to use.
+type MemorySessionCache struct {
+	entries sync.Map
+}
+
+func (o *MemorySessionCache) Load(key string) ([]byte, error) {
+	data, ok := o.entries.Load(key)
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), data.([]byte)...), nil
+}
+
+func (o *MemorySessionCache) Store(key string, data []byte) error {
+	o.entries.Store(key, append([]byte(nil), data...))
+	return nil
+}
+
+func (o *MemorySessionCache) Delete(key string) error {
+	o.entries.Delete(key)
+	return nil
+}
+
+// cachedToken is the SessionCache entry for an auth token. Expires is zero
+// when the token's lifetime couldn't be determined.
+type cachedToken struct {
+	Token   string    `json:"token"`
+	UserId  ObjectId  `json:"user_id"`
+	Expires time.Time `json:"expires"`
+}
+
+func (o *cachedToken) valid(now time.Time) bool {
+	return o.Token != "" && (o.Expires.IsZero() || now.Add(sessionTokenExpiryMargin).Before(o.Expires))
+}
+
+// cachedDiscovery is the SessionCache entry for feature and version discovery
+type cachedDiscovery struct {
+	ApiVersion string          `json:"api_version"`
+	Features   map[string]bool `json:"features"`
+	Fetched    time.Time       `json:"fetched"`
+}
+
+// tokenExpiry returns the expiry ("exp" claim) of a JWT auth token, or the
+// zero time if the token isn't a JWT with an expiry.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != sessionTokenJwtPartsCount {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil || claims.Exp == nil {
+		return time.Time{}
+	}
+
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(exp), 0)
+}
+
+func (o *Client) tokenCacheKey() string {
+	return strings.Join([]string{sessionCacheKeyToken, o.baseUrl.String(), o.credentialIdentity()}, sessionCacheKeyDelim)
+}
+
+func (o *Client) discoveryCacheKey() string {
+	return strings.Join([]string{sessionCacheKeyDiscovery, o.baseUrl.String()}, sessionCacheKeyDelim)
+}
+
+// loadCachedToken installs an unexpired auth token from the session cache, if
+// one is found. Cache errors are logged rather than returned: the client can
+// always log in.
+func (o *Client) loadCachedToken() {
+	if o.cfg.SessionCache == nil {
+		return
+	}
+
+	data, err := o.cfg.SessionCache.Load(o.tokenCacheKey())
+	if err != nil {
+		o.logStrf(1, "failed loading cached auth token - %s", err)
+		return
+	}
+	if data == nil {
+		return
+	}
+
+	var entry cachedToken
+	err = json.Unmarshal(data, &entry)
+	if err != nil {
+		o.logStrf(1, "failed parsing cached auth token - %s", err)
+		return
+	}
+	if !entry.valid(time.Now()) {
+		return
+	}
+
+	o.logStr(1, "using cached auth token")
+	o.lock(mutexKeyHttpHeaders)
+	o.httpHeaders[apstraAuthHeader] = entry.Token
+	o.unlock(mutexKeyHttpHeaders)
+
+	o.id = entry.UserId
+	o.startTaskMonitor()
+}
+
+// storeCachedToken saves the auth token in the session cache
+func (o *Client) storeCachedToken(token string, userId ObjectId) {
+	if o.cfg.SessionCache == nil {
+		return
+	}
+
+	data, err := json.Marshal(cachedToken{
+		Token:   token,
+		UserId:  userId,
+		Expires: tokenExpiry(token),
+	})
+	if err == nil {
+		err = o.cfg.SessionCache.Store(o.tokenCacheKey(), data)
+	}
+	if err != nil {
+		o.logStrf(1, "failed caching auth token - %s", err)
+	}
+}
+
+// deleteCachedToken removes the auth token from the session cache
+func (o *Client) deleteCachedToken() {
+	if o.cfg.SessionCache == nil {
+		return
+	}
+
+	err := o.cfg.SessionCache.Delete(o.tokenCacheKey())
+	if err != nil {
+		o.logStrf(1, "failed deleting cached auth token - %s", err)
+	}
+}
+
+// loadCachedDiscovery populates the client's features and API version from
+// the session cache. It returns false when no fresh entry is found.
+func (o *Client) loadCachedDiscovery() bool {
+	if o.cfg.SessionCache == nil {
+		return false
+	}
+
+	data, err := o.cfg.SessionCache.Load(o.discoveryCacheKey())
+	if err != nil {
+		o.logStrf(1, "failed loading cached discovery results - %s", err)
+		return false
+	}
+	if data == nil {
+		return false
+	}
+
+	var entry cachedDiscovery
+	err = json.Unmarshal(data, &entry)
+	if err != nil {
+		o.logStrf(1, "failed parsing cached discovery results - %s", err)
+		return false
+	}
+
+	ttl := o.cfg.DiscoveryCacheTtl
+	if ttl == 0 {
+		ttl = DefaultDiscoveryCacheTtl
+	}
+	if time.Since(entry.Fetched) > ttl {
+		return false
+	}
+
+	apiVersion, err := version.NewVersion(entry.ApiVersion)
+	if err != nil {
+		o.logStrf(1, "failed parsing cached API version %q - %s", entry.ApiVersion, err)
+		return false
+	}
+
+	o.lock(apiUrlFeatures)
+	o.features = make(map[enum.ApiFeature]bool, len(entry.Features))
+	for f, enabled := range entry.Features {
+		o.features[enum.ApiFeature{Value: f}] = enabled
+	}
+	o.unlock(apiUrlFeatures)
+
+	o.apiVersion = apiVersion
+	o.logStr(1, "using cached discovery results")
+	return true
+}
+
+// storeCachedDiscovery saves the client's features and API version in the
+// session cache
+func (o *Client) storeCachedDiscovery() {
+	if o.cfg.SessionCache == nil || o.apiVersion == nil {
+		return
+	}
+
+	entry := cachedDiscovery{
+		ApiVersion: o.apiVersion.Original(),
+		Fetched:    time.Now(),
+	}
+
+	o.lock(apiUrlFeatures)
+	entry.Features = make(map[string]bool, len(o.features))
+	for f, enabled := range o.features {
+		entry.Features[f.Value] = enabled
+	}
+	o.unlock(apiUrlFeatures)
+
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = o.cfg.SessionCache.Store(o.discoveryCacheKey(), data)
+	}
+	if err != nil {
+		o.logStrf(1, "failed caching discovery results - %s", err)
+	}
+}
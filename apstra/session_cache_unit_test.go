@@ -0,0 +1,212 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/enum"
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+)
+
+func testSessionCacheClient(t testing.TB, cache SessionCache) *Client {
+	t.Helper()
+
+	baseUrl, err := url.Parse("https://apstra.example.com")
+	require.NoError(t, err)
+
+	return &Client{
+		cfg: ClientCfg{
+			User:         "admin",
+			Pass:         "secret",
+			SessionCache: cache,
+		},
+		baseUrl:     baseUrl,
+		httpHeaders: make(map[string]string),
+		taskMonChan: make(chan *taskMonitorMonReq),
+		sync:        make(map[string]*sync.Mutex),
+		ctx:         context.Background(),
+	}
+}
+
+func testJwt(exp time.Time) string {
+	payload := fmt.Sprintf(`{"username":"admin","exp":%d}`, exp.Unix())
+	return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+func TestSessionCaches(t *testing.T) {
+	fileCache, err := NewFileSessionCache(filepath.Join(t.TempDir(), "cache"))
+	require.NoError(t, err)
+
+	for name, cache := range map[string]SessionCache{"file": fileCache, "memory": new(MemorySessionCache)} {
+		t.Run(name, func(t *testing.T) {
+			data, err := cache.Load("missing")
+			require.NoError(t, err)
+			require.Nil(t, data)
+
+			require.NoError(t, cache.Store("key", []byte("one")))
+			require.NoError(t, cache.Store("key", []byte("two")))
+			data, err = cache.Load("key")
+			require.NoError(t, err)
+			require.Equal(t, []byte("two"), data)
+
+			require.NoError(t, cache.Delete("key"))
+			require.NoError(t, cache.Delete("key"))
+			data, err = cache.Load("key")
+			require.NoError(t, err)
+			require.Nil(t, data)
+		})
+	}
+}
+
+func TestFileSessionCachePermissions(t *testing.T) {
+	cache, err := NewFileSessionCache(filepath.Join(t.TempDir(), "cache"))
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Store("key", []byte("data")))
+
+	dirInfo, err := os.Stat(cache.Dir)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o700), dirInfo.Mode().Perm())
+
+	fileInfo, err := os.Stat(cache.fileName("key"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), fileInfo.Mode().Perm())
+
+	// no temporary files left behind
+	entries, err := os.ReadDir(cache.Dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// files readable by others are ignored
+	require.NoError(t, os.Chmod(cache.fileName("key"), 0o644))
+	_, err = cache.Load("key")
+	require.Error(t, err)
+}
+
+func TestTokenExpiry(t *testing.T) {
+	exp := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+	require.Equal(t, exp, tokenExpiry(testJwt(exp)))
+	require.True(t, tokenExpiry("opaque-token").IsZero())
+	require.True(t, tokenExpiry("a.!!!.c").IsZero())
+
+	now := time.Now()
+	require.True(t, (&cachedToken{Token: "t"}).valid(now))
+	require.True(t, (&cachedToken{Token: "t", Expires: now.Add(time.Hour)}).valid(now))
+	require.False(t, (&cachedToken{Token: "t", Expires: now.Add(time.Second)}).valid(now))
+	require.False(t, (&cachedToken{}).valid(now))
+}
+
+func TestSessionCacheToken(t *testing.T) {
+	cache := new(MemorySessionCache)
+
+	// a token stored by one client is used by another
+	client1 := testSessionCacheClient(t, cache)
+	token := testJwt(time.Now().Add(time.Hour))
+	client1.storeCachedToken(token, "user-id")
+
+	client2 := testSessionCacheClient(t, cache)
+	client2.loadCachedToken()
+	defer client2.stopTaskMonitor()
+	require.Equal(t, token, client2.httpHeaders[apstraAuthHeader])
+	require.Equal(t, ObjectId("user-id"), client2.ID())
+
+	// tokens are keyed by user
+	client3 := testSessionCacheClient(t, cache)
+	client3.cfg.User = "other"
+	client3.loadCachedToken()
+	require.NotContains(t, client3.httpHeaders, apstraAuthHeader)
+
+	// expired tokens are ignored
+	client1.storeCachedToken(testJwt(time.Now().Add(-time.Hour)), "user-id")
+	client4 := testSessionCacheClient(t, cache)
+	client4.loadCachedToken()
+	require.NotContains(t, client4.httpHeaders, apstraAuthHeader)
+
+	// deleted tokens are gone
+	client1.deleteCachedToken()
+	data, err := cache.Load(client1.tokenCacheKey())
+	require.NoError(t, err)
+	require.Nil(t, data)
+}
+
+// countingCredentials is a CredentialProvider which counts its invocations
+type countingCredentials struct {
+	calls *int
+}
+
+func (o countingCredentials) Credentials(_ context.Context) (string, string, error) {
+	*o.calls++
+	return "admin", "secret", nil
+}
+
+func TestSessionCacheTokenProvider(t *testing.T) {
+	cache := new(MemorySessionCache)
+
+	withProvider := func(provider CredentialProvider) *Client {
+		client := testSessionCacheClient(t, cache)
+		client.cfg.User, client.cfg.Pass = "", ""
+		client.cfg.CredentialProvider = provider
+		return client
+	}
+
+	// the cache is keyed on provider configuration, so loading a cached
+	// token doesn't invoke the provider
+	var calls int
+	token := testJwt(time.Now().Add(time.Hour))
+	withProvider(countingCredentials{calls: &calls}).storeCachedToken(token, "user-id")
+
+	client := withProvider(countingCredentials{calls: &calls})
+	client.loadCachedToken()
+	defer client.stopTaskMonitor()
+	require.Equal(t, token, client.httpHeaders[apstraAuthHeader])
+	require.Zero(t, calls)
+
+	// differently configured providers don't share tokens
+	command := withProvider(CommandCredentials{Command: []string{"false"}})
+	command.storeCachedToken(token, "user-id")
+	require.NotEqual(t, command.tokenCacheKey(), withProvider(CommandCredentials{Command: []string{"true"}}).tokenCacheKey())
+	require.Equal(t, command.tokenCacheKey(), withProvider(CommandCredentials{Command: []string{"false"}}).tokenCacheKey())
+	require.NotContains(t, command.tokenCacheKey(), "false", "provider configuration may contain secrets")
+
+	// static credentials and ClientCfg.User are the same user
+	require.Equal(t, testSessionCacheClient(t, cache).tokenCacheKey(), withProvider(StaticCredentials{User: "admin"}).tokenCacheKey())
+}
+
+func TestSessionCacheDiscovery(t *testing.T) {
+	cache := new(MemorySessionCache)
+
+	client1 := testSessionCacheClient(t, cache)
+	require.False(t, client1.loadCachedDiscovery())
+
+	client1.apiVersion = version.Must(version.NewVersion("5.0.0"))
+	client1.features = map[enum.ApiFeature]bool{
+		enum.ApiFeatureFreeform:        true,
+		enum.ApiFeatureAiFabric:        false,
+		{Value: "not_a_known_feature"}: true,
+	}
+	client1.storeCachedDiscovery()
+
+	client2 := testSessionCacheClient(t, cache)
+	require.True(t, client2.loadCachedDiscovery())
+	require.Equal(t, "5.0.0", client2.apiVersion.String())
+	require.Equal(t, client1.features, client2.features)
+	require.True(t, client2.FeatureEnabled(enum.ApiFeatureFreeform))
+
+	// stale results are ignored
+	client3 := testSessionCacheClient(t, cache)
+	client3.cfg.DiscoveryCacheTtl = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	require.False(t, client3.loadCachedDiscovery())
+}
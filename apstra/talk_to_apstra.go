@@ -316,7 +316,11 @@ func (o *Client) talkToApstra(ctx context.Context, in *talkToApstraIn) error {
 		}
 		o.Log(2, "no task ID response, parse apstra reply for caller")
 		// no task ID, decode response body into the caller-specified structure
-		return json.NewDecoder(resp.Body).Decode(in.apiResponse)
+		err = json.NewDecoder(resp.Body).Decode(in.apiResponse)
+		if err != nil {
+			return err
+		}
+		return o.checkResponseEnums(in.apiResponse)
 	}
 
 	// we got a task ID, instead of the expected response object. tasks are
@@ -396,7 +400,11 @@ func (o *Client) talkToApstra(ctx context.Context, in *talkToApstraIn) error {
 	// the getTaskResponse data structure is only partially unmarshaled because
 	// it's impossible to know exactly what'll be in there. Extract it now into
 	// whatever in.apiResponse (interface{} controlled by the caller) is.
-	return json.Unmarshal(taskResponse.DetailedStatus.ApiResponse, in.apiResponse)
+	err = json.Unmarshal(taskResponse.DetailedStatus.ApiResponse, in.apiResponse)
+	if err != nil {
+		return err
+	}
+	return o.checkResponseEnums(in.apiResponse)
 }
 
 // TalkToApstraErr implements error{} and carries around http.Request and
@@ -0,0 +1,93 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+)
+
+var _ apstraHttpClient = new(stubHttpClient)
+
+// stubHttpClient stands in for the Apstra API. GET requests are answered from
+// responses, keyed by URL path. Other requests are recorded in requests and
+// answered with an empty object.
+type stubHttpClient struct {
+	responses map[string]string
+	requests  []stubHttpRequest
+	lock      sync.Mutex
+}
+
+type stubHttpRequest struct {
+	method string
+	path   string
+	body   []byte
+}
+
+func (o *stubHttpClient) Do(req *http.Request) (*http.Response, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	response := &http.Response{StatusCode: http.StatusOK, Request: req}
+
+	if req.Method == http.MethodGet {
+		body, ok := o.responses[req.URL.Path]
+		if !ok {
+			response.StatusCode = http.StatusNotFound
+			body = `{"errors":"not found"}`
+		}
+		response.Body = io.NopCloser(bytes.NewBufferString(body))
+		return response, nil
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	o.requests = append(o.requests, stubHttpRequest{method: req.Method, path: req.URL.Path, body: body})
+
+	response.Body = io.NopCloser(bytes.NewBufferString("{}"))
+	return response, nil
+}
+
+// lastRequest returns the most recent non-GET request.
+func (o *stubHttpClient) lastRequest(t testing.TB) stubHttpRequest {
+	t.Helper()
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	require.NotEmpty(t, o.requests)
+	return o.requests[len(o.requests)-1]
+}
+
+// testStubClient returns a Client which talks to stub rather than to an
+// Apstra server.
+func testStubClient(t testing.TB, stub *stubHttpClient, cfg ClientCfg) *Client {
+	t.Helper()
+
+	baseUrl, err := url.Parse("https://apstra.example")
+	require.NoError(t, err)
+
+	return &Client{
+		apiVersion:  version.Must(version.NewVersion("4.2.0")),
+		baseUrl:     baseUrl,
+		cfg:         cfg,
+		httpClient:  stub,
+		httpHeaders: make(map[string]string),
+		sync:        make(map[string]*sync.Mutex),
+	}
+}
@@ -8,8 +8,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-
-	"github.com/Juniper/apstra-go-sdk/apstra/compatibility"
 )
 
 const (
@@ -18,8 +16,8 @@ const (
 
 // getAntiAffinityPolicy is for Apstra 4.2.0 and earlier (not available in 4.2.1)
 func (o *TwoStageL3ClosClient) getAntiAffinityPolicy(ctx context.Context) (*rawAntiAffinityPolicy, error) {
-	if !compatibility.EqApstra420.Check(o.client.apiVersion) {
-		return nil, fmt.Errorf("apstra %s does not support %q", o.client.apiVersion, apiUrlBlueprintAntiAffinityPolicy)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetFabricSettings.PolicyNodes"); err != nil {
+		return nil, err
 	}
 
 	var result rawAntiAffinityPolicy
@@ -41,8 +39,8 @@ func (o *TwoStageL3ClosClient) setAntiAffinityPolicy(ctx context.Context, in *ra
 		return nil
 	}
 
-	if !compatibility.EqApstra420.Check(o.client.apiVersion) {
-		return fmt.Errorf("apstra %s does not support %q", o.client.apiVersion, apiUrlBlueprintAntiAffinityPolicy)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.SetFabricSettings.PolicyNodes"); err != nil {
+		return err
 	}
 
 	err := o.client.talkToApstra(ctx, &talkToApstraIn{
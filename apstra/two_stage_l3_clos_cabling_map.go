@@ -77,6 +77,9 @@ func (o InterfaceType) String() string {
 	case InterfaceTypeSubinterface:
 		return string(interfaceTypeSubinterface)
 	default:
+		if s, ok := lenientEnumString[interfaceType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(interfaceTypeUnknown, o)
 	}
 }
@@ -112,6 +115,9 @@ func (o interfaceType) parse() (int, error) {
 	case interfaceTypeSubinterface:
 		return int(InterfaceTypeSubinterface), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(InterfaceTypeUnknown, o)
 	}
 }
@@ -150,6 +156,9 @@ func (o InterfaceOperationState) String() string {
 	case InterfaceOperationStateAdminDown:
 		return string(interfaceOperationStateAdminDown)
 	default:
+		if s, ok := lenientEnumString[interfaceOperationState](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(interfaceOperationStateUnknown, o)
 	}
 }
@@ -173,6 +182,9 @@ func (o interfaceOperationState) parse() (int, error) {
 	case interfaceOperationStateAdminDown:
 		return int(InterfaceOperationStateAdminDown), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(InterfaceOperationStateUnknown, o)
 	}
 }
@@ -255,12 +267,15 @@ func (o LinkRole) String() string {
 	case LinkRoleToGeneric:
 		return string(linkRoleToGeneric)
 	default:
+		if s, ok := lenientEnumString[linkRole](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(linkRoleUnknown, o)
 	}
 }
 
 func (o *LinkRole) FromString(s string) error {
-	i, err := linkRole(s).parse()
+	i, err := parseKnown(linkRole(s))
 	if err != nil {
 		return err
 	}
@@ -309,6 +324,9 @@ func (o linkRole) parse() (int, error) {
 	case linkRoleToGeneric:
 		return int(LinkRoleToGeneric), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(LinkRoleUnknown, o)
 	}
 }
@@ -363,6 +381,9 @@ func (o SystemNodeRole) String() string {
 	case SystemNodeRoleSuperspine:
 		return string(systemNodeRoleSuperspine)
 	default:
+		if s, ok := lenientEnumString[systemNodeRole](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(systemNodeRoleUnknown, o)
 	}
 }
@@ -398,6 +419,9 @@ func (o systemNodeRole) parse() (int, error) {
 	case systemNodeRoleSuperspine:
 		return int(SystemNodeRoleSuperspine), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(SystemNodeRoleUnknown, o)
 	}
 }
@@ -432,6 +456,9 @@ func (o LinkType) String() string {
 	case LinkTypeLogicalLink:
 		return string(linkTypeLogicalLink)
 	default:
+		if s, ok := lenientEnumString[linkType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(linkTypeUnknown, o)
 	}
 }
@@ -457,6 +484,9 @@ func (o linkType) parse() (int, error) {
 	case linkTypeLogicalLink:
 		return int(LinkTypeLogicalLink), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(LinkTypeUnknown, o)
 	}
 }
@@ -0,0 +1,321 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CablingObservedNeighbor describes an LLDP neighbor seen on an interface of
+// a blueprint system. NeighborNodeId is the ID of the blueprint system node
+// whose hostname or label matches the LLDP neighbor system name, or nil when
+// no such node exists.
+type CablingObservedNeighbor struct {
+	SystemNodeId          ObjectId
+	InterfaceName         string
+	NeighborSystemName    string
+	NeighborInterfaceName string
+	NeighborNodeId        *ObjectId
+}
+
+// CablingMiswire is an intended link for which LLDP reports a neighbor other
+// than the one called for by the cabling map.
+type CablingMiswire struct {
+	Link     CablingMapLink
+	Observed []CablingObservedNeighbor
+}
+
+// CablingValidationReport is the result of comparing the cabling map with
+// LLDP neighbor telemetry.
+//   - Verified links have been confirmed by LLDP on at least one end.
+//   - Missing links have no LLDP neighbor on any end which reports telemetry.
+//   - Miswired links have an LLDP neighbor which doesn't match the cabling map.
+//   - Unexpected neighbors are blueprint systems seen on interfaces which are
+//     not part of any link in the cabling map.
+//   - Unmodelled neighbors are systems outside of the blueprint (management
+//     switches, etc...) seen on interfaces which are not part of any link in
+//     the cabling map. They're reported for information only.
+//   - Unverified links terminate only on systems which don't report LLDP
+//     telemetry (unassigned switches, generic systems, etc...).
+type CablingValidationReport struct {
+	Verified   []CablingMapLink
+	Missing    []CablingMapLink
+	Miswired   []CablingMiswire
+	Unexpected []CablingObservedNeighbor
+	Unmodelled []CablingObservedNeighbor
+	Unverified []CablingMapLink
+}
+
+// Ok returns true when the report contains no missing, miswired or unexpected
+// links. Unmodelled neighbors don't affect the result.
+func (o *CablingValidationReport) Ok() bool {
+	return len(o.Missing) == 0 && len(o.Miswired) == 0 && len(o.Unexpected) == 0
+}
+
+// CablingMapOverride is the payload used to update interface assignments in
+// the cabling map.
+type CablingMapOverride struct {
+	Links []CablingMapOverrideLink `json:"links"`
+}
+
+type CablingMapOverrideLink struct {
+	Id        ObjectId                     `json:"id"`
+	Endpoints []CablingMapOverrideEndpoint `json:"endpoints"`
+}
+
+type CablingMapOverrideEndpoint struct {
+	Interface CablingMapOverrideInterface `json:"interface"`
+}
+
+type CablingMapOverrideInterface struct {
+	Id     ObjectId `json:"id"`
+	IfName string   `json:"if_name"`
+}
+
+// Override returns the cabling map payload which would make the blueprint
+// match the LLDP observations in the report. Only miswires where a link lands
+// on the intended system, but on the wrong interface, can be corrected this
+// way: the cabling map cannot move a link to a different system. Returns nil
+// if no corrections are possible.
+func (o *CablingValidationReport) Override() *CablingMapOverride {
+	var result CablingMapOverride
+	for _, miswire := range o.Miswired {
+		if len(miswire.Link.Endpoints) != 2 {
+			continue
+		}
+
+		// start with the intended interface names, then apply LLDP observations
+		ifNames := make(map[ObjectId]string, 2)
+		for _, endpoint := range miswire.Link.Endpoints {
+			ifNames[endpoint.System.Id] = *endpoint.Interface.IfName
+		}
+
+		var changed bool
+		for _, observed := range miswire.Observed {
+			remote := miswire.Link.OppositeEndpointBySystemId(observed.SystemNodeId)
+			if remote == nil || observed.NeighborNodeId == nil || *observed.NeighborNodeId != remote.System.Id {
+				continue // neighbor isn't the intended system; can't fix that here
+			}
+			if !strings.EqualFold(ifNames[remote.System.Id], observed.NeighborInterfaceName) {
+				ifNames[remote.System.Id] = observed.NeighborInterfaceName
+				changed = true
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		overrideLink := CablingMapOverrideLink{Id: miswire.Link.Id}
+		for _, endpoint := range miswire.Link.Endpoints {
+			overrideLink.Endpoints = append(overrideLink.Endpoints, CablingMapOverrideEndpoint{
+				Interface: CablingMapOverrideInterface{
+					Id:     endpoint.Interface.Id,
+					IfName: ifNames[endpoint.System.Id],
+				},
+			})
+		}
+		result.Links = append(result.Links, overrideLink)
+	}
+
+	if len(result.Links) == 0 {
+		return nil
+	}
+
+	return &result
+}
+
+// cablingValidationSystem holds the details of a blueprint system node needed
+// to match it with LLDP telemetry.
+type cablingValidationSystem struct {
+	Id       ObjectId  `json:"id"`
+	Label    string    `json:"label"`
+	Hostname string    `json:"hostname"`
+	SystemId *SystemId `json:"system_id"`
+}
+
+// ValidateCabling compares the blueprint's cabling map with the LLDP neighbor
+// telemetry of each system with an assigned device, and returns a report of
+// missing, miswired and unexpected links. Use the report's Override method
+// to generate a payload for ApplyCablingMapOverride.
+func (o *TwoStageL3ClosClient) ValidateCabling(ctx context.Context) (*CablingValidationReport, error) {
+	links, err := o.GetCablingMapLinks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching cabling map - %w", err)
+	}
+
+	var nodesResponse struct {
+		Nodes map[ObjectId]cablingValidationSystem `json:"nodes"`
+	}
+	err = o.GetNodes(ctx, NodeTypeSystem, &nodesResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching blueprint system nodes - %w", err)
+	}
+
+	lldp := make(map[ObjectId][]LldpNeighbor)
+	for id, node := range nodesResponse.Nodes {
+		if node.SystemId == nil || *node.SystemId == "" {
+			continue // no device assigned
+		}
+
+		neighbors, err := o.client.getSystemLldpNeighbors(ctx, *node.SystemId)
+		if err != nil {
+			var ace ClientErr
+			if errors.As(err, &ace) && ace.Type() == ErrNotfound {
+				continue // system doesn't report lldp telemetry
+			}
+			return nil, fmt.Errorf("failed fetching lldp neighbors of system %q - %w", *node.SystemId, err)
+		}
+		lldp[id] = neighbors
+	}
+
+	return validateCabling(links, nodesResponse.Nodes, lldp), nil
+}
+
+// ApplyCablingMapOverride updates interface assignments in the cabling map.
+// The payload is typically produced by CablingValidationReport.Override.
+func (o *TwoStageL3ClosClient) ApplyCablingMapOverride(ctx context.Context, in *CablingMapOverride) error {
+	err := o.client.talkToApstra(ctx, &talkToApstraIn{
+		method:   http.MethodPatch,
+		urlStr:   fmt.Sprintf(apiUrlBlueprintCablingMap, o.blueprintId),
+		apiInput: in,
+	})
+	return convertTtaeToAceWherePossible(err)
+}
+
+// validateCabling classifies the links in the cabling map according to the
+// LLDP telemetry in lldp, which is keyed by blueprint system node ID. Systems
+// absent from lldp are considered to not report telemetry.
+func validateCabling(links []CablingMapLink, systems map[ObjectId]cablingValidationSystem, lldp map[ObjectId][]LldpNeighbor) *CablingValidationReport {
+	// nodeIdsByName allows us to find a blueprint node by the LLDP system name
+	nodeIdsByName := make(map[string]ObjectId, len(systems)*2)
+	for id, system := range systems {
+		if system.Label != "" {
+			nodeIdsByName[strings.ToLower(system.Label)] = id
+		}
+	}
+	for id, system := range systems {
+		if system.Hostname != "" {
+			nodeIdsByName[strings.ToLower(system.Hostname)] = id // hostname wins over label
+		}
+	}
+
+	// neighborsByPort is keyed by system node ID then by lower-case interface name
+	neighborsByPort := make(map[ObjectId]map[string]LldpNeighbor, len(lldp))
+	for id, neighbors := range lldp {
+		neighborsByPort[id] = make(map[string]LldpNeighbor, len(neighbors))
+		for _, neighbor := range neighbors {
+			neighborsByPort[id][strings.ToLower(neighbor.InterfaceName)] = neighbor
+		}
+	}
+
+	observe := func(systemNodeId ObjectId, neighbor LldpNeighbor) CablingObservedNeighbor {
+		result := CablingObservedNeighbor{
+			SystemNodeId:          systemNodeId,
+			InterfaceName:         neighbor.InterfaceName,
+			NeighborSystemName:    neighbor.NeighborSystemName,
+			NeighborInterfaceName: neighbor.NeighborInterfaceName,
+		}
+		if id, ok := nodeIdsByName[strings.ToLower(neighbor.NeighborSystemName)]; ok {
+			result.NeighborNodeId = &id
+		}
+		return result
+	}
+
+	var result CablingValidationReport
+	intendedPorts := make(map[ObjectId]map[string]struct{})
+	for _, link := range links {
+		if link.Type != LinkTypeEthernet || len(link.Endpoints) != 2 {
+			continue // only physical point-to-point links can be validated
+		}
+
+		var digestOk bool
+		for _, endpoint := range link.Endpoints {
+			digestOk = endpoint.Digest() != nil
+			if !digestOk {
+				break
+			}
+			if intendedPorts[endpoint.System.Id] == nil {
+				intendedPorts[endpoint.System.Id] = make(map[string]struct{})
+			}
+			intendedPorts[endpoint.System.Id][strings.ToLower(*endpoint.Interface.IfName)] = struct{}{}
+		}
+		if !digestOk {
+			continue
+		}
+
+		var telemetry, confirmed, miswired bool
+		var observed []CablingObservedNeighbor
+		for i, local := range link.Endpoints {
+			remote := link.Endpoints[(i+1)%2]
+
+			ports, ok := neighborsByPort[local.System.Id]
+			if !ok {
+				continue // no telemetry from this system
+			}
+			telemetry = true
+
+			neighbor, ok := ports[strings.ToLower(*local.Interface.IfName)]
+			if !ok {
+				continue // nothing seen on this interface
+			}
+
+			obs := observe(local.System.Id, neighbor)
+			observed = append(observed, obs)
+			if obs.NeighborNodeId != nil && *obs.NeighborNodeId == remote.System.Id &&
+				strings.EqualFold(obs.NeighborInterfaceName, *remote.Interface.IfName) {
+				confirmed = true
+			} else {
+				miswired = true
+			}
+		}
+
+		switch {
+		case !telemetry:
+			result.Unverified = append(result.Unverified, link)
+		case miswired:
+			result.Miswired = append(result.Miswired, CablingMiswire{Link: link, Observed: observed})
+		case confirmed:
+			result.Verified = append(result.Verified, link)
+		default:
+			result.Missing = append(result.Missing, link)
+		}
+	}
+
+	for id, ports := range neighborsByPort {
+		for ifName, neighbor := range ports {
+			if _, ok := intendedPorts[id][ifName]; ok {
+				continue
+			}
+
+			obs := observe(id, neighbor)
+			if obs.NeighborNodeId == nil {
+				result.Unmodelled = append(result.Unmodelled, obs)
+			} else {
+				result.Unexpected = append(result.Unexpected, obs)
+			}
+		}
+	}
+
+	// map iteration leaves unexpected and unmodelled neighbors in random order
+	sortCablingObservedNeighbors(result.Unexpected)
+	sortCablingObservedNeighbors(result.Unmodelled)
+
+	return &result
+}
+
+func sortCablingObservedNeighbors(in []CablingObservedNeighbor) {
+	sort.Slice(in, func(i, j int) bool {
+		if in[i].SystemNodeId != in[j].SystemNodeId {
+			return in[i].SystemNodeId < in[j].SystemNodeId
+		}
+		return in[i].InterfaceName < in[j].InterfaceName
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCabling(t *testing.T) {
+	link := func(id ObjectId, aSys ObjectId, aIf string, bSys ObjectId, bIf string) CablingMapLink {
+		endpoint := func(sys ObjectId, ifName string) CablingMapLinkEndpoint {
+			return CablingMapLinkEndpoint{
+				Interface: &CablingMapLinkEndpointInterface{Id: ObjectId(string(sys) + "_" + ifName), IfName: &ifName},
+				System:    &CablingMapLinkEndpointSystem{Id: sys},
+			}
+		}
+		return CablingMapLink{
+			Id:        id,
+			Type:      LinkTypeEthernet,
+			Endpoints: []CablingMapLinkEndpoint{endpoint(aSys, aIf), endpoint(bSys, bIf)},
+		}
+	}
+
+	systems := map[ObjectId]cablingValidationSystem{
+		"spine1":  {Id: "spine1", Label: "spine_1", Hostname: "spine1.example"},
+		"leaf1":   {Id: "leaf1", Label: "leaf_1", Hostname: "leaf1.example"},
+		"leaf2":   {Id: "leaf2", Label: "leaf_2", Hostname: "leaf2.example"},
+		"server1": {Id: "server1", Label: "server_1"},
+	}
+
+	links := []CablingMapLink{
+		link("ok", "spine1", "xe-0/0/0", "leaf1", "xe-0/0/0"),
+		link("swapped", "spine1", "xe-0/0/1", "leaf2", "xe-0/0/0"),
+		link("missing", "leaf1", "xe-0/0/1", "leaf2", "xe-0/0/1"),
+		link("wrong_system", "spine1", "xe-0/0/2", "leaf1", "xe-0/0/2"),
+		link("server", "server1", "eth0", "server1", "eth1"),
+	}
+
+	lldp := map[ObjectId][]LldpNeighbor{
+		"spine1": {
+			{InterfaceName: "xe-0/0/0", NeighborSystemName: "leaf1.example", NeighborInterfaceName: "xe-0/0/0"},
+			{InterfaceName: "xe-0/0/1", NeighborSystemName: "leaf2.example", NeighborInterfaceName: "xe-0/0/7"},
+			{InterfaceName: "xe-0/0/2", NeighborSystemName: "leaf2.example", NeighborInterfaceName: "xe-0/0/2"},
+			{InterfaceName: "xe-0/0/9", NeighborSystemName: "mystery", NeighborInterfaceName: "eth0"},
+			{InterfaceName: "xe-0/0/8", NeighborSystemName: "leaf_2", NeighborInterfaceName: "xe-0/0/8"},
+		},
+		"leaf1": {
+			{InterfaceName: "xe-0/0/0", NeighborSystemName: "spine1.example", NeighborInterfaceName: "xe-0/0/0"},
+		},
+		"leaf2": {},
+	}
+
+	report := validateCabling(links, systems, lldp)
+	require.False(t, report.Ok())
+
+	require.Len(t, report.Verified, 1)
+	require.Equal(t, ObjectId("ok"), report.Verified[0].Id)
+
+	require.Len(t, report.Missing, 1)
+	require.Equal(t, ObjectId("missing"), report.Missing[0].Id)
+
+	require.Len(t, report.Miswired, 2)
+	require.Equal(t, ObjectId("swapped"), report.Miswired[0].Link.Id)
+	require.Equal(t, ObjectId("wrong_system"), report.Miswired[1].Link.Id)
+
+	require.Len(t, report.Unverified, 1)
+	require.Equal(t, ObjectId("server"), report.Unverified[0].Id)
+
+	require.Len(t, report.Unexpected, 1)
+	require.Equal(t, "xe-0/0/8", report.Unexpected[0].InterfaceName)
+	require.Equal(t, ObjectId("leaf2"), *report.Unexpected[0].NeighborNodeId)
+
+	require.Len(t, report.Unmodelled, 1)
+	require.Equal(t, "xe-0/0/9", report.Unmodelled[0].InterfaceName)
+	require.Nil(t, report.Unmodelled[0].NeighborNodeId)
+
+	// only the "swapped" link lands on the intended system, so only it can be fixed
+	override := report.Override()
+	require.NotNil(t, override)
+	require.Len(t, override.Links, 1)
+	require.Equal(t, ObjectId("swapped"), override.Links[0].Id)
+	require.Equal(t, "xe-0/0/1", override.Links[0].Endpoints[0].Interface.IfName)
+	require.Equal(t, "xe-0/0/7", override.Links[0].Endpoints[1].Interface.IfName)
+	require.Equal(t, ObjectId("leaf2_xe-0/0/0"), override.Links[0].Endpoints[1].Interface.Id)
+
+	// neighbors outside of the blueprint (management switches, etc...) don't fail validation
+	report = validateCabling(links[:1], systems, map[ObjectId][]LldpNeighbor{
+		"spine1": {
+			{InterfaceName: "xe-0/0/0", NeighborSystemName: "leaf1.example", NeighborInterfaceName: "xe-0/0/0"},
+			{InterfaceName: "em0", NeighborSystemName: "oob-mgmt-switch", NeighborInterfaceName: "ge-0/0/12"},
+		},
+	})
+	require.True(t, report.Ok())
+	require.Len(t, report.Unmodelled, 1)
+}
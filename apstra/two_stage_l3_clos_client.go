@@ -14,7 +14,6 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/Juniper/apstra-go-sdk/apstra/compatibility"
 	"github.com/Juniper/apstra-go-sdk/apstra/enum"
 )
 
@@ -78,6 +77,13 @@ func (o *TwoStageL3ClosClient) Id() ObjectId {
 	return o.blueprintId
 }
 
+// RWMutex returns o.Mutex as an RWMutex, for shared locking. It returns false
+// when Mutex has been replaced with an implementation without shared locks.
+func (o *TwoStageL3ClosClient) RWMutex() (RWMutex, bool) {
+	result, ok := o.Mutex.(RWMutex)
+	return result, ok
+}
+
 // lockId returns a string intended to be used with Client.lock()
 func (o *TwoStageL3ClosClient) lockId(ids ...ObjectId) string {
 	var buf bytes.Buffer
@@ -272,7 +278,9 @@ func (o *TwoStageL3ClosClient) UpdateSecurityZone(ctx context.Context, zoneId Ob
 		return errors.New("junos_evpn_irb_mode cannot be nil")
 	}
 
-	return o.updateSecurityZone(ctx, zoneId, cfg.raw())
+	raw := cfg.raw()
+	raw.unknownFields = o.client.unknownJsonFieldsForUpdate(cfg.unknownFields)
+	return o.updateSecurityZone(ctx, zoneId, raw)
 }
 
 // GetAllPolicies returns []Policy representing all policies configured within the DC blueprint
@@ -395,7 +403,9 @@ func (o *TwoStageL3ClosClient) GetAllVirtualNetworks(ctx context.Context) (map[O
 // UpdateVirtualNetwork updates the virtual network specified by ID using the
 // VirtualNetworkData and HTTP method PUT.
 func (o *TwoStageL3ClosClient) UpdateVirtualNetwork(ctx context.Context, id ObjectId, in *VirtualNetworkData) error {
-	return o.updateVirtualNetwork(ctx, id, in.raw())
+	raw := in.raw()
+	raw.unknownFields = o.client.unknownJsonFieldsForUpdate(in.unknownFields)
+	return o.updateVirtualNetwork(ctx, id, raw)
 }
 
 // DeleteVirtualNetwork deletes the virtual network specified by id from the
@@ -491,13 +501,24 @@ func (o *TwoStageL3ClosClient) GetDefaultRoutingPolicy(ctx context.Context) (*Dc
 // CreateRoutingPolicy creates a blueprint routing policy according to the
 // supplied *DcRoutingPolicyData.
 func (o *TwoStageL3ClosClient) CreateRoutingPolicy(ctx context.Context, in *DcRoutingPolicyData) (ObjectId, error) {
-	return o.createRoutingPolicy(ctx, in.raw())
+	raw := in.raw()
+	err := o.checkRoutingPolicyExportPolicy(raw)
+	if err != nil {
+		return "", err
+	}
+	return o.createRoutingPolicy(ctx, raw)
 }
 
 // UpdateRoutingPolicy modifies the blueprint routing policy specified by 'id'
 // according to the supplied *DcRoutingPolicyData.
 func (o *TwoStageL3ClosClient) UpdateRoutingPolicy(ctx context.Context, id ObjectId, in *DcRoutingPolicyData) error {
-	return o.updateRoutingPolicy(ctx, id, in.raw())
+	raw := in.raw()
+	raw.unknownFields = o.client.unknownJsonFieldsForUpdate(in.unknownFields)
+	err := o.checkRoutingPolicyExportPolicy(raw)
+	if err != nil {
+		return err
+	}
+	return o.updateRoutingPolicy(ctx, id, raw)
 }
 
 // DeleteRoutingPolicy deletes the routing policy specified by id.
@@ -666,11 +687,8 @@ func (o *TwoStageL3ClosClient) GetSystemNodeInfo(ctx context.Context, nodeId Obj
 
 // GetAllIbaWidgets returns a list of IBA Widgets in the blueprint
 func (o *TwoStageL3ClosClient) GetAllIbaWidgets(ctx context.Context) ([]IbaWidget, error) {
-	if !compatibility.IbaWidgetSupported.Check(o.client.apiVersion) {
-		return nil, ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba widgets supported only with apstra version %s", compatibility.IbaWidgetSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetAllIbaWidgets"); err != nil {
+		return nil, err
 	}
 
 	rawWidgets, err := o.client.getAllIbaWidgets(ctx, o.blueprintId)
@@ -693,11 +711,8 @@ func (o *TwoStageL3ClosClient) GetAllIbaWidgets(ctx context.Context) ([]IbaWidge
 // GetIbaWidgetByLabel returns the IBA Widgets in the blueprint which matches the specified
 // label, or an error in the case of no matches, or multiple matches
 func (o *TwoStageL3ClosClient) GetIbaWidgetByLabel(ctx context.Context, label string) (*IbaWidget, error) {
-	if !compatibility.IbaWidgetSupported.Check(o.client.apiVersion) {
-		return nil, ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba widgets supported only with apstra version %s", compatibility.IbaWidgetSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaWidgetByLabel"); err != nil {
+		return nil, err
 	}
 
 	rawWidget, err := o.client.getIbaWidgetByLabel(ctx, o.blueprintId, label)
@@ -710,11 +725,8 @@ func (o *TwoStageL3ClosClient) GetIbaWidgetByLabel(ctx context.Context, label st
 
 // GetIbaWidgetsByLabel returns a list of IBA Widgets in the blueprint that match the label
 func (o *TwoStageL3ClosClient) GetIbaWidgetsByLabel(ctx context.Context, label string) ([]IbaWidget, error) {
-	if !compatibility.IbaWidgetSupported.Check(o.client.apiVersion) {
-		return nil, ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba widgets supported only with apstra version %s", compatibility.IbaWidgetSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaWidgetsByLabel"); err != nil {
+		return nil, err
 	}
 
 	rawWidgets, err := o.client.getIbaWidgetsByLabel(ctx, o.blueprintId, label)
@@ -736,11 +748,8 @@ func (o *TwoStageL3ClosClient) GetIbaWidgetsByLabel(ctx context.Context, label s
 
 // GetIbaWidget returns the IBA Widget that matches the ID
 func (o *TwoStageL3ClosClient) GetIbaWidget(ctx context.Context, id ObjectId) (*IbaWidget, error) {
-	if !compatibility.IbaWidgetSupported.Check(o.client.apiVersion) {
-		return nil, ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba widgets supported only with apstra version %s", compatibility.IbaWidgetSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaWidget"); err != nil {
+		return nil, err
 	}
 
 	rawWidget, err := o.client.getIbaWidget(ctx, o.blueprintId, id)
@@ -754,11 +763,8 @@ func (o *TwoStageL3ClosClient) GetIbaWidget(ctx context.Context, id ObjectId) (*
 // CreateIbaWidget creates an IBA Widget and returns the id of the created dashboard on success,
 // or a blank and error on failure
 func (o *TwoStageL3ClosClient) CreateIbaWidget(ctx context.Context, data *IbaWidgetData) (ObjectId, error) {
-	if !compatibility.IbaWidgetSupported.Check(o.client.apiVersion) {
-		return "", ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba widgets supported only with apstra version %s", compatibility.IbaWidgetSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.CreateIbaWidget"); err != nil {
+		return "", err
 	}
 
 	id, err := o.client.createIbaWidget(ctx, o.blueprintId, data.raw())
@@ -771,11 +777,8 @@ func (o *TwoStageL3ClosClient) CreateIbaWidget(ctx context.Context, data *IbaWid
 
 // UpdateIbaWidget updates an IBA Widget.
 func (o *TwoStageL3ClosClient) UpdateIbaWidget(ctx context.Context, id ObjectId, c *IbaWidgetData) error {
-	if !compatibility.IbaWidgetSupported.Check(o.client.apiVersion) {
-		return ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba widgets supported only with apstra version %s", compatibility.IbaWidgetSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.UpdateIbaWidget"); err != nil {
+		return err
 	}
 
 	return o.client.updateIbaWidget(ctx, o.blueprintId, id, c.raw())
@@ -783,11 +786,8 @@ func (o *TwoStageL3ClosClient) UpdateIbaWidget(ctx context.Context, id ObjectId,
 
 // DeleteIbaWidget deletes an IBA Widget
 func (o *TwoStageL3ClosClient) DeleteIbaWidget(ctx context.Context, id ObjectId) error {
-	if !compatibility.IbaWidgetSupported.Check(o.client.apiVersion) {
-		return ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba widgets supported only with apstra version %s", compatibility.IbaWidgetSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.DeleteIbaWidget"); err != nil {
+		return err
 	}
 
 	return o.client.deleteIbaWidget(ctx, o.blueprintId, id)
@@ -796,11 +796,8 @@ func (o *TwoStageL3ClosClient) DeleteIbaWidget(ctx context.Context, id ObjectId)
 // InstantiateIbaPredefinedProbe instantiates a predefined probe using the name and properties specified in data
 // and returns the id of the created probe on success, or a blank and error on failure.
 func (o *TwoStageL3ClosClient) InstantiateIbaPredefinedProbe(ctx context.Context, data *IbaPredefinedProbeRequest) (ObjectId, error) {
-	if !compatibility.IbaProbeSupported.Check(o.client.apiVersion) {
-		return "", ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba probes supported only with apstra version %s", compatibility.IbaProbeSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.InstantiateIbaPredefinedProbe"); err != nil {
+		return "", err
 	}
 
 	return o.client.instantiatePredefinedIbaProbe(ctx, o.blueprintId, data)
@@ -808,11 +805,8 @@ func (o *TwoStageL3ClosClient) InstantiateIbaPredefinedProbe(ctx context.Context
 
 // GetAllIbaPredefinedProbes lists all the Predefined IBA probes available to a blueprint
 func (o *TwoStageL3ClosClient) GetAllIbaPredefinedProbes(ctx context.Context) ([]IbaPredefinedProbe, error) {
-	if !compatibility.IbaProbeSupported.Check(o.client.apiVersion) {
-		return nil, ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba probes supported only with apstra version %s", compatibility.IbaProbeSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetAllIbaPredefinedProbes"); err != nil {
+		return nil, err
 	}
 
 	return o.client.getAllIbaPredefinedProbes(ctx, o.blueprintId)
@@ -820,11 +814,8 @@ func (o *TwoStageL3ClosClient) GetAllIbaPredefinedProbes(ctx context.Context) ([
 
 // GetIbaPredefinedProbeByName locates a predefined probe by name
 func (o *TwoStageL3ClosClient) GetIbaPredefinedProbeByName(ctx context.Context, name string) (*IbaPredefinedProbe, error) {
-	if !compatibility.IbaProbeSupported.Check(o.client.apiVersion) {
-		return nil, ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba probes supported only with apstra version %s", compatibility.IbaProbeSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaPredefinedProbeByName"); err != nil {
+		return nil, err
 	}
 
 	return o.client.getIbaPredefinedProbeByName(ctx, o.blueprintId, name)
@@ -832,11 +823,8 @@ func (o *TwoStageL3ClosClient) GetIbaPredefinedProbeByName(ctx context.Context,
 
 // GetIbaProbe returns the IBA Probe that matches the ID
 func (o *TwoStageL3ClosClient) GetIbaProbe(ctx context.Context, id ObjectId) (*IbaProbe, error) {
-	if !compatibility.IbaProbeSupported.Check(o.client.apiVersion) {
-		return nil, ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba probes supported only with apstra version %s", compatibility.IbaProbeSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaProbe"); err != nil {
+		return nil, err
 	}
 
 	probe, err := o.client.getIbaProbe(ctx, o.blueprintId, id)
@@ -849,11 +837,8 @@ func (o *TwoStageL3ClosClient) GetIbaProbe(ctx context.Context, id ObjectId) (*I
 
 // GetIbaProbeState returns the State of the IBA Probe that matches the ID
 func (o *TwoStageL3ClosClient) GetIbaProbeState(ctx context.Context, id ObjectId) (*IbaProbeState, error) {
-	if !compatibility.IbaProbeSupported.Check(o.client.apiVersion) {
-		return nil, ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba probes supported only with apstra version %s", compatibility.IbaProbeSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaProbeState"); err != nil {
+		return nil, err
 	}
 
 	probe, err := o.client.getIbaProbeState(ctx, o.blueprintId, id)
@@ -866,11 +851,8 @@ func (o *TwoStageL3ClosClient) GetIbaProbeState(ctx context.Context, id ObjectId
 
 // DeleteIbaProbe deletes an IBA Probe
 func (o *TwoStageL3ClosClient) DeleteIbaProbe(ctx context.Context, id ObjectId) error {
-	if !compatibility.IbaProbeSupported.Check(o.client.apiVersion) {
-		return ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba probes supported only with apstra version %s", compatibility.IbaProbeSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.DeleteIbaProbe"); err != nil {
+		return err
 	}
 
 	return o.client.deleteIbaProbe(ctx, o.blueprintId, id)
@@ -878,20 +860,72 @@ func (o *TwoStageL3ClosClient) DeleteIbaProbe(ctx context.Context, id ObjectId)
 
 // CreateIbaProbeFromJson creates an IBA Probe
 func (o *TwoStageL3ClosClient) CreateIbaProbeFromJson(ctx context.Context, probeJson json.RawMessage) (ObjectId, error) {
-	if !compatibility.IbaProbeSupported.Check(o.client.apiVersion) {
-		return "", ClientErr{
-			errType: ErrCompatibility,
-			err:     fmt.Errorf("iba probes supported only with apstra version %s", compatibility.IbaProbeSupported),
-		}
+	if err := o.client.checkSupports("TwoStageL3ClosClient.CreateIbaProbeFromJson"); err != nil {
+		return "", err
+	}
+
+	return o.client.createIbaProbeFromJson(ctx, o.blueprintId, probeJson)
+}
+
+// CreateIbaProbe validates the stage graph of the probe described by in and
+// creates it, returning the ID of the new probe.
+func (o *TwoStageL3ClosClient) CreateIbaProbe(ctx context.Context, in *IbaProbeRequest) (ObjectId, error) {
+	if err := o.client.checkSupports("TwoStageL3ClosClient.CreateIbaProbe"); err != nil {
+		return "", err
+	}
+
+	err := in.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := in.raw()
+	if err != nil {
+		return "", err
+	}
+
+	probeJson, err := json.Marshal(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed marshaling iba probe request - %w", err)
 	}
 
 	return o.client.createIbaProbeFromJson(ctx, o.blueprintId, probeJson)
 }
 
+// UpdateIbaProbe validates the stage graph of the probe described by in and
+// uses it to replace the IBA Probe with the specified ID.
+func (o *TwoStageL3ClosClient) UpdateIbaProbe(ctx context.Context, id ObjectId, in *IbaProbeRequest) error {
+	if err := o.client.checkSupports("TwoStageL3ClosClient.UpdateIbaProbe"); err != nil {
+		return err
+	}
+
+	err := in.Validate()
+	if err != nil {
+		return err
+	}
+
+	raw, err := in.raw()
+	if err != nil {
+		return err
+	}
+
+	return o.client.updateIbaProbe(ctx, o.blueprintId, id, raw)
+}
+
+// GetIbaProbeStageData returns the current output of the named stage of the
+// IBA Probe with the specified ID.
+func (o *TwoStageL3ClosClient) GetIbaProbeStageData(ctx context.Context, id ObjectId, stage string) (*IbaProbeStageData, error) {
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaProbeStageData"); err != nil {
+		return nil, err
+	}
+
+	return o.client.getIbaProbeStageData(ctx, o.blueprintId, id, stage)
+}
+
 // GetAllIbaDashboards returns a list of IBA Dashboards in the blueprint
 func (o *TwoStageL3ClosClient) GetAllIbaDashboards(ctx context.Context) ([]IbaDashboard, error) {
-	if compatibility.GeApstra500.Check(o.client.apiVersion) {
-		return nil, fmt.Errorf("IBA Dashboard not supported with Asptra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetAllIbaDashboards"); err != nil {
+		return nil, err
 	}
 
 	rawDashes, err := o.client.getAllIbaDashboards(ctx, o.blueprintId)
@@ -913,8 +947,8 @@ func (o *TwoStageL3ClosClient) GetAllIbaDashboards(ctx context.Context) ([]IbaDa
 
 // GetIbaDashboard returns the IBA Dashboard that matches the ID
 func (o *TwoStageL3ClosClient) GetIbaDashboard(ctx context.Context, id ObjectId) (*IbaDashboard, error) {
-	if compatibility.GeApstra500.Check(o.client.apiVersion) {
-		return nil, fmt.Errorf("IBA Dashboard not supported with Asptra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaDashboard"); err != nil {
+		return nil, err
 	}
 
 	rawIbaDb, err := o.client.getIbaDashboard(ctx, o.blueprintId, id)
@@ -928,8 +962,8 @@ func (o *TwoStageL3ClosClient) GetIbaDashboard(ctx context.Context, id ObjectId)
 // GetIbaDashboardByLabel returns the IBA Dashboard that matches the label.
 // It will return an error if more than one IBA dashboard matches the label.
 func (o *TwoStageL3ClosClient) GetIbaDashboardByLabel(ctx context.Context, label string) (*IbaDashboard, error) {
-	if compatibility.GeApstra500.Check(o.client.apiVersion) {
-		return nil, fmt.Errorf("IBA Dashboard not supported with Asptra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetIbaDashboardByLabel"); err != nil {
+		return nil, err
 	}
 
 	rawIbaDb, err := o.client.getIbaDashboardByLabel(ctx, o.blueprintId, label)
@@ -943,8 +977,8 @@ func (o *TwoStageL3ClosClient) GetIbaDashboardByLabel(ctx context.Context, label
 // CreateIbaDashboard creates an IBA Dashboard and returns the id of the created dashboard on success,
 // or a blank and error on failure
 func (o *TwoStageL3ClosClient) CreateIbaDashboard(ctx context.Context, data *IbaDashboardData) (ObjectId, error) {
-	if compatibility.GeApstra500.Check(o.client.apiVersion) {
-		return "", fmt.Errorf("IBA Dashboard not supported with Asptra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.CreateIbaDashboard"); err != nil {
+		return "", err
 	}
 
 	id, err := o.client.createIbaDashboard(ctx, o.blueprintId, data.raw())
@@ -957,8 +991,8 @@ func (o *TwoStageL3ClosClient) CreateIbaDashboard(ctx context.Context, data *Iba
 
 // UpdateIbaDashboard updates an IBA Dashboard and returns an error on failure
 func (o *TwoStageL3ClosClient) UpdateIbaDashboard(ctx context.Context, id ObjectId, data *IbaDashboardData) error {
-	if compatibility.GeApstra500.Check(o.client.apiVersion) {
-		return fmt.Errorf("IBA Dashboard not supported with Asptra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.UpdateIbaDashboard"); err != nil {
+		return err
 	}
 
 	return o.client.updateIbaDashboard(ctx, o.blueprintId, id, data.raw())
@@ -966,8 +1000,8 @@ func (o *TwoStageL3ClosClient) UpdateIbaDashboard(ctx context.Context, id Object
 
 // DeleteIbaDashboard deletes an IBA Dashboard and returns an error on failure
 func (o *TwoStageL3ClosClient) DeleteIbaDashboard(ctx context.Context, id ObjectId) error {
-	if compatibility.GeApstra500.Check(o.client.apiVersion) {
-		return fmt.Errorf("IBA Dashboard not supported with Asptra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.DeleteIbaDashboard"); err != nil {
+		return err
 	}
 
 	return o.client.deleteIbaDashboard(ctx, o.blueprintId, id)
@@ -1092,19 +1126,27 @@ func (o *TwoStageL3ClosClient) GetFabricSettings(ctx context.Context) (*FabricSe
 	var raw *rawFabricSettings
 	var err error
 
-	switch {
-	case compatibility.FabricSettingsApiOk.Check(o.client.apiVersion):
+	if o.client.Supports("TwoStageL3ClosClient.GetFabricSettings.FabricSettingsApi") {
 		raw, err = o.getFabricSettings(ctx)
-	case compatibility.EqApstra420.Check(o.client.apiVersion):
+	} else {
 		raw, err = o.getFabricSettings420(ctx)
-	default:
-		return nil, fmt.Errorf("cannot invoke GetFabricSettings, not supported with Apstra version %q", o.client.apiVersion)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return raw.polish()
+	result, err := raw.polish()
+	if err != nil {
+		return nil, err
+	}
+
+	// feature switches are parsed by polish(), rather than when decoding
+	err = o.client.checkResponseEnums(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 // SetFabricSettings sets the specified fabric settings
@@ -1113,12 +1155,19 @@ func (o *TwoStageL3ClosClient) SetFabricSettings(ctx context.Context, in *Fabric
 		return errors.New("SpineLeafLinks and SpineSuperspineLinks must be nil in SetFabricSettings()")
 	}
 
-	switch {
-	case compatibility.FabricSettingsApiOk.Check(o.client.apiVersion):
+	var err error
+	in.fabricSettingsFields(func(name string) {
+		if err == nil {
+			err = o.client.checkSupports("FabricSettings." + name)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.client.Supports("TwoStageL3ClosClient.SetFabricSettings.FabricSettingsApi") {
 		return o.setFabricSettings(ctx, in.raw())
-	case compatibility.EqApstra420.Check(o.client.apiVersion):
-		return o.setFabricSettings420(ctx, in.raw())
 	}
 
-	return fmt.Errorf("cannot invoke SetFabricSettings, not supported with Apstra version %q", o.client.apiVersion)
+	return o.setFabricSettings420(ctx, in.raw())
 }
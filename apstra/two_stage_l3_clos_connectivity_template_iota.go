@@ -76,12 +76,15 @@ func (o CtPrimitivePolicyTypeName) raw() ctPrimitivePolicyTypeName {
 	case CtPrimitivePolicyTypeNameAttachRoutingZoneConstraint:
 		return ctPrimitivePolicyTypeNameAttachRoutingZoneConstraint
 	default:
+		if s, ok := lenientEnumString[ctPrimitivePolicyTypeName](int(o)); ok {
+			return ctPrimitivePolicyTypeName(s)
+		}
 		return ctPrimitivePolicyTypeName(fmt.Sprintf(ctPrimitivePolicyTypeNameUnknown, o))
 	}
 }
 
 func (o *CtPrimitivePolicyTypeName) FromString(in string) error {
-	i, err := ctPrimitivePolicyTypeName(in).parse()
+	i, err := parseKnown(ctPrimitivePolicyTypeName(in))
 	if err != nil {
 		return err
 	}
@@ -118,6 +121,9 @@ func (o ctPrimitivePolicyTypeName) parse() (int, error) {
 	case ctPrimitivePolicyTypeNameAttachRoutingZoneConstraint:
 		return int(CtPrimitivePolicyTypeNameAttachRoutingZoneConstraint), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(CtPrimitivePolicyTypeNameUnknown, o)
 	}
 }
@@ -148,12 +154,15 @@ func (o CtPrimitiveBgpPeerTo) String() string {
 	case CtPrimitiveBgpPeerToInterfaceOrSharedIpEndpoint:
 		return string(ctPrimitiveBgpPeerToInterfaceOrSharedIpEndpoint)
 	default:
+		if s, ok := lenientEnumString[ctPrimitiveBgpPeerTo](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(ctPrimitiveBgpPeerToUnknown, o)
 	}
 }
 
 func (o *CtPrimitiveBgpPeerTo) FromString(in string) error {
-	i, err := ctPrimitiveBgpPeerTo(in).parse()
+	i, err := parseKnown(ctPrimitiveBgpPeerTo(in))
 	if err != nil {
 		return err
 	}
@@ -174,6 +183,9 @@ func (o ctPrimitiveBgpPeerTo) parse() (int, error) {
 	case ctPrimitiveBgpPeerToInterfaceOrSharedIpEndpoint:
 		return int(CtPrimitiveBgpPeerToInterfaceOrSharedIpEndpoint), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(CtPrimitiveBgpPeerToUnknown, o)
 	}
 }
@@ -200,12 +212,15 @@ func (o CtPrimitiveIPv4ProtocolSessionAddressing) String() string {
 	case CtPrimitiveIPv4ProtocolSessionAddressingAddressed:
 		return string(ctPrimitiveIPv4ProtocolSessionAddressingAddressed)
 	default:
+		if s, ok := lenientEnumString[ctPrimitiveIPv4ProtocolSessionAddressing](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(ctPrimitiveIPv4ProtocolSessionAddressingUnknown, o)
 	}
 }
 
 func (o *CtPrimitiveIPv4ProtocolSessionAddressing) FromString(in string) error {
-	i, err := ctPrimitiveIPv4ProtocolSessionAddressing(in).parse()
+	i, err := parseKnown(ctPrimitiveIPv4ProtocolSessionAddressing(in))
 	if err != nil {
 		return err
 	}
@@ -224,6 +239,9 @@ func (o ctPrimitiveIPv4ProtocolSessionAddressing) parse() (int, error) {
 	case ctPrimitiveIPv4ProtocolSessionAddressingAddressed:
 		return int(CtPrimitiveIPv4ProtocolSessionAddressingAddressed), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(CtPrimitiveIPv4ProtocolSessionAddressingUnknown, o)
 	}
 }
@@ -254,12 +272,15 @@ func (o CtPrimitiveIPv6ProtocolSessionAddressing) String() string {
 	case CtPrimitiveIPv6ProtocolSessionAddressingLinkLocal:
 		return string(ctPrimitiveIPv6ProtocolSessionAddressingLinkLocal)
 	default:
+		if s, ok := lenientEnumString[ctPrimitiveIPv6ProtocolSessionAddressing](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(ctPrimitiveIPv6ProtocolSessionAddressingUnknown, o)
 	}
 }
 
 func (o *CtPrimitiveIPv6ProtocolSessionAddressing) FromString(in string) error {
-	i, err := ctPrimitiveIPv6ProtocolSessionAddressing(in).parse()
+	i, err := parseKnown(ctPrimitiveIPv6ProtocolSessionAddressing(in))
 	if err != nil {
 		return err
 	}
@@ -280,6 +301,9 @@ func (o ctPrimitiveIPv6ProtocolSessionAddressing) parse() (int, error) {
 	case ctPrimitiveIPv6ProtocolSessionAddressingLinkLocal:
 		return int(CtPrimitiveIPv6ProtocolSessionAddressingLinkLocal), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(CtPrimitiveIPv6ProtocolSessionAddressingUnknown, o)
 	}
 }
@@ -306,12 +330,15 @@ func (o CtPrimitiveIPv4AddressingType) String() string {
 	case CtPrimitiveIPv4AddressingTypeNumbered:
 		return string(ctPrimitiveIPv4AddressingTypeNumbered)
 	default:
+		if s, ok := lenientEnumString[ctPrimitiveIPv4AddressingType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(ctPrimitiveIPv4AddressingTypeUnknown, o)
 	}
 }
 
 func (o *CtPrimitiveIPv4AddressingType) FromString(in string) error {
-	i, err := ctPrimitiveIPv4AddressingType(in).parse()
+	i, err := parseKnown(ctPrimitiveIPv4AddressingType(in))
 	if err != nil {
 		return err
 	}
@@ -330,6 +357,9 @@ func (o ctPrimitiveIPv4AddressingType) parse() (int, error) {
 	case ctPrimitiveIPv4AddressingTypeNumbered:
 		return int(CtPrimitiveIPv4AddressingTypeNumbered), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(CtPrimitiveIPv4AddressingTypeUnknown, o)
 	}
 }
@@ -360,12 +390,15 @@ func (o CtPrimitiveIPv6AddressingType) String() string {
 	case CtPrimitiveIPv6AddressingTypeNumbered:
 		return string(ctPrimitiveIPv6AddressingTypeNumbered)
 	default:
+		if s, ok := lenientEnumString[ctPrimitiveIPv6AddressingType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(ctPrimitiveIPv6AddressingTypeUnknown, o)
 	}
 }
 
 func (o *CtPrimitiveIPv6AddressingType) FromString(in string) error {
-	i, err := ctPrimitiveIPv6AddressingType(in).parse()
+	i, err := parseKnown(ctPrimitiveIPv6AddressingType(in))
 	if err != nil {
 		return err
 	}
@@ -386,6 +419,9 @@ func (o ctPrimitiveIPv6AddressingType) parse() (int, error) {
 	case ctPrimitiveIPv6AddressingTypeNumbered:
 		return int(CtPrimitiveIPv6AddressingTypeNumbered), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(CtPrimitiveIPv6AddressingTypeUnknown, o)
 	}
 }
@@ -416,12 +452,15 @@ func (o CtPrimitiveStatus) String() string {
 	case CtPrimitiveStatusReady:
 		return string(ctPrimitiveStatusReady)
 	default:
+		if s, ok := lenientEnumString[ctPrimitiveStatus](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(ctPrimitiveStatusUnknown, o)
 	}
 }
 
 func (o *CtPrimitiveStatus) FromString(in string) error {
-	i, err := ctPrimitiveStatus(in).parse()
+	i, err := parseKnown(ctPrimitiveStatus(in))
 	if err != nil {
 		return err
 	}
@@ -442,6 +481,9 @@ func (o ctPrimitiveStatus) parse() (int, error) {
 	case ctPrimitiveStatusReady:
 		return int(CtPrimitiveStatusReady), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(CtPrimitiveStatusUnknown, o)
 	}
 }
@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/Juniper/apstra-go-sdk/apstra/compatibility"
 	"github.com/Juniper/apstra-go-sdk/apstra/enum"
 	oenum "github.com/orsinium-labs/enum"
 )
@@ -231,6 +230,10 @@ func (o *TwoStageL3ClosClient) getFabricSettings(ctx context.Context) (*rawFabri
 // getFabricSettings420 does the same job as setFabricSettings, but for Apstra 4.2.0, which collects
 // the parameters in rawFabricSettings from 3 different places
 func (o *TwoStageL3ClosClient) getFabricSettings420(ctx context.Context) (*rawFabricSettings, error) {
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetFabricSettings.PolicyNodes"); err != nil {
+		return nil, err
+	}
+
 	fabricAddressingPolicy, err := o.GetFabricAddressingPolicy(ctx)
 	if err != nil {
 		return nil, err
@@ -289,6 +292,10 @@ func (o *TwoStageL3ClosClient) setFabricSettings(ctx context.Context, in *rawFab
 // setFabricSettings420 does the same job as setFabricSettings, but for Apstra 4.2.0, which controls
 // the parameters in rawFabricSettings in 3 different places
 func (o *TwoStageL3ClosClient) setFabricSettings420(ctx context.Context, in *rawFabricSettings) error {
+	if err := o.client.checkSupports("TwoStageL3ClosClient.SetFabricSettings.PolicyNodes"); err != nil {
+		return err
+	}
+
 	err := o.SetFabricAddressingPolicy(ctx, &TwoStageL3ClosFabricAddressingPolicy{
 		Ipv6Enabled: in.Ipv6Enabled,
 		EsiMacMsb:   in.EsiMacMsb,
@@ -317,8 +324,8 @@ func (o *TwoStageL3ClosClient) setFabricSettings420(ctx context.Context, in *raw
 }
 
 func (o *TwoStageL3ClosClient) getSzFootprintOptimization420(ctx context.Context) (string, error) {
-	if !compatibility.EqApstra420.Check(o.client.apiVersion) {
-		return "", fmt.Errorf("getSzFootprintOptimization420() must not be invoked with apstra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.GetFabricSettings.PolicyNodes"); err != nil {
+		return "", err
 	}
 
 	securityZonePolicyNodeIds, err := o.NodeIdsByType(ctx, NodeTypeSecurityZonePolicy)
@@ -346,8 +353,8 @@ func (o *TwoStageL3ClosClient) setSzFootprintOptimization420(ctx context.Context
 		return nil
 	}
 
-	if !compatibility.EqApstra420.Check(o.client.apiVersion) {
-		return fmt.Errorf("setSzFootprintOptimization420() must not be invoked with apstra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.SetFabricSettings.PolicyNodes"); err != nil {
+		return err
 	}
 
 	securityZonePolicyNodeIds, err := o.NodeIdsByType(ctx, NodeTypeSecurityZonePolicy)
@@ -0,0 +1,186 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/compatibility"
+	"github.com/hashicorp/go-version"
+)
+
+// fabricSettingsFieldConstraints maps FabricSettings fields which are not
+// available on every Apstra release to the versions which support them.
+// Apstra 4.2.0 spreads fabric settings across the fabric addressing policy,
+// the virtual network policy node, the security zone policy node and the
+// anti-affinity policy, and has no way to set OverlayControlProtocol.
+// Apstra 4.2.1 and later (including 5.0) accept every remaining field via
+// the fabric-settings API. Fields not listed here are supported wherever
+// fabric settings are.
+var fabricSettingsFieldConstraints = map[string]compatibility.Constraint{
+	"OverlayControlProtocol": compatibility.FabricSettingsApiOk,
+}
+
+// fabricSettingsCreationOnlyFields can be specified only at blueprint
+// creation time; SetFabricSettings rejects them with any Apstra version.
+var fabricSettingsCreationOnlyFields = map[string]bool{
+	"SpineLeafLinks":       true,
+	"SpineSuperspineLinks": true,
+}
+
+// FabricSettingsDifference describes a FabricSettings field with different
+// values in two FabricSettings. A and B are nil when the field is not set.
+type FabricSettingsDifference struct {
+	Field string
+	A     any
+	B     any
+}
+
+func (o FabricSettingsDifference) String() string {
+	return fmt.Sprintf("%s: %s -> %s", o.Field, fabricSettingsValueString(o.A), fabricSettingsValueString(o.B))
+}
+
+func fabricSettingsValueString(v any) string {
+	if v == nil {
+		return "<nil>"
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// fabricSettingsFields invokes f with the name of each non-nil field in o.
+func (o *FabricSettings) fabricSettingsFields(f func(name string)) {
+	if o == nil {
+		return
+	}
+
+	v := reflect.ValueOf(*o)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsNil() {
+			continue
+		}
+		f(v.Type().Field(i).Name)
+	}
+}
+
+// Diff returns the fields which differ between o and other, in FabricSettings
+// field order. A field which is set in one and nil in the other counts as a
+// difference.
+func (o *FabricSettings) Diff(other *FabricSettings) []FabricSettingsDifference {
+	if o == nil {
+		o = new(FabricSettings)
+	}
+	if other == nil {
+		other = new(FabricSettings)
+	}
+
+	a := reflect.ValueOf(*o)
+	b := reflect.ValueOf(*other)
+
+	var result []FabricSettingsDifference
+	for i := 0; i < a.NumField(); i++ {
+		fa, fb := a.Field(i), b.Field(i)
+
+		var va, vb any
+		if !fa.IsNil() {
+			va = fa.Elem().Interface()
+		}
+		if !fb.IsNil() {
+			vb = fb.Elem().Interface()
+		}
+
+		if reflect.DeepEqual(va, vb) {
+			continue
+		}
+
+		result = append(result, FabricSettingsDifference{
+			Field: a.Type().Field(i).Name,
+			A:     va,
+			B:     vb,
+		})
+	}
+
+	return result
+}
+
+// fabricSettingsFieldSupported returns true when the named FabricSettings
+// field can be sent to an Apstra server running version v with SetFabricSettings.
+func fabricSettingsFieldSupported(name string, v *version.Version) bool {
+	if fabricSettingsCreationOnlyFields[name] {
+		return false
+	}
+	if constraint, ok := fabricSettingsFieldConstraints[name]; ok {
+		return constraint.Check(v)
+	}
+	return true
+}
+
+// fabricSettingsSupport sorts the non-nil fields of in according to whether
+// they can be sent to an Apstra server running version v with SetFabricSettings.
+func fabricSettingsSupport(in *FabricSettings, v *version.Version) (supported, unsupported []string) {
+	in.fabricSettingsFields(func(name string) {
+		if fabricSettingsFieldSupported(name, v) {
+			supported = append(supported, name)
+		} else {
+			unsupported = append(unsupported, name)
+		}
+	})
+
+	return supported, unsupported
+}
+
+// supportedBy returns a copy of o with fields which cannot be sent to an
+// Apstra server running version v cleared, along with the names of the
+// cleared fields.
+func (o FabricSettings) supportedBy(v *version.Version) (*FabricSettings, []string) {
+	_, dropped := fabricSettingsSupport(&o, v)
+
+	result := reflect.ValueOf(&o).Elem()
+	for _, name := range dropped {
+		field := result.FieldByName(name)
+		field.Set(reflect.Zero(field.Type()))
+	}
+
+	return &o, dropped
+}
+
+// FabricSettingsSupport reports which of the non-nil fields of in can be
+// applied to a blueprint on this Apstra server with SetFabricSettings.
+// Fields which can only be specified at blueprint creation time are always
+// unsupported.
+func (o *Client) FabricSettingsSupport(in *FabricSettings) (supported, unsupported []string) {
+	return fabricSettingsSupport(in, o.apiVersion)
+}
+
+// ApplyFabricSettings invokes SetFabricSettings using only the fields of in
+// which are supported by the blueprint's Apstra server. The names of the
+// fields which were not applied are returned.
+func (o *TwoStageL3ClosClient) ApplyFabricSettings(ctx context.Context, in *FabricSettings) ([]string, error) {
+	settings, dropped := in.supportedBy(o.client.apiVersion)
+
+	err := o.SetFabricSettings(ctx, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed applying fabric settings to blueprint %q - %w", o.blueprintId, err)
+	}
+
+	return dropped, nil
+}
+
+// CopyFabricSettings copies fabric settings from the src blueprint to the dst
+// blueprint, which may be hosted on a different Apstra server and release.
+// Settings which cannot be applied to dst are skipped, and their names are
+// returned.
+func CopyFabricSettings(ctx context.Context, src, dst *TwoStageL3ClosClient) ([]string, error) {
+	settings, err := src.GetFabricSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading fabric settings from blueprint %q - %w", src.blueprintId, err)
+	}
+
+	return dst.ApplyFabricSettings(ctx, settings)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"testing"
+
+	"github.com/Juniper/apstra-go-sdk/apstra/enum"
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFabricSettingsDiff(t *testing.T) {
+	a := &FabricSettings{
+		EsiMacMsb:            toPtr(uint8(2)),
+		JunosGracefulRestart: toPtr(enum.FeatureSwitchEnabled),
+		MaxEvpnRoutes:        toPtr(uint32(0)),
+	}
+	b := &FabricSettings{
+		EsiMacMsb:            toPtr(uint8(2)),
+		JunosGracefulRestart: toPtr(enum.FeatureSwitchDisabled),
+		FabricL3Mtu:          toPtr(uint16(9170)),
+	}
+
+	diff := a.Diff(b)
+	require.Equal(t, []FabricSettingsDifference{
+		{Field: "FabricL3Mtu", A: nil, B: uint16(9170)},
+		{Field: "JunosGracefulRestart", A: enum.FeatureSwitchEnabled, B: enum.FeatureSwitchDisabled},
+		{Field: "MaxEvpnRoutes", A: uint32(0), B: nil},
+	}, diff)
+	require.Equal(t, "FabricL3Mtu: <nil> -> 9170", diff[0].String())
+	require.Equal(t, "JunosGracefulRestart: enabled -> disabled", diff[1].String())
+
+	require.Empty(t, a.Diff(a))
+	require.Empty(t, (*FabricSettings)(nil).Diff(new(FabricSettings)))
+}
+
+func TestFabricSettingsSupport(t *testing.T) {
+	in := FabricSettings{
+		EsiMacMsb:              toPtr(uint8(2)),
+		FabricL3Mtu:            toPtr(uint16(9170)),
+		OptimiseSzFootprint:    toPtr(enum.FeatureSwitchEnabled),
+		OverlayControlProtocol: toPtr(OverlayControlProtocolEvpn),
+		SpineLeafLinks:         toPtr(AddressingSchemeIp4),
+	}
+
+	supported, unsupported := fabricSettingsSupport(&in, version.Must(version.NewVersion("4.2.0")))
+	require.Equal(t, []string{"EsiMacMsb", "FabricL3Mtu", "OptimiseSzFootprint"}, supported)
+	require.Equal(t, []string{"OverlayControlProtocol", "SpineLeafLinks"}, unsupported)
+
+	for _, v := range []string{"4.2.1", "4.2.1.1", "4.2.2", "5.0.0"} {
+		supported, unsupported = fabricSettingsSupport(&in, version.Must(version.NewVersion(v)))
+		require.Equalf(t, []string{"EsiMacMsb", "FabricL3Mtu", "OptimiseSzFootprint", "OverlayControlProtocol"}, supported, "version %s", v)
+		require.Equalf(t, []string{"SpineLeafLinks"}, unsupported, "version %s", v)
+	}
+
+	settings, dropped := in.supportedBy(version.Must(version.NewVersion("4.2.0")))
+	require.Equal(t, []string{"OverlayControlProtocol", "SpineLeafLinks"}, dropped)
+	require.Equal(t, &FabricSettings{
+		EsiMacMsb:           toPtr(uint8(2)),
+		FabricL3Mtu:         toPtr(uint16(9170)),
+		OptimiseSzFootprint: toPtr(enum.FeatureSwitchEnabled),
+	}, settings)
+	require.NotNil(t, in.OverlayControlProtocol) // the original is not modified
+}
@@ -48,6 +48,9 @@ func (o LockStatus) String() string {
 	case LockStatusLockedByDeletedUser:
 		return string(lockStatusLockedByDeletedUser)
 	default:
+		if s, ok := lenientEnumString[lockStatus](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(lockStatusUnknown, o)
 	}
 }
@@ -69,6 +72,9 @@ func (o lockStatus) parse() (int, error) {
 	case lockStatusLockedByDeletedUser:
 		return int(LockStatusLockedByDeletedUser), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(LockStatusUnknown, o)
 	}
 }
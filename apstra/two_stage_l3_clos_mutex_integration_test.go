@@ -375,3 +375,98 @@ func TestReadOnlyBlueprintMutex(t *testing.T) {
 		})
 	}
 }
+
+// TestExpiredLeaseBlueprintMutex simulates a lock holder which crashes without
+// unlocking by stopping its heartbeat. A second client must break the lock
+// once the lease expires.
+func TestExpiredLeaseBlueprintMutex(t *testing.T) {
+	ctx := context.Background()
+
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bpA := testBlueprintA(ctx, t, client.client)
+			bpB, err := client.client.NewTwoStageL3ClosClient(ctx, bpA.Id())
+			require.NoError(t, err)
+
+			mutexA := bpA.Mutex.(*TwoStageL3ClosMutex)
+			require.NoError(t, mutexA.SetLease(3*time.Second))
+
+			log.Printf("testing Lock() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, mutexA.Lock(ctx))
+
+			// the lease is renewed while the heartbeat runs
+			time.Sleep(2 * (3*time.Second + mutexLeaseSkewAllowance))
+			var mutexErr MutexErr
+			err = bpB.Mutex.TryLock(ctx)
+			require.ErrorAs(t, err, &mutexErr)
+			require.NotNil(t, mutexErr.Mutex.(*TwoStageL3ClosMutex).Lease())
+
+			// simulate a crash of client A
+			mutexA.stopHeartbeat()
+
+			log.Printf("testing Lock() of expired lease against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			lockCtx, cancel := context.WithTimeout(ctx, time.Minute)
+			defer cancel()
+			require.NoError(t, bpB.Mutex.Lock(lockCtx))
+
+			// client A's stale tag ID is gone, so unlocking it must not
+			// disturb client B's lock
+			require.NoError(t, mutexA.Unlock(ctx))
+			err = mutexA.TryLock(ctx)
+			require.ErrorAs(t, err, &mutexErr)
+
+			require.NoError(t, bpB.Mutex.Unlock(ctx))
+		})
+	}
+}
+
+// TestForceUnlockBlueprintMutex breaks a lock using the read-only mutex found
+// in a MutexErr, and checks that the holder learns of it via LeaseLost.
+func TestForceUnlockBlueprintMutex(t *testing.T) {
+	ctx := context.Background()
+
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bpA := testBlueprintA(ctx, t, client.client)
+			bpB, err := client.client.NewTwoStageL3ClosClient(ctx, bpA.Id())
+			require.NoError(t, err)
+
+			mutexA := bpA.Mutex.(*TwoStageL3ClosMutex)
+			require.NoError(t, mutexA.SetLease(3*time.Second))
+			require.NoError(t, mutexA.Lock(ctx))
+
+			var mutexErr MutexErr
+			err = bpB.Mutex.TryLock(ctx)
+			require.ErrorAs(t, err, &mutexErr)
+
+			log.Printf("testing ForceUnlock() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, mutexErr.Mutex.(*TwoStageL3ClosMutex).ForceUnlock(ctx))
+
+			select {
+			case <-mutexA.LeaseLost():
+			case <-time.After(10 * time.Second):
+				t.Fatal("lock holder did not notice ForceUnlock()")
+			}
+
+			require.NoError(t, bpB.Mutex.TryLock(ctx))
+			require.NoError(t, mutexA.Unlock(ctx))
+			require.NoError(t, bpB.Mutex.Unlock(ctx))
+		})
+	}
+}
@@ -0,0 +1,738 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+const (
+	apiUrlBlueprintRacks    = apiUrlBlueprintByIdPrefix + "racks"
+	apiUrlBlueprintRackById = apiUrlBlueprintRacks + apiUrlPathDelim + "%s"
+)
+
+// TwoStageL3ClosRackSystem is a leaf switch, access switch or generic system
+// which belongs to a rack.
+type TwoStageL3ClosRackSystem struct {
+	Id                ObjectId
+	Label             string
+	Hostname          string
+	Role              SystemRole
+	GroupLabel        string   // label of the rack type element which produced the system
+	RedundancyGroupId ObjectId // empty unless the system is part of an ESI or MLAG pair
+}
+
+// TwoStageL3ClosRackLinkEndpoint is one end of a TwoStageL3ClosRackLink.
+type TwoStageL3ClosRackLinkEndpoint struct {
+	SystemId    ObjectId
+	InterfaceId ObjectId
+}
+
+// TwoStageL3ClosRackLink is an ethernet link with at least one end on a system
+// which belongs to the rack. Links toward spines are included.
+type TwoStageL3ClosRackLink struct {
+	Id        ObjectId
+	Role      string
+	Endpoints []TwoStageL3ClosRackLinkEndpoint
+}
+
+type TwoStageL3ClosRack struct {
+	Id   ObjectId
+	Data *TwoStageL3ClosRackData
+}
+
+type TwoStageL3ClosRackData struct {
+	Label          string
+	RackType       *RackType
+	LeafSwitches   []TwoStageL3ClosRackSystem
+	AccessSwitches []TwoStageL3ClosRackSystem
+	GenericSystems []TwoStageL3ClosRackSystem
+	Links          []TwoStageL3ClosRackLink
+}
+
+// systems returns the leaf switches, access switches and generic systems of
+// the rack.
+func (o *TwoStageL3ClosRackData) systems() []TwoStageL3ClosRackSystem {
+	var result []TwoStageL3ClosRackSystem
+	result = append(result, o.LeafSwitches...)
+	result = append(result, o.AccessSwitches...)
+	result = append(result, o.GenericSystems...)
+	return result
+}
+
+// rawTwoStageL3ClosRack is a rack node as returned by the nodes API, with the
+// rack type which was used to create (or last change) the rack.
+type rawTwoStageL3ClosRack struct {
+	Id           ObjectId        `json:"id"`
+	Label        string          `json:"label"`
+	RackTypeJson json.RawMessage `json:"rack_type_json"`
+}
+
+// rackType decodes the rack type embedded in the rack node. Depending on the
+// Apstra release, rack_type_json is either a JSON object or a string
+// containing one.
+func (o *rawTwoStageL3ClosRack) rackType() (*rawRackType, error) {
+	data := bytes.TrimSpace(o.RackTypeJson)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, fmt.Errorf("rack %q has no rack type", o.Id)
+	}
+
+	if data[0] == '"' {
+		var s string
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return nil, fmt.Errorf("failed unpacking rack type string of rack %q - %w", o.Id, err)
+		}
+		data = []byte(s)
+	}
+
+	var result rawRackType
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed unpacking rack type of rack %q - %w", o.Id, err)
+	}
+
+	return &result, nil
+}
+
+func (o *TwoStageL3ClosClient) getAllRackNodes(ctx context.Context) (map[ObjectId]rawTwoStageL3ClosRack, error) {
+	var response struct {
+		Nodes map[ObjectId]rawTwoStageL3ClosRack `json:"nodes"`
+	}
+
+	err := o.GetNodes(ctx, NodeTypeRack, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching rack nodes - %w", err)
+	}
+
+	return response.Nodes, nil
+}
+
+// getRackSystems returns the leaf, access and generic systems of each rack,
+// keyed by rack ID.
+func (o *TwoStageL3ClosClient) getRackSystems(ctx context.Context) (map[ObjectId][]TwoStageL3ClosRackSystem, error) {
+	query := new(MatchQuery).
+		SetBlueprintId(o.blueprintId).
+		SetBlueprintType(BlueprintTypeStaging).
+		SetClient(o.client).
+		Match(new(PathQuery).
+			Node([]QEEAttribute{
+				NodeTypeSystem.QEEAttribute(),
+				{"role", QEStringValIsIn{SystemRoleLeaf.String(), SystemRoleAccess.String(), SystemRoleGeneric.String()}},
+				{"name", QEStringVal("n_system")},
+			}).
+			Out([]QEEAttribute{RelationshipTypePartOfRack.QEEAttribute()}).
+			Node([]QEEAttribute{NodeTypeRack.QEEAttribute(), {"name", QEStringVal("n_rack")}})).
+		Optional(new(PathQuery).
+			Node([]QEEAttribute{{"name", QEStringVal("n_system")}}).
+			In([]QEEAttribute{RelationshipTypeComposedOfSystems.QEEAttribute()}).
+			Node([]QEEAttribute{NodeTypeRedundancyGroup.QEEAttribute(), {"name", QEStringVal("n_redundancy_group")}}))
+
+	var response struct {
+		Items []struct {
+			System struct {
+				Id         ObjectId `json:"id"`
+				Label      string   `json:"label"`
+				Hostname   string   `json:"hostname"`
+				Role       string   `json:"role"`
+				GroupLabel *string  `json:"group_label"`
+			} `json:"n_system"`
+			Rack struct {
+				Id ObjectId `json:"id"`
+			} `json:"n_rack"`
+			RedundancyGroup *struct {
+				Id ObjectId `json:"id"`
+			} `json:"n_redundancy_group"`
+		} `json:"items"`
+	}
+
+	err := query.Do(ctx, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying rack systems - %w", convertTtaeToAceWherePossible(err))
+	}
+
+	result := make(map[ObjectId][]TwoStageL3ClosRackSystem)
+	for _, item := range response.Items {
+		var role SystemRole
+		err = role.FromString(item.System.Role)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing role of system %q - %w", item.System.Id, err)
+		}
+
+		system := TwoStageL3ClosRackSystem{
+			Id:       item.System.Id,
+			Label:    item.System.Label,
+			Hostname: item.System.Hostname,
+			Role:     role,
+		}
+		if item.System.GroupLabel != nil {
+			system.GroupLabel = *item.System.GroupLabel
+		}
+		if item.RedundancyGroup != nil {
+			system.RedundancyGroupId = item.RedundancyGroup.Id
+		}
+
+		result[item.Rack.Id] = append(result[item.Rack.Id], system)
+	}
+
+	return result, nil
+}
+
+// getRackLinks returns the ethernet links of each rack, keyed by rack ID.
+func (o *TwoStageL3ClosClient) getRackLinks(ctx context.Context) (map[ObjectId][]TwoStageL3ClosRackLink, error) {
+	query := new(PathQuery).
+		SetBlueprintId(o.blueprintId).
+		SetBlueprintType(BlueprintTypeStaging).
+		SetClient(o.client).
+		Node([]QEEAttribute{NodeTypeRack.QEEAttribute(), {"name", QEStringVal("n_rack")}}).
+		In([]QEEAttribute{RelationshipTypePartOfRack.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeSystem.QEEAttribute(), {"name", QEStringVal("n_system")}}).
+		Out([]QEEAttribute{RelationshipTypeHostedInterfaces.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeInterface.QEEAttribute(), {"name", QEStringVal("n_interface")}}).
+		Out([]QEEAttribute{RelationshipTypeLink.QEEAttribute()}).
+		Node([]QEEAttribute{
+			NodeTypeLink.QEEAttribute(),
+			{"link_type", QEStringVal("ethernet")},
+			{"name", QEStringVal("n_link")},
+		}).
+		In([]QEEAttribute{RelationshipTypeLink.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeInterface.QEEAttribute(), {"name", QEStringVal("n_peer_interface")}}).
+		In([]QEEAttribute{RelationshipTypeHostedInterfaces.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeSystem.QEEAttribute(), {"name", QEStringVal("n_peer_system")}})
+
+	type node struct {
+		Id ObjectId `json:"id"`
+	}
+	var response struct {
+		Items []struct {
+			Rack      node `json:"n_rack"`
+			System    node `json:"n_system"`
+			Interface node `json:"n_interface"`
+			Link      struct {
+				Id   ObjectId `json:"id"`
+				Role string   `json:"role"`
+			} `json:"n_link"`
+			PeerInterface node `json:"n_peer_interface"`
+			PeerSystem    node `json:"n_peer_system"`
+		} `json:"items"`
+	}
+
+	err := query.Do(ctx, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying rack links - %w", convertTtaeToAceWherePossible(err))
+	}
+
+	// each link appears once per (local, peer) interface pair; links with
+	// both ends in the rack appear twice
+	result := make(map[ObjectId][]TwoStageL3ClosRackLink)
+	seen := make(map[ObjectId]bool)
+	for _, item := range response.Items {
+		if item.Interface.Id == item.PeerInterface.Id || seen[item.Link.Id] {
+			continue
+		}
+		seen[item.Link.Id] = true
+
+		result[item.Rack.Id] = append(result[item.Rack.Id], TwoStageL3ClosRackLink{
+			Id:   item.Link.Id,
+			Role: item.Link.Role,
+			Endpoints: []TwoStageL3ClosRackLinkEndpoint{
+				{SystemId: item.System.Id, InterfaceId: item.Interface.Id},
+				{SystemId: item.PeerSystem.Id, InterfaceId: item.PeerInterface.Id},
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// newTwoStageL3ClosRack assembles a TwoStageL3ClosRack from the rack node and
+// the rack's systems and links. Output is sorted by ID.
+func newTwoStageL3ClosRack(node *rawTwoStageL3ClosRack, systems []TwoStageL3ClosRackSystem, links []TwoStageL3ClosRackLink) (*TwoStageL3ClosRack, error) {
+	rawRackType, err := node.rackType()
+	if err != nil {
+		return nil, err
+	}
+
+	rackType, err := rawRackType.polish()
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing rack type of rack %q - %w", node.Id, err)
+	}
+
+	result := TwoStageL3ClosRack{
+		Id: node.Id,
+		Data: &TwoStageL3ClosRackData{
+			Label:    node.Label,
+			RackType: rackType,
+			Links:    links,
+		},
+	}
+
+	sort.Slice(systems, func(i, j int) bool { return systems[i].Id < systems[j].Id })
+	for _, system := range systems {
+		switch system.Role {
+		case SystemRoleLeaf:
+			result.Data.LeafSwitches = append(result.Data.LeafSwitches, system)
+		case SystemRoleAccess:
+			result.Data.AccessSwitches = append(result.Data.AccessSwitches, system)
+		case SystemRoleGeneric:
+			result.Data.GenericSystems = append(result.Data.GenericSystems, system)
+		}
+	}
+
+	sort.Slice(result.Data.Links, func(i, j int) bool { return result.Data.Links[i].Id < result.Data.Links[j].Id })
+
+	return &result, nil
+}
+
+// GetAllRacks returns every rack in the blueprint with its systems, links and
+// rack type.
+func (o *TwoStageL3ClosClient) GetAllRacks(ctx context.Context) ([]TwoStageL3ClosRack, error) {
+	nodes, err := o.getAllRackNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	systems, err := o.getRackSystems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := o.getRackLinks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TwoStageL3ClosRack, 0, len(nodes))
+	for _, id := range sortedKeys(nodes) {
+		node := nodes[id]
+		rack, err := newTwoStageL3ClosRack(&node, systems[id], links[id])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *rack)
+	}
+
+	return result, nil
+}
+
+// GetRack returns the rack with the given ID with its systems, links and rack
+// type. If the rack does not exist, a ClientErr with type ErrNotfound is
+// returned.
+func (o *TwoStageL3ClosClient) GetRack(ctx context.Context, id ObjectId) (*TwoStageL3ClosRack, error) {
+	rack, _, err := o.rackAndNode(ctx, id)
+	return rack, err
+}
+
+// RackChangeCtAssignment is a connectivity template assignment which would be
+// invalidated by a rack change.
+type RackChangeCtAssignment struct {
+	ApplicationPointId    ObjectId // switch interface
+	SystemId              ObjectId // system at risk
+	ConnectivityTemplates []ObjectId
+}
+
+// RackChangeVnBinding is a virtual network binding which would be
+// invalidated by a rack change. SystemId is the bound leaf switch or
+// redundancy group; AccessSwitchIds lists the access switches at risk.
+type RackChangeVnBinding struct {
+	VirtualNetworkId ObjectId
+	SystemId         ObjectId
+	AccessSwitchIds  []ObjectId
+}
+
+// RackChangeImpact describes the systems which may be removed or rebuilt by a
+// rack change, and the configuration which depends on them.
+type RackChangeImpact struct {
+	RackId        ObjectId
+	Systems       []ObjectId
+	CtAssignments []RackChangeCtAssignment
+	VnBindings    []RackChangeVnBinding
+}
+
+// Empty returns true when the rack change invalidates no CT assignments or VN
+// bindings.
+func (o *RackChangeImpact) Empty() bool {
+	return len(o.CtAssignments) == 0 && len(o.VnBindings) == 0
+}
+
+func (o *RackChangeImpact) String() string {
+	return fmt.Sprintf("changing rack %q affects %d systems, %d connectivity template assignments and %d virtual network bindings",
+		o.RackId, len(o.Systems), len(o.CtAssignments), len(o.VnBindings))
+}
+
+// rackTypeChange is the labels of the rack type elements which differ between
+// two rack types, keyed by the role of the systems they produce.
+type rackTypeChange map[SystemRole]map[string]bool
+
+// affects returns true when the system may be removed or rebuilt by the
+// change. Systems without a group label are affected by any change to
+// elements with their role.
+func (o rackTypeChange) affects(system TwoStageL3ClosRackSystem) bool {
+	labels := o[system.Role]
+	if len(labels) == 0 {
+		return false
+	}
+	return system.GroupLabel == "" || labels[system.GroupLabel]
+}
+
+// rackTypeChangedGroups compares the leaf, access and generic system elements
+// of two rack types and returns the labels of elements which were added,
+// removed or modified. Systems produced by those elements may be removed or
+// rebuilt when the rack type is changed.
+func rackTypeChangedGroups(before, after *rawRackType) (rackTypeChange, error) {
+	type labeled struct {
+		label   string
+		element any
+	}
+
+	elements := func(rt *rawRackType) map[SystemRole][]labeled {
+		result := make(map[SystemRole][]labeled)
+		for _, e := range rt.LeafSwitches {
+			result[SystemRoleLeaf] = append(result[SystemRoleLeaf], labeled{e.Label, e})
+		}
+		for _, e := range rt.AccessSwitches {
+			result[SystemRoleAccess] = append(result[SystemRoleAccess], labeled{e.Label, e})
+		}
+		for _, e := range rt.GenericSystems {
+			result[SystemRoleGeneric] = append(result[SystemRoleGeneric], labeled{e.Label, e})
+		}
+		return result
+	}
+
+	encode := func(role SystemRole, in []labeled) (map[string][]byte, error) {
+		result := make(map[string][]byte, len(in))
+		for _, e := range in {
+			data, err := json.Marshal(e.element)
+			if err != nil {
+				return nil, fmt.Errorf("failed marshaling %s element %q - %w", role, e.label, err)
+			}
+			result[e.label] = data
+		}
+		return result, nil
+	}
+
+	beforeElements, afterElements := elements(before), elements(after)
+
+	result := make(rackTypeChange)
+	for _, role := range []SystemRole{SystemRoleLeaf, SystemRoleAccess, SystemRoleGeneric} {
+		b, err := encode(role, beforeElements[role])
+		if err != nil {
+			return nil, err
+		}
+		a, err := encode(role, afterElements[role])
+		if err != nil {
+			return nil, err
+		}
+
+		changed := make(map[string]bool)
+		for label, data := range b {
+			if !bytes.Equal(data, a[label]) {
+				changed[label] = true
+			}
+		}
+		for label := range a {
+			if _, ok := b[label]; !ok {
+				changed[label] = true
+			}
+		}
+		if len(changed) > 0 {
+			result[role] = changed
+		}
+	}
+
+	return result, nil
+}
+
+// rackChangeImpact finds the CT assignments and VN bindings which depend on
+// the rack's systems affected by the change. CT assignments on switch ports
+// facing a system at risk are included.
+func rackChangeImpact(rack *TwoStageL3ClosRack, change rackTypeChange, ctsByInterface map[ObjectId][]ObjectId, vns map[ObjectId]VirtualNetwork) *RackChangeImpact {
+	result := RackChangeImpact{RackId: rack.Id}
+
+	atRisk := make(map[ObjectId]bool)
+	for _, system := range rack.Data.systems() {
+		if !change.affects(system) {
+			continue
+		}
+		atRisk[system.Id] = true
+		if system.RedundancyGroupId != "" {
+			atRisk[system.RedundancyGroupId] = true
+		}
+		result.Systems = append(result.Systems, system.Id)
+	}
+
+	// interfaces on, or facing, systems at risk
+	interfaceSystems := make(map[ObjectId]ObjectId)
+	for _, link := range rack.Data.Links {
+		for _, endpoint := range link.Endpoints {
+			if atRisk[endpoint.SystemId] {
+				for _, ep := range link.Endpoints {
+					interfaceSystems[ep.InterfaceId] = endpoint.SystemId
+				}
+			}
+		}
+	}
+
+	for _, interfaceId := range sortedKeys(interfaceSystems) {
+		if cts := ctsByInterface[interfaceId]; len(cts) > 0 {
+			result.CtAssignments = append(result.CtAssignments, RackChangeCtAssignment{
+				ApplicationPointId:    interfaceId,
+				SystemId:              interfaceSystems[interfaceId],
+				ConnectivityTemplates: cts,
+			})
+		}
+	}
+
+	for _, vnId := range sortedKeys(vns) {
+		vn := vns[vnId]
+		if vn.Data == nil {
+			continue
+		}
+		for _, binding := range vn.Data.VnBindings {
+			var accessIds []ObjectId
+			for _, accessId := range binding.AccessSwitchNodeIds {
+				if atRisk[accessId] {
+					accessIds = append(accessIds, accessId)
+				}
+			}
+			if atRisk[binding.SystemId] || len(accessIds) > 0 {
+				result.VnBindings = append(result.VnBindings, RackChangeVnBinding{
+					VirtualNetworkId: vnId,
+					SystemId:         binding.SystemId,
+					AccessSwitchIds:  accessIds,
+				})
+			}
+		}
+	}
+
+	return &result
+}
+
+// preflightRackChange returns the impact of replacing the rack type embedded
+// in the rack with rackType.
+func (o *TwoStageL3ClosClient) preflightRackChange(ctx context.Context, rack *TwoStageL3ClosRack, node *rawTwoStageL3ClosRack, rackType *rawRackType) (*RackChangeImpact, error) {
+	current, err := node.rackType()
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := rackTypeChangedGroups(current, rackType)
+	if err != nil {
+		return nil, err
+	}
+
+	cts, err := o.GetAllInterfacesConnectivityTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching connectivity template assignments - %w", err)
+	}
+
+	vns, err := o.GetAllVirtualNetworks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching virtual networks - %w", err)
+	}
+
+	return rackChangeImpact(rack, change, cts, vns), nil
+}
+
+// rackAndNode fetches the rack and its raw node.
+func (o *TwoStageL3ClosClient) rackAndNode(ctx context.Context, id ObjectId) (*TwoStageL3ClosRack, *rawTwoStageL3ClosRack, error) {
+	nodes, err := o.getAllRackNodes(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	node, ok := nodes[id]
+	if !ok {
+		return nil, nil, ClientErr{
+			errType: ErrNotfound,
+			err:     fmt.Errorf("rack %q not found in blueprint %q", id, o.blueprintId),
+		}
+	}
+
+	systems, err := o.getRackSystems(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	links, err := o.getRackLinks(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rack, err := newTwoStageL3ClosRack(&node, systems[id], links[id])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rack, &node, nil
+}
+
+// changeRack replaces the rack type embedded in the rack after running the
+// preflight checks. Unless force is set, a ClientErr with type ErrInUse and
+// the *RackChangeImpact as its Detail() is returned when the change would
+// invalidate CT assignments or VN bindings.
+func (o *TwoStageL3ClosClient) changeRack(ctx context.Context, id ObjectId, modify func(*rawRackType) (*rawRackType, error), force bool) error {
+	rack, node, err := o.rackAndNode(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	current, err := node.rackType()
+	if err != nil {
+		return err
+	}
+
+	rackType, err := modify(current)
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		impact, err := o.preflightRackChange(ctx, rack, node, rackType)
+		if err != nil {
+			return err
+		}
+		if !impact.Empty() {
+			return ClientErr{
+				errType: ErrInUse,
+				err:     errors.New(impact.String()),
+				detail:  impact,
+			}
+		}
+	}
+
+	err = o.client.talkToApstra(ctx, &talkToApstraIn{
+		method: http.MethodPatch,
+		urlStr: fmt.Sprintf(apiUrlBlueprintRackById, o.blueprintId, id),
+		apiInput: &struct {
+			RackType *rawRackType `json:"rack_type"`
+		}{RackType: rackType},
+	})
+	if err != nil {
+		return fmt.Errorf("failed changing rack %q - %w", id, convertTtaeToAceWherePossible(err))
+	}
+
+	return nil
+}
+
+// PreflightRackTypeChange reports the CT assignments and VN bindings which
+// would be invalidated by changing the rack to the given design rack type.
+// Leaf switches, access switches and generic systems are considered at risk
+// when the rack type element which produced them was changed or removed.
+func (o *TwoStageL3ClosClient) PreflightRackTypeChange(ctx context.Context, id ObjectId, rackTypeId ObjectId) (*RackChangeImpact, error) {
+	rack, node, err := o.rackAndNode(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rackType, err := o.client.getRackType(ctx, rackTypeId)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching rack type %q - %w", rackTypeId, err)
+	}
+
+	return o.preflightRackChange(ctx, rack, node, rackType)
+}
+
+// ChangeRackType changes the rack to the given design rack type in place. See
+// PreflightRackTypeChange. When the change would invalidate CT assignments or
+// VN bindings and force is not set, the rack is not changed and a ClientErr
+// with type ErrInUse and the *RackChangeImpact as its Detail() is returned.
+func (o *TwoStageL3ClosClient) ChangeRackType(ctx context.Context, id ObjectId, rackTypeId ObjectId, force bool) error {
+	return o.changeRack(ctx, id, func(_ *rawRackType) (*rawRackType, error) {
+		rackType, err := o.client.getRackType(ctx, rackTypeId)
+		if err != nil {
+			return nil, fmt.Errorf("failed fetching rack type %q - %w", rackTypeId, err)
+		}
+		return rackType, nil
+	}, force)
+}
+
+// AddRackGenericSystems adds generic system groups to the rack in place.
+// Existing systems are not affected, so no preflight checks are performed.
+func (o *TwoStageL3ClosClient) AddRackGenericSystems(ctx context.Context, id ObjectId, groups []RackElementGenericSystemRequest) error {
+	request, err := (&RackTypeRequest{GenericSystems: groups}).raw(ctx, o.client)
+	if err != nil {
+		return fmt.Errorf("failed preparing generic system groups - %w", err)
+	}
+
+	return o.changeRack(ctx, id, func(rackType *rawRackType) (*rawRackType, error) {
+		return addRackTypeGenericSystems(rackType, request)
+	}, true)
+}
+
+// RemoveRackGenericSystems removes the generic system groups with the given
+// labels from the rack in place. The generic systems in those groups are
+// considered at risk by the preflight checks; see ChangeRackType for the
+// meaning of force.
+func (o *TwoStageL3ClosClient) RemoveRackGenericSystems(ctx context.Context, id ObjectId, labels []string, force bool) error {
+	return o.changeRack(ctx, id, func(rackType *rawRackType) (*rawRackType, error) {
+		return removeRackTypeGenericSystems(rackType, labels)
+	}, force)
+}
+
+// addRackTypeGenericSystems returns a copy of rackType with the generic
+// systems, logical devices and tags of request added.
+func addRackTypeGenericSystems(rackType *rawRackType, request *rawRackTypeRequest) (*rawRackType, error) {
+	result := *rackType
+	result.GenericSystems = append([]rawRackElementGenericSystem{}, rackType.GenericSystems...)
+	result.LogicalDevices = append([]rawLogicalDevice{}, rackType.LogicalDevices...)
+	result.Tags = append([]DesignTagData{}, rackType.Tags...)
+
+	for _, gs := range request.GenericSystems {
+		for _, existing := range result.GenericSystems {
+			if existing.Label == gs.Label {
+				return nil, fmt.Errorf("rack type %q already has generic system group %q", rackType.Id, gs.Label)
+			}
+		}
+		result.GenericSystems = append(result.GenericSystems, gs)
+	}
+
+	for _, ld := range request.LogicalDevices {
+		if _, ok := result.logicalDeviceById(ld.Id); !ok {
+			result.LogicalDevices = append(result.LogicalDevices, ld)
+		}
+	}
+
+	for _, tag := range request.Tags {
+		if _, ok := result.tagByLabel(tag.Label); !ok {
+			result.Tags = append(result.Tags, tag)
+		}
+	}
+
+	return &result, nil
+}
+
+// removeRackTypeGenericSystems returns a copy of rackType without the generic
+// systems with the given labels.
+func removeRackTypeGenericSystems(rackType *rawRackType, labels []string) (*rawRackType, error) {
+	result := *rackType
+	result.GenericSystems = nil
+
+	removed := make(map[string]bool, len(labels))
+	for _, gs := range rackType.GenericSystems {
+		if itemInSlice(gs.Label, labels) {
+			removed[gs.Label] = true
+			continue
+		}
+		result.GenericSystems = append(result.GenericSystems, gs)
+	}
+
+	for _, label := range labels {
+		if !removed[label] {
+			return nil, ClientErr{
+				errType: ErrNotfound,
+				err:     fmt.Errorf("rack type %q has no generic system group %q", rackType.Id, label),
+			}
+		}
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRackLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bp := testBlueprintA(ctx, t, client.client)
+
+			log.Printf("testing GetAllRacks() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			racks, err := bp.GetAllRacks(ctx)
+			require.NoError(t, err)
+			require.NotEmpty(t, racks)
+
+			for _, rack := range racks {
+				log.Printf("testing GetRack() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+				r, err := bp.GetRack(ctx, rack.Id)
+				require.NoError(t, err)
+				require.Equal(t, rack, *r)
+				require.NotEmpty(t, r.Data.LeafSwitches)
+				require.NotNil(t, r.Data.RackType)
+			}
+
+			_, err = bp.GetRack(ctx, "bogus")
+			var ace ClientErr
+			require.True(t, errors.As(err, &ace) && ace.Type() == ErrNotfound)
+
+			rack := racks[0]
+			ldId := ObjectId("AOS-1x10-1")
+			label := "gs-" + randString(6, "hex")
+
+			log.Printf("testing AddRackGenericSystems() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			groups := []RackElementGenericSystemRequest{{
+				Count:           1,
+				Label:           label,
+				LogicalDeviceId: ldId,
+				AsnDomain:       FeatureSwitchDisabled,
+				ManagementLevel: SystemManagementLevelUnmanaged,
+				Loopback:        FeatureSwitchDisabled,
+				Links: []RackLinkRequest{{
+					Label:              "link",
+					LinkPerSwitchCount: 1,
+					LinkSpeed:          "10G",
+					TargetSwitchLabel:  rack.Data.RackType.Data.LeafSwitches[0].Label,
+					AttachmentType:     RackLinkAttachmentTypeSingle,
+					SwitchPeer:         RackLinkSwitchPeerFirst,
+				}},
+			}}
+			require.NoError(t, bp.AddRackGenericSystems(ctx, rack.Id, groups))
+
+			r, err := bp.GetRack(ctx, rack.Id)
+			require.NoError(t, err)
+			require.Len(t, r.Data.GenericSystems, len(rack.Data.GenericSystems)+1)
+
+			log.Printf("testing RemoveRackGenericSystems() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, bp.RemoveRackGenericSystems(ctx, rack.Id, []string{label}, false))
+
+			r, err = bp.GetRack(ctx, rack.Id)
+			require.NoError(t, err)
+			require.Len(t, r.Data.GenericSystems, len(rack.Data.GenericSystems))
+
+			log.Printf("testing PreflightRackTypeChange() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			impact, err := bp.PreflightRackTypeChange(ctx, rack.Id, rack.Data.RackType.Id)
+			require.NoError(t, err)
+			require.True(t, impact.Empty())
+			require.Empty(t, impact.Systems)
+
+			// add the generic systems again, then change the rack back to its
+			// design rack type, which removes them
+			require.NoError(t, bp.AddRackGenericSystems(ctx, rack.Id, groups))
+			r, err = bp.GetRack(ctx, rack.Id)
+			require.NoError(t, err)
+			var added []ObjectId
+			for _, gs := range r.Data.GenericSystems {
+				if gs.GroupLabel == label {
+					added = append(added, gs.Id)
+				}
+			}
+			require.Len(t, added, 1)
+
+			// only the systems of the removed group are at risk
+			impact, err = bp.PreflightRackTypeChange(ctx, rack.Id, rack.Data.RackType.Id)
+			require.NoError(t, err)
+			require.Equal(t, added, impact.Systems)
+
+			log.Printf("testing ChangeRackType() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, bp.ChangeRackType(ctx, rack.Id, rack.Data.RackType.Id, false))
+
+			r, err = bp.GetRack(ctx, rack.Id)
+			require.NoError(t, err)
+			require.Len(t, r.Data.GenericSystems, len(rack.Data.GenericSystems))
+			require.Equal(t, rack.Data.RackType.Id, r.Data.RackType.Id)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawTwoStageL3ClosRackRackType(t *testing.T) {
+	rackType := `{"id":"rt1","display_name":"rack type 1","fabric_connectivity_design":"l3clos","generic_systems":[{"label":"gs1","count":2}]}`
+
+	quoted, err := json.Marshal(rackType)
+	require.NoError(t, err)
+
+	for _, data := range []json.RawMessage{json.RawMessage(rackType), quoted} {
+		rt, err := (&rawTwoStageL3ClosRack{Id: "rack1", RackTypeJson: data}).rackType()
+		require.NoError(t, err)
+		require.Equal(t, ObjectId("rt1"), rt.Id)
+		require.Len(t, rt.GenericSystems, 1)
+		require.Equal(t, 2, rt.GenericSystems[0].Count)
+	}
+
+	_, err = (&rawTwoStageL3ClosRack{Id: "rack1", RackTypeJson: json.RawMessage("null")}).rackType()
+	require.Error(t, err)
+}
+
+func TestRackTypeChangedGroups(t *testing.T) {
+	before := &rawRackType{
+		Id:             "rt1",
+		LeafSwitches:   []rawRackElementLeafSwitch{{Label: "leaf"}},
+		GenericSystems: []rawRackElementGenericSystem{{Label: "gs1", Count: 2}, {Label: "gs2", Count: 1}, {Label: "gs3", Count: 1}},
+	}
+
+	after := *before
+	after.Id = "rt2"
+	change, err := rackTypeChangedGroups(before, &after)
+	require.NoError(t, err)
+	require.Empty(t, change) // only elements are compared
+
+	// gs1 modified, gs2 unchanged, gs3 removed, access added
+	after.GenericSystems = []rawRackElementGenericSystem{{Label: "gs1", Count: 3}, {Label: "gs2", Count: 1}}
+	after.AccessSwitches = []rawRackElementAccessSwitch{{Label: "access"}}
+	change, err = rackTypeChangedGroups(before, &after)
+	require.NoError(t, err)
+	require.Equal(t, rackTypeChange{
+		SystemRoleAccess:  {"access": true},
+		SystemRoleGeneric: {"gs1": true, "gs3": true},
+	}, change)
+
+	require.True(t, change.affects(TwoStageL3ClosRackSystem{Role: SystemRoleGeneric, GroupLabel: "gs3"}))
+	require.False(t, change.affects(TwoStageL3ClosRackSystem{Role: SystemRoleGeneric, GroupLabel: "gs2"}))
+	require.False(t, change.affects(TwoStageL3ClosRackSystem{Role: SystemRoleLeaf, GroupLabel: "leaf"}))
+	require.True(t, change.affects(TwoStageL3ClosRackSystem{Role: SystemRoleGeneric})) // unknown group
+}
+
+func TestRackChangeImpact(t *testing.T) {
+	rack := &TwoStageL3ClosRack{
+		Id: "rack1",
+		Data: &TwoStageL3ClosRackData{
+			LeafSwitches: []TwoStageL3ClosRackSystem{
+				{Id: "leaf1", Role: SystemRoleLeaf, RedundancyGroupId: "rg1"},
+				{Id: "leaf2", Role: SystemRoleLeaf, RedundancyGroupId: "rg1"},
+			},
+			AccessSwitches: []TwoStageL3ClosRackSystem{{Id: "access1", Role: SystemRoleAccess}},
+			GenericSystems: []TwoStageL3ClosRackSystem{
+				{Id: "gs1", Role: SystemRoleGeneric, GroupLabel: "group1"},
+				{Id: "gs2", Role: SystemRoleGeneric, GroupLabel: "group2"},
+			},
+			Links: []TwoStageL3ClosRackLink{
+				{Id: "l1", Endpoints: []TwoStageL3ClosRackLinkEndpoint{{"leaf1", "leaf1_if1"}, {"gs1", "gs1_if1"}}},
+				{Id: "l4", Endpoints: []TwoStageL3ClosRackLinkEndpoint{{"leaf2", "leaf2_if1"}, {"gs2", "gs2_if1"}}},
+				{Id: "l2", Endpoints: []TwoStageL3ClosRackLinkEndpoint{{"leaf1", "leaf1_if2"}, {"access1", "access1_if1"}}},
+				{Id: "l3", Endpoints: []TwoStageL3ClosRackLinkEndpoint{{"leaf1", "leaf1_if3"}, {"spine1", "spine1_if1"}}},
+			},
+		},
+	}
+
+	cts := map[ObjectId][]ObjectId{
+		"leaf1_if1": {"ct1"},
+		"leaf1_if3": {"ct2"},
+		"leaf2_if1": {"ct3"},
+	}
+
+	vns := map[ObjectId]VirtualNetwork{
+		"vn1": {Data: &VirtualNetworkData{VnBindings: []VnBinding{{SystemId: "rg1", AccessSwitchNodeIds: []ObjectId{"access1"}}}}},
+		"vn2": {Data: &VirtualNetworkData{VnBindings: []VnBinding{{SystemId: "leaf3"}}}},
+	}
+
+	// changing generic system group1 affects the CT on the leaf port facing
+	// gs1, but not the one facing gs2 in group2
+	impact := rackChangeImpact(rack, rackTypeChange{SystemRoleGeneric: {"group1": true}}, cts, vns)
+	require.Equal(t, []ObjectId{"gs1"}, impact.Systems)
+	require.Equal(t, []RackChangeCtAssignment{{ApplicationPointId: "leaf1_if1", SystemId: "gs1", ConnectivityTemplates: []ObjectId{"ct1"}}}, impact.CtAssignments)
+	require.Empty(t, impact.VnBindings)
+	require.False(t, impact.Empty())
+
+	// changing access switches affects the VN binding, but no CTs
+	impact = rackChangeImpact(rack, rackTypeChange{SystemRoleAccess: {"access": true}}, cts, vns)
+	require.Empty(t, impact.CtAssignments)
+	require.Equal(t, []RackChangeVnBinding{{VirtualNetworkId: "vn1", SystemId: "rg1", AccessSwitchIds: []ObjectId{"access1"}}}, impact.VnBindings)
+
+	// changing leafs affects everything attached to them, via the redundancy group
+	impact = rackChangeImpact(rack, rackTypeChange{SystemRoleLeaf: {"leaf": true}}, cts, vns)
+	require.Equal(t, []ObjectId{"leaf1", "leaf2"}, impact.Systems)
+	require.Len(t, impact.CtAssignments, 3)
+	require.Equal(t, []RackChangeVnBinding{{VirtualNetworkId: "vn1", SystemId: "rg1"}}, impact.VnBindings)
+
+	require.True(t, rackChangeImpact(rack, nil, cts, vns).Empty())
+}
+
+func TestRackTypeGenericSystems(t *testing.T) {
+	rackType := &rawRackType{
+		Id:             "rt1",
+		LogicalDevices: []rawLogicalDevice{{Id: "ld1"}},
+		GenericSystems: []rawRackElementGenericSystem{{Label: "gs1", LogicalDevice: "ld1"}},
+	}
+
+	added, err := addRackTypeGenericSystems(rackType, &rawRackTypeRequest{
+		GenericSystems: []rawRackElementGenericSystem{{Label: "gs2", LogicalDevice: "ld2", Tags: []string{"red"}}},
+		LogicalDevices: []rawLogicalDevice{{Id: "ld1"}, {Id: "ld2"}},
+		Tags:           []DesignTagData{{Label: "red"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, added.GenericSystems, 2)
+	require.Len(t, added.LogicalDevices, 2)
+	require.Len(t, added.Tags, 1)
+	require.Len(t, rackType.GenericSystems, 1) // the original is not modified
+
+	_, err = addRackTypeGenericSystems(rackType, &rawRackTypeRequest{
+		GenericSystems: []rawRackElementGenericSystem{{Label: "gs1"}},
+	})
+	require.Error(t, err)
+
+	removed, err := removeRackTypeGenericSystems(added, []string{"gs1"})
+	require.NoError(t, err)
+	require.Len(t, removed.GenericSystems, 1)
+	require.Equal(t, "gs2", removed.GenericSystems[0].Label)
+	require.Len(t, added.GenericSystems, 2)
+
+	_, err = removeRackTypeGenericSystems(added, []string{"gs3"})
+	var ace ClientErr
+	require.ErrorAs(t, err, &ace)
+	require.Equal(t, ErrNotfound, ace.Type())
+}
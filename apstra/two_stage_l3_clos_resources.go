@@ -119,7 +119,7 @@ func (o ResourceGroupName) Int() int {
 }
 
 func (o *ResourceGroupName) FromString(in string) error {
-	i, err := resourceGroupName(in).parse()
+	i, err := parseKnown(resourceGroupName(in))
 	if err != nil {
 		return err
 	}
@@ -278,6 +278,9 @@ func (o ResourceGroupName) raw() resourceGroupName {
 	case ResourceGroupNameToGenericLinkIpv6:
 		return resourceGroupNameToGenericLinkIpv6
 	default:
+		if s, ok := lenientEnumString[resourceGroupName](int(o)); ok {
+			return resourceGroupName(s)
+		}
 		return resourceGroupName(fmt.Sprintf(resourceGroupNameUnknown, o))
 	}
 }
@@ -351,6 +354,9 @@ func (o resourceGroupName) parse() (int, error) {
 	case resourceGroupNameToGenericLinkIpv6:
 		return int(ResourceGroupNameToGenericLinkIpv6), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(ResourceGroupNameUnknown, o)
 	}
 }
@@ -382,12 +388,15 @@ func (o ResourceType) raw() resourceType {
 	case ResourceTypeVniPool:
 		return resourceTypeVniPool
 	default:
+		if s, ok := lenientEnumString[resourceType](int(o)); ok {
+			return resourceType(s)
+		}
 		return resourceType(fmt.Sprintf(resourceTypeUnknown, o))
 	}
 }
 
 func (o *ResourceType) FromString(in string) error {
-	i, err := resourceType(in).parse()
+	i, err := parseKnown(resourceType(in))
 	if err != nil {
 		return err
 	}
@@ -430,6 +439,9 @@ func (o resourceType) parse() (int, error) {
 	case resourceTypeVniPool:
 		return int(ResourceTypeVniPool), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return int(ResourceTypeUnknown), fmt.Errorf("unknown resource type '%s'", o)
 	}
 }
@@ -6,6 +6,7 @@ package apstra
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -47,12 +48,15 @@ func (o DcRoutingPolicyType) String() string {
 	case DcRoutingPolicyTypeUser:
 		return string(dcRoutingPolicyTypeUser)
 	default:
+		if s, ok := lenientEnumString[dcRoutingPolicyType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(dcRoutingPolicyTypeUnknown, o)
 	}
 }
 
 func (o *DcRoutingPolicyType) FromString(in string) error {
-	i, err := dcRoutingPolicyType(in).parse()
+	i, err := parseKnown(dcRoutingPolicyType(in))
 	if err != nil {
 		return err
 	}
@@ -77,6 +81,9 @@ func (o dcRoutingPolicyType) parse() (int, error) {
 	case dcRoutingPolicyTypeUser:
 		return int(DcRoutingPolicyTypeUser), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(DcRoutingPolicyTypeUnknown, o)
 	}
 }
@@ -111,12 +118,15 @@ func (o PrefixFilterAction) String() string {
 	case PrefixFilterActionDeny:
 		return string(prefixFilterActionDeny)
 	default:
+		if s, ok := lenientEnumString[prefixFilterAction](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(prefixFilterActionUnknown, o)
 	}
 }
 
 func (o *PrefixFilterAction) FromString(in string) error {
-	i, err := prefixFilterAction(in).parse()
+	i, err := parseKnown(prefixFilterAction(in))
 	if err != nil {
 		return err
 	}
@@ -141,6 +151,9 @@ func (o prefixFilterAction) parse() (int, error) {
 	case prefixFilterActionDeny:
 		return int(PrefixFilterActionDeny), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(PrefixFilterActionUnknown, o)
 	}
 }
@@ -195,12 +208,15 @@ func (o DcRoutingPolicyImportPolicy) String() string {
 	case DcRoutingPolicyImportPolicyExtraOnly:
 		return string(dcRoutingPolicyImportPolicyExtraOnly)
 	default:
+		if s, ok := lenientEnumString[dcRoutingPolicyImportPolicy](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(dcRoutingPolicyImportPolicyUnknown, o)
 	}
 }
 
 func (o *DcRoutingPolicyImportPolicy) FromString(in string) error {
-	i, err := dcRoutingPolicyImportPolicy(in).parse()
+	i, err := parseKnown(dcRoutingPolicyImportPolicy(in))
 	if err != nil {
 		return err
 	}
@@ -227,6 +243,9 @@ func (o dcRoutingPolicyImportPolicy) parse() (int, error) {
 	case dcRoutingPolicyImportPolicyExtraOnly:
 		return int(DcRoutingPolicyImportPolicyExtraOnly), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(DcRoutingPolicyImportPolicyUnknown, o)
 	}
 }
@@ -320,18 +339,57 @@ type DcRoutingExportPolicy struct {
 	L2EdgeSubnets        bool `json:"l2edge_subnets"`
 }
 
+// rawDcRoutingExportPolicy omits L3EdgeServerLinks when it is nil, because
+// Apstra 5.0 and later don't have the l3edge_server_links export policy.
+type rawDcRoutingExportPolicy struct {
+	StaticRoutes         bool  `json:"static_routes"`
+	Loopbacks            bool  `json:"loopbacks"`
+	SpineSuperspineLinks bool  `json:"spine_superspine_links"`
+	L3EdgeServerLinks    *bool `json:"l3edge_server_links,omitempty"`
+	SpineLeafLinks       bool  `json:"spine_leaf_links"`
+	L2EdgeSubnets        bool  `json:"l2edge_subnets"`
+}
+
+func (o rawDcRoutingExportPolicy) polish() DcRoutingExportPolicy {
+	return DcRoutingExportPolicy{
+		StaticRoutes:         o.StaticRoutes,
+		Loopbacks:            o.Loopbacks,
+		SpineSuperspineLinks: o.SpineSuperspineLinks,
+		L3EdgeServerLinks:    o.L3EdgeServerLinks != nil && *o.L3EdgeServerLinks,
+		SpineLeafLinks:       o.SpineLeafLinks,
+		L2EdgeSubnets:        o.L2EdgeSubnets,
+	}
+}
+
 type rawDcRoutingPolicy struct {
 	Id                     ObjectId                    `json:"id,omitempty"`
 	Label                  string                      `json:"label"`
 	Description            string                      `json:"description"`
 	PolicyType             dcRoutingPolicyType         `json:"policy_type"`
 	ImportPolicy           dcRoutingPolicyImportPolicy `json:"import_policy"`
-	ExportPolicy           DcRoutingExportPolicy       `json:"export_policy"`
+	ExportPolicy           rawDcRoutingExportPolicy    `json:"export_policy"`
 	ExpectDefaultIpv4Route bool                        `json:"expect_default_ipv4_route"`
 	ExpectDefaultIpv6Route bool                        `json:"expect_default_ipv6_route"`
 	AggregatePrefixes      []string                    `json:"aggregate_prefixes"`
 	ExtraImportRoutes      []rawPrefixFilter           `json:"extra_import_routes"`
 	ExtraExportRoutes      []rawPrefixFilter           `json:"extra_export_routes"`
+	unknownFields          unknownJsonFields
+}
+
+func (o *rawDcRoutingPolicy) UnmarshalJSON(b []byte) error {
+	type alias rawDcRoutingPolicy
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{})
+	return err
+}
+
+func (o rawDcRoutingPolicy) MarshalJSON() ([]byte, error) {
+	type alias rawDcRoutingPolicy
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 func (o rawDcRoutingPolicy) polish() (*DcRoutingPolicy, error) {
@@ -382,12 +440,13 @@ func (o rawDcRoutingPolicy) polish() (*DcRoutingPolicy, error) {
 			Description:            o.Description,
 			PolicyType:             DcRoutingPolicyType(policyType),
 			ImportPolicy:           DcRoutingPolicyImportPolicy(importPolicy),
-			ExportPolicy:           o.ExportPolicy,
+			ExportPolicy:           o.ExportPolicy.polish(),
 			ExpectDefaultIpv4Route: o.ExpectDefaultIpv4Route,
 			ExpectDefaultIpv6Route: o.ExpectDefaultIpv6Route,
 			AggregatePrefixes:      aggregatePrefixes,
 			ExtraImportRoutes:      extraImportRoutes,
 			ExtraExportRoutes:      extraExportRoutes,
+			unknownFields:          o.unknownFields,
 		},
 	}, nil
 }
@@ -408,6 +467,8 @@ type DcRoutingPolicyData struct {
 	AggregatePrefixes      []net.IPNet                 `json:"aggregate_prefixes"`
 	ExtraImportRoutes      []PrefixFilter              `json:"extra_import_routes"`
 	ExtraExportRoutes      []PrefixFilter              `json:"extra_export_routes"`
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 func (o *DcRoutingPolicyData) raw() *rawDcRoutingPolicy {
@@ -433,11 +494,11 @@ func (o *DcRoutingPolicyData) raw() *rawDcRoutingPolicy {
 		Description:  o.Description,
 		PolicyType:   o.PolicyType.raw(),
 		ImportPolicy: o.ImportPolicy.raw(),
-		ExportPolicy: DcRoutingExportPolicy{
+		ExportPolicy: rawDcRoutingExportPolicy{
 			StaticRoutes:         o.ExportPolicy.StaticRoutes,
 			Loopbacks:            o.ExportPolicy.Loopbacks,
 			SpineSuperspineLinks: o.ExportPolicy.SpineSuperspineLinks,
-			L3EdgeServerLinks:    o.ExportPolicy.L3EdgeServerLinks,
+			L3EdgeServerLinks:    toPtr(o.ExportPolicy.L3EdgeServerLinks),
 			SpineLeafLinks:       o.ExportPolicy.SpineLeafLinks,
 			L2EdgeSubnets:        o.ExportPolicy.L2EdgeSubnets,
 		},
@@ -449,6 +510,22 @@ func (o *DcRoutingPolicyData) raw() *rawDcRoutingPolicy {
 	}
 }
 
+// checkRoutingPolicyExportPolicy ensures that the export policy in raw is
+// compatible with the Apstra server's version. L3EdgeServerLinks isn't sent
+// to servers which don't support it, and may only be set where supported.
+func (o *TwoStageL3ClosClient) checkRoutingPolicyExportPolicy(raw *rawDcRoutingPolicy) error {
+	if o.client.Supports("DcRoutingExportPolicy.L3EdgeServerLinks") {
+		return nil
+	}
+
+	if raw.ExportPolicy.L3EdgeServerLinks != nil && *raw.ExportPolicy.L3EdgeServerLinks {
+		return o.client.checkSupports("DcRoutingExportPolicy.L3EdgeServerLinks")
+	}
+
+	raw.ExportPolicy.L3EdgeServerLinks = nil
+	return nil
+}
+
 func (o *TwoStageL3ClosClient) getAllRoutingPolicies(ctx context.Context) ([]rawDcRoutingPolicy, error) {
 	response := &struct {
 		Items []rawDcRoutingPolicy `json:"items"`
@@ -15,7 +15,6 @@ import (
 	"net"
 	"testing"
 
-	"github.com/Juniper/apstra-go-sdk/apstra/compatibility"
 	"github.com/stretchr/testify/require"
 )
 
@@ -340,7 +339,7 @@ func TestRoutingPolicies(t *testing.T) {
 				StaticRoutes:         true,
 				Loopbacks:            true,
 				SpineSuperspineLinks: true,
-				L3EdgeServerLinks:    compatibility.RoutingPolicyExportHasL3EdgeLinks.Check(bpClient.client.apiVersion),
+				L3EdgeServerLinks:    bpClient.client.Supports("DcRoutingExportPolicy.L3EdgeServerLinks"),
 				SpineLeafLinks:       true,
 				L2EdgeSubnets:        true,
 			}
@@ -5,7 +5,11 @@
 package apstra
 
 import (
+	"encoding/json"
 	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDcRoutingPoliciesStrings(t *testing.T) {
@@ -81,3 +85,28 @@ func TestAllPrefixFilterActions(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckRoutingPolicyExportPolicy(t *testing.T) {
+	bpClient := &TwoStageL3ClosClient{client: &Client{apiVersion: version.Must(version.NewVersion("4.2.0"))}}
+
+	raw := (&DcRoutingPolicyData{ExportPolicy: DcRoutingExportPolicy{L3EdgeServerLinks: true}}).raw()
+	require.NoError(t, bpClient.checkRoutingPolicyExportPolicy(raw))
+	require.NotNil(t, raw.ExportPolicy.L3EdgeServerLinks)
+	require.True(t, *raw.ExportPolicy.L3EdgeServerLinks)
+
+	// 5.0.0 and later don't know about l3edge_server_links
+	bpClient.client.apiVersion = version.Must(version.NewVersion("5.0.0"))
+
+	raw = (&DcRoutingPolicyData{ExportPolicy: DcRoutingExportPolicy{Loopbacks: true}}).raw()
+	require.NoError(t, bpClient.checkRoutingPolicyExportPolicy(raw))
+	require.Nil(t, raw.ExportPolicy.L3EdgeServerLinks)
+	payload, err := json.Marshal(raw.ExportPolicy)
+	require.NoError(t, err)
+	require.NotContains(t, string(payload), "l3edge_server_links")
+
+	raw = (&DcRoutingPolicyData{ExportPolicy: DcRoutingExportPolicy{L3EdgeServerLinks: true}}).raw()
+	err = bpClient.checkRoutingPolicyExportPolicy(raw)
+	var ace ClientErr
+	require.ErrorAs(t, err, &ace)
+	require.Equal(t, ErrCompatibility, ace.Type())
+}
@@ -0,0 +1,304 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"fmt"
+	"net"
+)
+
+type RouteOrigin int
+
+const (
+	RouteOriginExternal            = RouteOrigin(iota) // learned from an external router
+	RouteOriginLoopback                                // switch loopback
+	RouteOriginSpineLeafLink                           // spine-leaf link subnet
+	RouteOriginSpineSuperspineLink                     // spine-superspine link subnet
+	RouteOriginL3EdgeServerLink                        // L3 edge server link subnet
+	RouteOriginL2EdgeSubnet                            // L2 edge (virtual network) subnet
+	RouteOriginStaticRoute                             // static route
+	RouteOriginAggregate                               // one of the policy's AggregatePrefixes
+)
+
+func (o RouteOrigin) String() string {
+	switch o {
+	case RouteOriginExternal:
+		return "external"
+	case RouteOriginLoopback:
+		return "loopback"
+	case RouteOriginSpineLeafLink:
+		return "spine-leaf link"
+	case RouteOriginSpineSuperspineLink:
+		return "spine-superspine link"
+	case RouteOriginL3EdgeServerLink:
+		return "L3 edge server link"
+	case RouteOriginL2EdgeSubnet:
+		return "L2 edge subnet"
+	case RouteOriginStaticRoute:
+		return "static route"
+	case RouteOriginAggregate:
+		return "aggregate"
+	default:
+		return fmt.Sprintf("unknown route origin %d", o)
+	}
+}
+
+// RoutingPolicyRoute is a candidate prefix for SimulateExport or SimulateImport.
+type RoutingPolicyRoute struct {
+	Prefix net.IPNet
+	Origin RouteOrigin
+}
+
+// RoutingPolicyDecision is the fate of a single RoutingPolicyRoute. Filter is
+// the index (into ExtraExportRoutes or ExtraImportRoutes) of the prefix filter
+// which decided the route, or -1 when the decision was made by the import or
+// export policy. Aggregates lists the aggregate prefixes to which an exported
+// route contributes.
+type RoutingPolicyDecision struct {
+	Route      RoutingPolicyRoute
+	Accepted   bool
+	Filter     int
+	Reason     string
+	Aggregates []net.IPNet
+}
+
+func (o RoutingPolicyDecision) String() string {
+	verdict := "rejected"
+	if o.Accepted {
+		verdict = "accepted"
+	}
+	return fmt.Sprintf("%s %s %s: %s", o.Route.Origin, o.Route.Prefix.String(), verdict, o.Reason)
+}
+
+// lengths returns the range of prefix lengths matched by the filter. With
+// neither mask set, only the filter's own prefix length matches. GeMask alone
+// extends the range to the host route length; LeMask alone extends it from
+// the filter's own prefix length.
+func (o *PrefixFilter) lengths() (int, int) {
+	ones, bits := o.Prefix.Mask.Size()
+	lo, hi := ones, ones
+	if o.GeMask != nil {
+		lo, hi = *o.GeMask, bits
+	}
+	if o.LeMask != nil {
+		hi = *o.LeMask
+	}
+	return lo, hi
+}
+
+// Matches returns true when prefix falls within the filter's prefix and its
+// length is within the range permitted by the filter's GeMask and LeMask.
+func (o *PrefixFilter) Matches(prefix net.IPNet) bool {
+	ones, bits := o.Prefix.Mask.Size()
+	pOnes, pBits := prefix.Mask.Size()
+	if bits == 0 || bits != pBits || pOnes < ones || !o.Prefix.Contains(prefix.IP) {
+		return false
+	}
+	lo, hi := o.lengths()
+	return pOnes >= lo && pOnes <= hi
+}
+
+// permits returns false for deny filters. Filters with no action are treated
+// as permit filters, matching the API default.
+func (o *PrefixFilter) permits() bool {
+	return o.Action != PrefixFilterActionDeny
+}
+
+func (o *PrefixFilter) String() string {
+	result := fmt.Sprintf("%s %s", o.Action, o.Prefix.String())
+	if o.GeMask != nil {
+		result += fmt.Sprintf(" ge %d", *o.GeMask)
+	}
+	if o.LeMask != nil {
+		result += fmt.Sprintf(" le %d", *o.LeMask)
+	}
+	return result
+}
+
+// firstMatchingPrefixFilter returns the index of the first filter matching
+// prefix, or -1.
+func firstMatchingPrefixFilter(filters []PrefixFilter, prefix net.IPNet) int {
+	for i := range filters {
+		if filters[i].Matches(prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+func prefixFilterDecision(route RoutingPolicyRoute, filters []PrefixFilter, i int, list string) RoutingPolicyDecision {
+	return RoutingPolicyDecision{
+		Route:    route,
+		Accepted: filters[i].permits(),
+		Filter:   i,
+		Reason:   fmt.Sprintf("%s[%d] (%s)", list, i, filters[i].String()),
+	}
+}
+
+// exportsOrigin indicates whether the export policy advertises routes of the
+// given origin.
+func (o *DcRoutingExportPolicy) exportsOrigin(origin RouteOrigin) bool {
+	switch origin {
+	case RouteOriginLoopback:
+		return o.Loopbacks
+	case RouteOriginSpineLeafLink:
+		return o.SpineLeafLinks
+	case RouteOriginSpineSuperspineLink:
+		return o.SpineSuperspineLinks
+	case RouteOriginL3EdgeServerLink:
+		return o.L3EdgeServerLinks
+	case RouteOriginL2EdgeSubnet:
+		return o.L2EdgeSubnets
+	case RouteOriginStaticRoute:
+		return o.StaticRoutes
+	default:
+		return false
+	}
+}
+
+// SimulateExport evaluates routes against the export side of the policy, in
+// the order used by the generated device configuration:
+//   - the first matching ExtraExportRoutes filter decides the route
+//   - otherwise the route is advertised if ExportPolicy enables its origin
+//
+// Routes learned from external routers are never re-advertised unless an
+// extra export filter permits them. A decision for each AggregatePrefixes
+// entry follows the route decisions: aggregates are advertised when at least
+// one candidate route (accepted or not) contributes to them.
+func (o *DcRoutingPolicyData) SimulateExport(routes []RoutingPolicyRoute) []RoutingPolicyDecision {
+	result := make([]RoutingPolicyDecision, 0, len(routes)+len(o.AggregatePrefixes))
+	contributors := make([]int, len(o.AggregatePrefixes))
+
+	for _, route := range routes {
+		var decision RoutingPolicyDecision
+		if i := firstMatchingPrefixFilter(o.ExtraExportRoutes, route.Prefix); i >= 0 {
+			decision = prefixFilterDecision(route, o.ExtraExportRoutes, i, "extra export routes")
+		} else {
+			decision = RoutingPolicyDecision{
+				Route:    route,
+				Accepted: o.ExportPolicy.exportsOrigin(route.Origin),
+				Filter:   -1,
+			}
+			if decision.Accepted {
+				decision.Reason = fmt.Sprintf("export policy permits %s routes", route.Origin)
+			} else {
+				decision.Reason = fmt.Sprintf("export policy does not permit %s routes", route.Origin)
+			}
+		}
+
+		for i, aggregate := range o.AggregatePrefixes {
+			if prefixContains(aggregate, route.Prefix) {
+				contributors[i]++
+				if decision.Accepted {
+					decision.Aggregates = append(decision.Aggregates, aggregate)
+				}
+			}
+		}
+
+		result = append(result, decision)
+	}
+
+	for i, aggregate := range o.AggregatePrefixes {
+		decision := RoutingPolicyDecision{
+			Route:    RoutingPolicyRoute{Prefix: aggregate, Origin: RouteOriginAggregate},
+			Accepted: contributors[i] > 0,
+			Filter:   -1,
+		}
+		if decision.Accepted {
+			decision.Reason = fmt.Sprintf("aggregate prefix has %d contributing routes", contributors[i])
+		} else {
+			decision.Reason = "aggregate prefix has no contributing routes"
+		}
+		result = append(result, decision)
+	}
+
+	return result
+}
+
+// SimulateImport evaluates prefixes learned from external routers against the
+// import side of the policy:
+//   - the first matching ExtraImportRoutes filter decides the route
+//   - otherwise ImportPolicy decides: DcRoutingPolicyImportPolicyAll accepts
+//     everything, DcRoutingPolicyImportPolicyDefaultOnly accepts only the
+//     IPv4 and IPv6 default routes, others reject the route
+func (o *DcRoutingPolicyData) SimulateImport(prefixes []net.IPNet) []RoutingPolicyDecision {
+	result := make([]RoutingPolicyDecision, len(prefixes))
+	for i, prefix := range prefixes {
+		route := RoutingPolicyRoute{Prefix: prefix, Origin: RouteOriginExternal}
+		if f := firstMatchingPrefixFilter(o.ExtraImportRoutes, prefix); f >= 0 {
+			result[i] = prefixFilterDecision(route, o.ExtraImportRoutes, f, "extra import routes")
+			continue
+		}
+
+		ones, _ := prefix.Mask.Size()
+		result[i] = RoutingPolicyDecision{Route: route, Filter: -1}
+		switch {
+		case o.ImportPolicy == DcRoutingPolicyImportPolicyAll:
+			result[i].Accepted = true
+			result[i].Reason = fmt.Sprintf("import policy %q accepts all routes", o.ImportPolicy)
+		case o.ImportPolicy == DcRoutingPolicyImportPolicyDefaultOnly && ones == 0:
+			result[i].Accepted = true
+			result[i].Reason = fmt.Sprintf("import policy %q accepts the default route", o.ImportPolicy)
+		case o.ImportPolicy == DcRoutingPolicyImportPolicyDefaultOnly:
+			result[i].Reason = fmt.Sprintf("import policy %q accepts only the default route", o.ImportPolicy)
+		default:
+			result[i].Reason = fmt.Sprintf("import policy %q accepts only extra import routes", o.ImportPolicy)
+		}
+	}
+	return result
+}
+
+// prefixContains returns true when inner is equal to, or more specific than,
+// outer.
+func prefixContains(outer, inner net.IPNet) bool {
+	oOnes, oBits := outer.Mask.Size()
+	iOnes, iBits := inner.Mask.Size()
+	return oBits != 0 && oBits == iBits && iOnes >= oOnes && outer.Contains(inner.IP)
+}
+
+// PrefixFilterOverlap describes two filters in the same list which match at
+// least one common prefix. First precedes Second in the list. Shadowed
+// indicates that every prefix matched by Second is also matched by First, so
+// Second never decides anything. Conflicting indicates that the filters have
+// opposite actions.
+type PrefixFilterOverlap struct {
+	First       int
+	Second      int
+	Shadowed    bool
+	Conflicting bool
+}
+
+// FindPrefixFilterOverlaps compares each pair of filters in the list (e.g.
+// ExtraImportRoutes or ExtraExportRoutes) and returns those which overlap.
+func FindPrefixFilterOverlaps(filters []PrefixFilter) []PrefixFilterOverlap {
+	var result []PrefixFilterOverlap
+	for i := range filters {
+		for j := i + 1; j < len(filters); j++ {
+			a, b := &filters[i], &filters[j]
+
+			// a prefix matching both filters must fall within both filter
+			// prefixes, so one of them must contain the other
+			if !prefixContains(a.Prefix, b.Prefix) && !prefixContains(b.Prefix, a.Prefix) {
+				continue
+			}
+
+			aLo, aHi := a.lengths()
+			bLo, bHi := b.lengths()
+			aOnes, _ := a.Prefix.Mask.Size()
+			bOnes, _ := b.Prefix.Mask.Size()
+			if max(aLo, bLo, aOnes, bOnes) > min(aHi, bHi) {
+				continue
+			}
+
+			result = append(result, PrefixFilterOverlap{
+				First:       i,
+				Second:      j,
+				Shadowed:    prefixContains(a.Prefix, b.Prefix) && max(bLo, bOnes) >= max(aLo, aOnes) && bHi <= aHi,
+				Conflicting: a.permits() != b.permits(),
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,162 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testPrefix(t testing.TB, s string) net.IPNet {
+	t.Helper()
+	_, result, err := net.ParseCIDR(s)
+	require.NoError(t, err)
+	return *result
+}
+
+func TestPrefixFilterMatches(t *testing.T) {
+	type testCase struct {
+		filter  PrefixFilter
+		prefix  string
+		matches bool
+	}
+
+	ge, le := 24, 28
+	filter := func(prefix string, geMask, leMask *int) PrefixFilter {
+		return PrefixFilter{Action: PrefixFilterActionPermit, Prefix: testPrefix(t, prefix), GeMask: geMask, LeMask: leMask}
+	}
+
+	testCases := map[string]testCase{
+		"exact":             {filter: filter("10.0.0.0/16", nil, nil), prefix: "10.0.0.0/16", matches: true},
+		"exact_longer":      {filter: filter("10.0.0.0/16", nil, nil), prefix: "10.0.1.0/24", matches: false},
+		"ge_in_range":       {filter: filter("10.0.0.0/16", &ge, nil), prefix: "10.0.1.1/32", matches: true},
+		"ge_too_short":      {filter: filter("10.0.0.0/16", &ge, nil), prefix: "10.0.0.0/20", matches: false},
+		"le_in_range":       {filter: filter("10.0.0.0/16", nil, &le), prefix: "10.0.0.0/16", matches: true},
+		"le_too_long":       {filter: filter("10.0.0.0/16", nil, &le), prefix: "10.0.0.0/29", matches: false},
+		"ge_le_in_range":    {filter: filter("10.0.0.0/16", &ge, &le), prefix: "10.0.0.16/28", matches: true},
+		"ge_le_too_short":   {filter: filter("10.0.0.0/16", &ge, &le), prefix: "10.0.0.0/23", matches: false},
+		"outside":           {filter: filter("10.0.0.0/16", &ge, &le), prefix: "10.1.0.0/24", matches: false},
+		"wrong_family":      {filter: filter("::/0", nil, &le), prefix: "0.0.0.0/0", matches: false},
+		"ipv6_ge":           {filter: filter("2001:db8::/32", &ge, nil), prefix: "2001:db8::/64", matches: true},
+		"shorter_than_self": {filter: filter("10.0.0.0/16", nil, &le), prefix: "10.0.0.0/8", matches: false},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tCase.matches, tCase.filter.Matches(testPrefix(t, tCase.prefix)))
+		})
+	}
+}
+
+func TestSimulateExport(t *testing.T) {
+	le := 32
+	policy := DcRoutingPolicyData{
+		ExportPolicy: DcRoutingExportPolicy{Loopbacks: true, L2EdgeSubnets: true},
+		ExtraExportRoutes: []PrefixFilter{
+			{Action: PrefixFilterActionDeny, Prefix: testPrefix(t, "10.1.1.0/24")},
+			{Action: PrefixFilterActionPermit, Prefix: testPrefix(t, "172.16.0.0/12"), LeMask: &le},
+		},
+		AggregatePrefixes: []net.IPNet{testPrefix(t, "10.1.0.0/16"), testPrefix(t, "192.168.0.0/16")},
+	}
+
+	decisions := policy.SimulateExport([]RoutingPolicyRoute{
+		{Prefix: testPrefix(t, "10.0.0.1/32"), Origin: RouteOriginLoopback},
+		{Prefix: testPrefix(t, "10.0.1.0/31"), Origin: RouteOriginSpineLeafLink},
+		{Prefix: testPrefix(t, "10.1.1.0/24"), Origin: RouteOriginL2EdgeSubnet},
+		{Prefix: testPrefix(t, "10.1.2.0/24"), Origin: RouteOriginL2EdgeSubnet},
+		{Prefix: testPrefix(t, "172.16.5.0/24"), Origin: RouteOriginStaticRoute},
+		{Prefix: testPrefix(t, "198.51.100.0/24"), Origin: RouteOriginExternal},
+	})
+
+	type summary struct {
+		accepted   bool
+		filter     int
+		aggregates int
+	}
+	summaries := make([]summary, len(decisions))
+	for i, decision := range decisions {
+		summaries[i] = summary{accepted: decision.Accepted, filter: decision.Filter, aggregates: len(decision.Aggregates)}
+	}
+
+	require.Equal(t, []summary{
+		{accepted: true, filter: -1},                // loopback enabled
+		{accepted: false, filter: -1},               // spine-leaf links disabled
+		{accepted: false, filter: 0},                // denied by filter
+		{accepted: true, filter: -1, aggregates: 1}, // L2 edge subnets enabled
+		{accepted: true, filter: 1},                 // static routes disabled, but permitted by filter
+		{accepted: false, filter: -1},               // external routes are not re-advertised
+		{accepted: true, filter: -1},                // aggregate 10.1.0.0/16 has contributors
+		{accepted: false, filter: -1},               // aggregate 192.168.0.0/16 has none
+	}, summaries)
+
+	require.Equal(t, RouteOriginAggregate, decisions[6].Route.Origin)
+	require.Contains(t, decisions[2].String(), "extra export routes[0] (deny 10.1.1.0/24)")
+	require.Contains(t, decisions[6].Reason, "2 contributing routes")
+}
+
+func TestSimulateImport(t *testing.T) {
+	ge := 24
+	filters := []PrefixFilter{
+		{Action: PrefixFilterActionPermit, Prefix: testPrefix(t, "192.0.2.0/24")},
+		{Action: PrefixFilterActionDeny, Prefix: testPrefix(t, "0.0.0.0/0"), GeMask: &ge},
+	}
+	prefixes := []net.IPNet{
+		testPrefix(t, "0.0.0.0/0"),
+		testPrefix(t, "::/0"),
+		testPrefix(t, "192.0.2.0/24"),
+		testPrefix(t, "198.51.100.0/25"),
+		testPrefix(t, "203.0.113.0/23"),
+	}
+
+	type testCase struct {
+		policy   DcRoutingPolicyImportPolicy
+		accepted []bool
+	}
+
+	testCases := map[string]testCase{
+		"default_only": {policy: DcRoutingPolicyImportPolicyDefaultOnly, accepted: []bool{true, true, true, false, false}},
+		"all":          {policy: DcRoutingPolicyImportPolicyAll, accepted: []bool{true, true, true, false, true}},
+		"extra_only":   {policy: DcRoutingPolicyImportPolicyExtraOnly, accepted: []bool{false, false, true, false, false}},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			policy := DcRoutingPolicyData{ImportPolicy: tCase.policy, ExtraImportRoutes: filters}
+			decisions := policy.SimulateImport(prefixes)
+			require.Len(t, decisions, len(prefixes))
+			for i, decision := range decisions {
+				require.Equalf(t, tCase.accepted[i], decision.Accepted, decision.String())
+			}
+			require.Equal(t, 0, decisions[2].Filter)
+			require.Equal(t, 1, decisions[3].Filter)
+			require.Equal(t, -1, decisions[4].Filter)
+		})
+	}
+}
+
+func TestFindPrefixFilterOverlaps(t *testing.T) {
+	ge24, le24, le32 := 24, 24, 32
+	filters := []PrefixFilter{
+		{Action: PrefixFilterActionPermit, Prefix: testPrefix(t, "10.0.0.0/8"), LeMask: &le32},    // 0
+		{Action: PrefixFilterActionDeny, Prefix: testPrefix(t, "10.1.0.0/16"), GeMask: &ge24},     // 1: shadowed by 0
+		{Action: PrefixFilterActionPermit, Prefix: testPrefix(t, "172.16.0.0/12"), LeMask: &le24}, // 2
+		{Action: PrefixFilterActionPermit, Prefix: testPrefix(t, "172.16.1.0/24"), GeMask: &ge24}, // 3: overlaps 2 at /24 only
+		{Action: PrefixFilterActionPermit, Prefix: testPrefix(t, "172.16.2.0/25")},                // 4: longer than 2 permits
+		{Action: PrefixFilterActionPermit, Prefix: testPrefix(t, "2001:db8::/32"), LeMask: &le32}, // 5
+	}
+
+	require.Equal(t, []PrefixFilterOverlap{
+		{First: 0, Second: 1, Shadowed: true, Conflicting: true},
+		{First: 2, Second: 3, Shadowed: false, Conflicting: false},
+	}, FindPrefixFilterOverlaps(filters))
+
+	require.Empty(t, FindPrefixFilterOverlaps(filters[4:]))
+}
@@ -72,7 +72,7 @@ func (o *RoutingZoneConstraint) UnmarshalJSON(bytes []byte) error {
 	o.Id = raw.Id
 	o.Data = new(RoutingZoneConstraintData)
 	o.Data.Label = raw.Label
-	err = o.Data.Mode.FromString(raw.RoutingZonesListConstraint)
+	o.Data.Mode, err = apiEnumFromString[enum.RoutingZoneConstraintMode](raw.RoutingZonesListConstraint)
 	if err != nil {
 		return err
 	}
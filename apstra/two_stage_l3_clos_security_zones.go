@@ -6,6 +6,7 @@ package apstra
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -53,12 +54,15 @@ func (o SecurityZoneType) String() string {
 	case SecurityZoneTypeEVPN:
 		return string(securityZoneTypeEVPN)
 	default:
+		if s, ok := lenientEnumString[securityZoneType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(securityZoneTypeUnknown, o)
 	}
 }
 
 func (o *SecurityZoneType) FromString(in string) error {
-	i, err := securityZoneType(in).parse()
+	i, err := parseKnown(securityZoneType(in))
 	if err != nil {
 		return err
 	}
@@ -85,6 +89,9 @@ func (o securityZoneType) parse() (int, error) {
 	case securityZoneTypeEVPN:
 		return int(SecurityZoneTypeEVPN), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(SecurityZoneTypeUnknown, o)
 	}
 }
@@ -105,6 +112,8 @@ type SecurityZoneData struct {
 	VlanId           *Vlan                  // can be null
 	VniId            *int                   // can be null
 	JunosEvpnIrbMode *enum.JunosEvpnIrbMode // Apstra 4.2+ only
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 func (o SecurityZoneData) raw() *rawSecurityZone {
@@ -142,6 +151,23 @@ type rawSecurityZone struct {
 	VlanId           *Vlan            `json:"vlan_id,omitempty"`
 	VniId            *int             `json:"vni_id,omitempty"`
 	JunosEvpnIrbMode string           `json:"junos_evpn_irb_mode,omitempty"`
+	unknownFields    unknownJsonFields
+}
+
+func (o *rawSecurityZone) UnmarshalJSON(b []byte) error {
+	type alias rawSecurityZone
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{})
+	return err
+}
+
+func (o rawSecurityZone) MarshalJSON() ([]byte, error) {
+	type alias rawSecurityZone
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 func (o rawSecurityZone) polish() (*SecurityZone, error) {
@@ -168,6 +194,7 @@ func (o rawSecurityZone) polish() (*SecurityZone, error) {
 			VlanId:           o.VlanId,
 			VniId:            o.VniId,
 			JunosEvpnIrbMode: enum.JunosEvpnIrbModes.Parse(o.JunosEvpnIrbMode),
+			unknownFields:    o.unknownFields,
 		},
 	}, nil
 }
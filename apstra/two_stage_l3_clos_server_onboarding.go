@@ -0,0 +1,609 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Column names recognized by ParseServerOnboardingCsv. Column order doesn't
+// matter; names are not case-sensitive.
+const (
+	ServerOnboardingCsvHostname      = "hostname"
+	ServerOnboardingCsvLeafHostname  = "leaf_hostname"
+	ServerOnboardingCsvLeafInterface = "leaf_interface"
+	ServerOnboardingCsvNic           = "nic"
+	ServerOnboardingCsvLagGroup      = "lag_group"
+	ServerOnboardingCsvLagMode       = "lag_mode"
+	ServerOnboardingCsvSpeed         = "speed"
+	ServerOnboardingCsvTags          = "tags"
+	ServerOnboardingCsvLogicalDevice = "logical_device"
+
+	serverOnboardingCsvTagSeparator = ";"
+)
+
+// ServerOnboardingLink is a link between a server (generic system) and a leaf
+// switch port. LagGroup links of a server are bundled together; when LagMode
+// isn't specified for a LAG, RackLinkLagModeActive is used. Speed, when
+// specified, must match the leaf port speed in its interface map. The
+// LogicalDeviceId is required for servers which don't exist yet.
+type ServerOnboardingLink struct {
+	Row             int // CSV line number, for error messages
+	Hostname        string
+	LeafHostname    string
+	LeafInterface   string
+	Nic             string
+	LagGroup        string
+	LagMode         RackLinkLagMode
+	Speed           LogicalDevicePortSpeed
+	Tags            []string
+	LogicalDeviceId ObjectId
+}
+
+func (o *ServerOnboardingLink) leafPort() string {
+	return o.LeafHostname + ":" + o.LeafInterface
+}
+
+func (o *ServerOnboardingLink) validate() error {
+	if o.Hostname == "" {
+		return fmt.Errorf("row %d: %s is required", o.Row, ServerOnboardingCsvHostname)
+	}
+	if o.LeafHostname == "" {
+		return fmt.Errorf("row %d: %s is required", o.Row, ServerOnboardingCsvLeafHostname)
+	}
+	if o.LeafInterface == "" {
+		return fmt.Errorf("row %d: %s is required", o.Row, ServerOnboardingCsvLeafInterface)
+	}
+	if o.LagGroup == "" && o.LagMode != RackLinkLagModeNone {
+		return fmt.Errorf("row %d: %s requires %s", o.Row, ServerOnboardingCsvLagMode, ServerOnboardingCsvLagGroup)
+	}
+	return nil
+}
+
+// ParseServerOnboardingCsv reads server links from CSV with a header row. The
+// hostname, leaf_hostname and leaf_interface columns are required. Multiple
+// tags are separated with ";".
+func ParseServerOnboardingCsv(r io.Reader) ([]ServerOnboardingLink, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading CSV header - %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{ServerOnboardingCsvHostname, ServerOnboardingCsvLeafHostname, ServerOnboardingCsvLeafInterface} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header is missing required column %q", name)
+		}
+	}
+
+	var result []ServerOnboardingLink
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed reading CSV - %w", err)
+		}
+
+		row, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		link := ServerOnboardingLink{
+			Row:             row,
+			Hostname:        field(ServerOnboardingCsvHostname),
+			LeafHostname:    field(ServerOnboardingCsvLeafHostname),
+			LeafInterface:   field(ServerOnboardingCsvLeafInterface),
+			Nic:             field(ServerOnboardingCsvNic),
+			LagGroup:        field(ServerOnboardingCsvLagGroup),
+			Speed:           LogicalDevicePortSpeed(field(ServerOnboardingCsvSpeed)),
+			LogicalDeviceId: ObjectId(field(ServerOnboardingCsvLogicalDevice)),
+		}
+
+		if lagMode := field(ServerOnboardingCsvLagMode); lagMode != "" {
+			err = link.LagMode.FromString(lagMode)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: failed parsing %s - %w", row, ServerOnboardingCsvLagMode, err)
+			}
+		}
+		if link.LagGroup != "" && link.LagMode == RackLinkLagModeNone {
+			link.LagMode = RackLinkLagModeActive
+		}
+
+		for _, tag := range strings.Split(field(ServerOnboardingCsvTags), serverOnboardingCsvTagSeparator) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				link.Tags = append(link.Tags, tag)
+			}
+		}
+
+		err = link.validate()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, link)
+	}
+
+	return result, nil
+}
+
+// serverOnboardingPort is a leaf port as described by the leaf's interface map
+type serverOnboardingPort struct {
+	speed            LogicalDevicePortSpeed
+	transformationId int
+}
+
+type serverOnboardingLeaf struct {
+	id    ObjectId
+	ports map[string]serverOnboardingPort // keyed by interface name; nil without an interface map
+}
+
+type serverOnboardingExistingLink struct {
+	id            ObjectId
+	leafHostname  string
+	leafInterface string
+	groupLabel    string
+	lagMode       RackLinkLagMode
+}
+
+func (o *serverOnboardingExistingLink) leafPort() string {
+	return o.leafHostname + ":" + o.leafInterface
+}
+
+type serverOnboardingServer struct {
+	id    ObjectId
+	links []serverOnboardingExistingLink
+}
+
+// serverOnboardingFabric is the state of the blueprint relevant to server
+// onboarding, keyed by hostname.
+type serverOnboardingFabric struct {
+	leafs   map[string]serverOnboardingLeaf
+	servers map[string]*serverOnboardingServer
+}
+
+// ServerOnboardingPlan is the set of changes needed to make the blueprint
+// match a list of ServerOnboardingLink. DeleteLinks is populated only when
+// pruning; LagParams updates the LAG configuration of existing links.
+type ServerOnboardingPlan struct {
+	NewSystems  []CreateLinksWithNewSystemRequest
+	NewLinks    []CreateLinkRequest
+	LagParams   SetLinkLagParamsRequest
+	DeleteLinks []ObjectId
+}
+
+// Empty returns true when the blueprint already matches.
+func (o *ServerOnboardingPlan) Empty() bool {
+	return len(o.NewSystems) == 0 && len(o.NewLinks) == 0 && len(o.LagParams) == 0 && len(o.DeleteLinks) == 0
+}
+
+// switchEndpoint resolves the leaf ID and transformation of the link's leaf port
+func (o *serverOnboardingFabric) switchEndpoint(link *ServerOnboardingLink) (SwitchLinkEndpoint, error) {
+	leaf, ok := o.leafs[link.LeafHostname]
+	if !ok {
+		return SwitchLinkEndpoint{}, fmt.Errorf("row %d: leaf %q not found", link.Row, link.LeafHostname)
+	}
+
+	result := SwitchLinkEndpoint{SystemId: leaf.id, IfName: link.LeafInterface}
+
+	port, ok := leaf.ports[link.LeafInterface]
+	switch {
+	case ok:
+		result.TransformationId = port.transformationId
+		if link.Speed != "" && !link.Speed.IsEqual(port.speed) {
+			return SwitchLinkEndpoint{}, fmt.Errorf("row %d: interface %q of leaf %q is %s, not %s",
+				link.Row, link.LeafInterface, link.LeafHostname, port.speed, link.Speed)
+		}
+	case leaf.ports != nil:
+		return SwitchLinkEndpoint{}, fmt.Errorf("row %d: leaf %q has no interface %q", link.Row, link.LeafHostname, link.LeafInterface)
+	case link.Speed != "":
+		return SwitchLinkEndpoint{}, fmt.Errorf("row %d: cannot check speed of interface %q: leaf %q has no interface map", link.Row, link.LeafInterface, link.LeafHostname)
+	}
+
+	return result, nil
+}
+
+// planServerOnboarding compares the desired links with the fabric. Only the
+// links of servers named in the input are considered for removal when prune
+// is set.
+func planServerOnboarding(fabric *serverOnboardingFabric, links []ServerOnboardingLink, prune bool) (*ServerOnboardingPlan, error) {
+	// group links by server, in input order
+	var hostnames []string
+	byServer := make(map[string][]ServerOnboardingLink)
+	ports := make(map[string]int) // leaf port -> row
+	lagModes := make(map[string]RackLinkLagMode)
+	var errs []error
+	for _, link := range links {
+		if row, ok := ports[link.leafPort()]; ok {
+			errs = append(errs, fmt.Errorf("row %d: interface %q of leaf %q is already used in row %d", link.Row, link.LeafInterface, link.LeafHostname, row))
+			continue
+		}
+		ports[link.leafPort()] = link.Row
+
+		if link.LagGroup != "" {
+			key := link.Hostname + ":" + link.LagGroup
+			if mode, ok := lagModes[key]; ok && mode != link.LagMode {
+				errs = append(errs, fmt.Errorf("row %d: conflicting LAG modes in group %q of server %q", link.Row, link.LagGroup, link.Hostname))
+			}
+			lagModes[key] = link.LagMode
+		}
+
+		if _, ok := byServer[link.Hostname]; !ok {
+			hostnames = append(hostnames, link.Hostname)
+		}
+		byServer[link.Hostname] = append(byServer[link.Hostname], link)
+	}
+
+	result := ServerOnboardingPlan{LagParams: make(SetLinkLagParamsRequest)}
+	for _, hostname := range hostnames {
+		serverLinks := byServer[hostname]
+		server := fabric.servers[hostname]
+
+		existing := make(map[string]serverOnboardingExistingLink)
+		if server != nil {
+			for _, link := range server.links {
+				existing[link.leafPort()] = link
+			}
+		}
+
+		var newLinks []CreateLinkRequest
+		var logicalDeviceId ObjectId
+		for _, link := range serverLinks {
+			if link.LogicalDeviceId != "" {
+				if logicalDeviceId != "" && logicalDeviceId != link.LogicalDeviceId {
+					errs = append(errs, fmt.Errorf("row %d: conflicting logical devices for server %q", link.Row, hostname))
+				}
+				logicalDeviceId = link.LogicalDeviceId
+			}
+
+			if current, ok := existing[link.leafPort()]; ok {
+				delete(existing, link.leafPort())
+				if current.lagMode != link.LagMode || (link.LagGroup != "" && current.groupLabel != link.LagGroup) {
+					result.LagParams[current.id] = LinkLagParams{GroupLabel: link.LagGroup, LagMode: link.LagMode, Tags: link.Tags}
+				}
+				continue
+			}
+
+			switchEndpoint, err := fabric.switchEndpoint(&link)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			request := CreateLinkRequest{
+				Tags:           link.Tags,
+				SwitchEndpoint: switchEndpoint,
+				SystemEndpoint: SwitchLinkEndpoint{IfName: link.Nic},
+				GroupLabel:     link.LagGroup,
+				LagMode:        link.LagMode,
+			}
+			if server != nil {
+				request.SystemEndpoint.SystemId = server.id
+			}
+			newLinks = append(newLinks, request)
+		}
+
+		switch {
+		case server != nil:
+			result.NewLinks = append(result.NewLinks, newLinks...)
+		case logicalDeviceId == "":
+			errs = append(errs, fmt.Errorf("row %d: new server %q requires a %s", serverLinks[0].Row, hostname, ServerOnboardingCsvLogicalDevice))
+		case len(newLinks) > 0:
+			result.NewSystems = append(result.NewSystems, CreateLinksWithNewSystemRequest{
+				Links: newLinks,
+				System: CreateLinksWithNewSystemRequestSystem{
+					Hostname:        hostname,
+					Label:           hostname,
+					LogicalDeviceId: logicalDeviceId,
+					Type:            SystemTypeServer,
+				},
+			})
+		}
+
+		if prune && server != nil {
+			for _, link := range server.links {
+				if _, ok := existing[link.leafPort()]; ok {
+					result.DeleteLinks = append(result.DeleteLinks, link.id)
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return &result, nil
+}
+
+// getServerOnboardingFabric collects leaf switches (with their interface
+// maps), and the generic systems with their leaf links.
+func (o *TwoStageL3ClosClient) getServerOnboardingFabric(ctx context.Context) (*serverOnboardingFabric, error) {
+	leafQuery := new(MatchQuery).
+		SetBlueprintId(o.blueprintId).
+		SetBlueprintType(BlueprintTypeStaging).
+		SetClient(o.client).
+		Match(new(PathQuery).
+			Node([]QEEAttribute{
+				NodeTypeSystem.QEEAttribute(),
+				{"role", QEStringVal(SystemRoleLeaf.String())},
+				{"name", QEStringVal("n_leaf")},
+			})).
+		Optional(new(PathQuery).
+			Node([]QEEAttribute{{"name", QEStringVal("n_leaf")}}).
+			Out([]QEEAttribute{RelationshipTypeInterfaceMap.QEEAttribute()}).
+			Node([]QEEAttribute{NodeTypeInterfaceMap.QEEAttribute(), {"name", QEStringVal("n_interface_map")}}))
+
+	var leafResponse struct {
+		Items []struct {
+			Leaf struct {
+				Id       ObjectId `json:"id"`
+				Hostname string   `json:"hostname"`
+			} `json:"n_leaf"`
+			InterfaceMap *struct {
+				Interfaces []struct {
+					Name    string                    `json:"name"`
+					Mapping rawInterfaceMapping       `json:"mapping"`
+					Speed   rawLogicalDevicePortSpeed `json:"speed"`
+				} `json:"interfaces"`
+			} `json:"n_interface_map"`
+		} `json:"items"`
+	}
+
+	err := leafQuery.Do(ctx, &leafResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying leaf switches - %w", convertTtaeToAceWherePossible(err))
+	}
+
+	result := serverOnboardingFabric{
+		leafs:   make(map[string]serverOnboardingLeaf, len(leafResponse.Items)),
+		servers: make(map[string]*serverOnboardingServer),
+	}
+	for _, item := range leafResponse.Items {
+		leaf := serverOnboardingLeaf{id: item.Leaf.Id}
+		if item.InterfaceMap != nil {
+			leaf.ports = make(map[string]serverOnboardingPort, len(item.InterfaceMap.Interfaces))
+			for _, intf := range item.InterfaceMap.Interfaces {
+				port := serverOnboardingPort{speed: intf.Speed.parse()}
+				if len(intf.Mapping) == 5 { // polish() requires all five elements
+					port.transformationId = intf.Mapping.polish().DPTransformId
+				}
+				leaf.ports[intf.Name] = port
+			}
+		}
+		result.leafs[item.Leaf.Hostname] = leaf
+	}
+
+	var nodesResponse struct {
+		Nodes map[ObjectId]struct {
+			Id       ObjectId `json:"id"`
+			Hostname string   `json:"hostname"`
+			Role     string   `json:"role"`
+		} `json:"nodes"`
+	}
+	err = o.GetNodes(ctx, NodeTypeSystem, &nodesResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching system nodes - %w", err)
+	}
+	for _, node := range nodesResponse.Nodes {
+		if node.Role == SystemRoleGeneric.String() && node.Hostname != "" {
+			result.servers[node.Hostname] = &serverOnboardingServer{id: node.Id}
+		}
+	}
+
+	linkQuery := new(PathQuery).
+		SetBlueprintId(o.blueprintId).
+		SetBlueprintType(BlueprintTypeStaging).
+		SetClient(o.client).
+		Node([]QEEAttribute{
+			NodeTypeSystem.QEEAttribute(),
+			{"role", QEStringVal(SystemRoleGeneric.String())},
+			{"name", QEStringVal("n_server")},
+		}).
+		Out([]QEEAttribute{RelationshipTypeHostedInterfaces.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeInterface.QEEAttribute()}).
+		Out([]QEEAttribute{RelationshipTypeLink.QEEAttribute()}).
+		Node([]QEEAttribute{
+			NodeTypeLink.QEEAttribute(),
+			{"link_type", QEStringVal("ethernet")},
+			{"name", QEStringVal("n_link")},
+		}).
+		In([]QEEAttribute{RelationshipTypeLink.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeInterface.QEEAttribute(), {"name", QEStringVal("n_leaf_interface")}}).
+		In([]QEEAttribute{RelationshipTypeHostedInterfaces.QEEAttribute()}).
+		Node([]QEEAttribute{
+			NodeTypeSystem.QEEAttribute(),
+			{"role", QEStringVal(SystemRoleLeaf.String())},
+			{"name", QEStringVal("n_leaf")},
+		})
+
+	var linkResponse struct {
+		Items []struct {
+			Server struct {
+				Hostname string `json:"hostname"`
+			} `json:"n_server"`
+			Link struct {
+				Id         ObjectId `json:"id"`
+				GroupLabel *string  `json:"group_label"`
+			} `json:"n_link"`
+			LeafInterface struct {
+				IfName string `json:"if_name"`
+			} `json:"n_leaf_interface"`
+			Leaf struct {
+				Hostname string `json:"hostname"`
+			} `json:"n_leaf"`
+		} `json:"items"`
+	}
+
+	err = linkQuery.Do(ctx, &linkResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying server links - %w", convertTtaeToAceWherePossible(err))
+	}
+
+	lagModes, err := o.getServerOnboardingLagModes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range linkResponse.Items {
+		server, ok := result.servers[item.Server.Hostname]
+		if !ok {
+			continue
+		}
+
+		link := serverOnboardingExistingLink{
+			id:            item.Link.Id,
+			leafHostname:  item.Leaf.Hostname,
+			leafInterface: item.LeafInterface.IfName,
+			lagMode:       lagModes[item.Link.Id],
+		}
+		if item.Link.GroupLabel != nil {
+			link.groupLabel = *item.Link.GroupLabel
+		}
+		server.links = append(server.links, link)
+	}
+
+	return &result, nil
+}
+
+// getServerOnboardingLagModes returns the LAG mode of each generic system
+// link which is a LAG member, keyed by link ID.
+func (o *TwoStageL3ClosClient) getServerOnboardingLagModes(ctx context.Context) (map[ObjectId]RackLinkLagMode, error) {
+	query := new(PathQuery).
+		SetBlueprintId(o.blueprintId).
+		SetBlueprintType(BlueprintTypeStaging).
+		SetClient(o.client).
+		Node([]QEEAttribute{
+			NodeTypeSystem.QEEAttribute(),
+			{"role", QEStringVal(SystemRoleGeneric.String())},
+		}).
+		Out([]QEEAttribute{RelationshipTypeHostedInterfaces.QEEAttribute()}).
+		Node([]QEEAttribute{
+			NodeTypeInterface.QEEAttribute(),
+			{"if_type", QEStringVal("port_channel")},
+			{"name", QEStringVal("n_lag")},
+		}).
+		Out([]QEEAttribute{RelationshipTypeComposedOf.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeInterface.QEEAttribute()}).
+		Out([]QEEAttribute{RelationshipTypeLink.QEEAttribute()}).
+		Node([]QEEAttribute{
+			NodeTypeLink.QEEAttribute(),
+			{"link_type", QEStringVal("ethernet")},
+			{"name", QEStringVal("n_link")},
+		})
+
+	var response struct {
+		Items []struct {
+			Lag struct {
+				LagMode *string `json:"lag_mode"`
+			} `json:"n_lag"`
+			Link struct {
+				Id ObjectId `json:"id"`
+			} `json:"n_link"`
+		} `json:"items"`
+	}
+
+	err := query.Do(ctx, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying server LAGs - %w", convertTtaeToAceWherePossible(err))
+	}
+
+	result := make(map[ObjectId]RackLinkLagMode, len(response.Items))
+	for _, item := range response.Items {
+		if item.Lag.LagMode == nil {
+			continue
+		}
+		var lagMode RackLinkLagMode
+		err = lagMode.FromString(*item.Lag.LagMode)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing LAG mode of link %q - %w", item.Link.Id, err)
+		}
+		result[item.Link.Id] = lagMode
+	}
+
+	return result, nil
+}
+
+// PlanServerOnboarding resolves leaf switch IDs and port transformations, and
+// compares the links with the servers (generic systems, matched by hostname)
+// in the blueprint. When prune is set, links of the listed servers which are
+// not in links are scheduled for deletion. Servers which are not listed are
+// never modified.
+func (o *TwoStageL3ClosClient) PlanServerOnboarding(ctx context.Context, links []ServerOnboardingLink, prune bool) (*ServerOnboardingPlan, error) {
+	fabric, err := o.getServerOnboardingFabric(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return planServerOnboarding(fabric, links, prune)
+}
+
+// ApplyServerOnboarding deletes links, creates new servers and links, and
+// sets LAG parameters of existing links, in that order.
+func (o *TwoStageL3ClosClient) ApplyServerOnboarding(ctx context.Context, plan *ServerOnboardingPlan) error {
+	if len(plan.DeleteLinks) > 0 {
+		err := o.DeleteLinksFromSystem(ctx, plan.DeleteLinks)
+		if err != nil {
+			return fmt.Errorf("failed deleting server links - %w", err)
+		}
+	}
+
+	for i := range plan.NewSystems {
+		_, err := o.CreateLinksWithNewSystem(ctx, &plan.NewSystems[i])
+		if err != nil {
+			return fmt.Errorf("failed creating server %q - %w", plan.NewSystems[i].System.Hostname, err)
+		}
+	}
+
+	if len(plan.NewLinks) > 0 {
+		_, err := o.AddLinksToSystem(ctx, plan.NewLinks)
+		if err != nil {
+			return fmt.Errorf("failed adding server links - %w", err)
+		}
+	}
+
+	if len(plan.LagParams) > 0 {
+		err := o.SetLinkLagParams(ctx, &plan.LagParams)
+		if err != nil {
+			return fmt.Errorf("failed setting server LAG parameters - %w", err)
+		}
+	}
+
+	return nil
+}
+
+// OnboardServersFromCsv parses the CSV (see ParseServerOnboardingCsv), plans
+// (see PlanServerOnboarding) and applies the changes. The applied plan is
+// returned.
+func (o *TwoStageL3ClosClient) OnboardServersFromCsv(ctx context.Context, r io.Reader, prune bool) (*ServerOnboardingPlan, error) {
+	links, err := ParseServerOnboardingCsv(r)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := o.PlanServerOnboarding(ctx, links, prune)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, o.ApplyServerOnboarding(ctx, plan)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnboardServersFromCsv(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bp := testBlueprintA(ctx, t, client.client)
+
+			fabric, err := bp.getServerOnboardingFabric(ctx)
+			require.NoError(t, err)
+
+			// use the highest-numbered port of each leaf with an interface map
+			var rows []string
+			hostname := "server-" + randString(6, "hex")
+			for _, leafHostname := range sortedKeys(fabric.leafs) {
+				ports := sortedKeys(fabric.leafs[leafHostname].ports)
+				if len(ports) == 0 {
+					continue
+				}
+				port := ports[len(ports)-1]
+				rows = append(rows, fmt.Sprintf("%s,%s,%s,eth%d,bond0,lacp_active,%s,,AOS-2x10-1",
+					hostname, leafHostname, port, len(rows), fabric.leafs[leafHostname].ports[port].speed))
+			}
+			if len(rows) == 0 {
+				t.Skip("no leaf switch has an interface map")
+			}
+			csv := "hostname,leaf_hostname,leaf_interface,nic,lag_group,lag_mode,speed,tags,logical_device\n" + strings.Join(rows, "\n")
+
+			log.Printf("testing OnboardServersFromCsv() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			plan, err := bp.OnboardServersFromCsv(ctx, strings.NewReader(csv), false)
+			require.NoError(t, err)
+			require.Len(t, plan.NewSystems, 1)
+
+			fabric, err = bp.getServerOnboardingFabric(ctx)
+			require.NoError(t, err)
+			server, ok := fabric.servers[hostname]
+			require.True(t, ok)
+			require.Len(t, server.links, len(rows))
+			t.Cleanup(func() { require.NoError(t, bp.DeleteGenericSystem(ctx, server.id)) })
+
+			links, err := ParseServerOnboardingCsv(strings.NewReader(csv))
+			require.NoError(t, err)
+
+			log.Printf("testing PlanServerOnboarding() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			plan, err = bp.PlanServerOnboarding(ctx, links, true)
+			require.NoError(t, err)
+			require.True(t, plan.Empty())
+
+			if len(links) > 1 {
+				plan, err = bp.PlanServerOnboarding(ctx, links[:1], true)
+				require.NoError(t, err)
+				require.Len(t, plan.DeleteLinks, len(links)-1)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerOnboardingCsv(t *testing.T) {
+	csv := `Hostname, Leaf_Hostname, Leaf_Interface, NIC, LAG_Group, LAG_Mode, Speed, Tags, Logical_Device
+# comments are ignored
+server1, leaf1, xe-0/0/1, eth0, bond0, , 10G, red;blue, AOS-2x10-1
+server1, leaf2, xe-0/0/1, eth1, bond0, , 10G, , AOS-2x10-1
+server2, leaf1, xe-0/0/2, eth0, bond0, static_lag, , ,
+server3, leaf1, xe-0/0/3, , , , , ,
+`
+
+	links, err := ParseServerOnboardingCsv(strings.NewReader(csv))
+	require.NoError(t, err)
+	require.Len(t, links, 4)
+
+	require.Equal(t, ServerOnboardingLink{
+		Row:             3,
+		Hostname:        "server1",
+		LeafHostname:    "leaf1",
+		LeafInterface:   "xe-0/0/1",
+		Nic:             "eth0",
+		LagGroup:        "bond0",
+		LagMode:         RackLinkLagModeActive, // default for LAGs
+		Speed:           "10G",
+		Tags:            []string{"red", "blue"},
+		LogicalDeviceId: "AOS-2x10-1",
+	}, links[0])
+	require.Equal(t, RackLinkLagModeStatic, links[2].LagMode)
+	require.Equal(t, RackLinkLagModeNone, links[3].LagMode)
+	require.Nil(t, links[3].Tags)
+
+	type testCase struct {
+		csv string
+	}
+
+	testCases := map[string]testCase{
+		"missing_column":    {csv: "hostname,leaf_hostname\nserver1,leaf1\n"},
+		"missing_value":     {csv: "hostname,leaf_hostname,leaf_interface\nserver1,,xe-0/0/1\n"},
+		"bad_lag_mode":      {csv: "hostname,leaf_hostname,leaf_interface,lag_group,lag_mode\nserver1,leaf1,xe-0/0/1,bond0,bogus\n"},
+		"lag_mode_no_group": {csv: "hostname,leaf_hostname,leaf_interface,lag_mode\nserver1,leaf1,xe-0/0/1,lacp_active\n"},
+		"empty":             {csv: ""},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseServerOnboardingCsv(strings.NewReader(tCase.csv))
+			require.Error(t, err)
+		})
+	}
+}
+
+func testServerOnboardingFabric() *serverOnboardingFabric {
+	return &serverOnboardingFabric{
+		leafs: map[string]serverOnboardingLeaf{
+			"leaf1": {id: "leaf1_id", ports: map[string]serverOnboardingPort{
+				"xe-0/0/1": {speed: "10G", transformationId: 1},
+				"xe-0/0/2": {speed: "10G", transformationId: 1},
+				"xe-0/0/3": {speed: "25G", transformationId: 2},
+			}},
+			"leaf2": {id: "leaf2_id"}, // no interface map
+		},
+		servers: map[string]*serverOnboardingServer{
+			"server2": {id: "server2_id", links: []serverOnboardingExistingLink{
+				{id: "link_a", leafHostname: "leaf1", leafInterface: "xe-0/0/2", groupLabel: "x"},
+				{id: "link_b", leafHostname: "leaf2", leafInterface: "xe-0/0/9", groupLabel: "y"},
+			}},
+			"server9": {id: "server9_id", links: []serverOnboardingExistingLink{
+				{id: "link_c", leafHostname: "leaf2", leafInterface: "xe-0/0/8"},
+			}},
+		},
+	}
+}
+
+func TestPlanServerOnboarding(t *testing.T) {
+	links := []ServerOnboardingLink{
+		{Row: 2, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/1", Nic: "eth0", LagGroup: "bond0", LagMode: RackLinkLagModeActive, Speed: "10G", LogicalDeviceId: "AOS-2x10-1"},
+		{Row: 3, Hostname: "server1", LeafHostname: "leaf2", LeafInterface: "xe-0/0/1", Nic: "eth1", LagGroup: "bond0", LagMode: RackLinkLagModeActive},
+		{Row: 4, Hostname: "server2", LeafHostname: "leaf1", LeafInterface: "xe-0/0/2", LagGroup: "bond0", LagMode: RackLinkLagModeStatic},
+		{Row: 5, Hostname: "server2", LeafHostname: "leaf1", LeafInterface: "xe-0/0/3", Tags: []string{"red"}},
+	}
+
+	plan, err := planServerOnboarding(testServerOnboardingFabric(), links, false)
+	require.NoError(t, err)
+
+	require.Equal(t, []CreateLinksWithNewSystemRequest{{
+		Links: []CreateLinkRequest{
+			{
+				SwitchEndpoint: SwitchLinkEndpoint{SystemId: "leaf1_id", IfName: "xe-0/0/1", TransformationId: 1},
+				SystemEndpoint: SwitchLinkEndpoint{IfName: "eth0"},
+				GroupLabel:     "bond0",
+				LagMode:        RackLinkLagModeActive,
+			},
+			{
+				SwitchEndpoint: SwitchLinkEndpoint{SystemId: "leaf2_id", IfName: "xe-0/0/1"},
+				SystemEndpoint: SwitchLinkEndpoint{IfName: "eth1"},
+				GroupLabel:     "bond0",
+				LagMode:        RackLinkLagModeActive,
+			},
+		},
+		System: CreateLinksWithNewSystemRequestSystem{
+			Hostname:        "server1",
+			Label:           "server1",
+			LogicalDeviceId: "AOS-2x10-1",
+			Type:            SystemTypeServer,
+		},
+	}}, plan.NewSystems)
+
+	require.Equal(t, []CreateLinkRequest{{
+		Tags:           []string{"red"},
+		SwitchEndpoint: SwitchLinkEndpoint{SystemId: "leaf1_id", IfName: "xe-0/0/3", TransformationId: 2},
+		SystemEndpoint: SwitchLinkEndpoint{SystemId: "server2_id"},
+	}}, plan.NewLinks)
+
+	// link_a becomes a static LAG member
+	require.Equal(t, SetLinkLagParamsRequest{
+		"link_a": {GroupLabel: "bond0", LagMode: RackLinkLagModeStatic},
+	}, plan.LagParams)
+
+	// link_b isn't in the input, but isn't removed without pruning
+	require.Empty(t, plan.DeleteLinks)
+
+	plan, err = planServerOnboarding(testServerOnboardingFabric(), links, true)
+	require.NoError(t, err)
+	require.Equal(t, []ObjectId{"link_b"}, plan.DeleteLinks) // server9 isn't listed, so link_c stays
+	require.False(t, plan.Empty())
+
+	// the blueprint matches after the plan is applied
+	fabric := testServerOnboardingFabric()
+	fabric.servers["server2"].links[0].groupLabel = "bond0"
+	fabric.servers["server2"].links[0].lagMode = RackLinkLagModeStatic
+	plan, err = planServerOnboarding(fabric, links[2:3], false)
+	require.NoError(t, err)
+	require.True(t, plan.Empty())
+}
+
+func TestPlanServerOnboardingErrors(t *testing.T) {
+	type testCase struct {
+		links []ServerOnboardingLink
+		err   string
+	}
+
+	testCases := map[string]testCase{
+		"unknown_leaf": {
+			links: []ServerOnboardingLink{{Row: 2, Hostname: "server1", LeafHostname: "leaf9", LeafInterface: "xe-0/0/1", LogicalDeviceId: "ld"}},
+			err:   `leaf "leaf9" not found`,
+		},
+		"unknown_interface": {
+			links: []ServerOnboardingLink{{Row: 2, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/9", LogicalDeviceId: "ld"}},
+			err:   `has no interface "xe-0/0/9"`,
+		},
+		"wrong_speed": {
+			links: []ServerOnboardingLink{{Row: 2, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/1", Speed: "25G", LogicalDeviceId: "ld"}},
+			err:   "is 10G, not 25G",
+		},
+		"speed_without_interface_map": {
+			links: []ServerOnboardingLink{{Row: 2, Hostname: "server1", LeafHostname: "leaf2", LeafInterface: "xe-0/0/1", Speed: "25G", LogicalDeviceId: "ld"}},
+			err:   "has no interface map",
+		},
+		"port_reuse": {
+			links: []ServerOnboardingLink{
+				{Row: 2, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/1", LogicalDeviceId: "ld"},
+				{Row: 3, Hostname: "server3", LeafHostname: "leaf1", LeafInterface: "xe-0/0/1", LogicalDeviceId: "ld"},
+			},
+			err: "already used in row 2",
+		},
+		"lag_mode_conflict": {
+			links: []ServerOnboardingLink{
+				{Row: 2, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/1", LagGroup: "bond0", LagMode: RackLinkLagModeActive, LogicalDeviceId: "ld"},
+				{Row: 3, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/2", LagGroup: "bond0", LagMode: RackLinkLagModeStatic},
+			},
+			err: "conflicting LAG modes",
+		},
+		"no_logical_device": {
+			links: []ServerOnboardingLink{{Row: 2, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/1"}},
+			err:   "requires a logical_device",
+		},
+		"logical_device_conflict": {
+			links: []ServerOnboardingLink{
+				{Row: 2, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/1", LogicalDeviceId: "ld1"},
+				{Row: 3, Hostname: "server1", LeafHostname: "leaf1", LeafInterface: "xe-0/0/2", LogicalDeviceId: "ld2"},
+			},
+			err: "conflicting logical devices",
+		},
+	}
+
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			_, err := planServerOnboarding(testServerOnboardingFabric(), tCase.links, false)
+			require.ErrorContains(t, err, tCase.err)
+		})
+	}
+}
@@ -70,12 +70,12 @@ func (o *TwoStageL3ClosSubinterface) UnmarshalJSON(bytes []byte) error {
 		return err
 	}
 
-	err = o.Ipv4AddrType.FromString(raw.Ipv4AddrType)
+	o.Ipv4AddrType, err = apiEnumFromString[enum.InterfaceNumberingIpv4Type](raw.Ipv4AddrType)
 	if err != nil {
 		return fmt.Errorf("failed parsing ipv4_addr_type %q while unmarshaling TwoStageL3ClosSubinterface", raw.Ipv4AddrType)
 	}
 
-	err = o.Ipv6AddrType.FromString(raw.Ipv6AddrType)
+	o.Ipv6AddrType, err = apiEnumFromString[enum.InterfaceNumberingIpv6Type](raw.Ipv6AddrType)
 	if err != nil {
 		return fmt.Errorf("failed parsing ipv6_addr_type %q while unmarshaling TwoStageL3ClosSubinterface", raw.Ipv6AddrType)
 	}
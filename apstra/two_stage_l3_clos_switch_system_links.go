@@ -53,6 +53,9 @@ func (o SystemType) String() string {
 	case SystemTypeServer:
 		return string(systemTypeServer)
 	default:
+		if s, ok := lenientEnumString[systemType](int(o)); ok {
+			return s
+		}
 		return fmt.Sprintf(systemTypeUnknown, o)
 	}
 }
@@ -76,6 +79,9 @@ func (o systemType) parse() (int, error) {
 	case systemTypeServer:
 		return int(SystemTypeServer), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(SystemTypeUnknown, o)
 	}
 }
@@ -0,0 +1,217 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	apiUrlBlueprintNodeConfigRendering   = apiUrlBlueprintNodeById + apiUrlPathDelim + "config-rendering"
+	apiUrlBlueprintNodeConfigIncremental = apiUrlBlueprintNodeById + apiUrlPathDelim + "config-incremental"
+	apiUrlSystemsPristineConfig          = apiUrlSystemsById + apiUrlPathDelim + "pristine-config"
+
+	renderedConfigVersionAttempts = 3
+)
+
+// RenderedConfig is configuration Apstra has rendered for a blueprint system
+// node from the staging blueprint: what Apstra will push on commit.
+type RenderedConfig struct {
+	NodeId           ObjectId
+	SystemId         *ObjectId // device key of the assigned device; nil when no device is assigned
+	BlueprintVersion int       // staging blueprint version the config was rendered from
+	Incremental      bool      // Config holds only the changes to be pushed, rather than the full config
+	Config           string
+}
+
+// ConfigDeviation compares the configuration Apstra expects a deployed
+// device to have with the device's running configuration.
+type ConfigDeviation struct {
+	NodeId         ObjectId
+	SystemId       ObjectId
+	Revision       uint32 // AOS config version of the expected config
+	Deviated       bool
+	ErrorMessage   *string
+	ExpectedConfig string
+	ActualConfig   string
+}
+
+// Diff returns the line-based differences between the expected and running
+// configurations.
+func (o *ConfigDeviation) Diff() ConfigDiff {
+	return DiffConfig(o.ExpectedConfig, o.ActualConfig)
+}
+
+// PristineConfig is the configuration a device had before Apstra managed it.
+// Apstra restores it when the device is removed from a blueprint.
+type PristineConfig struct {
+	NodeId   ObjectId
+	SystemId ObjectId
+	Config   string
+}
+
+// GetRenderedConfig returns the full configuration rendered for the system
+// node with the given ID.
+func (o *TwoStageL3ClosClient) GetRenderedConfig(ctx context.Context, nodeId ObjectId) (*RenderedConfig, error) {
+	return o.getRenderedConfig(ctx, nodeId, false)
+}
+
+// GetIncrementalConfig returns the configuration changes which would be
+// pushed to the system node with the given ID by committing the blueprint.
+func (o *TwoStageL3ClosClient) GetIncrementalConfig(ctx context.Context, nodeId ObjectId) (*RenderedConfig, error) {
+	return o.getRenderedConfig(ctx, nodeId, true)
+}
+
+func (o *TwoStageL3ClosClient) getRenderedConfig(ctx context.Context, nodeId ObjectId, incremental bool) (*RenderedConfig, error) {
+	urlFmt := apiUrlBlueprintNodeConfigRendering
+	if incremental {
+		urlFmt = apiUrlBlueprintNodeConfigIncremental
+	}
+
+	systemId, err := o.systemIdOfNode(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rendering API doesn't report the blueprint version, so we read it
+	// before and after rendering, and retry if the blueprint changed.
+	for attempt := 0; attempt < renderedConfigVersionAttempts; attempt++ {
+		before, err := o.client.getBlueprintStatus(ctx, o.blueprintId)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading blueprint %q version - %w", o.blueprintId, err)
+		}
+
+		var response struct {
+			Config string `json:"config"`
+		}
+		err = o.client.talkToApstra(ctx, &talkToApstraIn{
+			method:      http.MethodGet,
+			urlStr:      fmt.Sprintf(urlFmt, o.blueprintId, nodeId),
+			apiResponse: &response,
+		})
+		if err != nil {
+			return nil, convertTtaeToAceWherePossible(err)
+		}
+
+		after, err := o.client.getBlueprintStatus(ctx, o.blueprintId)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading blueprint %q version - %w", o.blueprintId, err)
+		}
+
+		if before.Version == after.Version {
+			return &RenderedConfig{
+				NodeId:           nodeId,
+				SystemId:         systemId,
+				BlueprintVersion: after.Version,
+				Incremental:      incremental,
+				Config:           response.Config,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("blueprint %q changed during each of %d attempts to render config for node %q",
+		o.blueprintId, renderedConfigVersionAttempts, nodeId)
+}
+
+// GetConfigDeviation compares the expected and running configuration of the
+// device assigned to the system node with the given ID.
+func (o *TwoStageL3ClosClient) GetConfigDeviation(ctx context.Context, nodeId ObjectId) (*ConfigDeviation, error) {
+	systemId, err := o.requireSystemIdOfNode(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	systemConfig, err := o.client.GetSystemConfig(ctx, systemId)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching configuration of system %q - %w", systemId, err)
+	}
+
+	return &ConfigDeviation{
+		NodeId:         nodeId,
+		SystemId:       systemId,
+		Revision:       systemConfig.AosConfigVersion,
+		Deviated:       systemConfig.Deviated,
+		ErrorMessage:   systemConfig.ErrorMessage,
+		ExpectedConfig: systemConfig.ExpectedConfig,
+		ActualConfig:   systemConfig.ActualConfig,
+	}, nil
+}
+
+// GetPristineConfig returns the pristine configuration of the device assigned
+// to the system node with the given ID.
+func (o *TwoStageL3ClosClient) GetPristineConfig(ctx context.Context, nodeId ObjectId) (*PristineConfig, error) {
+	systemId, err := o.requireSystemIdOfNode(ctx, nodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		PristineData []struct {
+			Content string `json:"content"`
+		} `json:"pristine_data"`
+	}
+	err = o.client.talkToApstra(ctx, &talkToApstraIn{
+		method:      http.MethodGet,
+		urlStr:      fmt.Sprintf(apiUrlSystemsPristineConfig, systemId),
+		apiResponse: &response,
+	})
+	if err != nil {
+		return nil, convertTtaeToAceWherePossible(err)
+	}
+
+	var sb strings.Builder
+	for _, data := range response.PristineData {
+		sb.WriteString(data.Content)
+	}
+
+	return &PristineConfig{
+		NodeId:   nodeId,
+		SystemId: systemId,
+		Config:   sb.String(),
+	}, nil
+}
+
+// systemIdOfNode returns the device key of the device assigned to the system
+// node, or nil if no device is assigned.
+func (o *TwoStageL3ClosClient) systemIdOfNode(ctx context.Context, nodeId ObjectId) (*ObjectId, error) {
+	var node struct {
+		Type     string    `json:"type"`
+		SystemId *ObjectId `json:"system_id"`
+	}
+	err := o.client.getNode(ctx, o.blueprintId, nodeId, &node)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching node %q - %w", nodeId, err)
+	}
+
+	if node.Type != string(nodeTypeSystem) {
+		return nil, fmt.Errorf("node %q has type %q, expected %q", nodeId, node.Type, nodeTypeSystem)
+	}
+
+	if node.SystemId != nil && *node.SystemId == "" {
+		return nil, nil
+	}
+
+	return node.SystemId, nil
+}
+
+// requireSystemIdOfNode is systemIdOfNode for callers which need a device.
+func (o *TwoStageL3ClosClient) requireSystemIdOfNode(ctx context.Context, nodeId ObjectId) (ObjectId, error) {
+	systemId, err := o.systemIdOfNode(ctx, nodeId)
+	if err != nil {
+		return "", err
+	}
+
+	if systemId == nil {
+		return "", ClientErr{
+			errType: ErrNotfound,
+			err:     fmt.Errorf("no device assigned to system node %q", nodeId),
+		}
+	}
+
+	return *systemId, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderedConfig(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bp := testBlueprintA(ctx, t, client.client)
+
+			nodeInfos, err := bp.GetAllSystemNodeInfos(ctx)
+			require.NoError(t, err)
+
+			var leafId ObjectId
+			for id, nodeInfo := range nodeInfos {
+				if nodeInfo.Role == SystemRoleLeaf {
+					leafId = id
+					break
+				}
+			}
+			require.NotEmpty(t, leafId)
+
+			log.Printf("testing GetRenderedConfig() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			full, err := bp.GetRenderedConfig(ctx, leafId)
+			require.NoError(t, err)
+			require.Equal(t, leafId, full.NodeId)
+			require.False(t, full.Incremental)
+			require.NotZero(t, full.BlueprintVersion)
+
+			log.Printf("testing GetIncrementalConfig() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			incremental, err := bp.GetIncrementalConfig(ctx, leafId)
+			require.NoError(t, err)
+			require.True(t, incremental.Incremental)
+
+			if full.SystemId == nil {
+				log.Printf("testing GetConfigDeviation() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+				_, err = bp.GetConfigDeviation(ctx, leafId)
+				var ace ClientErr
+				require.ErrorAs(t, err, &ace)
+				require.Equal(t, ErrNotfound, ace.Type())
+			}
+		})
+	}
+}
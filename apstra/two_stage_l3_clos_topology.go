@@ -0,0 +1,336 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TopologyFilter selects the systems included in a Topology. Empty fields
+// match every system. When more than one field is set, a system must satisfy
+// all of them. Tags matches systems with any of the listed tags. When
+// IncludeNeighbors is set, systems directly connected to a selected system
+// (e.g. the spines above a selected rack) are included as well.
+type TopologyFilter struct {
+	RackIds          []ObjectId
+	Roles            []SystemNodeRole
+	Tags             []string
+	IncludeNeighbors bool
+}
+
+func (o *TopologyFilter) match(node *TopologyNode) bool {
+	if o == nil {
+		return true
+	}
+
+	if len(o.RackIds) > 0 {
+		if node.RackId == nil {
+			return false
+		}
+		var found bool
+		for _, rackId := range o.RackIds {
+			if rackId == *node.RackId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(o.Roles) > 0 {
+		var found bool
+		for _, role := range o.Roles {
+			if role.String() == node.Role {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(o.Tags) > 0 {
+		var found bool
+		for _, tag := range o.Tags {
+			for _, nodeTag := range node.Tags {
+				if tag == nodeTag {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TopologyNode is a system in a Topology.
+type TopologyNode struct {
+	Id       ObjectId  `json:"id"`
+	Label    string    `json:"label"`
+	Hostname string    `json:"hostname,omitempty"`
+	Role     string    `json:"role"`
+	RackId   *ObjectId `json:"rack_id,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+}
+
+// TopologyLink is a physical link between two systems in a Topology. Member
+// links of a LAG share a non-empty AggregateLinkId.
+type TopologyLink struct {
+	Id              ObjectId `json:"id"`
+	Label           string   `json:"label,omitempty"`
+	Role            string   `json:"role"`
+	Speed           string   `json:"speed,omitempty"`
+	Source          ObjectId `json:"source"`
+	SourceInterface string   `json:"source_interface,omitempty"`
+	Target          ObjectId `json:"target"`
+	TargetInterface string   `json:"target_interface,omitempty"`
+	AggregateLinkId ObjectId `json:"aggregate_link_id,omitempty"`
+}
+
+// Topology is a neutral node-link representation of a blueprint's systems and
+// physical links. It marshals to JSON as {"nodes": [...], "links": [...]},
+// and can be rendered as Graphviz DOT with the Dot method.
+type Topology struct {
+	Nodes []TopologyNode `json:"nodes"`
+	Links []TopologyLink `json:"links"`
+}
+
+// topologyTiers orders system roles from the top of a drawing to the bottom.
+var topologyTiers = []SystemNodeRole{
+	SystemNodeRoleSuperspine,
+	SystemNodeRoleSpine,
+	SystemNodeRoleLeaf,
+	SystemNodeRoleAccess,
+	SystemNodeRoleGeneric,
+	SystemNodeRoleL3Server,
+	SystemNodeRoleRemoteGateway,
+}
+
+func topologyTier(role string) int {
+	for i, tier := range topologyTiers {
+		if tier.String() == role {
+			return i
+		}
+	}
+	return len(topologyTiers)
+}
+
+// GetTopology returns a Topology describing the systems selected by filter
+// (nil selects all systems) and the physical links between them.
+func (o *TwoStageL3ClosClient) GetTopology(ctx context.Context, filter *TopologyFilter) (*Topology, error) {
+	links, err := o.GetCablingMapLinks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching cabling map - %w", err)
+	}
+
+	systems, err := o.GetAllSystemNodeInfos(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching system node info - %w", err)
+	}
+
+	return newTopology(links, systems, filter), nil
+}
+
+func newTopology(links []CablingMapLink, systems map[ObjectId]SystemNodeInfo, filter *TopologyFilter) *Topology {
+	nodes := make(map[ObjectId]TopologyNode, len(systems))
+	for id, system := range systems {
+		if system.Role == SystemRoleRedundancyGroup {
+			continue // logical construct, not something we can draw a cable to
+		}
+		nodes[id] = TopologyNode{
+			Id:       id,
+			Label:    system.Label,
+			Hostname: system.Hostname,
+			Role:     system.Role.String(),
+			RackId:   system.RackId,
+			Tags:     system.Tags,
+		}
+	}
+
+	var topologyLinks []TopologyLink
+	for _, link := range links {
+		if link.Type != LinkTypeEthernet || len(link.Endpoints) != 2 ||
+			link.Endpoints[0].System == nil || link.Endpoints[1].System == nil {
+			continue // aggregate and logical links are represented by their members
+		}
+
+		a, b := link.Endpoints[0], link.Endpoints[1]
+		if _, ok := nodes[a.System.Id]; !ok {
+			continue
+		}
+		if _, ok := nodes[b.System.Id]; !ok {
+			continue
+		}
+
+		// the higher tier system is always the link source
+		if topologyTier(nodes[b.System.Id].Role) < topologyTier(nodes[a.System.Id].Role) {
+			a, b = b, a
+		}
+
+		topologyLinks = append(topologyLinks, TopologyLink{
+			Id:              link.Id,
+			Label:           link.Label,
+			Role:            link.Role.String(),
+			Speed:           string(link.Speed),
+			Source:          a.System.Id,
+			SourceInterface: topologyIfName(a),
+			Target:          b.System.Id,
+			TargetInterface: topologyIfName(b),
+			AggregateLinkId: link.AggregateLinkId,
+		})
+	}
+
+	selected := make(map[ObjectId]bool, len(nodes))
+	for id, node := range nodes {
+		node := node
+		selected[id] = filter.match(&node)
+	}
+
+	if filter != nil && filter.IncludeNeighbors {
+		neighbors := make(map[ObjectId]bool)
+		for _, link := range topologyLinks {
+			if selected[link.Source] {
+				neighbors[link.Target] = true
+			}
+			if selected[link.Target] {
+				neighbors[link.Source] = true
+			}
+		}
+		for id := range neighbors {
+			selected[id] = true
+		}
+	}
+
+	var result Topology
+	for id, node := range nodes {
+		if selected[id] {
+			result.Nodes = append(result.Nodes, node)
+		}
+	}
+	for _, link := range topologyLinks {
+		if selected[link.Source] && selected[link.Target] {
+			result.Links = append(result.Links, link)
+		}
+	}
+
+	// produce stable output for use in change-review artifacts
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		ti, tj := topologyTier(result.Nodes[i].Role), topologyTier(result.Nodes[j].Role)
+		if ti != tj {
+			return ti < tj
+		}
+		if result.Nodes[i].Label != result.Nodes[j].Label {
+			return result.Nodes[i].Label < result.Nodes[j].Label
+		}
+		return result.Nodes[i].Id < result.Nodes[j].Id
+	})
+	sort.Slice(result.Links, func(i, j int) bool {
+		li, lj := result.Links[i], result.Links[j]
+		if li.Source != lj.Source {
+			return li.Source < lj.Source
+		}
+		if li.SourceInterface != lj.SourceInterface {
+			return li.SourceInterface < lj.SourceInterface
+		}
+		return li.Id < lj.Id
+	})
+
+	return &result
+}
+
+func topologyIfName(endpoint CablingMapLinkEndpoint) string {
+	if endpoint.Interface == nil || endpoint.Interface.IfName == nil {
+		return ""
+	}
+	return *endpoint.Interface.IfName
+}
+
+// Dot renders the topology in the Graphviz DOT language. Systems are ranked
+// in tiers by role (superspine, spine, leaf, access, generic), member links
+// of each LAG are drawn as a single bold edge, and edges are labeled with
+// interface names.
+func (o *Topology) Dot() string {
+	var sb strings.Builder
+
+	sb.WriteString("graph topology {\n")
+	sb.WriteString("  rankdir=TB;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	// nodes, grouped into tiers
+	tiers := make(map[int][]TopologyNode)
+	for _, node := range o.Nodes {
+		tier := topologyTier(node.Role)
+		tiers[tier] = append(tiers[tier], node)
+	}
+	for tier := 0; tier <= len(topologyTiers); tier++ {
+		if len(tiers[tier]) == 0 {
+			continue
+		}
+		sb.WriteString("  {\n    rank=same;\n")
+		for _, node := range tiers[tier] {
+			label := node.Label
+			if node.Hostname != "" && node.Hostname != node.Label {
+				label += "\n" + node.Hostname
+			}
+			sb.WriteString(fmt.Sprintf("    %s [label=%s, role=%s];\n", dotQuote(node.Id.String()), dotQuote(label), dotQuote(node.Role)))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	// links, with LAG members between the same pair of systems collapsed into one edge
+	type lagKey struct {
+		id     ObjectId
+		source ObjectId
+		target ObjectId
+	}
+	lags := make(map[lagKey][]TopologyLink)
+	var lagKeys []lagKey
+	for _, link := range o.Links {
+		if link.AggregateLinkId == "" {
+			sb.WriteString(fmt.Sprintf("  %s -- %s [label=%s];\n",
+				dotQuote(link.Source.String()), dotQuote(link.Target.String()),
+				dotQuote(link.SourceInterface+" - "+link.TargetInterface)))
+			continue
+		}
+
+		key := lagKey{id: link.AggregateLinkId, source: link.Source, target: link.Target}
+		if _, ok := lags[key]; !ok {
+			lagKeys = append(lagKeys, key)
+		}
+		lags[key] = append(lags[key], link)
+	}
+
+	for _, key := range lagKeys {
+		labels := make([]string, len(lags[key]))
+		for i, link := range lags[key] {
+			labels[i] = link.SourceInterface + " - " + link.TargetInterface
+		}
+		sb.WriteString(fmt.Sprintf("  %s -- %s [label=%s, style=bold, lag=%s];\n",
+			dotQuote(key.source.String()), dotQuote(key.target.String()),
+			dotQuote(strings.Join(labels, "\n")), dotQuote(key.id.String())))
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// dotQuote renders s as a quoted DOT identifier
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
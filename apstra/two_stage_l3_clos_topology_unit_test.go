@@ -0,0 +1,97 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopology(t *testing.T) {
+	rack1 := ObjectId("rack1")
+	rack2 := ObjectId("rack2")
+
+	systems := map[ObjectId]SystemNodeInfo{
+		"spine1":  {Id: "spine1", Label: "spine_1", Role: SystemRoleSpine},
+		"leaf1":   {Id: "leaf1", Label: "leaf_1", Role: SystemRoleLeaf, RackId: &rack1, Tags: []string{"prod"}},
+		"leaf2":   {Id: "leaf2", Label: "leaf_2", Role: SystemRoleLeaf, RackId: &rack2},
+		"server1": {Id: "server1", Label: "server_1", Role: SystemRoleGeneric, RackId: &rack1},
+		"rg1":     {Id: "rg1", Label: "rg", Role: SystemRoleRedundancyGroup},
+	}
+
+	link := func(id ObjectId, aSys ObjectId, aIf string, bSys ObjectId, bIf string, lag ObjectId) CablingMapLink {
+		endpoint := func(sys ObjectId, ifName string) CablingMapLinkEndpoint {
+			return CablingMapLinkEndpoint{
+				Interface: &CablingMapLinkEndpointInterface{IfName: &ifName},
+				System:    &CablingMapLinkEndpointSystem{Id: sys},
+			}
+		}
+		return CablingMapLink{
+			Id:              id,
+			Type:            LinkTypeEthernet,
+			AggregateLinkId: lag,
+			Endpoints:       []CablingMapLinkEndpoint{endpoint(aSys, aIf), endpoint(bSys, bIf)},
+		}
+	}
+
+	links := []CablingMapLink{
+		link("l1", "leaf1", "xe-0/0/0", "spine1", "xe-0/0/0", ""), // reversed; spine should become source
+		link("l2", "spine1", "xe-0/0/1", "leaf2", "xe-0/0/0", ""),
+		link("l3", "leaf1", "xe-0/0/10", "server1", "eth0", "lag1"),
+		link("l4", "leaf1", "xe-0/0/11", "server1", "eth1", "lag1"),
+		{Id: "lag1", Type: LinkTypeAggregateLink},
+	}
+
+	t.Run("all", func(t *testing.T) {
+		topology := newTopology(links, systems, nil)
+		require.Len(t, topology.Nodes, 4)
+		require.Equal(t, ObjectId("spine1"), topology.Nodes[0].Id)
+		require.Len(t, topology.Links, 4)
+
+		for _, l := range topology.Links {
+			if l.Id == "l1" {
+				require.Equal(t, ObjectId("spine1"), l.Source)
+				require.Equal(t, ObjectId("leaf1"), l.Target)
+			}
+		}
+
+		dot := topology.Dot()
+		require.True(t, strings.HasPrefix(dot, "graph topology {"))
+		require.Contains(t, dot, `"spine1" -- "leaf1" [label="xe-0/0/0 - xe-0/0/0"];`)
+		require.Contains(t, dot, `"leaf1" -- "server1" [label="xe-0/0/10 - eth0\nxe-0/0/11 - eth1", style=bold, lag="lag1"];`)
+
+		j, err := json.Marshal(topology)
+		require.NoError(t, err)
+		require.Contains(t, string(j), `"nodes":[`)
+		require.Contains(t, string(j), `"links":[`)
+	})
+
+	t.Run("rack", func(t *testing.T) {
+		topology := newTopology(links, systems, &TopologyFilter{RackIds: []ObjectId{rack1}})
+		require.Len(t, topology.Nodes, 2)
+		require.Len(t, topology.Links, 2)
+	})
+
+	t.Run("rack_with_neighbors", func(t *testing.T) {
+		topology := newTopology(links, systems, &TopologyFilter{RackIds: []ObjectId{rack1}, IncludeNeighbors: true})
+		require.Len(t, topology.Nodes, 3)
+		require.Len(t, topology.Links, 3)
+	})
+
+	t.Run("role", func(t *testing.T) {
+		topology := newTopology(links, systems, &TopologyFilter{Roles: []SystemNodeRole{SystemNodeRoleSpine, SystemNodeRoleLeaf}})
+		require.Len(t, topology.Nodes, 3)
+		require.Len(t, topology.Links, 2)
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		topology := newTopology(links, systems, &TopologyFilter{Tags: []string{"prod"}})
+		require.Len(t, topology.Nodes, 1)
+		require.Empty(t, topology.Links)
+	})
+}
@@ -8,8 +8,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-
-	"github.com/Juniper/apstra-go-sdk/apstra/compatibility"
 )
 
 const (
@@ -52,8 +50,8 @@ func (o *TwoStageL3ClosClient) getVirtualNetworkPolicy420(ctx context.Context) (
 }
 
 func (o *TwoStageL3ClosClient) setVirtualNetworkPolicy420(ctx context.Context, in *rawFabricSettings) error {
-	if !compatibility.EqApstra420.Check(o.client.apiVersion) {
-		return fmt.Errorf("setRawVirtualNetworkPolicy420() must not be invoked with apstra %s", o.client.apiVersion)
+	if err := o.client.checkSupports("TwoStageL3ClosClient.SetFabricSettings.PolicyNodes"); err != nil {
+		return err
 	}
 
 	apiInput := rawVirtualNetworkPolicy420{
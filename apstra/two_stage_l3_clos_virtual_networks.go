@@ -6,6 +6,7 @@ package apstra
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -100,6 +101,9 @@ func (o SviIpRequirement) raw() sviIpRequirement {
 	case SviIpRequirementIntentionConflict:
 		return sviIpRequirementIntentionConflict
 	default:
+		if s, ok := lenientEnumString[sviIpRequirement](int(o)); ok {
+			return sviIpRequirement(s)
+		}
 		return sviIpRequirement(fmt.Sprintf(sviIpRequirementUnknown, o))
 	}
 }
@@ -121,6 +125,9 @@ func (o sviIpRequirement) parse() (int, error) {
 	case sviIpRequirementIntentionConflict:
 		return int(SviIpRequirementIntentionConflict), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(SviIpRequirementUnknown, o)
 	}
 }
@@ -163,6 +170,9 @@ func (o Ipv4Mode) raw() ipv4Mode {
 	case Ipv4ModeForced:
 		return ipv4ModeForced
 	default:
+		if s, ok := lenientEnumString[ipv4Mode](int(o)); ok {
+			return ipv4Mode(s)
+		}
 		return ipv4Mode(fmt.Sprintf(ipv4ModeUnknown, o))
 	}
 }
@@ -182,6 +192,9 @@ func (o ipv4Mode) parse() (int, error) {
 	case ipv4ModeForced:
 		return int(Ipv4ModeForced), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(Ipv4ModeUnknown, o)
 	}
 }
@@ -228,6 +241,9 @@ func (o Ipv6Mode) raw() ipv6Mode {
 	case Ipv6ModeForced:
 		return ipv6ModeForced
 	default:
+		if s, ok := lenientEnumString[ipv6Mode](int(o)); ok {
+			return ipv6Mode(s)
+		}
 		return ipv6Mode(fmt.Sprintf(ipv6ModeUnknown, o))
 	}
 }
@@ -249,6 +265,9 @@ func (o ipv6Mode) parse() (int, error) {
 	case ipv6ModeForced:
 		return int(Ipv6ModeForced), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(Ipv6ModeUnknown, o)
 	}
 }
@@ -279,7 +298,7 @@ func (o VnType) int() int {
 }
 
 func (o *VnType) FromString(s string) error {
-	i, err := vnType(s).parse()
+	i, err := parseKnown(vnType(s))
 	if err != nil {
 		return err
 	}
@@ -296,6 +315,9 @@ func (o VnType) raw() vnType {
 	case VnTypeVxlan:
 		return vnTypeVxlan
 	default:
+		if s, ok := lenientEnumString[vnType](int(o)); ok {
+			return vnType(s)
+		}
 		return vnType(fmt.Sprintf(vnTypeUnknown, o))
 	}
 }
@@ -313,6 +335,9 @@ func (o vnType) parse() (int, error) {
 	case vnTypeVxlan:
 		return int(VnTypeVxlan), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(VnTypeUnknown, o)
 	}
 }
@@ -383,12 +408,15 @@ func (o SystemRole) raw() systemRole {
 	case SystemRoleRedundancyGroup:
 		return systemRoleRedundancyGroup
 	default:
+		if s, ok := lenientEnumString[systemRole](int(o)); ok {
+			return systemRole(s)
+		}
 		return systemRole(fmt.Sprintf(systemRoleUnknown, o))
 	}
 }
 
 func (o *SystemRole) FromString(in string) error {
-	i, err := systemRole(in).parse()
+	i, err := parseKnown(systemRole(in))
 	if err != nil {
 		return err
 	}
@@ -417,6 +445,9 @@ func (o systemRole) parse() (int, error) {
 	case systemRoleRedundancyGroup:
 		return int(SystemRoleRedundancyGroup), nil
 	default:
+		if i, ok := lenientEnumCode(o); ok {
+			return i, nil
+		}
 		return 0, fmt.Errorf(SystemRoleUnknown, o)
 	}
 }
@@ -558,6 +589,8 @@ type VirtualNetworkData struct {
 	VnId                      *VNI
 	VnType                    VnType
 	VirtualMac                net.HardwareAddr
+
+	unknownFields unknownJsonFields // see ClientCfg.DropUnknownJsonFields
 }
 
 func (o *VirtualNetworkData) raw() *rawVirtualNetwork {
@@ -648,6 +681,31 @@ type rawVirtualNetwork struct {
 	// L3Connectivity          *l3ConnectivityMode `json:"l3_connectivity,omitempty"` // does not appear in 4.1.2 swagger
 	// VniIds                  []interface{}   `json:"vni_ids,omitempty"`             // unknown, sent by web UI as empty list
 	// Endpoints               []interface{}   `json:"endpoints"`                     // unknown, maybe relates to servers, etc?
+	unknownFields unknownJsonFields
+}
+
+// rawVirtualNetworkReadOnlyFields are virtual network members which the API
+// reports, but which must not be sent back to it.
+var rawVirtualNetworkReadOnlyFields = []string{
+	"default_endpoint_tag_types",
+	"endpoints",
+	"floating_ips",
+}
+
+func (o *rawVirtualNetwork) UnmarshalJSON(b []byte) error {
+	type alias rawVirtualNetwork
+	err := json.Unmarshal(b, (*alias)(o))
+	if err != nil {
+		return err
+	}
+
+	o.unknownFields, err = unmarshalUnknownJsonFields(b, alias{}, rawVirtualNetworkReadOnlyFields...)
+	return err
+}
+
+func (o rawVirtualNetwork) MarshalJSON() ([]byte, error) {
+	type alias rawVirtualNetwork
+	return marshalWithUnknownJsonFields(alias(o), o.unknownFields)
 }
 
 func (o rawVirtualNetwork) polish() (*VirtualNetwork, error) {
@@ -730,6 +788,7 @@ func (o rawVirtualNetwork) polish() (*VirtualNetwork, error) {
 			VnId:                      vnId,
 			VnType:                    VnType(vntype),
 			VirtualMac:                virtualMac,
+			unknownFields:             o.unknownFields,
 		},
 	}, nil
 }
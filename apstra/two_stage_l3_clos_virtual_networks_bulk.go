@@ -0,0 +1,466 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+const (
+	bulkVirtualNetworkDefaultConcurrency = 4
+	bulkVirtualNetworkDefaultPrefixLen   = 24
+)
+
+// VlanRange is an inclusive range of VLAN IDs
+type VlanRange struct {
+	First Vlan
+	Last  Vlan
+}
+
+func (o VlanRange) validate() error {
+	if err := o.First.validate(); err != nil {
+		return err
+	}
+	if err := o.Last.validate(); err != nil {
+		return err
+	}
+	if o.First > o.Last {
+		return fmt.Errorf("VLAN range %d-%d is backwards", o.First, o.Last)
+	}
+	return nil
+}
+
+// BulkVirtualNetworkRequest describes virtual networks to be created by
+// CreateVirtualNetworks. Values missing from each VirtualNetworkData are
+// planned, avoiding values already in use:
+//   - VLAN IDs for bindings without one come from the VlanRanges entry for
+//     the virtual network's routing zone. Each virtual network gets a single
+//     VLAN ID for all of its bindings. VLAN IDs in use in the blueprint are
+//     avoided.
+//   - VNIs for VXLAN virtual networks without one come from VniPoolId.
+//   - IPv4 subnets of size Ipv4PrefixLen (0/default: /24) for IPv4-enabled
+//     virtual networks without one are carved from the subnets of Ipv4PoolId.
+//
+// Pools are shared between blueprints, so VNIs and IPv4 subnets in use by
+// any datacenter blueprint are avoided, as are pool ranges and subnets which
+// the pool reports as fully used.
+//
+// Concurrency limits simultaneous create calls (0/default: 4). When
+// RollbackOnError is set, any failure stops further creation and deletes the
+// virtual networks which were created.
+type BulkVirtualNetworkRequest struct {
+	VirtualNetworks []VirtualNetworkData
+	VlanRanges      map[ObjectId]VlanRange // keyed by routing zone ID
+	VniPoolId       ObjectId
+	Ipv4PoolId      ObjectId
+	Ipv4PrefixLen   int
+	Concurrency     int
+	RollbackOnError bool
+}
+
+// BulkVirtualNetworkResult is the outcome for one virtual network in a
+// BulkVirtualNetworkRequest.
+type BulkVirtualNetworkResult struct {
+	Id          ObjectId            // set when the virtual network was created
+	Data        *VirtualNetworkData // as planned; nil if planning failed
+	Err         error               // planning or creation error
+	RolledBack  bool                // the virtual network was created, then deleted
+	RollbackErr error               // the virtual network was created, and couldn't be deleted
+}
+
+var errBulkVirtualNetworkSkipped = errors.New("not attempted because an earlier virtual network failed")
+
+// CreateVirtualNetworks plans and creates virtual networks in bulk. Results
+// are returned in the order of in.VirtualNetworks. Failures of individual
+// virtual networks are reported in their results, and summarized by the
+// returned error. An error with no results means nothing was attempted.
+func (o *TwoStageL3ClosClient) CreateVirtualNetworks(ctx context.Context, in *BulkVirtualNetworkRequest) ([]BulkVirtualNetworkResult, error) {
+	planner, err := o.newVnPlanner(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkVirtualNetworkResult, len(in.VirtualNetworks))
+	for i := range in.VirtualNetworks {
+		results[i].Data, results[i].Err = planner.plan(&in.VirtualNetworks[i])
+	}
+
+	if in.RollbackOnError && bulkVirtualNetworkFailures(results) > 0 {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = errBulkVirtualNetworkSkipped
+			}
+		}
+		return results, bulkVirtualNetworkError(results)
+	}
+
+	concurrency := in.Concurrency
+	if concurrency <= 0 {
+		concurrency = bulkVirtualNetworkDefaultConcurrency
+	}
+
+	var failed bool
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+
+		sem <- struct{}{}
+
+		mu.Lock()
+		skip := in.RollbackOnError && failed
+		mu.Unlock()
+		if skip {
+			<-sem
+			results[i].Err = errBulkVirtualNetworkSkipped
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *BulkVirtualNetworkResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result.Id, result.Err = o.CreateVirtualNetwork(ctx, result.Data)
+			if result.Err != nil {
+				result.Err = fmt.Errorf("failed creating virtual network %q - %w", result.Data.Label, result.Err)
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+
+	if in.RollbackOnError && failed {
+		// delete even if the caller's context has been cancelled
+		rollbackCtx := context.WithoutCancel(ctx)
+		for i := range results {
+			if results[i].Id == "" {
+				continue
+			}
+			results[i].RollbackErr = o.DeleteVirtualNetwork(rollbackCtx, results[i].Id)
+			results[i].RolledBack = results[i].RollbackErr == nil
+		}
+	}
+
+	return results, bulkVirtualNetworkError(results)
+}
+
+func bulkVirtualNetworkFailures(results []BulkVirtualNetworkResult) int {
+	var count int
+	for _, result := range results {
+		if result.Err != nil && !errors.Is(result.Err, errBulkVirtualNetworkSkipped) {
+			count++
+		}
+	}
+	return count
+}
+
+func bulkVirtualNetworkError(results []BulkVirtualNetworkResult) error {
+	var firstErr error
+	var rollbackFailures int
+	for _, result := range results {
+		if firstErr == nil && result.Err != nil && !errors.Is(result.Err, errBulkVirtualNetworkSkipped) {
+			firstErr = result.Err
+		}
+		if result.RollbackErr != nil {
+			rollbackFailures++
+		}
+	}
+
+	if firstErr == nil {
+		return nil
+	}
+
+	if rollbackFailures > 0 {
+		return fmt.Errorf("failed creating %d of %d virtual networks, and failed rolling back %d - %w",
+			bulkVirtualNetworkFailures(results), len(results), rollbackFailures, firstErr)
+	}
+
+	return fmt.Errorf("failed creating %d of %d virtual networks - %w",
+		bulkVirtualNetworkFailures(results), len(results), firstErr)
+}
+
+// vnPlanner assigns VLAN IDs, VNIs and IPv4 subnets to virtual networks,
+// avoiding values in use and values it has already assigned.
+type vnPlanner struct {
+	vlanRanges    map[ObjectId]VlanRange
+	vniRanges     []IntRangeRequest
+	ipv4Subnets   []*net.IPNet
+	ipv4PrefixLen int
+
+	usedVlans   map[Vlan]struct{}
+	usedVnis    map[VNI]struct{}
+	usedSubnets []*net.IPNet
+}
+
+func (o *TwoStageL3ClosClient) newVnPlanner(ctx context.Context, in *BulkVirtualNetworkRequest) (*vnPlanner, error) {
+	for szId, vlanRange := range in.VlanRanges {
+		if err := vlanRange.validate(); err != nil {
+			return nil, fmt.Errorf("invalid VLAN range for routing zone %q - %w", szId, err)
+		}
+	}
+
+	result := &vnPlanner{
+		vlanRanges:    in.VlanRanges,
+		ipv4PrefixLen: in.Ipv4PrefixLen,
+		usedVlans:     make(map[Vlan]struct{}),
+		usedVnis:      make(map[VNI]struct{}),
+	}
+	if result.ipv4PrefixLen == 0 {
+		result.ipv4PrefixLen = bulkVirtualNetworkDefaultPrefixLen
+	}
+	if result.ipv4PrefixLen < 1 || result.ipv4PrefixLen > 32 {
+		return nil, fmt.Errorf("invalid IPv4 prefix length %d", result.ipv4PrefixLen)
+	}
+
+	if in.VniPoolId != "" {
+		pool, err := o.client.GetVniPool(ctx, in.VniPoolId)
+		if err != nil {
+			return nil, fmt.Errorf("failed fetching VNI pool %q - %w", in.VniPoolId, err)
+		}
+		for _, r := range pool.Ranges {
+			if r.Total > 0 && r.Used >= r.Total {
+				continue // fully allocated
+			}
+			result.vniRanges = append(result.vniRanges, IntRangeRequest{First: r.First, Last: r.Last})
+		}
+	}
+
+	if in.Ipv4PoolId != "" {
+		pool, err := o.client.GetIp4Pool(ctx, in.Ipv4PoolId)
+		if err != nil {
+			return nil, fmt.Errorf("failed fetching IPv4 pool %q - %w", in.Ipv4PoolId, err)
+		}
+		for _, subnet := range pool.Subnets {
+			if subnet.Total.Sign() > 0 && subnet.Used.Cmp(&subnet.Total) >= 0 {
+				continue // fully allocated
+			}
+			result.ipv4Subnets = append(result.ipv4Subnets, subnet.Network)
+		}
+	}
+
+	inventory, err := o.GetRoutingZoneInventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.reserveInventory(inventory, true)
+
+	if in.VniPoolId != "" || in.Ipv4PoolId != "" {
+		err = o.reserveOtherBlueprints(ctx, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// values specified by the caller are in use, regardless of their position
+	for i := range in.VirtualNetworks {
+		result.reserve(&in.VirtualNetworks[i])
+	}
+
+	return result, nil
+}
+
+// reserveOtherBlueprints marks the VNIs and IPv4 subnets of the routing zones
+// and virtual networks in the other datacenter blueprints as in use. VLAN IDs
+// are local to each blueprint.
+func (o *TwoStageL3ClosClient) reserveOtherBlueprints(ctx context.Context, planner *vnPlanner) error {
+	statuses, err := o.client.GetAllBlueprintStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching blueprints - %w", err)
+	}
+
+	for _, status := range statuses {
+		if status.Design != RefDesignTwoStageL3Clos || status.Id == o.blueprintId {
+			continue
+		}
+
+		bp, err := o.client.NewTwoStageL3ClosClient(ctx, status.Id)
+		if err != nil {
+			return fmt.Errorf("failed creating client for blueprint %q - %w", status.Id, err)
+		}
+
+		inventory, err := bp.GetRoutingZoneInventory(ctx)
+		if err != nil {
+			return err
+		}
+		planner.reserveInventory(inventory, false)
+	}
+
+	return nil
+}
+
+// reserveInventory marks the values used by the routing zones and virtual
+// networks of a blueprint as in use. VLAN IDs are only reserved when vlans is
+// set.
+func (o *vnPlanner) reserveInventory(inventory *RoutingZoneInventory, vlans bool) {
+	for _, vn := range inventory.VirtualNetworks {
+		if vlans {
+			o.reserve(vn.Data)
+		} else {
+			o.reservePoolValues(vn.Data)
+		}
+	}
+
+	for _, sz := range inventory.SecurityZones {
+		if vlans && sz.Data.VlanId != nil {
+			o.usedVlans[*sz.Data.VlanId] = struct{}{}
+		}
+		if sz.Data.VniId != nil {
+			o.usedVnis[VNI(*sz.Data.VniId)] = struct{}{}
+		}
+	}
+}
+
+// reserve marks the VLAN IDs, VNI and IPv4 subnet of the virtual network as in use
+func (o *vnPlanner) reserve(vn *VirtualNetworkData) {
+	if vn.ReservedVlanId != nil {
+		o.usedVlans[*vn.ReservedVlanId] = struct{}{}
+	}
+	for _, binding := range vn.VnBindings {
+		if binding.VlanId != nil {
+			o.usedVlans[*binding.VlanId] = struct{}{}
+		}
+	}
+	o.reservePoolValues(vn)
+}
+
+// reservePoolValues marks the VNI and IPv4 subnet of the virtual network,
+// which come from pools shared between blueprints, as in use
+func (o *vnPlanner) reservePoolValues(vn *VirtualNetworkData) {
+	if vn.VnId != nil {
+		o.usedVnis[*vn.VnId] = struct{}{}
+	}
+	if vn.Ipv4Subnet != nil {
+		o.usedSubnets = append(o.usedSubnets, vn.Ipv4Subnet)
+	}
+}
+
+// plan returns a copy of vn with missing VLAN IDs, VNI and IPv4 subnet filled in
+func (o *vnPlanner) plan(vn *VirtualNetworkData) (*VirtualNetworkData, error) {
+	result := *vn
+	result.VnBindings = append([]VnBinding(nil), vn.VnBindings...)
+
+	var needVlan bool
+	for _, binding := range result.VnBindings {
+		needVlan = needVlan || binding.VlanId == nil
+	}
+
+	// allocate everything before reserving anything, so a failure leaks nothing
+	var vlan *Vlan
+	if needVlan {
+		vlanRange, ok := o.vlanRanges[vn.SecurityZoneId]
+		if !ok {
+			return nil, fmt.Errorf("virtual network %q needs a VLAN ID, and no VLAN range was specified for routing zone %q", vn.Label, vn.SecurityZoneId)
+		}
+		vlan = o.nextVlan(vlanRange)
+		if vlan == nil {
+			return nil, fmt.Errorf("virtual network %q needs a VLAN ID, and VLAN range %d-%d is exhausted", vn.Label, vlanRange.First, vlanRange.Last)
+		}
+	}
+
+	var vni *VNI
+	if result.VnId == nil && result.VnType == VnTypeVxlan {
+		if len(o.vniRanges) == 0 {
+			return nil, fmt.Errorf("virtual network %q needs a VNI, and no VNI pool was specified", vn.Label)
+		}
+		vni = o.nextVni()
+		if vni == nil {
+			return nil, fmt.Errorf("virtual network %q needs a VNI, and the VNI pool is exhausted", vn.Label)
+		}
+	}
+
+	var subnet *net.IPNet
+	if result.Ipv4Subnet == nil && result.Ipv4Enabled {
+		if len(o.ipv4Subnets) == 0 {
+			return nil, fmt.Errorf("virtual network %q needs an IPv4 subnet, and no IPv4 pool was specified", vn.Label)
+		}
+		subnet = o.nextSubnet()
+		if subnet == nil {
+			return nil, fmt.Errorf("virtual network %q needs an IPv4 subnet, and the IPv4 pool has no free /%d", vn.Label, o.ipv4PrefixLen)
+		}
+	}
+
+	for i := range result.VnBindings {
+		if result.VnBindings[i].VlanId == nil {
+			result.VnBindings[i].VlanId = vlan
+		}
+	}
+	if vni != nil {
+		result.VnId = vni
+	}
+	if subnet != nil {
+		result.Ipv4Subnet = subnet
+	}
+
+	o.reserve(&result)
+	return &result, nil
+}
+
+func (o *vnPlanner) nextVlan(vlanRange VlanRange) *Vlan {
+	for v := int(vlanRange.First); v <= int(vlanRange.Last); v++ {
+		vlan := Vlan(v)
+		if _, ok := o.usedVlans[vlan]; !ok {
+			return &vlan
+		}
+	}
+	return nil
+}
+
+func (o *vnPlanner) nextVni() *VNI {
+	for _, r := range o.vniRanges {
+		for v := uint64(r.First); v <= uint64(r.Last); v++ {
+			vni := VNI(v)
+			if _, ok := o.usedVnis[vni]; !ok {
+				return &vni
+			}
+		}
+	}
+	return nil
+}
+
+func (o *vnPlanner) nextSubnet() *net.IPNet {
+	mask := net.CIDRMask(o.ipv4PrefixLen, 32)
+	step := uint64(1) << (32 - o.ipv4PrefixLen)
+
+	for _, poolSubnet := range o.ipv4Subnets {
+		base := poolSubnet.IP.To4()
+		ones, bits := poolSubnet.Mask.Size()
+		if base == nil || bits != 32 || ones > o.ipv4PrefixLen {
+			continue // IPv6, or smaller than the requested subnet size
+		}
+
+		first := uint64(binary.BigEndian.Uint32(base))
+		last := first + uint64(1)<<(32-ones)
+		for start := first; start < last; start += step {
+			ip := make(net.IP, net.IPv4len)
+			binary.BigEndian.PutUint32(ip, uint32(start))
+			candidate := &net.IPNet{IP: ip, Mask: mask}
+			if !o.subnetUsed(candidate) {
+				return candidate
+			}
+		}
+	}
+
+	return nil
+}
+
+func (o *vnPlanner) subnetUsed(candidate *net.IPNet) bool {
+	for _, used := range o.usedSubnets {
+		if used.Contains(candidate.IP) || candidate.Contains(used.IP) {
+			return true
+		}
+	}
+	return false
+}
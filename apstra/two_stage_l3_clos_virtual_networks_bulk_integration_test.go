@@ -0,0 +1,96 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateVirtualNetworks(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bp := testBlueprintA(ctx, t, client.client)
+			szId := testSecurityZone(t, ctx, bp)
+			vniPoolId := testVniPool(ctx, t, client.client)
+
+			poolSubnet := randomPrefix(t, "10.0.0.0/8", 22)
+			ipPoolId, err := client.client.CreateIp4Pool(ctx, &NewIpPoolRequest{
+				DisplayName: "test-" + randString(6, "hex"),
+				Subnets:     []NewIpSubnet{{Network: poolSubnet.String()}},
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, client.client.DeleteIp4Pool(ctx, ipPoolId)) })
+
+			bindings, err := bp.ResolveVnBindings(ctx, nil, VnBindingSelector{})
+			require.NoError(t, err)
+
+			vns := make([]VirtualNetworkData, 3)
+			for i := range vns {
+				vns[i] = VirtualNetworkData{
+					Ipv4Enabled:               true,
+					Label:                     randString(6, "hex"),
+					SecurityZoneId:            szId,
+					VirtualGatewayIpv4Enabled: true,
+					VnBindings:                bindings,
+					VnType:                    VnTypeVxlan,
+				}
+			}
+
+			log.Printf("testing CreateVirtualNetworks() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			results, err := bp.CreateVirtualNetworks(ctx, &BulkVirtualNetworkRequest{
+				VirtualNetworks: vns,
+				VlanRanges:      map[ObjectId]VlanRange{szId: {First: 3000, Last: 3099}},
+				VniPoolId:       vniPoolId,
+				Ipv4PoolId:      ipPoolId,
+				Ipv4PrefixLen:   24,
+			})
+			require.NoError(t, err)
+			require.Len(t, results, len(vns))
+
+			for _, result := range results {
+				require.NoError(t, result.Err)
+				vn, err := bp.GetVirtualNetwork(ctx, result.Id)
+				require.NoError(t, err)
+				require.Equal(t, result.Data.VnId, vn.Data.VnId)
+				require.Equal(t, result.Data.Ipv4Subnet.String(), vn.Data.Ipv4Subnet.String())
+				require.True(t, poolSubnet.Contains(vn.Data.Ipv4Subnet.IP))
+			}
+
+			log.Printf("testing CreateVirtualNetworks() rollback against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			vns[0].Label = randString(6, "hex")
+			vns[1].Label = randString(6, "hex")
+			vns[1].SecurityZoneId = "bogus"
+			results, err = bp.CreateVirtualNetworks(ctx, &BulkVirtualNetworkRequest{
+				VirtualNetworks: vns[:2],
+				VlanRanges:      map[ObjectId]VlanRange{szId: {First: 3000, Last: 3099}, "bogus": {First: 3100, Last: 3199}},
+				VniPoolId:       vniPoolId,
+				Ipv4PoolId:      ipPoolId,
+				Concurrency:     1,
+				RollbackOnError: true,
+			})
+			require.Error(t, err)
+			require.True(t, results[0].RolledBack)
+			require.Error(t, results[1].Err)
+
+			_, err = bp.GetVirtualNetwork(ctx, results[0].Id)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testVnPlanner(t testing.TB, existing ...VirtualNetworkData) *vnPlanner {
+	t.Helper()
+
+	_, poolSubnet, err := net.ParseCIDR("10.0.0.0/22")
+	require.NoError(t, err)
+
+	result := &vnPlanner{
+		vlanRanges:    map[ObjectId]VlanRange{"sz1": {First: 100, Last: 102}, "sz2": {First: 200, Last: 299}},
+		vniRanges:     []IntRangeRequest{{First: 10000, Last: 10001}, {First: 20000, Last: 20099}},
+		ipv4Subnets:   []*net.IPNet{poolSubnet},
+		ipv4PrefixLen: 24,
+		usedVlans:     make(map[Vlan]struct{}),
+		usedVnis:      make(map[VNI]struct{}),
+	}
+	for i := range existing {
+		result.reserve(&existing[i])
+	}
+	return result
+}
+
+func TestVnPlanner(t *testing.T) {
+	vlan100, vlan200 := Vlan(100), Vlan(200)
+	vni10000 := VNI(10000)
+	_, existingSubnet, err := net.ParseCIDR("10.0.1.0/24")
+	require.NoError(t, err)
+
+	planner := testVnPlanner(t, VirtualNetworkData{
+		SecurityZoneId: "sz1",
+		VnBindings:     []VnBinding{{SystemId: "leaf1", VlanId: &vlan100}},
+		VnId:           &vni10000,
+		Ipv4Enabled:    true,
+		Ipv4Subnet:     existingSubnet,
+	})
+
+	in := VirtualNetworkData{
+		Label:          "vn1",
+		SecurityZoneId: "sz1",
+		VnType:         VnTypeVxlan,
+		Ipv4Enabled:    true,
+		VnBindings: []VnBinding{
+			{SystemId: "leaf1"},
+			{SystemId: "leaf2", VlanId: &vlan200}, // caller-specified values are kept
+		},
+	}
+
+	planned, err := planner.plan(&in)
+	require.NoError(t, err)
+	require.Equal(t, Vlan(101), *planned.VnBindings[0].VlanId)
+	require.Equal(t, Vlan(200), *planned.VnBindings[1].VlanId)
+	require.Equal(t, VNI(10001), *planned.VnId)
+	require.Equal(t, "10.0.0.0/24", planned.Ipv4Subnet.String())
+
+	// the input is not modified
+	require.Nil(t, in.VnBindings[0].VlanId)
+	require.Nil(t, in.VnId)
+	require.Nil(t, in.Ipv4Subnet)
+
+	// planned values are not reused; the VNI pool moves on to its second
+	// range, subnets skip the existing allocation
+	planned, err = planner.plan(&in)
+	require.NoError(t, err)
+	require.Equal(t, Vlan(102), *planned.VnBindings[0].VlanId)
+	require.Equal(t, VNI(20000), *planned.VnId)
+	require.Equal(t, "10.0.2.0/24", planned.Ipv4Subnet.String())
+
+	// VLAN range for sz1 is exhausted; nothing is consumed by the failure
+	_, err = planner.plan(&in)
+	require.Error(t, err)
+
+	in.SecurityZoneId = "sz2"
+	planned, err = planner.plan(&in)
+	require.NoError(t, err)
+	require.Equal(t, Vlan(201), *planned.VnBindings[0].VlanId) // 200 is used by leaf2
+	require.Equal(t, VNI(20001), *planned.VnId)
+	require.Equal(t, "10.0.3.0/24", planned.Ipv4Subnet.String())
+
+	// IPv4 pool is exhausted
+	_, err = planner.plan(&in)
+	require.Error(t, err)
+
+	// VLAN networks without bindings or IPv4 need nothing
+	planned, err = planner.plan(&VirtualNetworkData{Label: "vn2", SecurityZoneId: "sz3", VnType: VnTypeVlan})
+	require.NoError(t, err)
+	require.Nil(t, planned.VnId)
+	require.Nil(t, planned.Ipv4Subnet)
+
+	// no VLAN range for the routing zone
+	_, err = planner.plan(&VirtualNetworkData{Label: "vn3", SecurityZoneId: "sz3", VnBindings: []VnBinding{{SystemId: "leaf1"}}})
+	require.Error(t, err)
+}
+
+func TestVnPlannerSubnetSizes(t *testing.T) {
+	_, existingSubnet, err := net.ParseCIDR("10.0.0.64/26")
+	require.NoError(t, err)
+
+	planner := testVnPlanner(t, VirtualNetworkData{Ipv4Subnet: existingSubnet})
+	planner.ipv4PrefixLen = 25
+
+	var subnets []string
+	for i := 0; i < 3; i++ {
+		planned, err := planner.plan(&VirtualNetworkData{Ipv4Enabled: true})
+		require.NoError(t, err)
+		subnets = append(subnets, planned.Ipv4Subnet.String())
+	}
+	require.Equal(t, []string{"10.0.0.128/25", "10.0.1.0/25", "10.0.1.128/25"}, subnets)
+
+	// pool subnets smaller than the requested size are skipped
+	planner = testVnPlanner(t)
+	planner.ipv4PrefixLen = 21
+	_, err = planner.plan(&VirtualNetworkData{Ipv4Enabled: true})
+	require.Error(t, err)
+}
+
+func TestVnPlannerOtherBlueprints(t *testing.T) {
+	vlan100 := Vlan(100)
+	vni10000 := VNI(10000)
+	szVni := 10001
+	_, otherSubnet, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+
+	// another blueprint's VNIs and subnets come from the same pools, but its
+	// VLAN IDs don't matter
+	planner := testVnPlanner(t)
+	planner.reserveInventory(&RoutingZoneInventory{
+		BlueprintId:   "other",
+		SecurityZones: []SecurityZone{{Id: "sz", Data: &SecurityZoneData{VlanId: &vlan100, VniId: &szVni}}},
+		VirtualNetworks: map[ObjectId]VirtualNetwork{"vn": {Id: "vn", Data: &VirtualNetworkData{
+			VnBindings: []VnBinding{{SystemId: "leaf1", VlanId: &vlan100}},
+			VnId:       &vni10000,
+			Ipv4Subnet: otherSubnet,
+		}}},
+	}, false)
+
+	planned, err := planner.plan(&VirtualNetworkData{
+		SecurityZoneId: "sz1",
+		VnType:         VnTypeVxlan,
+		Ipv4Enabled:    true,
+		VnBindings:     []VnBinding{{SystemId: "leaf1"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, Vlan(100), *planned.VnBindings[0].VlanId)
+	require.Equal(t, VNI(20000), *planned.VnId)
+	require.Equal(t, "10.0.1.0/24", planned.Ipv4Subnet.String())
+}
+
+func TestVlanRangeValidate(t *testing.T) {
+	require.NoError(t, VlanRange{First: 1, Last: 4094}.validate())
+	require.Error(t, VlanRange{First: 0, Last: 10}.validate())
+	require.Error(t, VlanRange{First: 10, Last: 4095}.validate())
+	require.Error(t, VlanRange{First: 20, Last: 10}.validate())
+}
+
+func TestBulkVirtualNetworkError(t *testing.T) {
+	require.NoError(t, bulkVirtualNetworkError([]BulkVirtualNetworkResult{{Id: "a"}, {Id: "b"}}))
+
+	failure := errors.New("failure")
+	err := bulkVirtualNetworkError([]BulkVirtualNetworkResult{
+		{Id: "a", RolledBack: true},
+		{Err: failure},
+		{Err: errBulkVirtualNetworkSkipped},
+	})
+	require.ErrorIs(t, err, failure)
+	require.Contains(t, err.Error(), "1 of 3")
+
+	err = bulkVirtualNetworkError([]BulkVirtualNetworkResult{
+		{Id: "a", RollbackErr: errors.New("rollback")},
+		{Err: failure},
+	})
+	require.ErrorIs(t, err, failure)
+	require.Contains(t, err.Error(), "failed rolling back 1")
+}
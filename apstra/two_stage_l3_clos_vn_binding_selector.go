@@ -0,0 +1,518 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// VnBindingSelector selects leaf and access switches to which a virtual
+// network should be bound. Every non-empty field must match (AND), while any
+// one value within a field may match (OR). The zero value selects all leaf
+// switches.
+//
+// A switch which is a member of an ESI or MLAG redundancy group is selected
+// along with its peer: the group, rather than the individual switch, appears
+// in the resulting VnBinding.
+type VnBindingSelector struct {
+	Roles              []SystemRole   // SystemRoleLeaf and/or SystemRoleAccess; empty selects leaf switches
+	RackIds            []ObjectId     // rack node IDs
+	Tags               []string       // tags applied to the switch, its redundancy group or its rack
+	RedundancyGroupIds []ObjectId     // redundancy group node IDs
+	HostnameRegex      *regexp.Regexp // matched against the switch hostname
+}
+
+func (o *VnBindingSelector) validate() error {
+	for _, role := range o.Roles {
+		if role != SystemRoleLeaf && role != SystemRoleAccess {
+			return fmt.Errorf("VN binding selector role must be %q or %q, got %q", SystemRoleLeaf, SystemRoleAccess, role)
+		}
+	}
+	return nil
+}
+
+func (o *VnBindingSelector) matches(sw *vnBindingSwitch) bool {
+	roles := o.Roles
+	if len(roles) == 0 {
+		roles = []SystemRole{SystemRoleLeaf}
+	}
+	if !itemInSlice(sw.role, roles) {
+		return false
+	}
+
+	if len(o.RackIds) > 0 && !itemInSlice(sw.rackId, o.RackIds) {
+		return false
+	}
+
+	if len(o.RedundancyGroupIds) > 0 && !itemInSlice(sw.redundancyGroupId, o.RedundancyGroupIds) {
+		return false
+	}
+
+	if len(o.Tags) > 0 {
+		var tagged bool
+		for _, tag := range o.Tags {
+			if sw.tags[tag] {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false
+		}
+	}
+
+	if o.HostnameRegex != nil && !o.HostnameRegex.MatchString(sw.hostname) {
+		return false
+	}
+
+	return true
+}
+
+// vnBindingSwitch is a leaf or access switch, with the details needed to
+// evaluate a VnBindingSelector.
+type vnBindingSwitch struct {
+	id                ObjectId
+	role              SystemRole
+	hostname          string
+	rackId            ObjectId
+	redundancyGroupId ObjectId
+	tags              map[string]bool
+	leafIds           []ObjectId // leaf switches linked to an access switch
+}
+
+// unitId returns the ID used in a VnBinding: the redundancy group ID for
+// members of ESI and MLAG pairs, otherwise the switch ID.
+func (o *vnBindingSwitch) unitId() ObjectId {
+	if o.redundancyGroupId != "" {
+		return o.redundancyGroupId
+	}
+	return o.id
+}
+
+// vnBindingFabric is the collection of leaf and access switches in a
+// blueprint, keyed by system node ID.
+type vnBindingFabric map[ObjectId]*vnBindingSwitch
+
+// vnBindingSelection is the result of evaluating VnBindingSelectors: leaf
+// units, and access units along with the leaf units they're linked to.
+type vnBindingSelection struct {
+	leafUnits   map[ObjectId]struct{}
+	accessUnits map[ObjectId][]ObjectId
+}
+
+func (o vnBindingFabric) selection(selectors []VnBindingSelector) (*vnBindingSelection, error) {
+	for i := range selectors {
+		err := selectors[i].validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &vnBindingSelection{
+		leafUnits:   make(map[ObjectId]struct{}),
+		accessUnits: make(map[ObjectId][]ObjectId),
+	}
+
+	for _, id := range sortedKeys(o) {
+		sw := o[id]
+		var selected bool
+		for i := range selectors {
+			if selectors[i].matches(sw) {
+				selected = true
+				break
+			}
+		}
+		if !selected {
+			continue
+		}
+
+		switch sw.role {
+		case SystemRoleLeaf:
+			result.leafUnits[sw.unitId()] = struct{}{}
+		case SystemRoleAccess:
+			leafUnits, err := o.leafUnitsOf(sw)
+			if err != nil {
+				return nil, err
+			}
+			for _, leafUnit := range leafUnits {
+				if !itemInSlice(leafUnit, result.accessUnits[sw.unitId()]) {
+					result.accessUnits[sw.unitId()] = append(result.accessUnits[sw.unitId()], leafUnit)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// leafUnitsOf returns the leaf units to which an access switch is linked
+func (o vnBindingFabric) leafUnitsOf(access *vnBindingSwitch) ([]ObjectId, error) {
+	var result []ObjectId
+	for _, leafId := range access.leafIds {
+		leaf, ok := o[leafId]
+		if !ok {
+			return nil, fmt.Errorf("access switch %q is linked to unknown leaf switch %q", access.id, leafId)
+		}
+		if !itemInSlice(leaf.unitId(), result) {
+			result = append(result, leaf.unitId())
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("access switch %q is not linked to any leaf switch", access.id)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result, nil
+}
+
+// ids returns the IDs of the selected leaf and access units
+func (o *vnBindingSelection) ids() []ObjectId {
+	result := make([]ObjectId, 0, len(o.leafUnits)+len(o.accessUnits))
+	result = append(result, sortedKeys(o.leafUnits)...)
+	result = append(result, sortedKeys(o.accessUnits)...)
+	return result
+}
+
+// bindings renders the selection as VnBindings. Selected access units are
+// bound along with the leaf units they're linked to.
+func (o *vnBindingSelection) bindings(vlanId *Vlan) []VnBinding {
+	accessByLeaf := make(map[ObjectId][]ObjectId, len(o.leafUnits))
+	for leafUnit := range o.leafUnits {
+		accessByLeaf[leafUnit] = nil
+	}
+	for _, accessUnit := range sortedKeys(o.accessUnits) {
+		for _, leafUnit := range o.accessUnits[accessUnit] {
+			accessByLeaf[leafUnit] = append(accessByLeaf[leafUnit], accessUnit)
+		}
+	}
+
+	result := make([]VnBinding, 0, len(accessByLeaf))
+	for _, leafUnit := range sortedKeys(accessByLeaf) {
+		accessSwitchNodeIds := accessByLeaf[leafUnit]
+		if accessSwitchNodeIds == nil {
+			accessSwitchNodeIds = []ObjectId{}
+		}
+		result = append(result, VnBinding{
+			SystemId:            leafUnit,
+			AccessSwitchNodeIds: accessSwitchNodeIds,
+			VlanId:              vlanId,
+		})
+	}
+
+	return result
+}
+
+// mergeVnBindings adds bindings to existing, combining the access switches of
+// bindings to the same leaf unit. Conflicting VLAN IDs produce an error.
+func mergeVnBindings(existing, add []VnBinding) ([]VnBinding, error) {
+	result := make([]VnBinding, len(existing))
+	indexBySystemId := make(map[ObjectId]int, len(existing))
+	for i, binding := range existing {
+		result[i] = binding
+		result[i].AccessSwitchNodeIds = append([]ObjectId{}, binding.AccessSwitchNodeIds...)
+		indexBySystemId[binding.SystemId] = i
+	}
+
+	for _, binding := range add {
+		i, ok := indexBySystemId[binding.SystemId]
+		if !ok {
+			binding.AccessSwitchNodeIds = append([]ObjectId{}, binding.AccessSwitchNodeIds...)
+			indexBySystemId[binding.SystemId] = len(result)
+			result = append(result, binding)
+			continue
+		}
+
+		switch {
+		case binding.VlanId == nil:
+		case result[i].VlanId == nil:
+			result[i].VlanId = binding.VlanId
+		case *result[i].VlanId != *binding.VlanId:
+			return nil, fmt.Errorf("cannot bind %q with VLAN %d: already bound with VLAN %d",
+				binding.SystemId, *binding.VlanId, *result[i].VlanId)
+		}
+
+		for _, accessId := range binding.AccessSwitchNodeIds {
+			if !itemInSlice(accessId, result[i].AccessSwitchNodeIds) {
+				result[i].AccessSwitchNodeIds = append(result[i].AccessSwitchNodeIds, accessId)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// removeVnBindings removes bindings to the leaf units named in ids (along
+// with their access switches), and removes access units named in ids from
+// the remaining bindings.
+func removeVnBindings(existing []VnBinding, ids []ObjectId) []VnBinding {
+	result := make([]VnBinding, 0, len(existing))
+	for _, binding := range existing {
+		if itemInSlice(binding.SystemId, ids) {
+			continue
+		}
+
+		accessSwitchNodeIds := make([]ObjectId, 0, len(binding.AccessSwitchNodeIds))
+		for _, accessId := range binding.AccessSwitchNodeIds {
+			if !itemInSlice(accessId, ids) {
+				accessSwitchNodeIds = append(accessSwitchNodeIds, accessId)
+			}
+		}
+		binding.AccessSwitchNodeIds = accessSwitchNodeIds
+		result = append(result, binding)
+	}
+
+	return result
+}
+
+// getVnBindingFabric collects the leaf and access switches of the blueprint
+// along with their racks, redundancy groups, tags and (for access switches)
+// leaf uplinks.
+func (o *TwoStageL3ClosClient) getVnBindingFabric(ctx context.Context) (vnBindingFabric, error) {
+	systemQuery := new(MatchQuery).
+		SetBlueprintId(o.blueprintId).
+		SetBlueprintType(BlueprintTypeStaging).
+		SetClient(o.client).
+		Match(new(PathQuery).
+			Node([]QEEAttribute{
+				NodeTypeSystem.QEEAttribute(),
+				{"role", QEStringValIsIn{SystemRoleLeaf.String(), SystemRoleAccess.String()}},
+				{"name", QEStringVal("n_system")},
+			})).
+		Optional(new(PathQuery).
+			Node([]QEEAttribute{{"name", QEStringVal("n_system")}}).
+			Out([]QEEAttribute{RelationshipTypePartOfRack.QEEAttribute()}).
+			Node([]QEEAttribute{NodeTypeRack.QEEAttribute(), {"name", QEStringVal("n_rack")}})).
+		Optional(new(PathQuery).
+			Node([]QEEAttribute{{"name", QEStringVal("n_system")}}).
+			In([]QEEAttribute{RelationshipTypeComposedOfSystems.QEEAttribute()}).
+			Node([]QEEAttribute{NodeTypeRedundancyGroup.QEEAttribute(), {"name", QEStringVal("n_redundancy_group")}}))
+
+	var systemResponse struct {
+		Items []struct {
+			System struct {
+				Id       ObjectId `json:"id"`
+				Role     string   `json:"role"`
+				Hostname string   `json:"hostname"`
+			} `json:"n_system"`
+			Rack *struct {
+				Id ObjectId `json:"id"`
+			} `json:"n_rack"`
+			RedundancyGroup *struct {
+				Id ObjectId `json:"id"`
+			} `json:"n_redundancy_group"`
+		} `json:"items"`
+	}
+
+	err := systemQuery.Do(ctx, &systemResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying switches - %w", convertTtaeToAceWherePossible(err))
+	}
+
+	result := make(vnBindingFabric, len(systemResponse.Items))
+	for _, item := range systemResponse.Items {
+		var role SystemRole
+		err = role.FromString(item.System.Role)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing role of switch %q - %w", item.System.Id, err)
+		}
+
+		sw := &vnBindingSwitch{
+			id:       item.System.Id,
+			role:     role,
+			hostname: item.System.Hostname,
+			tags:     make(map[string]bool),
+		}
+		if item.Rack != nil {
+			sw.rackId = item.Rack.Id
+		}
+		if item.RedundancyGroup != nil {
+			sw.redundancyGroupId = item.RedundancyGroup.Id
+		}
+		result[sw.id] = sw
+	}
+
+	tagQuery := new(PathQuery).
+		SetBlueprintId(o.blueprintId).
+		SetBlueprintType(BlueprintTypeStaging).
+		SetClient(o.client).
+		Node([]QEEAttribute{NodeTypeTag.QEEAttribute(), {"name", QEStringVal("n_tag")}}).
+		Out([]QEEAttribute{RelationshipTypeTag.QEEAttribute()}).
+		Node([]QEEAttribute{{"name", QEStringVal("n_target")}})
+
+	var tagResponse struct {
+		Items []struct {
+			Tag struct {
+				Label string `json:"label"`
+			} `json:"n_tag"`
+			Target struct {
+				Id ObjectId `json:"id"`
+			} `json:"n_target"`
+		} `json:"items"`
+	}
+
+	err = tagQuery.Do(ctx, &tagResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying tags - %w", convertTtaeToAceWherePossible(err))
+	}
+
+	tagsByNodeId := make(map[ObjectId][]string)
+	for _, item := range tagResponse.Items {
+		tagsByNodeId[item.Target.Id] = append(tagsByNodeId[item.Target.Id], item.Tag.Label)
+	}
+
+	for _, sw := range result {
+		for _, nodeId := range []ObjectId{sw.id, sw.rackId, sw.redundancyGroupId} {
+			if nodeId == "" {
+				continue
+			}
+			for _, tag := range tagsByNodeId[nodeId] {
+				sw.tags[tag] = true
+			}
+		}
+	}
+
+	uplinkQuery := new(PathQuery).
+		SetBlueprintId(o.blueprintId).
+		SetBlueprintType(BlueprintTypeStaging).
+		SetClient(o.client).
+		Node([]QEEAttribute{
+			NodeTypeSystem.QEEAttribute(),
+			{"role", QEStringVal(SystemRoleAccess.String())},
+			{"name", QEStringVal("n_access")},
+		}).
+		Out([]QEEAttribute{RelationshipTypeHostedInterfaces.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeInterface.QEEAttribute()}).
+		Out([]QEEAttribute{RelationshipTypeLink.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeLink.QEEAttribute()}).
+		In([]QEEAttribute{RelationshipTypeLink.QEEAttribute()}).
+		Node([]QEEAttribute{NodeTypeInterface.QEEAttribute()}).
+		In([]QEEAttribute{RelationshipTypeHostedInterfaces.QEEAttribute()}).
+		Node([]QEEAttribute{
+			NodeTypeSystem.QEEAttribute(),
+			{"role", QEStringVal(SystemRoleLeaf.String())},
+			{"name", QEStringVal("n_leaf")},
+		})
+
+	var uplinkResponse struct {
+		Items []struct {
+			Access struct {
+				Id ObjectId `json:"id"`
+			} `json:"n_access"`
+			Leaf struct {
+				Id ObjectId `json:"id"`
+			} `json:"n_leaf"`
+		} `json:"items"`
+	}
+
+	err = uplinkQuery.Do(ctx, &uplinkResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying access switch uplinks - %w", convertTtaeToAceWherePossible(err))
+	}
+
+	for _, item := range uplinkResponse.Items {
+		access, ok := result[item.Access.Id]
+		if !ok {
+			continue
+		}
+		if !itemInSlice(item.Leaf.Id, access.leafIds) {
+			access.leafIds = append(access.leafIds, item.Leaf.Id)
+		}
+	}
+
+	return result, nil
+}
+
+// ResolveVnBindings returns bindings for the switches matched by any of the
+// selectors. Selected access switches are bound along with the leaf switches
+// they're linked to. vlanId may be nil (auto-assign).
+func (o *TwoStageL3ClosClient) ResolveVnBindings(ctx context.Context, vlanId *Vlan, selectors ...VnBindingSelector) ([]VnBinding, error) {
+	if len(selectors) == 0 {
+		return nil, errors.New("at least one VN binding selector is required")
+	}
+
+	fabric, err := o.getVnBindingFabric(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selection, err := fabric.selection(selectors)
+	if err != nil {
+		return nil, err
+	}
+
+	return selection.bindings(vlanId), nil
+}
+
+// AddVnBindings adds bindings to the virtual network with the given ID,
+// leaving its other bindings and settings unchanged. Bindings to a leaf unit
+// which is already bound are combined. The read-modify-write is not atomic;
+// use the blueprint Mutex to guard against concurrent changes.
+func (o *TwoStageL3ClosClient) AddVnBindings(ctx context.Context, vnId ObjectId, bindings []VnBinding) error {
+	vn, err := o.GetVirtualNetwork(ctx, vnId)
+	if err != nil {
+		return fmt.Errorf("failed fetching virtual network %q - %w", vnId, err)
+	}
+
+	vn.Data.VnBindings, err = mergeVnBindings(vn.Data.VnBindings, bindings)
+	if err != nil {
+		return fmt.Errorf("failed adding bindings to virtual network %q - %w", vnId, err)
+	}
+
+	return o.UpdateVirtualNetwork(ctx, vnId, vn.Data)
+}
+
+// RemoveVnBindings removes bindings from the virtual network with the given
+// ID. Each ID may be a leaf switch or leaf redundancy group, in which case its
+// binding (including access switches) is removed, or an access switch or
+// access redundancy group, in which case only the access switch is removed.
+// See AddVnBindings regarding concurrent changes.
+func (o *TwoStageL3ClosClient) RemoveVnBindings(ctx context.Context, vnId ObjectId, ids []ObjectId) error {
+	vn, err := o.GetVirtualNetwork(ctx, vnId)
+	if err != nil {
+		return fmt.Errorf("failed fetching virtual network %q - %w", vnId, err)
+	}
+
+	vn.Data.VnBindings = removeVnBindings(vn.Data.VnBindings, ids)
+
+	return o.UpdateVirtualNetwork(ctx, vnId, vn.Data)
+}
+
+// BindVirtualNetwork binds the virtual network with the given ID to the
+// switches matched by any of the selectors. See ResolveVnBindings and
+// AddVnBindings.
+func (o *TwoStageL3ClosClient) BindVirtualNetwork(ctx context.Context, vnId ObjectId, vlanId *Vlan, selectors ...VnBindingSelector) error {
+	bindings, err := o.ResolveVnBindings(ctx, vlanId, selectors...)
+	if err != nil {
+		return err
+	}
+
+	return o.AddVnBindings(ctx, vnId, bindings)
+}
+
+// UnbindVirtualNetwork unbinds the virtual network with the given ID from the
+// switches matched by any of the selectors. Unbinding a leaf switch unbinds
+// the access switches linked to it. See RemoveVnBindings.
+func (o *TwoStageL3ClosClient) UnbindVirtualNetwork(ctx context.Context, vnId ObjectId, selectors ...VnBindingSelector) error {
+	if len(selectors) == 0 {
+		return errors.New("at least one VN binding selector is required")
+	}
+
+	fabric, err := o.getVnBindingFabric(ctx)
+	if err != nil {
+		return err
+	}
+
+	selection, err := fabric.selection(selectors)
+	if err != nil {
+		return err
+	}
+
+	return o.RemoveVnBindings(ctx, vnId, selection.ids())
+}
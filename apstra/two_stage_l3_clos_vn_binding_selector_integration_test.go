@@ -0,0 +1,65 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package apstra
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVnBindingSelectors(t *testing.T) {
+	ctx := context.Background()
+
+	clients, err := getTestClients(ctx, t)
+	require.NoError(t, err)
+
+	for clientName, client := range clients {
+		clientName, client := clientName, client
+		t.Run(fmt.Sprintf("%s_%s", client.client.apiVersion, clientName), func(t *testing.T) {
+			t.Parallel()
+
+			bp := testBlueprintA(ctx, t, client.client)
+			szId := testSecurityZone(t, ctx, bp)
+
+			log.Printf("testing ResolveVnBindings() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			bindings, err := bp.ResolveVnBindings(ctx, nil, VnBindingSelector{})
+			require.NoError(t, err)
+			require.NotEmpty(t, bindings)
+
+			vnId, err := bp.CreateVirtualNetwork(ctx, &VirtualNetworkData{
+				Ipv4Enabled:               true,
+				Label:                     randString(6, "hex"),
+				SecurityZoneId:            szId,
+				VirtualGatewayIpv4Enabled: true,
+				VnBindings:                bindings[:1],
+				VnType:                    VnTypeVxlan,
+			})
+			require.NoError(t, err)
+
+			log.Printf("testing BindVirtualNetwork() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, bp.BindVirtualNetwork(ctx, vnId, nil, VnBindingSelector{}))
+
+			vn, err := bp.GetVirtualNetwork(ctx, vnId)
+			require.NoError(t, err)
+			require.Len(t, vn.Data.VnBindings, len(bindings))
+
+			log.Printf("testing RemoveVnBindings() against %s %s (%s)", client.clientType, clientName, client.client.ApiVersion())
+			require.NoError(t, bp.RemoveVnBindings(ctx, vnId, []ObjectId{bindings[0].SystemId}))
+
+			vn, err = bp.GetVirtualNetwork(ctx, vnId)
+			require.NoError(t, err)
+			require.Len(t, vn.Data.VnBindings, len(bindings)-1)
+			for _, binding := range vn.Data.VnBindings {
+				require.NotEqual(t, bindings[0].SystemId, binding.SystemId)
+			}
+		})
+	}
+}
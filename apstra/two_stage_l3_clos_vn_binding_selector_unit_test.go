@@ -0,0 +1,154 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+package apstra
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testVnBindingFabric has three racks:
+//   - rack1: ESI leaf pair (rg_leaf1) with an ESI access pair (rg_access1)
+//   - rack2: single leaf, rack tagged "prod"
+//   - rack3: single leaf tagged "edge" with a single access switch
+func testVnBindingFabric() vnBindingFabric {
+	return vnBindingFabric{
+		"leaf1a":   {id: "leaf1a", role: SystemRoleLeaf, hostname: "rack1-leaf-a", rackId: "rack1", redundancyGroupId: "rg_leaf1", tags: map[string]bool{}},
+		"leaf1b":   {id: "leaf1b", role: SystemRoleLeaf, hostname: "rack1-leaf-b", rackId: "rack1", redundancyGroupId: "rg_leaf1", tags: map[string]bool{}},
+		"access1a": {id: "access1a", role: SystemRoleAccess, hostname: "rack1-access-a", rackId: "rack1", redundancyGroupId: "rg_access1", tags: map[string]bool{}, leafIds: []ObjectId{"leaf1a", "leaf1b"}},
+		"access1b": {id: "access1b", role: SystemRoleAccess, hostname: "rack1-access-b", rackId: "rack1", redundancyGroupId: "rg_access1", tags: map[string]bool{}, leafIds: []ObjectId{"leaf1a", "leaf1b"}},
+		"leaf2":    {id: "leaf2", role: SystemRoleLeaf, hostname: "rack2-leaf", rackId: "rack2", tags: map[string]bool{"prod": true}},
+		"leaf3":    {id: "leaf3", role: SystemRoleLeaf, hostname: "rack3-leaf", rackId: "rack3", tags: map[string]bool{"edge": true}},
+		"access3":  {id: "access3", role: SystemRoleAccess, hostname: "rack3-access", rackId: "rack3", tags: map[string]bool{}, leafIds: []ObjectId{"leaf3"}},
+	}
+}
+
+func TestVnBindingSelection(t *testing.T) {
+	vlan := Vlan(100)
+
+	type testCase struct {
+		selectors []VnBindingSelector
+		expected  []VnBinding
+		ids       []ObjectId
+		errors    bool
+	}
+
+	testCases := map[string]testCase{
+		"all_leafs": {
+			selectors: []VnBindingSelector{{}},
+			expected: []VnBinding{
+				{SystemId: "leaf2", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan},
+				{SystemId: "leaf3", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan},
+				{SystemId: "rg_leaf1", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan},
+			},
+			ids: []ObjectId{"leaf2", "leaf3", "rg_leaf1"},
+		},
+		"rack_tag": {
+			selectors: []VnBindingSelector{{Tags: []string{"prod"}}},
+			expected:  []VnBinding{{SystemId: "leaf2", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan}},
+			ids:       []ObjectId{"leaf2"},
+		},
+		"pair_member_by_hostname": {
+			selectors: []VnBindingSelector{{HostnameRegex: regexp.MustCompile(`-leaf-b$`)}},
+			expected:  []VnBinding{{SystemId: "rg_leaf1", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan}},
+			ids:       []ObjectId{"rg_leaf1"},
+		},
+		"access_by_rack": {
+			selectors: []VnBindingSelector{{Roles: []SystemRole{SystemRoleAccess}, RackIds: []ObjectId{"rack1"}}},
+			expected:  []VnBinding{{SystemId: "rg_leaf1", AccessSwitchNodeIds: []ObjectId{"rg_access1"}, VlanId: &vlan}},
+			ids:       []ObjectId{"rg_access1"},
+		},
+		"leaf_and_access_union": {
+			selectors: []VnBindingSelector{
+				{Roles: []SystemRole{SystemRoleLeaf, SystemRoleAccess}, RackIds: []ObjectId{"rack3"}},
+				{RedundancyGroupIds: []ObjectId{"rg_leaf1"}},
+			},
+			expected: []VnBinding{
+				{SystemId: "leaf3", AccessSwitchNodeIds: []ObjectId{"access3"}, VlanId: &vlan},
+				{SystemId: "rg_leaf1", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan},
+			},
+			ids: []ObjectId{"leaf3", "rg_leaf1", "access3"},
+		},
+		"and_within_selector": {
+			selectors: []VnBindingSelector{{Tags: []string{"edge"}, RackIds: []ObjectId{"rack2"}}},
+			expected:  []VnBinding{},
+			ids:       []ObjectId{},
+		},
+		"bad_role": {
+			selectors: []VnBindingSelector{{Roles: []SystemRole{SystemRoleSpine}}},
+			errors:    true,
+		},
+	}
+
+	fabric := testVnBindingFabric()
+	for tName, tCase := range testCases {
+		tName, tCase := tName, tCase
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+
+			selection, err := fabric.selection(tCase.selectors)
+			if tCase.errors {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tCase.expected, selection.bindings(&vlan))
+			require.Equal(t, tCase.ids, selection.ids())
+		})
+	}
+}
+
+func TestVnBindingSelectionUnlinkedAccess(t *testing.T) {
+	fabric := testVnBindingFabric()
+	fabric["access3"].leafIds = nil
+
+	_, err := fabric.selection([]VnBindingSelector{{Roles: []SystemRole{SystemRoleAccess}}})
+	require.Error(t, err)
+}
+
+func TestMergeVnBindings(t *testing.T) {
+	vlan100, vlan200 := Vlan(100), Vlan(200)
+
+	existing := []VnBinding{
+		{SystemId: "leaf1", AccessSwitchNodeIds: []ObjectId{"access1"}, VlanId: &vlan100},
+		{SystemId: "leaf2", AccessSwitchNodeIds: []ObjectId{}},
+	}
+
+	result, err := mergeVnBindings(existing, []VnBinding{
+		{SystemId: "leaf1", AccessSwitchNodeIds: []ObjectId{"access1", "access2"}},
+		{SystemId: "leaf2", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan200},
+		{SystemId: "leaf3", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan100},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []VnBinding{
+		{SystemId: "leaf1", AccessSwitchNodeIds: []ObjectId{"access1", "access2"}, VlanId: &vlan100},
+		{SystemId: "leaf2", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan200},
+		{SystemId: "leaf3", AccessSwitchNodeIds: []ObjectId{}, VlanId: &vlan100},
+	}, result)
+
+	// existing bindings are not modified
+	require.Equal(t, []ObjectId{"access1"}, existing[0].AccessSwitchNodeIds)
+	require.Nil(t, existing[1].VlanId)
+
+	_, err = mergeVnBindings(existing, []VnBinding{{SystemId: "leaf1", VlanId: &vlan200}})
+	require.Error(t, err)
+}
+
+func TestRemoveVnBindings(t *testing.T) {
+	existing := []VnBinding{
+		{SystemId: "leaf1", AccessSwitchNodeIds: []ObjectId{"access1", "access2"}},
+		{SystemId: "leaf2", AccessSwitchNodeIds: []ObjectId{"access3"}},
+		{SystemId: "leaf3", AccessSwitchNodeIds: []ObjectId{}},
+	}
+
+	require.Equal(t, []VnBinding{
+		{SystemId: "leaf1", AccessSwitchNodeIds: []ObjectId{"access2"}},
+		{SystemId: "leaf3", AccessSwitchNodeIds: []ObjectId{}},
+	}, removeVnBindings(existing, []ObjectId{"leaf2", "access1"}))
+
+	require.Equal(t, existing, removeVnBindings(existing, []ObjectId{"unknown"}))
+}
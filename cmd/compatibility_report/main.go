@@ -0,0 +1,17 @@
+// Copyright (c) Juniper Networks, Inc., 2024-2024.
+// All rights reserved.
+// SPDX-License-Identifier: Apache-2.0
+
+// compatibility_report writes a markdown table describing which
+// version-dependent SDK features work with each supported Apstra version.
+package main
+
+import (
+	"fmt"
+
+	"github.com/Juniper/apstra-go-sdk/apstra"
+)
+
+func main() {
+	fmt.Print(apstra.GetCompatibilityReport().Markdown())
+}